@@ -13,6 +13,9 @@ import (
 	"strings"
 	"time"
 
+	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/crypto/remote"
+	"github.com/ethersphere/bee/pkg/localstore"
 	"github.com/ethersphere/bee/pkg/log"
 	"github.com/ethersphere/bee/pkg/node"
 	"github.com/ethersphere/bee/pkg/swarm"
@@ -23,10 +26,15 @@ import (
 const (
 	optionNameDataDir                    = "data-dir"
 	optionNameCacheCapacity              = "cache-capacity"
+	optionNameCacheGCPolicy              = "cache-gc-policy"
+	optionNameCacheMemorySize            = "cache-memory-size"
 	optionNameDBOpenFilesLimit           = "db-open-files-limit"
 	optionNameDBBlockCacheCapacity       = "db-block-cache-capacity"
 	optionNameDBWriteBufferSize          = "db-write-buffer-size"
 	optionNameDBDisableSeeksCompaction   = "db-disable-seeks-compaction"
+	optionNameDBFreeSpaceSoftLimit       = "db-free-space-soft-limit"
+	optionNameDBFreeSpaceHardLimit       = "db-free-space-hard-limit"
+	optionNameDBScrubInterval            = "db-scrub-interval"
 	optionNamePassword                   = "password"
 	optionNamePasswordFile               = "password-file"
 	optionNameAPIAddr                    = "api-addr"
@@ -54,7 +62,16 @@ const (
 	optionNameClefSignerEnable           = "clef-signer-enable"
 	optionNameClefSignerEndpoint         = "clef-signer-endpoint"
 	optionNameClefSignerEthereumAddress  = "clef-signer-ethereum-address"
+	optionNameRemoteSignerEnable         = "remote-signer-enable"
+	optionNameRemoteSignerEndpoint       = "remote-signer-endpoint"
+	optionNameRemoteSignerEthAddress     = "remote-signer-ethereum-address"
+	optionNameRemoteSignerTimeout        = "remote-signer-timeout"
+	optionNameRemoteSignerTLSCert        = "remote-signer-tls-cert"
+	optionNameRemoteSignerTLSKey         = "remote-signer-tls-key"
+	optionNameRemoteSignerTLSCA          = "remote-signer-tls-ca"
 	optionNameSwapEndpoint               = "swap-endpoint"
+	optionNameSwapFallbackEndpoints      = "swap-fallback-endpoints"
+	optionNameSwapEndpointQuorum         = "swap-endpoint-quorum"
 	optionNameSwapFactoryAddress         = "swap-factory-address"
 	optionNameSwapLegacyFactoryAddresses = "swap-legacy-factory-addresses"
 	optionNameSwapInitialDeposit         = "swap-initial-deposit"
@@ -63,8 +80,17 @@ const (
 	optionNameTransactionHash            = "transaction"
 	optionNameBlockHash                  = "block-hash"
 	optionNameSwapDeploymentGasPrice     = "swap-deployment-gas-price"
+	optionNameTransactionMaxFee          = "transaction-max-fee"
+	optionNameTransactionPriorityFee     = "transaction-priority-fee"
+	optionNameTransactionStuckTimeout    = "transaction-stuck-timeout"
+	optionNameTransactionFeeBump         = "transaction-fee-bump"
+	optionNameTransactionBumpMaxFee      = "transaction-bump-max-fee"
 	optionNameFullNode                   = "full-node"
 	optionNamePostageContractAddress     = "postage-stamp-address"
+	optionNamePostagePolicyBudget        = "postage-policy-budget"
+	optionNamePostagePolicyInterval      = "postage-policy-interval"
+	optionNamePostageEventsWebhook       = "postage-events-webhook"
+	optionNamePostageExpiryWarnings      = "postage-expiry-warnings"
 	optionNamePriceOracleAddress         = "price-oracle-address"
 	optionNameBlockTime                  = "block-time"
 	optionWarmUpTime                     = "warmup-time"
@@ -81,6 +107,9 @@ const (
 	optionNameTokenEncryptionKey         = "token-encryption-key"
 	optionNameAdminPasswordHash          = "admin-password"
 	optionNameUsePostageSnapshot         = "use-postage-snapshot"
+	optionNameUploadSessionExpiry        = "upload-session-expiry"
+	optionNameUploadZipMaxArchiveSize    = "upload-zip-max-archive-size"
+	optionNameUploadZipMaxSize           = "upload-zip-max-size"
 )
 
 func init() {
@@ -146,6 +175,9 @@ func newCommand(opts ...option) (c *command, err error) {
 
 	c.initVersionCmd()
 	c.initDBCmd()
+	c.initStatestoreCmd()
+	c.initStampCmd()
+	c.initPostageCmd()
 
 	if err := c.initConfigurateOptionsCmd(); err != nil {
 		return nil, err
@@ -219,10 +251,15 @@ func (c *command) setHomeDir() (err error) {
 func (c *command) setAllFlags(cmd *cobra.Command) {
 	cmd.Flags().String(optionNameDataDir, filepath.Join(c.homeDir, ".bee"), "data directory")
 	cmd.Flags().Uint64(optionNameCacheCapacity, 1000000, fmt.Sprintf("cache capacity in chunks, multiply by %d to get approximate capacity in bytes", swarm.ChunkSize))
+	cmd.Flags().String(optionNameCacheGCPolicy, localstore.GCPolicyLRU, fmt.Sprintf("cache garbage collection policy, one of %q, %q", localstore.GCPolicyLRU, localstore.GCPolicyLFU))
+	cmd.Flags().Uint64(optionNameCacheMemorySize, 0, "size of the in-memory cache of the requested chunks in bytes, 0 to disable")
 	cmd.Flags().Uint64(optionNameDBOpenFilesLimit, 200, "number of open files allowed by database")
 	cmd.Flags().Uint64(optionNameDBBlockCacheCapacity, 32*1024*1024, "size of block cache of the database in bytes")
 	cmd.Flags().Uint64(optionNameDBWriteBufferSize, 32*1024*1024, "size of the database write buffer in bytes")
 	cmd.Flags().Bool(optionNameDBDisableSeeksCompaction, false, "disables db compactions triggered by seeks")
+	cmd.Flags().Uint64(optionNameDBFreeSpaceSoftLimit, 0, "free disk space in bytes below which syncing is paused and garbage collection is forced, 0 to disable")
+	cmd.Flags().Uint64(optionNameDBFreeSpaceHardLimit, 0, "free disk space in bytes below which uploads are rejected, 0 to disable")
+	cmd.Flags().Duration(optionNameDBScrubInterval, 0, "time between validations of the pinned chunks, including the reserve, which refetch the corrupted ones from the network, 0 to disable")
 	cmd.Flags().String(optionNamePassword, "", "password for decrypting keys")
 	cmd.Flags().String(optionNamePasswordFile, "", "path to a file that contains password for decrypting keys")
 	cmd.Flags().String(optionNameAPIAddr, ":1633", "HTTP API listen address")
@@ -250,7 +287,16 @@ func (c *command) setAllFlags(cmd *cobra.Command) {
 	cmd.Flags().Bool(optionNameClefSignerEnable, false, "enable clef signer")
 	cmd.Flags().String(optionNameClefSignerEndpoint, "", "clef signer endpoint")
 	cmd.Flags().String(optionNameClefSignerEthereumAddress, "", "ethereum address to use from clef signer")
+	cmd.Flags().Bool(optionNameRemoteSignerEnable, false, "enable remote http signer")
+	cmd.Flags().String(optionNameRemoteSignerEndpoint, "", "remote http signer endpoint")
+	cmd.Flags().String(optionNameRemoteSignerEthAddress, "", "ethereum address to use from remote http signer")
+	cmd.Flags().Duration(optionNameRemoteSignerTimeout, remote.DefaultTimeout, "remote http signer request timeout")
+	cmd.Flags().String(optionNameRemoteSignerTLSCert, "", "path of the client certificate for the remote http signer")
+	cmd.Flags().String(optionNameRemoteSignerTLSKey, "", "path of the client certificate key for the remote http signer")
+	cmd.Flags().String(optionNameRemoteSignerTLSCA, "", "path of the ca certificate to verify the remote http signer with")
 	cmd.Flags().String(optionNameSwapEndpoint, "", "swap ethereum blockchain endpoint")
+	cmd.Flags().StringSlice(optionNameSwapFallbackEndpoints, nil, "swap ethereum blockchain endpoints to fail over to, in order of priority")
+	cmd.Flags().Int(optionNameSwapEndpointQuorum, 0, "number of swap endpoints which must agree on the block number and the logs")
 	cmd.Flags().String(optionNameSwapFactoryAddress, "", "swap factory addresses")
 	cmd.Flags().StringSlice(optionNameSwapLegacyFactoryAddresses, nil, "legacy swap factory addresses")
 	cmd.Flags().String(optionNameSwapInitialDeposit, "10000000000000000", "initial deposit if deploying a new chequebook")
@@ -258,14 +304,26 @@ func (c *command) setAllFlags(cmd *cobra.Command) {
 	cmd.Flags().Bool(optionNameChequebookEnable, true, "enable chequebook")
 	cmd.Flags().Bool(optionNameFullNode, false, "cause the node to start in full mode")
 	cmd.Flags().String(optionNamePostageContractAddress, "", "postage stamp contract address")
+	cmd.Flags().String(optionNamePostagePolicyBudget, "0", "total amount in PLUR that the automatic top-ups of the postage batches may spend")
+	cmd.Flags().Duration(optionNamePostagePolicyInterval, 5*time.Minute, "time between checks of the automatic top-up and dilution policies of the postage batches, 0 to disable")
+	cmd.Flags().String(optionNamePostageEventsWebhook, "", "URL the lifecycle events of the postage batches are posted to")
+	cmd.Flags().StringSlice(optionNamePostageExpiryWarnings, []string{"168h", "24h"}, "times to live of the postage batches below which the expiry warnings are published")
 	cmd.Flags().String(optionNamePriceOracleAddress, "", "price oracle contract address")
 	cmd.Flags().String(optionNameTransactionHash, "", "proof-of-identity transaction hash")
 	cmd.Flags().String(optionNameBlockHash, "", "block hash of the block whose parent is the block that contains the transaction hash")
 	cmd.Flags().Uint64(optionNameBlockTime, 15, "chain block time")
 	cmd.Flags().String(optionNameSwapDeploymentGasPrice, "", "gas price in wei to use for deployment and funding")
+	cmd.Flags().String(optionNameTransactionMaxFee, "", "max fee per gas in wei of the transactions on chains with EIP-1559")
+	cmd.Flags().String(optionNameTransactionPriorityFee, "", "priority fee per gas in wei of the transactions on chains with EIP-1559, defaults to the suggested one")
+	cmd.Flags().Duration(optionNameTransactionStuckTimeout, 15*time.Minute, "time after which a pending transaction is replaced or rebroadcast, 0 to disable")
+	cmd.Flags().Uint64(optionNameTransactionFeeBump, 20, "percentage by which the fees of the stuck transactions are bumped")
+	cmd.Flags().String(optionNameTransactionBumpMaxFee, "", "max fee per gas in wei up to which the fees of the stuck transactions are bumped, empty to only rebroadcast them")
 	cmd.Flags().Duration(optionWarmUpTime, time.Minute*5, "time to warmup the node before some major protocols can be kicked off.")
 	cmd.Flags().Bool(optionNameMainNet, true, "triggers connect to main net bootnodes.")
 	cmd.Flags().Bool(optionNameRetrievalCaching, true, "enable forwarded content caching")
+	cmd.Flags().Duration(optionNameUploadSessionExpiry, 24*time.Hour, "time after the last upload to a resumable upload session after which the session is removed, 0 to disable")
+	cmd.Flags().Int64(optionNameUploadZipMaxArchiveSize, api.DefaultZipMaxArchiveSize, "max size in bytes of the zip archives uploaded as collections")
+	cmd.Flags().Int64(optionNameUploadZipMaxSize, api.DefaultZipMaxSize, "max total size in bytes of the files in the zip archives uploaded as collections")
 	cmd.Flags().Bool(optionNameResync, false, "forces the node to resync postage contract data")
 	cmd.Flags().Bool(optionNamePProfBlock, false, "enable pprof block profile")
 	cmd.Flags().Bool(optionNamePProfMutex, false, "enable pprof mutex profile")
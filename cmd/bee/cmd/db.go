@@ -5,24 +5,75 @@
 package cmd
 
 import (
+	"archive/tar"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"net"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/ethersphere/bee/pkg/localstore"
+	"github.com/ethersphere/bee/pkg/node"
+	"github.com/ethersphere/bee/pkg/postage"
+	"github.com/ethersphere/bee/pkg/postage/batchstore"
 	"github.com/ethersphere/bee/pkg/statestore/leveldb"
+	"github.com/ethersphere/bee/pkg/swarm"
 	"github.com/spf13/cobra"
 )
 
 const (
 	optionNameForgetOverlay = "forget-overlay"
 	optionNameForgetStamps  = "forget-stamps"
+	optionNameRepair        = "repair"
+	optionNameBatchID       = "batch-id"
+	optionNamePinnedOnly    = "pinned-only"
+	optionNameMinPO         = "min-po"
+	optionNameMaxPO         = "max-po"
+	optionNameCursor        = "cursor"
+	optionNameGzip          = "gzip"
+	optionNameSkip          = "skip"
+	optionNameDir           = "dir"
 )
 
+// progressInterval is the number of chunks between
+// the progress reports of the export and import.
+const progressInterval = 10000
+
+// exportCursor holds the next bin ID per proximity order
+// bin from which an incremental export continues.
+type exportCursor map[uint8]uint64
+
+// loadExportCursor reads the cursor from the file, if it exists.
+func loadExportCursor(path string) (exportCursor, error) {
+	cursor := make(exportCursor)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return cursor, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, err
+	}
+	return cursor, nil
+}
+
+// save writes the cursor to the file.
+func (c exportCursor) save(path string) error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
 func (c *command) initDBCmd() {
 	cmd := &cobra.Command{
 		Use:   "db",
@@ -33,6 +84,11 @@ func (c *command) initDBCmd() {
 	dbImportCmd(cmd)
 	dbNukeCmd(cmd)
 	dbIndicesCmd(cmd)
+	dbCompactCmd(cmd)
+	dbValidateCmd(cmd)
+	dbBackupCmd(cmd)
+	dbRestoreCmd(cmd)
+	dbStatsCmd(cmd)
 
 	c.root.AddCommand(cmd)
 }
@@ -89,6 +145,365 @@ func dbIndicesCmd(cmd *cobra.Command) {
 	cmd.AddCommand(c)
 }
 
+func dbCompactCmd(cmd *cobra.Command) {
+	c := &cobra.Command{
+		Use:   "compact",
+		Short: "Compacts the sharky shard files by relocating chunks to free slots and truncating the shards",
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			start := time.Now()
+			v, err := cmd.Flags().GetString(optionNameVerbosity)
+			if err != nil {
+				return fmt.Errorf("get verbosity: %w", err)
+			}
+			v = strings.ToLower(v)
+			logger, err := newLogger(cmd, v)
+			if err != nil {
+				return fmt.Errorf("new logger: %w", err)
+			}
+
+			dataDir, err := cmd.Flags().GetString(optionNameDataDir)
+			if err != nil {
+				return fmt.Errorf("get data-dir: %w", err)
+			}
+			if dataDir == "" {
+				return errors.New("no data-dir provided")
+			}
+
+			logger.Info("starting compaction with data-dir", "path", dataDir)
+
+			path := filepath.Join(dataDir, "localstore")
+
+			storer, err := localstore.New(path, nil, nil, nil, logger)
+			if err != nil {
+				return fmt.Errorf("localstore: %w", err)
+			}
+			defer storer.Close()
+
+			err = storer.Compact(cmd.Context(), func(p localstore.CompactionProgress) {
+				logger.Info("compacted shard", "shard", p.Shard, "total_shards", p.Shards, "moved_chunks", p.Moved, "reclaimed_bytes", p.Reclaimed)
+			})
+			if err != nil {
+				return fmt.Errorf("error compacting database: %w", err)
+			}
+
+			logger.Info("done", "elapsed", time.Since(start))
+
+			return nil
+		},
+	}
+	c.Flags().String(optionNameDataDir, "", "data directory")
+	c.Flags().String(optionNameVerbosity, "info", "verbosity level")
+	cmd.AddCommand(c)
+}
+
+func dbValidateCmd(cmd *cobra.Command) {
+	c := &cobra.Command{
+		Use:   "validate",
+		Short: "Validates the stored chunks and the localstore indexes, optionally removing the corrupted entries",
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			start := time.Now()
+			v, err := cmd.Flags().GetString(optionNameVerbosity)
+			if err != nil {
+				return fmt.Errorf("get verbosity: %w", err)
+			}
+			v = strings.ToLower(v)
+			logger, err := newLogger(cmd, v)
+			if err != nil {
+				return fmt.Errorf("new logger: %w", err)
+			}
+
+			dataDir, err := cmd.Flags().GetString(optionNameDataDir)
+			if err != nil {
+				return fmt.Errorf("get data-dir: %w", err)
+			}
+			if dataDir == "" {
+				return errors.New("no data-dir provided")
+			}
+
+			repair, err := cmd.Flags().GetBool(optionNameRepair)
+			if err != nil {
+				return fmt.Errorf("get repair: %w", err)
+			}
+
+			logger.Info("starting validation with data-dir", "path", dataDir, "repair", repair)
+
+			stateStore, err := leveldb.NewStateStore(filepath.Join(dataDir, "statestore"), logger)
+			if err != nil {
+				return fmt.Errorf("new statestore: %w", err)
+			}
+			defer stateStore.Close()
+
+			batchStore, err := batchstore.New(stateStore, nil, logger)
+			if err != nil {
+				return fmt.Errorf("new batchstore: %w", err)
+			}
+
+			path := filepath.Join(dataDir, "localstore")
+
+			storer, err := localstore.New(path, nil, nil, nil, logger)
+			if err != nil {
+				return fmt.Errorf("localstore: %w", err)
+			}
+			defer storer.Close()
+
+			res, err := storer.Validate(cmd.Context(), &localstore.ValidateOptions{
+				ValidStamp: postage.ValidStamp(batchStore),
+				Repair:     repair,
+				Report: func(i localstore.ValidationIssue) {
+					logger.Warning("invalid entry", "index", i.Index, "address", i.Address, "error", i.Err)
+				},
+			})
+			if err != nil {
+				return fmt.Errorf("error validating database: %w", err)
+			}
+
+			var orphaned int
+			for _, n := range res.Orphaned {
+				orphaned += n
+			}
+			logger.Info("validation done",
+				"total_chunks", res.Total,
+				"unreadable_chunks", res.Unreadable,
+				"invalid_chunks", res.Invalid,
+				"invalid_stamps", res.InvalidStamp,
+				"unknown_batches", res.UnknownBatch,
+				"orphaned_entries", orphaned,
+				"repaired_entries", res.Repaired,
+				"elapsed", time.Since(start),
+			)
+
+			return nil
+		},
+	}
+	c.Flags().String(optionNameDataDir, "", "data directory")
+	c.Flags().String(optionNameVerbosity, "info", "verbosity level")
+	c.Flags().Bool(optionNameRepair, false, "remove the corrupted chunks and the orphaned index entries")
+	cmd.AddCommand(c)
+}
+
+func dbBackupCmd(cmd *cobra.Command) {
+	c := &cobra.Command{
+		Use:   "backup <target>",
+		Short: "Backup the DB and the statestore of a running node to a tar file or a directory. Use \"-\" as target in order to write to STDOUT",
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			if (len(args)) != 1 {
+				return cmd.Help()
+			}
+			start := time.Now()
+			v, err := cmd.Flags().GetString(optionNameVerbosity)
+			if err != nil {
+				return fmt.Errorf("get verbosity: %w", err)
+			}
+			v = strings.ToLower(v)
+			logger, err := newLogger(cmd, v)
+			if err != nil {
+				return fmt.Errorf("new logger: %w", err)
+			}
+
+			addr, err := cmd.Flags().GetString(optionNameDebugAPIAddr)
+			if err != nil {
+				return fmt.Errorf("get debug-api-addr: %w", err)
+			}
+			toDir, err := cmd.Flags().GetBool(optionNameDir)
+			if err != nil {
+				return fmt.Errorf("get dir: %w", err)
+			}
+
+			host, port, err := net.SplitHostPort(addr)
+			if err != nil {
+				return fmt.Errorf("invalid debug-api-addr: %w", err)
+			}
+			if host == "" {
+				host = "localhost"
+			}
+			url := fmt.Sprintf("http://%s/backup", net.JoinHostPort(host, port))
+
+			logger.Info("starting backup", "url", url, "target", args[0])
+
+			req, err := http.NewRequestWithContext(cmd.Context(), http.MethodGet, url, nil)
+			if err != nil {
+				return err
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				return fmt.Errorf("backup request: %w", err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				return fmt.Errorf("backup request: %s", resp.Status)
+			}
+
+			if toDir {
+				r := localstore.NewTarBackupReader(tar.NewReader(resp.Body))
+				w := localstore.NewDirBackupWriter(args[0])
+				for {
+					name, size, f, err := r.Next()
+					if errors.Is(err, io.EOF) {
+						break
+					}
+					if err != nil {
+						return fmt.Errorf("error reading backup: %w", err)
+					}
+					if err := w.WriteFile(name, size, f); err != nil {
+						return fmt.Errorf("error writing backup: %w", err)
+					}
+				}
+			} else {
+				var out io.Writer
+				if args[0] == "-" {
+					out = os.Stdout
+				} else {
+					f, err := os.Create(args[0])
+					if err != nil {
+						return fmt.Errorf("error opening output file: %w", err)
+					}
+					defer f.Close()
+					out = f
+				}
+				if _, err := io.Copy(out, resp.Body); err != nil {
+					return fmt.Errorf("error writing backup: %w", err)
+				}
+			}
+
+			logger.Info("backup done", "elapsed", time.Since(start))
+
+			return nil
+		},
+	}
+	c.Flags().String(optionNameDebugAPIAddr, ":1635", "debug HTTP API address of the running node")
+	c.Flags().Bool(optionNameDir, false, "write the backup files to the target directory instead of a tar file")
+	c.Flags().String(optionNameVerbosity, "info", "verbosity level")
+	cmd.AddCommand(c)
+}
+
+func dbRestoreCmd(cmd *cobra.Command) {
+	c := &cobra.Command{
+		Use:   "restore <source>",
+		Short: "Restore the DB and the statestore from a backup tar file or directory. Use \"-\" as source in order to read from STDIN",
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			if (len(args)) != 1 {
+				return cmd.Help()
+			}
+			start := time.Now()
+			v, err := cmd.Flags().GetString(optionNameVerbosity)
+			if err != nil {
+				return fmt.Errorf("get verbosity: %w", err)
+			}
+			v = strings.ToLower(v)
+			logger, err := newLogger(cmd, v)
+			if err != nil {
+				return fmt.Errorf("new logger: %w", err)
+			}
+
+			dataDir, err := cmd.Flags().GetString(optionNameDataDir)
+			if err != nil {
+				return fmt.Errorf("get data-dir: %w", err)
+			}
+			if dataDir == "" {
+				return errors.New("no data-dir provided")
+			}
+
+			logger.Info("starting restore with data-dir", "path", dataDir, "source", args[0])
+
+			var r localstore.BackupReader
+			if fi, err := os.Stat(args[0]); err == nil && fi.IsDir() {
+				r, err = localstore.NewDirBackupReader(args[0])
+				if err != nil {
+					return fmt.Errorf("error opening backup directory: %w", err)
+				}
+			} else {
+				var in io.Reader
+				if args[0] == "-" {
+					in = os.Stdin
+				} else {
+					f, err := os.Open(args[0])
+					if err != nil {
+						return fmt.Errorf("error opening input file: %w", err)
+					}
+					defer f.Close()
+					in = f
+				}
+				r = localstore.NewTarBackupReader(tar.NewReader(in))
+			}
+
+			if err := localstore.Restore(cmd.Context(), r, dataDir); err != nil {
+				return fmt.Errorf("error restoring backup: %w", err)
+			}
+
+			logger.Info("restore done", "elapsed", time.Since(start))
+
+			return nil
+		},
+	}
+	c.Flags().String(optionNameDataDir, "", "data directory")
+	c.Flags().String(optionNameVerbosity, "info", "verbosity level")
+	cmd.AddCommand(c)
+}
+
+func dbStatsCmd(cmd *cobra.Command) {
+	c := &cobra.Command{
+		Use:   "stats",
+		Short: "Prints the chunk distribution, the storage usage and the fragmentation of the DB",
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			v, err := cmd.Flags().GetString(optionNameVerbosity)
+			if err != nil {
+				return fmt.Errorf("get verbosity: %w", err)
+			}
+			v = strings.ToLower(v)
+			logger, err := newLogger(cmd, v)
+			if err != nil {
+				return fmt.Errorf("new logger: %w", err)
+			}
+
+			dataDir, err := cmd.Flags().GetString(optionNameDataDir)
+			if err != nil {
+				return fmt.Errorf("get data-dir: %w", err)
+			}
+			if dataDir == "" {
+				return errors.New("no data-dir provided")
+			}
+
+			logger.Info("getting db stats with data-dir", "path", dataDir)
+
+			stateStore, err := leveldb.NewStateStore(filepath.Join(dataDir, "statestore"), logger)
+			if err != nil {
+				return fmt.Errorf("new statestore: %w", err)
+			}
+			defer stateStore.Close()
+
+			// the proximity orders are relative to the overlay of the node
+			overlay, err := node.StoredOverlay(stateStore)
+			if err != nil {
+				return fmt.Errorf("get overlay: %w", err)
+			}
+			batchStore, err := batchstore.New(stateStore, nil, logger)
+			if err != nil {
+				return fmt.Errorf("new batchstore: %w", err)
+			}
+
+			path := filepath.Join(dataDir, "localstore")
+
+			storer, err := localstore.New(path, overlay.Bytes(), nil, nil, logger)
+			if err != nil {
+				return fmt.Errorf("localstore: %w", err)
+			}
+			defer storer.Close()
+
+			stats, err := storer.Stats(cmd.Context(), batchStore.GetReserveState().StorageRadius)
+			if err != nil {
+				return fmt.Errorf("error getting stats: %w", err)
+			}
+
+			enc := json.NewEncoder(cmd.OutOrStdout())
+			enc.SetIndent("", "  ")
+			return enc.Encode(stats)
+		},
+	}
+	c.Flags().String(optionNameDataDir, "", "data directory")
+	c.Flags().String(optionNameVerbosity, "info", "verbosity level")
+	cmd.AddCommand(c)
+}
+
 func dbExportCmd(cmd *cobra.Command) {
 	c := &cobra.Command{
 		Use:   "export <filename>",
@@ -124,6 +539,66 @@ func dbExportCmd(cmd *cobra.Command) {
 				return fmt.Errorf("localstore: %w", err)
 			}
 
+			o := new(localstore.ExportOptions)
+
+			batchID, err := cmd.Flags().GetString(optionNameBatchID)
+			if err != nil {
+				return fmt.Errorf("get batch-id: %w", err)
+			}
+			if batchID != "" {
+				o.BatchID, err = hex.DecodeString(batchID)
+				if err != nil {
+					return fmt.Errorf("invalid batch-id: %w", err)
+				}
+			}
+			o.PinnedOnly, err = cmd.Flags().GetBool(optionNamePinnedOnly)
+			if err != nil {
+				return fmt.Errorf("get pinned-only: %w", err)
+			}
+			minPO, err := cmd.Flags().GetUint8(optionNameMinPO)
+			if err != nil {
+				return fmt.Errorf("get min-po: %w", err)
+			}
+			maxPO, err := cmd.Flags().GetUint8(optionNameMaxPO)
+			if err != nil {
+				return fmt.Errorf("get max-po: %w", err)
+			}
+			if minPO > maxPO || maxPO > swarm.MaxPO {
+				return fmt.Errorf("invalid proximity order range %d-%d", minPO, maxPO)
+			}
+			o.FromPO, o.ToPO = minPO, maxPO+1
+			o.Compress, err = cmd.Flags().GetBool(optionNameGzip)
+			if err != nil {
+				return fmt.Errorf("get gzip: %w", err)
+			}
+
+			cursorPath, err := cmd.Flags().GetString(optionNameCursor)
+			if err != nil {
+				return fmt.Errorf("get cursor: %w", err)
+			}
+			var cursor exportCursor
+			if cursorPath != "" {
+				cursor, err = loadExportCursor(cursorPath)
+				if err != nil {
+					return fmt.Errorf("load cursor: %w", err)
+				}
+				o.Since = make(map[uint8]uint64)
+				for bin := minPO; bin <= maxPO; bin++ {
+					o.Since[bin] = cursor[bin]
+				}
+			}
+
+			next := int64(progressInterval)
+			o.Progress = func(p localstore.ExportProgress) {
+				if cursor != nil && p.BinID >= cursor[p.Bin] {
+					cursor[p.Bin] = p.BinID + 1
+				}
+				if p.Count >= next {
+					logger.Info("export progress", "total_records", p.Count)
+					next += progressInterval
+				}
+			}
+
 			var out io.Writer
 			if args[0] == "-" {
 				out = os.Stdout
@@ -135,11 +610,17 @@ func dbExportCmd(cmd *cobra.Command) {
 				defer f.Close()
 				out = f
 			}
-			c, err := storer.Export(out)
+			c, err := storer.Export(out, o)
 			if err != nil {
 				return fmt.Errorf("error exporting database: %w", err)
 			}
 
+			if cursorPath != "" {
+				if err := cursor.save(cursorPath); err != nil {
+					return fmt.Errorf("save cursor: %w", err)
+				}
+			}
+
 			logger.Info("database exported successfully", "total_records", c)
 
 			return nil
@@ -147,6 +628,12 @@ func dbExportCmd(cmd *cobra.Command) {
 	}
 	c.Flags().String(optionNameDataDir, "", "data directory")
 	c.Flags().String(optionNameVerbosity, "info", "verbosity level")
+	c.Flags().String(optionNameBatchID, "", "export only the chunks stamped by the postage batch")
+	c.Flags().Bool(optionNamePinnedOnly, false, "export only the pinned chunks")
+	c.Flags().Uint8(optionNameMinPO, 0, "export only the chunks with proximity order equal or higher")
+	c.Flags().Uint8(optionNameMaxPO, swarm.MaxPO, "export only the chunks with proximity order equal or lower")
+	c.Flags().String(optionNameCursor, "", "file holding the last exported bin IDs, used to export only the chunks stored since the previous export")
+	c.Flags().Bool(optionNameGzip, false, "compress the export with gzip")
 	cmd.AddCommand(c)
 }
 
@@ -195,18 +682,35 @@ func dbImportCmd(cmd *cobra.Command) {
 				defer f.Close()
 				in = f
 			}
-			c, err := storer.Import(cmd.Context(), in)
+			skip, err := cmd.Flags().GetInt64(optionNameSkip)
+			if err != nil {
+				return fmt.Errorf("get skip: %w", err)
+			}
+
+			var p localstore.ImportProgress
+			next := skip + progressInterval
+			c, err := storer.Import(cmd.Context(), in, &localstore.ImportOptions{
+				Skip: skip,
+				Progress: func(ip localstore.ImportProgress) {
+					p = ip
+					if p.Processed >= next {
+						logger.Info("import progress", "processed_records", p.Processed, "imported_records", p.Imported, "existing_records", p.Existing)
+						next += progressInterval
+					}
+				},
+			})
 			if err != nil {
-				return fmt.Errorf("error importing database: %w", err)
+				return fmt.Errorf("error importing database (resume with --%s=%d): %w", optionNameSkip, p.Processed, err)
 			}
 
-			fmt.Printf("database imported %d records successfully\n", c)
+			fmt.Printf("database imported %d records successfully, %d records already existed\n", c, p.Existing)
 
 			return nil
 		},
 	}
 	c.Flags().String(optionNameDataDir, "", "data directory")
 	c.Flags().String(optionNameVerbosity, "info", "verbosity level")
+	c.Flags().Int64(optionNameSkip, 0, "number of records to skip from the beginning of the input, used to resume an interrupted import")
 	cmd.AddCommand(c)
 }
 
@@ -36,7 +36,11 @@ func (c *command) initDeployCmd() error {
 			factoryAddress := c.config.GetString(optionNameSwapFactoryAddress)
 			swapInitialDeposit := c.config.GetString(optionNameSwapInitialDeposit)
 			swapEndpoint := c.config.GetString(optionNameSwapEndpoint)
+			swapFallbackEndpoints := c.config.GetStringSlice(optionNameSwapFallbackEndpoints)
+			swapEndpointQuorum := c.config.GetInt(optionNameSwapEndpointQuorum)
 			deployGasPrice := c.config.GetString(optionNameSwapDeploymentGasPrice)
+			transactionMaxFee := c.config.GetString(optionNameTransactionMaxFee)
+			transactionPriorityFee := c.config.GetString(optionNameTransactionPriorityFee)
 			networkID := c.config.GetUint64(optionNameNetworkID)
 
 			stateStore, err := node.InitStateStore(logger, dataDir)
@@ -59,9 +63,13 @@ func (c *command) initDeployCmd() error {
 				logger,
 				stateStore,
 				swapEndpoint,
+				swapFallbackEndpoints,
+				swapEndpointQuorum,
 				0,
 				signer,
 				blocktime,
+				transactionMaxFee,
+				transactionPriorityFee,
 				true,
 			)
 			if err != nil {
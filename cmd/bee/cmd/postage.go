@@ -0,0 +1,295 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethersphere/bee/pkg/config"
+	"github.com/ethersphere/bee/pkg/log"
+	"github.com/ethersphere/bee/pkg/postage"
+	"github.com/ethersphere/bee/pkg/postage/batchservice"
+	"github.com/ethersphere/bee/pkg/postage/batchstore"
+	"github.com/ethersphere/bee/pkg/postage/listener"
+	"github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/spf13/cobra"
+)
+
+const (
+	optionNameFromBlock = "from-block"
+	optionNameToBlock   = "to-block"
+	optionNameChecksum  = "checksum"
+	optionNameVerify    = "verify"
+)
+
+// checksumSuffix is the suffix of the file holding
+// the SHA-256 checksum of the snapshot file.
+const checksumSuffix = ".sha256"
+
+func (c *command) initPostageCmd() {
+	cmd := &cobra.Command{
+		Use:   "postage",
+		Short: "Perform postage related operations",
+	}
+
+	c.postageSnapshotCmd(cmd)
+
+	c.root.AddCommand(cmd)
+}
+
+func (c *command) postageSnapshotCmd(cmd *cobra.Command) {
+	sc := &cobra.Command{
+		Use:   "snapshot <filename>",
+		Short: "Generate or verify a postage chain snapshot",
+		Long: `Generate or verify a postage chain snapshot.
+
+The snapshot holds all the postage contract events between two blocks,
+collected from the chain backend, in the format used to bootstrap the
+nodes with the postage snapshot. Use "-" as the filename in order to
+write to STDOUT. With the checksum flag, the SHA-256 checksum of the
+snapshot is written next to it, to a file with the ` + checksumSuffix + ` suffix.
+
+In the verify mode the snapshot is read from the file, checked against
+its checksum file if there is one, and replayed into an in-memory batch
+store, in order to print the resulting chain state.`,
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			if len(args) != 1 {
+				return cmd.Help()
+			}
+			v, err := cmd.Flags().GetString(optionNameVerbosity)
+			if err != nil {
+				return fmt.Errorf("get verbosity: %w", err)
+			}
+			v = strings.ToLower(v)
+			logger, err := newLogger(cmd, v)
+			if err != nil {
+				return fmt.Errorf("new logger: %w", err)
+			}
+
+			verify, err := cmd.Flags().GetBool(optionNameVerify)
+			if err != nil {
+				return fmt.Errorf("get verify: %w", err)
+			}
+			if verify {
+				return verifyPostageSnapshot(cmd, logger, args[0])
+			}
+			return createPostageSnapshot(cmd, logger, args[0])
+		},
+	}
+	sc.Flags().String(optionNameSwapEndpoint, c.config.GetString(optionNameSwapEndpoint), "blockchain endpoint to collect the events from")
+	sc.Flags().String(optionNamePostageContractAddress, "", "postage stamp contract address, defaults to the one of the chain")
+	sc.Flags().Uint64(optionNameFromBlock, 0, "first block of the snapshot, defaults to the postage contract deployment block")
+	sc.Flags().Uint64(optionNameToBlock, 0, "last block of the snapshot, defaults to the last final block of the chain")
+	sc.Flags().Bool(optionNameGzip, false, "compress the snapshot with gzip")
+	sc.Flags().Bool(optionNameChecksum, false, "write the checksum of the snapshot")
+	sc.Flags().Bool(optionNameVerify, false, "verify the snapshot instead of generating it")
+	sc.Flags().String(optionNameVerbosity, "info", "verbosity level")
+
+	cmd.AddCommand(sc)
+}
+
+func createPostageSnapshot(cmd *cobra.Command, logger log.Logger, filename string) error {
+	endpoint, err := cmd.Flags().GetString(optionNameSwapEndpoint)
+	if err != nil {
+		return fmt.Errorf("get swap-endpoint: %w", err)
+	}
+	contractAddress, err := cmd.Flags().GetString(optionNamePostageContractAddress)
+	if err != nil {
+		return fmt.Errorf("get postage-stamp-address: %w", err)
+	}
+	from, err := cmd.Flags().GetUint64(optionNameFromBlock)
+	if err != nil {
+		return fmt.Errorf("get from-block: %w", err)
+	}
+	to, err := cmd.Flags().GetUint64(optionNameToBlock)
+	if err != nil {
+		return fmt.Errorf("get to-block: %w", err)
+	}
+	compress, err := cmd.Flags().GetBool(optionNameGzip)
+	if err != nil {
+		return fmt.Errorf("get gzip: %w", err)
+	}
+	checksum, err := cmd.Flags().GetBool(optionNameChecksum)
+	if err != nil {
+		return fmt.Errorf("get checksum: %w", err)
+	}
+	if checksum && filename == "-" {
+		return errors.New("checksum can not be written for the snapshot written to STDOUT")
+	}
+
+	ctx := cmd.Context()
+
+	backend, err := ethclient.DialContext(ctx, endpoint)
+	if err != nil {
+		return fmt.Errorf("dial eth client: %w", err)
+	}
+	defer backend.Close()
+
+	chainID, err := backend.ChainID(ctx)
+	if err != nil {
+		return fmt.Errorf("get chain id: %w", err)
+	}
+	chainCfg, found := config.GetChainConfig(chainID.Int64())
+	postageContractAddress, startBlock := chainCfg.PostageStamp, chainCfg.StartBlock
+	if contractAddress != "" {
+		if !common.IsHexAddress(contractAddress) {
+			return errors.New("malformed postage stamp address")
+		}
+		postageContractAddress = common.HexToAddress(contractAddress)
+	} else if !found {
+		return errors.New("no known postage stamp addresses for this network")
+	}
+	if !cmd.Flags().Changed(optionNameFromBlock) {
+		from = startBlock
+	}
+
+	logger.Info("collecting postage events", "chain_id", chainID, "contract_address", postageContractAddress, "from_block", from, "to_block", to)
+
+	snapshot, err := listener.Snapshot(ctx, backend, postageContractAddress, from, to)
+	if err != nil {
+		return fmt.Errorf("postage snapshot: %w", err)
+	}
+
+	var out io.Writer
+	if filename == "-" {
+		out = cmd.OutOrStdout()
+	} else {
+		f, err := os.Create(filename)
+		if err != nil {
+			return fmt.Errorf("error opening output file: %w", err)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	h := sha256.New()
+	w := io.MultiWriter(out, h)
+	var gw *gzip.Writer
+	if compress {
+		gw = gzip.NewWriter(w)
+		w = gw
+	}
+	if err := json.NewEncoder(w).Encode(snapshot); err != nil {
+		return fmt.Errorf("write snapshot: %w", err)
+	}
+	if gw != nil {
+		if err := gw.Close(); err != nil {
+			return fmt.Errorf("write snapshot: %w", err)
+		}
+	}
+
+	sum := hex.EncodeToString(h.Sum(nil))
+	if checksum {
+		line := fmt.Sprintf("%s  %s\n", sum, filepath.Base(filename))
+		if err := os.WriteFile(filename+checksumSuffix, []byte(line), 0644); err != nil {
+			return fmt.Errorf("write checksum: %w", err)
+		}
+	}
+
+	logger.Info("postage snapshot generated successfully", "first_block", snapshot.FirstBlockNumber, "last_block", snapshot.LastBlockNumber, "events", len(snapshot.Events), "sha256", sum)
+
+	return nil
+}
+
+type postageSnapshotState struct {
+	FirstBlock   uint64   `json:"firstBlock"`
+	LastBlock    uint64   `json:"lastBlock"`
+	Timestamp    int64    `json:"timestamp"`
+	Events       int      `json:"events"`
+	Batches      int      `json:"batches"`
+	Block        uint64   `json:"block"`
+	TotalAmount  *big.Int `json:"totalAmount"`
+	CurrentPrice *big.Int `json:"currentPrice"`
+}
+
+func verifyPostageSnapshot(cmd *cobra.Command, logger log.Logger, filename string) error {
+	var (
+		data []byte
+		err  error
+	)
+	if filename == "-" {
+		data, err = io.ReadAll(cmd.InOrStdin())
+	} else {
+		data, err = os.ReadFile(filename)
+	}
+	if err != nil {
+		return fmt.Errorf("read snapshot: %w", err)
+	}
+
+	if filename != "-" {
+		line, err := os.ReadFile(filename + checksumSuffix)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			logger.Info("no checksum file found, skipping checksum verification", "path", filename+checksumSuffix)
+		case err != nil:
+			return fmt.Errorf("read checksum: %w", err)
+		default:
+			fields := strings.Fields(string(line))
+			sum := sha256.Sum256(data)
+			if len(fields) == 0 || !strings.EqualFold(fields[0], hex.EncodeToString(sum[:])) {
+				return errors.New("snapshot checksum mismatch")
+			}
+			logger.Info("snapshot checksum verified")
+		}
+	}
+
+	snapshot, err := postage.DecodeChainSnapshot(data)
+	if err != nil {
+		return fmt.Errorf("decode snapshot: %w", err)
+	}
+
+	stateStore := mock.NewStateStore()
+	defer stateStore.Close()
+	store, err := batchstore.New(stateStore, func([]byte) error { return nil }, logger)
+	if err != nil {
+		return fmt.Errorf("batchstore: %w", err)
+	}
+	svc, err := batchservice.New(stateStore, store, logger, nil, nil, nil, nil, false)
+	if err != nil {
+		return fmt.Errorf("batchservice: %w", err)
+	}
+	if err := listener.Replay(logger, snapshot, svc); err != nil {
+		return fmt.Errorf("replay snapshot: %w", err)
+	}
+
+	batches := 0
+	err = store.Iterate(func(*postage.Batch) (bool, error) {
+		batches++
+		return false, nil
+	})
+	if err != nil {
+		return fmt.Errorf("iterate batches: %w", err)
+	}
+
+	cs := store.GetChainState()
+	out, err := json.MarshalIndent(postageSnapshotState{
+		FirstBlock:   snapshot.FirstBlockNumber,
+		LastBlock:    snapshot.LastBlockNumber,
+		Timestamp:    snapshot.Timestamp,
+		Events:       len(snapshot.Events),
+		Batches:      batches,
+		Block:        cs.Block,
+		TotalAmount:  cs.TotalAmount,
+		CurrentPrice: cs.CurrentPrice,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), string(out))
+
+	return nil
+}
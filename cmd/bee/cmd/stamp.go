@@ -0,0 +1,313 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/ethersphere/bee/pkg/crypto"
+	filekeystore "github.com/ethersphere/bee/pkg/keystore/file"
+	"github.com/ethersphere/bee/pkg/postage"
+	"github.com/ethersphere/bee/pkg/postage/archive"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/spf13/cobra"
+)
+
+const (
+	optionNameDepth       = "depth"
+	optionNameBucketDepth = "bucket-depth"
+	optionNameImmutable   = "immutable"
+	optionNameKeyName     = "key"
+	optionNameIssuerFile  = "issuer-file"
+)
+
+func (c *command) initStampCmd() {
+	cmd := &cobra.Command{
+		Use:   "stamp <input> <output>",
+		Short: "Stamp the chunks of an archive offline with a postage batch",
+		Long: `Stamp the chunks of an archive offline with a postage batch.
+
+The input is either a chunk archive in the format of the db export, whose
+stamps are replaced, or a directory of chunk files named by the hex encoded
+chunk addresses. The output is a chunk archive which can be imported with
+the db import command. Use "-" as the input or the output filename in order
+to read from STDIN or write to STDOUT.
+
+The stamps are signed by the key from the keystore of the data directory.
+The state of the stamp issuer is kept in the issuer file, so that the next
+runs with the same batch continue to issue new stamps. The batch must not
+be used by a node at the same time, as the stamps would collide.`,
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			if len(args) != 2 {
+				return cmd.Help()
+			}
+			v, err := cmd.Flags().GetString(optionNameVerbosity)
+			if err != nil {
+				return fmt.Errorf("get verbosity: %w", err)
+			}
+			v = strings.ToLower(v)
+			logger, err := newLogger(cmd, v)
+			if err != nil {
+				return fmt.Errorf("new logger: %w", err)
+			}
+			dataDir, err := cmd.Flags().GetString(optionNameDataDir)
+			if err != nil {
+				return fmt.Errorf("get data-dir: %w", err)
+			}
+			if dataDir == "" {
+				return errors.New("no data-dir provided")
+			}
+
+			batchIDStr, err := cmd.Flags().GetString(optionNameBatchID)
+			if err != nil {
+				return fmt.Errorf("get batch-id: %w", err)
+			}
+			batchID, err := hex.DecodeString(batchIDStr)
+			if err != nil || len(batchID) != swarm.HashSize {
+				return fmt.Errorf("invalid batch-id %q", batchIDStr)
+			}
+			depth, err := cmd.Flags().GetUint8(optionNameDepth)
+			if err != nil {
+				return fmt.Errorf("get depth: %w", err)
+			}
+			bucketDepth, err := cmd.Flags().GetUint8(optionNameBucketDepth)
+			if err != nil {
+				return fmt.Errorf("get bucket-depth: %w", err)
+			}
+			if depth <= bucketDepth {
+				return fmt.Errorf("depth %d must be greater than bucket depth %d", depth, bucketDepth)
+			}
+			immutable, err := cmd.Flags().GetBool(optionNameImmutable)
+			if err != nil {
+				return fmt.Errorf("get immutable: %w", err)
+			}
+			compress, err := cmd.Flags().GetBool(optionNameGzip)
+			if err != nil {
+				return fmt.Errorf("get gzip: %w", err)
+			}
+			issuerFile, err := cmd.Flags().GetString(optionNameIssuerFile)
+			if err != nil {
+				return fmt.Errorf("get issuer-file: %w", err)
+			}
+			if issuerFile == "" {
+				issuerFile = filepath.Join(dataDir, "stamps", batchIDStr+".json")
+			}
+
+			signer, err := c.stampSigner(cmd, dataDir)
+			if err != nil {
+				return err
+			}
+
+			issuer, err := loadStampIssuer(issuerFile, batchID, depth, bucketDepth, immutable)
+			if err != nil {
+				return fmt.Errorf("stamp issuer: %w", err)
+			}
+			// the issuer is saved even if stamping fails, so
+			// that the issued stamps are never issued again
+			defer func() {
+				if serr := saveStampIssuer(issuerFile, issuer); serr != nil && err == nil {
+					err = fmt.Errorf("save stamp issuer: %w", serr)
+				}
+			}()
+
+			var out io.Writer
+			if args[1] == "-" {
+				out = cmd.OutOrStdout()
+			} else {
+				f, err := os.Create(args[1])
+				if err != nil {
+					return fmt.Errorf("error opening output file: %w", err)
+				}
+				defer f.Close()
+				out = f
+			}
+			w, err := archive.NewWriter(out, compress)
+			if err != nil {
+				return err
+			}
+
+			var count int64
+			stamper := postage.NewStamper(issuer, signer)
+			stamp := func(ch swarm.Chunk) error {
+				stamp, err := stamper.Stamp(ch.Address())
+				if err != nil {
+					return fmt.Errorf("stamp chunk %s: %w", ch.Address(), err)
+				}
+				if err := w.Write(ch.WithStamp(stamp)); err != nil {
+					return err
+				}
+				count++
+				if count%progressInterval == 0 {
+					logger.Info("stamp progress", "stamped_chunks", count)
+				}
+				return nil
+			}
+
+			if fi, err := os.Stat(args[0]); err == nil && fi.IsDir() {
+				err = archive.ReadDir(args[0], stamp)
+			} else {
+				var in io.Reader
+				if args[0] == "-" {
+					in = cmd.InOrStdin()
+				} else {
+					f, err := os.Open(args[0])
+					if err != nil {
+						return fmt.Errorf("error opening input file: %w", err)
+					}
+					defer f.Close()
+					in = f
+				}
+				err = archive.ReadTar(in, stamp)
+			}
+			if err != nil {
+				return fmt.Errorf("error stamping chunks: %w", err)
+			}
+			if err := w.Close(); err != nil {
+				return err
+			}
+
+			logger.Info("chunks stamped successfully", "stamped_chunks", count, "utilization", issuer.Utilization(), "bucket_upper_bound", issuer.BucketUpperBound())
+
+			return nil
+		},
+	}
+	cmd.Flags().String(optionNameDataDir, c.config.GetString(optionNameDataDir), "data directory")
+	cmd.Flags().String(optionNameVerbosity, "info", "verbosity level")
+	cmd.Flags().String(optionNamePassword, "", "password for decrypting keys")
+	cmd.Flags().String(optionNamePasswordFile, "", "path to a file that contains password for decrypting keys")
+	cmd.Flags().String(optionNameKeyName, "swarm", "name of the key in the keystore that signs the stamps")
+	cmd.Flags().String(optionNameBatchID, "", "ID of the postage batch")
+	cmd.Flags().Uint8(optionNameDepth, 0, "depth of the postage batch")
+	cmd.Flags().Uint8(optionNameBucketDepth, postage.BucketDepth, "bucket depth of the postage batch")
+	cmd.Flags().Bool(optionNameImmutable, false, "whether the postage batch is immutable")
+	cmd.Flags().String(optionNameIssuerFile, "", "file keeping the state of the stamp issuer, defaults to stamps/<batch-id>.json in the data directory")
+	cmd.Flags().Bool(optionNameGzip, false, "compress the output with gzip")
+
+	c.root.AddCommand(cmd)
+}
+
+// stampSigner returns the signer of the key from the keystore of the data
+// directory. The key is never created, as it must own the batch.
+func (c *command) stampSigner(cmd *cobra.Command, dataDir string) (crypto.Signer, error) {
+	keyName, err := cmd.Flags().GetString(optionNameKeyName)
+	if err != nil {
+		return nil, fmt.Errorf("get key: %w", err)
+	}
+	keystore := filekeystore.New(filepath.Join(dataDir, "keys"))
+	exists, err := keystore.Exists(keyName)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("key %q not found in the keystore", keyName)
+	}
+
+	var password string
+	if p, _ := cmd.Flags().GetString(optionNamePassword); p != "" {
+		password = p
+	} else if pf, _ := cmd.Flags().GetString(optionNamePasswordFile); pf != "" {
+		b, err := os.ReadFile(pf)
+		if err != nil {
+			return nil, err
+		}
+		password = string(bytes.Trim(b, "\n"))
+	} else {
+		password, err = terminalPromptPassword(cmd, c.passwordReader, "Password")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	key, _, err := keystore.Key(keyName, password)
+	if err != nil {
+		return nil, fmt.Errorf("%s key: %w", keyName, err)
+	}
+	return crypto.NewDefaultSigner(key), nil
+}
+
+// loadStampIssuer reads the stamp issuer of the batch from the file or
+// returns a new one if the file does not exist.
+func loadStampIssuer(path string, batchID []byte, depth, bucketDepth uint8, immutable bool) (*postage.StampIssuer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return postage.NewStampIssuer("", "", batchID, new(big.Int), depth, bucketDepth, 0, immutable), nil
+		}
+		return nil, err
+	}
+
+	issuer := new(postage.StampIssuer)
+	if err := json.Unmarshal(data, issuer); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(issuer.ID(), batchID) || issuer.Depth() != depth || issuer.BucketDepth() != bucketDepth || issuer.ImmutableFlag() != immutable {
+		return nil, fmt.Errorf("file %s holds the stamp issuer of another batch", path)
+	}
+	return issuer, nil
+}
+
+// saveStampIssuer writes the stamp issuer to the file. The issuer is written
+// to a temporary file which replaces the previous one only once it is synced,
+// so that the bucket counts are never lost by an interrupted write.
+func saveStampIssuer(path string, issuer *postage.StampIssuer) (err error) {
+	data, err := json.Marshal(issuer)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(dir, filepath.Base(path)+".tmp-")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			f.Close()
+			os.Remove(f.Name())
+		}
+	}()
+	if err := f.Chmod(0600); err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(f.Name(), path); err != nil {
+		return err
+	}
+	return syncDir(dir)
+}
+
+// syncDir syncs the directory so that the renames of its files are durable.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	if err := d.Sync(); err != nil && runtime.GOOS != "windows" {
+		return err
+	}
+	return nil
+}
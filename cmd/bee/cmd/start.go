@@ -23,6 +23,7 @@ import (
 	"github.com/ethersphere/bee"
 	"github.com/ethersphere/bee/pkg/crypto"
 	"github.com/ethersphere/bee/pkg/crypto/clef"
+	"github.com/ethersphere/bee/pkg/crypto/remote"
 	"github.com/ethersphere/bee/pkg/keystore"
 	filekeystore "github.com/ethersphere/bee/pkg/keystore/file"
 	memkeystore "github.com/ethersphere/bee/pkg/keystore/mem"
@@ -162,6 +163,12 @@ func (c *command) initStartCmd() (err error) {
 				return errors.New("static nodes can only be configured on bootnodes")
 			}
 
+			for _, name := range []string{optionNameUploadZipMaxArchiveSize, optionNameUploadZipMaxSize} {
+				if c.config.GetInt64(name) <= 0 {
+					return fmt.Errorf("%s must be positive", name)
+				}
+			}
+
 			// Wait for termination or interrupt signals.
 			// We want to clean up things at the end.
 			sysInterruptChannel := make(chan os.Signal, 1)
@@ -172,10 +179,15 @@ func (c *command) initStartCmd() (err error) {
 			b, err := node.NewBee(interruptChannel, c.config.GetString(optionNameP2PAddr), signerConfig.publicKey, signerConfig.signer, networkID, logger, signerConfig.libp2pPrivateKey, signerConfig.pssPrivateKey, &node.Options{
 				DataDir:                    c.config.GetString(optionNameDataDir),
 				CacheCapacity:              c.config.GetUint64(optionNameCacheCapacity),
+				CacheGCPolicy:              c.config.GetString(optionNameCacheGCPolicy),
+				CacheMemorySize:            c.config.GetUint64(optionNameCacheMemorySize),
 				DBOpenFilesLimit:           c.config.GetUint64(optionNameDBOpenFilesLimit),
 				DBBlockCacheCapacity:       c.config.GetUint64(optionNameDBBlockCacheCapacity),
 				DBWriteBufferSize:          c.config.GetUint64(optionNameDBWriteBufferSize),
 				DBDisableSeeksCompaction:   c.config.GetBool(optionNameDBDisableSeeksCompaction),
+				DBFreeSpaceSoftLimit:       c.config.GetUint64(optionNameDBFreeSpaceSoftLimit),
+				DBFreeSpaceHardLimit:       c.config.GetUint64(optionNameDBFreeSpaceHardLimit),
+				DBScrubInterval:            c.config.GetDuration(optionNameDBScrubInterval),
 				APIAddr:                    c.config.GetString(optionNameAPIAddr),
 				DebugAPIAddr:               debugAPIAddr,
 				Addr:                       c.config.GetString(optionNameP2PAddr),
@@ -195,6 +207,13 @@ func (c *command) initStartCmd() (err error) {
 				GatewayMode:                c.config.GetBool(optionNameGatewayMode),
 				BootnodeMode:               bootNode,
 				SwapEndpoint:               c.config.GetString(optionNameSwapEndpoint),
+				SwapFallbackEndpoints:      c.config.GetStringSlice(optionNameSwapFallbackEndpoints),
+				SwapEndpointQuorum:         c.config.GetInt(optionNameSwapEndpointQuorum),
+				TransactionMaxFee:          c.config.GetString(optionNameTransactionMaxFee),
+				TransactionPriorityFee:     c.config.GetString(optionNameTransactionPriorityFee),
+				TransactionStuckTimeout:    c.config.GetDuration(optionNameTransactionStuckTimeout),
+				TransactionFeeBump:         c.config.GetUint64(optionNameTransactionFeeBump),
+				TransactionBumpMaxFee:      c.config.GetString(optionNameTransactionBumpMaxFee),
 				SwapFactoryAddress:         c.config.GetString(optionNameSwapFactoryAddress),
 				SwapLegacyFactoryAddresses: c.config.GetStringSlice(optionNameSwapLegacyFactoryAddresses),
 				SwapInitialDeposit:         c.config.GetString(optionNameSwapInitialDeposit),
@@ -204,12 +223,19 @@ func (c *command) initStartCmd() (err error) {
 				Transaction:                c.config.GetString(optionNameTransactionHash),
 				BlockHash:                  c.config.GetString(optionNameBlockHash),
 				PostageContractAddress:     c.config.GetString(optionNamePostageContractAddress),
+				PostagePolicyBudget:        c.config.GetString(optionNamePostagePolicyBudget),
+				PostagePolicyInterval:      c.config.GetDuration(optionNamePostagePolicyInterval),
+				PostageEventsWebhook:       c.config.GetString(optionNamePostageEventsWebhook),
+				PostageExpiryWarnings:      c.config.GetStringSlice(optionNamePostageExpiryWarnings),
 				PriceOracleAddress:         c.config.GetString(optionNamePriceOracleAddress),
 				BlockTime:                  networkConfig.blockTime,
 				DeployGasPrice:             c.config.GetString(optionNameSwapDeploymentGasPrice),
 				WarmupTime:                 c.config.GetDuration(optionWarmUpTime),
 				ChainID:                    networkConfig.chainID,
 				RetrievalCaching:           c.config.GetBool(optionNameRetrievalCaching),
+				UploadSessionExpiry:        c.config.GetDuration(optionNameUploadSessionExpiry),
+				UploadZipMaxArchiveSize:    c.config.GetInt64(optionNameUploadZipMaxArchiveSize),
+				UploadZipMaxSize:           c.config.GetInt64(optionNameUploadZipMaxSize),
 				Resync:                     c.config.GetBool(optionNameResync),
 				BlockProfile:               c.config.GetBool(optionNamePProfBlock),
 				MutexProfile:               c.config.GetBool(optionNamePProfMutex),
@@ -368,6 +394,10 @@ func (c *command) configureSigner(cmd *cobra.Command, logger log.Logger) (config
 		}
 	}
 
+	if c.config.GetBool(optionNameClefSignerEnable) && c.config.GetBool(optionNameRemoteSignerEnable) {
+		return nil, errors.New("clef and remote signers can not be enabled at the same time")
+	}
+
 	if c.config.GetBool(optionNameClefSignerEnable) {
 		endpoint := c.config.GetString(optionNameClefSignerEndpoint)
 		if endpoint == "" {
@@ -400,6 +430,29 @@ func (c *command) configureSigner(cmd *cobra.Command, logger log.Logger) (config
 			return nil, err
 		}
 
+		publicKey, err = signer.PublicKey()
+		if err != nil {
+			return nil, err
+		}
+	} else if c.config.GetBool(optionNameRemoteSignerEnable) {
+		o := remote.Options{
+			Endpoint:    c.config.GetString(optionNameRemoteSignerEndpoint),
+			Timeout:     c.config.GetDuration(optionNameRemoteSignerTimeout),
+			TLSCertFile: c.config.GetString(optionNameRemoteSignerTLSCert),
+			TLSKeyFile:  c.config.GetString(optionNameRemoteSignerTLSKey),
+			TLSCAFile:   c.config.GetString(optionNameRemoteSignerTLSCA),
+		}
+		// if wantedAddress was specified use that, otherwise the first account of the remote signer will be selected.
+		if wantedAddress := c.config.GetString(optionNameRemoteSignerEthAddress); wantedAddress != "" {
+			ethAddress := common.HexToAddress(wantedAddress)
+			o.EthereumAddress = &ethAddress
+		}
+
+		signer, err = remote.NewSigner(o, crypto.Recover)
+		if err != nil {
+			return nil, fmt.Errorf("remote signer: %w", err)
+		}
+
 		publicKey, err = signer.PublicKey()
 		if err != nil {
 			return nil, err
@@ -0,0 +1,199 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/log"
+	"github.com/ethersphere/bee/pkg/statestore/dump"
+	"github.com/ethersphere/bee/pkg/statestore/leveldb"
+	"github.com/spf13/cobra"
+)
+
+const optionNameForce = "force"
+
+func (c *command) initStatestoreCmd() {
+	cmd := &cobra.Command{
+		Use:   "statestore",
+		Short: "Perform statestore related operations",
+	}
+
+	statestoreDumpCmd(cmd)
+	statestoreRestoreCmd(cmd)
+
+	c.root.AddCommand(cmd)
+}
+
+// statestoreFlags returns the logger and the data
+// directory set by the flags of the statestore commands.
+func statestoreFlags(cmd *cobra.Command) (log.Logger, string, error) {
+	v, err := cmd.Flags().GetString(optionNameVerbosity)
+	if err != nil {
+		return nil, "", fmt.Errorf("get verbosity: %w", err)
+	}
+	v = strings.ToLower(v)
+	logger, err := newLogger(cmd, v)
+	if err != nil {
+		return nil, "", fmt.Errorf("new logger: %w", err)
+	}
+
+	dataDir, err := cmd.Flags().GetString(optionNameDataDir)
+	if err != nil {
+		return nil, "", fmt.Errorf("get data-dir: %w", err)
+	}
+	if dataDir == "" {
+		return nil, "", errors.New("no data-dir provided")
+	}
+	return logger, dataDir, nil
+}
+
+func statestoreDumpCmd(cmd *cobra.Command) {
+	c := &cobra.Command{
+		Use:   "dump <filename>",
+		Short: "Dump the statestore to a JSON lines file. Use \"-\" as filename in order to write to STDOUT",
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			if (len(args)) != 1 {
+				return cmd.Help()
+			}
+			logger, dataDir, err := statestoreFlags(cmd)
+			if err != nil {
+				return err
+			}
+
+			logger.Info("starting statestore dump with data-dir", "path", dataDir)
+
+			store, err := leveldb.NewStateStore(filepath.Join(dataDir, "statestore"), logger)
+			if err != nil {
+				return fmt.Errorf("statestore: %w", err)
+			}
+			defer store.Close()
+
+			var out io.Writer
+			if args[0] == "-" {
+				out = os.Stdout
+			} else {
+				f, err := os.Create(args[0])
+				if err != nil {
+					return fmt.Errorf("error opening output file: %w", err)
+				}
+				defer f.Close()
+				out = f
+			}
+
+			stats, err := dump.Dump(out, store)
+			if err != nil {
+				return fmt.Errorf("error dumping statestore: %w", err)
+			}
+
+			logger.Info("statestore dumped successfully", "total_entries", stats.Entries, "decoded_entries", stats.Decoded)
+
+			return nil
+		},
+	}
+	c.Flags().String(optionNameDataDir, "", "data directory")
+	c.Flags().String(optionNameVerbosity, "info", "verbosity level")
+	cmd.AddCommand(c)
+}
+
+func statestoreRestoreCmd(cmd *cobra.Command) {
+	c := &cobra.Command{
+		Use:   "restore <filename>",
+		Short: "Restore the statestore from a dump file. Use \"-\" as filename in order to read from STDIN",
+		Long: `Restore the statestore from a dump file. Use "-" as filename in order to read from STDIN.
+
+The existing statestore is replaced by the restored one only with the --force flag.`,
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			if (len(args)) != 1 {
+				return cmd.Help()
+			}
+			start := time.Now()
+			logger, dataDir, err := statestoreFlags(cmd)
+			if err != nil {
+				return err
+			}
+
+			force, err := cmd.Flags().GetBool(optionNameForce)
+			if err != nil {
+				return fmt.Errorf("get force: %w", err)
+			}
+
+			path := filepath.Join(dataDir, "statestore")
+			if !force {
+				if _, err := os.Stat(path); err == nil {
+					return fmt.Errorf("statestore %s already exists, use --%s to replace it", path, optionNameForce)
+				} else if !errors.Is(err, os.ErrNotExist) {
+					return err
+				}
+			}
+
+			logger.Info("starting statestore restore with data-dir", "path", dataDir, "source", args[0])
+
+			var in io.Reader
+			if args[0] == "-" {
+				in = os.Stdin
+			} else {
+				f, err := os.Open(args[0])
+				if err != nil {
+					return fmt.Errorf("error opening input file: %w", err)
+				}
+				defer f.Close()
+				in = f
+			}
+
+			// restore into a temporary directory and
+			// replace the statestore only on success
+			tmp := filepath.Join(dataDir, ".statestore-restore")
+			if err := os.RemoveAll(tmp); err != nil {
+				return err
+			}
+			defer os.RemoveAll(tmp)
+
+			store, err := leveldb.NewStateStore(tmp, logger)
+			if err != nil {
+				return fmt.Errorf("statestore: %w", err)
+			}
+			stats, err := dump.Restore(in, store, false)
+			if err != nil {
+				store.Close()
+				return fmt.Errorf("error restoring statestore: %w", err)
+			}
+			if err := store.Close(); err != nil {
+				return err
+			}
+
+			// opening the store again migrates the
+			// restored data to the current schema
+			store, err = leveldb.NewStateStore(tmp, logger)
+			if err != nil {
+				return fmt.Errorf("migrate restored statestore: %w", err)
+			}
+			if err := store.Close(); err != nil {
+				return err
+			}
+
+			if err := os.RemoveAll(path); err != nil {
+				return err
+			}
+			if err := os.Rename(tmp, path); err != nil {
+				return err
+			}
+
+			logger.Info("statestore restored successfully", "total_entries", stats.Entries, "decoded_entries", stats.Decoded, "elapsed", time.Since(start))
+
+			return nil
+		},
+	}
+	c.Flags().String(optionNameDataDir, "", "data directory")
+	c.Flags().String(optionNameVerbosity, "info", "verbosity level")
+	c.Flags().Bool(optionNameForce, false, "replace the existing statestore")
+	cmd.AddCommand(c)
+}
@@ -322,43 +322,54 @@ func (c *creditAction) Apply() error {
 
 	loggerV1.Debug("credit action apply", "crediting_peer_address", c.peer, "price", c.price, "new_balance", nextBalance)
 
-	err = c.accounting.store.Put(peerBalanceKey(c.peer), nextBalance)
+	// the balance and the originated balance are updated together
+	batch := c.accounting.store.Batch()
+	defer batch.Discard()
+
+	err = batch.Put(peerBalanceKey(c.peer), nextBalance)
 	if err != nil {
 		return fmt.Errorf("failed to persist balance: %w", err)
 	}
 
-	c.accounting.metrics.TotalCreditedAmount.Add(float64(c.price.Int64()))
-	c.accounting.metrics.CreditEventsCount.Inc()
+	if c.originated {
+		originBalance, err := c.accounting.OriginatedBalance(c.peer)
+		if err != nil && !errors.Is(err, ErrPeerNoBalance) {
+			return fmt.Errorf("failed to load originated balance: %w", err)
+		}
 
-	if !c.originated {
-		return nil
-	}
+		// Calculate next balance by decreasing current balance with the price we credit
+		nextOriginBalance := new(big.Int).Sub(originBalance, c.price)
 
-	originBalance, err := c.accounting.OriginatedBalance(c.peer)
-	if err != nil && !errors.Is(err, ErrPeerNoBalance) {
-		return fmt.Errorf("failed to load originated balance: %w", err)
-	}
+		loggerV1.Debug("credit action apply", "crediting_peer_address", c.peer, "price", c.price, "new_originated_balance", nextOriginBalance)
 
-	// Calculate next balance by decreasing current balance with the price we credit
-	nextOriginBalance := new(big.Int).Sub(originBalance, c.price)
+		zero := big.NewInt(0)
+		// only consider negative balance for limiting originated balance
+		if nextBalance.Cmp(zero) > 0 {
+			nextBalance.Set(zero)
+		}
 
-	loggerV1.Debug("credit action apply", "crediting_peer_address", c.peer, "price", c.price, "new_originated_balance", nextOriginBalance)
+		// If originated balance is more into the negative domain, set it to balance
+		if nextOriginBalance.Cmp(nextBalance) < 0 {
+			nextOriginBalance.Set(nextBalance)
+			loggerV1.Debug("credit action apply; decreasing originated balance", "crediting_peer_address", c.peer, "current_balance", nextOriginBalance)
+		}
 
-	zero := big.NewInt(0)
-	// only consider negative balance for limiting originated balance
-	if nextBalance.Cmp(zero) > 0 {
-		nextBalance.Set(zero)
+		err = batch.Put(originatedBalanceKey(c.peer), nextOriginBalance)
+		if err != nil {
+			return fmt.Errorf("failed to persist originated balance: %w", err)
+		}
 	}
 
-	// If originated balance is more into the negative domain, set it to balance
-	if nextOriginBalance.Cmp(nextBalance) < 0 {
-		nextOriginBalance.Set(nextBalance)
-		loggerV1.Debug("credit action apply; decreasing originated balance", "crediting_peer_address", c.peer, "current_balance", nextOriginBalance)
+	err = batch.Commit()
+	if err != nil {
+		return fmt.Errorf("failed to persist balances: %w", err)
 	}
 
-	err = c.accounting.store.Put(originatedBalanceKey(c.peer), nextOriginBalance)
-	if err != nil {
-		return fmt.Errorf("failed to persist originated balance: %w", err)
+	c.accounting.metrics.TotalCreditedAmount.Add(float64(c.price.Int64()))
+	c.accounting.metrics.CreditEventsCount.Inc()
+
+	if !c.originated {
+		return nil
 	}
 
 	c.accounting.metrics.TotalOriginatedCreditedAmount.Add(float64(c.price.Int64()))
@@ -449,15 +460,23 @@ func (a *Accounting) settle(peer swarm.Address, balance *accountingPeer) error {
 
 		loggerV1.Debug("registering refreshment sent", "peer_address", peer, "amount", acceptedAmount, "new_balance", oldBalance)
 
-		err = a.store.Put(peerBalanceKey(peer), oldBalance)
+		batch := a.store.Batch()
+		defer batch.Discard()
+
+		err = batch.Put(peerBalanceKey(peer), oldBalance)
 		if err != nil {
 			return fmt.Errorf("settle: failed to persist balance: %w", err)
 		}
 
-		err = a.decreaseOriginatedBalanceTo(peer, oldBalance)
+		err = a.decreaseOriginatedBalanceTo(batch, peer, oldBalance)
 		if err != nil {
 			return fmt.Errorf("settle: failed to decrease originated balance: %w", err)
 		}
+
+		err = batch.Commit()
+		if err != nil {
+			return fmt.Errorf("settle: failed to persist balances: %w", err)
+		}
 	}
 
 	if a.payFunction != nil && !balance.paymentOngoing {
@@ -997,7 +1016,7 @@ func (a *Accounting) PrepareDebit(ctx context.Context, peer swarm.Address, price
 	}, nil
 }
 
-func (a *Accounting) increaseBalance(peer swarm.Address, _ *accountingPeer, price *big.Int) (*big.Int, error) {
+func (a *Accounting) increaseBalance(batch storage.StateBatch, peer swarm.Address, _ *accountingPeer, price *big.Int) (*big.Int, error) {
 	loggerV1 := a.logger.V(1).Register()
 
 	cost := new(big.Int).Set(price)
@@ -1016,7 +1035,7 @@ func (a *Accounting) increaseBalance(peer swarm.Address, _ *accountingPeer, pric
 		if newSurplusBalance.Cmp(big.NewInt(0)) >= 0 {
 			loggerV1.Debug("surplus debiting peer", "peer_address", peer, "price", price, "new_balance", newSurplusBalance)
 
-			err = a.store.Put(peerSurplusBalanceKey(peer), newSurplusBalance)
+			err = batch.Put(peerSurplusBalanceKey(peer), newSurplusBalance)
 			if err != nil {
 				return nil, fmt.Errorf("failed to persist surplus balance: %w", err)
 			}
@@ -1037,7 +1056,7 @@ func (a *Accounting) increaseBalance(peer swarm.Address, _ *accountingPeer, pric
 		// let's store 0 as surplus balance
 		loggerV1.Debug("surplus debiting peer", "peer_address", peer, "amount", debitIncrease, "new_balance", 0)
 
-		err = a.store.Put(peerSurplusBalanceKey(peer), big.NewInt(0))
+		err = batch.Put(peerSurplusBalanceKey(peer), big.NewInt(0))
 		if err != nil {
 			return nil, fmt.Errorf("failed to persist surplus balance: %w", err)
 		}
@@ -1055,12 +1074,12 @@ func (a *Accounting) increaseBalance(peer swarm.Address, _ *accountingPeer, pric
 
 	loggerV1.Debug("debiting peer", "peer_address", peer, "price", price, "new_balance", nextBalance)
 
-	err = a.store.Put(peerBalanceKey(peer), nextBalance)
+	err = batch.Put(peerBalanceKey(peer), nextBalance)
 	if err != nil {
 		return nil, fmt.Errorf("failed to persist balance: %w", err)
 	}
 
-	err = a.decreaseOriginatedBalanceTo(peer, nextBalance)
+	err = a.decreaseOriginatedBalanceTo(batch, peer, nextBalance)
 	if err != nil {
 		a.logger.Warning("increase balance; failed to decrease originated balance", "error", err)
 	}
@@ -1077,11 +1096,20 @@ func (d *debitAction) Apply() error {
 
 	cost := new(big.Int).Set(d.price)
 
-	nextBalance, err := d.accounting.increaseBalance(d.peer, d.accountingPeer, cost)
+	// the balance, the surplus balance and the originated balance are updated together
+	batch := a.store.Batch()
+	defer batch.Discard()
+
+	nextBalance, err := d.accounting.increaseBalance(batch, d.peer, d.accountingPeer, cost)
 	if err != nil {
 		return err
 	}
 
+	err = batch.Commit()
+	if err != nil {
+		return fmt.Errorf("failed to persist balances: %w", err)
+	}
+
 	d.applied = true
 	d.accountingPeer.shadowReservedBalance = new(big.Int).Sub(d.accountingPeer.shadowReservedBalance, d.price)
 
@@ -1167,19 +1195,30 @@ func (a *Accounting) Connect(peer swarm.Address) {
 	accountingPeer.ghostBalance.Set(zero)
 	accountingPeer.reservedBalance.Set(zero)
 
-	err := a.store.Put(peerBalanceKey(peer), zero)
+	// the balance and the surplus balance are reset together
+	batch := a.store.Batch()
+	defer batch.Discard()
+
+	err := batch.Put(peerBalanceKey(peer), zero)
 	if err != nil {
 		a.logger.Error(err, "failed to persist balance")
+		return
 	}
 
-	err = a.store.Put(peerSurplusBalanceKey(peer), zero)
+	err = batch.Put(peerSurplusBalanceKey(peer), zero)
 	if err != nil {
 		a.logger.Error(err, "failed to persist surplus balance")
+		return
+	}
+
+	err = batch.Commit()
+	if err != nil {
+		a.logger.Error(err, "failed to persist balances")
 	}
 }
 
 // decreaseOriginatedBalanceTo decreases the originated balance to provided limit or 0 if limit is positive
-func (a *Accounting) decreaseOriginatedBalanceTo(peer swarm.Address, limit *big.Int) error {
+func (a *Accounting) decreaseOriginatedBalanceTo(batch storage.StateBatch, peer swarm.Address, limit *big.Int) error {
 	loggerV1 := a.logger.V(1).Register()
 
 	zero := big.NewInt(0)
@@ -1197,7 +1236,7 @@ func (a *Accounting) decreaseOriginatedBalanceTo(peer swarm.Address, limit *big.
 
 	// If originated balance is more into the negative domain, set it to limit
 	if originatedBalance.Cmp(toSet) < 0 {
-		err = a.store.Put(originatedBalanceKey(peer), toSet)
+		err = batch.Put(originatedBalanceKey(peer), toSet)
 		if err != nil {
 			return fmt.Errorf("failed to persist originated balance: %w", err)
 		}
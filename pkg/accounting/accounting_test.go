@@ -8,6 +8,7 @@ import (
 	"context"
 	"errors"
 	"math/big"
+	"reflect"
 	"sync"
 	"testing"
 	"time"
@@ -17,6 +18,7 @@ import (
 	"github.com/ethersphere/bee/pkg/p2p"
 	p2pmock "github.com/ethersphere/bee/pkg/p2p/mock"
 	"github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/storage"
 
 	"github.com/ethersphere/bee/pkg/swarm"
 )
@@ -352,6 +354,100 @@ func TestAccountingAdd_persistentBalances(t *testing.T) {
 	}
 }
 
+// failingCommitStore is a state store whose batches fail to commit, if
+// fail is set, without applying any of their writes.
+type failingCommitStore struct {
+	storage.StateStorer
+	fail bool
+}
+
+func (s *failingCommitStore) Batch() storage.StateBatch {
+	return &failingCommitBatch{StateBatch: s.StateStorer.Batch(), store: s}
+}
+
+type failingCommitBatch struct {
+	storage.StateBatch
+	store *failingCommitStore
+}
+
+func (b *failingCommitBatch) Commit() error {
+	if b.store.fail {
+		b.StateBatch.Discard()
+		return errors.New("commit failed")
+	}
+	return b.StateBatch.Commit()
+}
+
+// TestAccountingApplyAtomic tests that the balances of a peer
+// are not changed by the actions failing to persist them.
+func TestAccountingApplyAtomic(t *testing.T) {
+	logger := log.Noop
+
+	store := &failingCommitStore{StateStorer: mock.NewStateStore()}
+	defer store.Close()
+
+	acc, err := accounting.NewAccounting(testPaymentThreshold, testPaymentTolerance, testPaymentEarly, logger, store, nil, big.NewInt(testRefreshRate), p2pmock.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	peer1Addr, err := swarm.ParseHexAddress("00112233")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	acc.Connect(peer1Addr)
+
+	// the originated balance and the surplus balance are set as well
+	creditAction, err := acc.PrepareCredit(context.Background(), peer1Addr, testPrice, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := creditAction.Apply(); err != nil {
+		t.Fatal(err)
+	}
+	creditAction.Cleanup()
+
+	entries := func() map[string]string {
+		m := make(map[string]string)
+		err := store.Iterate("", func(key, value []byte) (bool, error) {
+			m[string(key)] = string(value)
+			return false, nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return m
+	}
+	want := entries()
+
+	store.fail = true
+
+	creditAction, err = acc.PrepareCredit(context.Background(), peer1Addr, testPrice, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := creditAction.Apply(); err == nil {
+		t.Fatal("credit applied without persisting the balances")
+	}
+	creditAction.Cleanup()
+
+	debitAction, err := acc.PrepareDebit(context.Background(), peer1Addr, 3*testPrice)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := debitAction.Apply(); err == nil {
+		t.Fatal("debit applied without persisting the balances")
+	}
+	debitAction.Cleanup()
+
+	acc.Connect(peer1Addr)
+
+	if got := entries(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got stored balances %q, want %q", got, want)
+	}
+}
+
 // TestAccountingReserve tests that reserve returns an error if the payment threshold would be exceeded
 func TestAccountingReserve(t *testing.T) {
 	logger := log.Noop
@@ -32,11 +32,14 @@ import (
 	"github.com/ethersphere/bee/pkg/file/pipeline"
 	"github.com/ethersphere/bee/pkg/file/pipeline/builder"
 	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/localstore"
 	"github.com/ethersphere/bee/pkg/log"
 	"github.com/ethersphere/bee/pkg/p2p"
 	"github.com/ethersphere/bee/pkg/pingpong"
 	"github.com/ethersphere/bee/pkg/pinning"
 	"github.com/ethersphere/bee/pkg/postage"
+	"github.com/ethersphere/bee/pkg/postage/notifier"
+	"github.com/ethersphere/bee/pkg/postage/policy"
 	"github.com/ethersphere/bee/pkg/postage/postagecontract"
 	"github.com/ethersphere/bee/pkg/pss"
 	"github.com/ethersphere/bee/pkg/pusher"
@@ -54,6 +57,7 @@ import (
 	"github.com/ethersphere/bee/pkg/tracing"
 	"github.com/ethersphere/bee/pkg/transaction"
 	"github.com/ethersphere/bee/pkg/traversal"
+	"github.com/ethersphere/bee/pkg/upload"
 	"github.com/gorilla/mux"
 	"github.com/prometheus/client_golang/prometheus"
 	"golang.org/x/sync/errgroup"
@@ -73,7 +77,10 @@ const (
 	SwarmFeedIndexNextHeader  = "Swarm-Feed-Index-Next"
 	SwarmCollectionHeader     = "Swarm-Collection"
 	SwarmPostageBatchIdHeader = "Swarm-Postage-Batch-Id"
+	SwarmPostageStampHeader   = "Swarm-Postage-Stamp"
+	SwarmPostageStampedHeader = "Swarm-Postage-Stamped"
 	SwarmDeferredUploadHeader = "Swarm-Deferred-Upload"
+	SwarmUploadOffsetHeader   = "Swarm-Upload-Offset"
 )
 
 // The size of buffer used for prefetching content with Langos.
@@ -94,6 +101,7 @@ const (
 	contentTypeHeader = "Content-Type"
 	multiPartFormData = "multipart/form-data"
 	contentTypeTar    = "application/x-tar"
+	contentTypeZip    = "application/zip"
 )
 
 var (
@@ -104,6 +112,9 @@ var (
 	errDirectoryStore       = errors.New("could not store directory")
 	errFileStore            = errors.New("could not store file")
 	errInvalidPostageBatch  = errors.New("invalid postage batch id")
+	errInvalidPostageStamp  = errors.New("invalid postage stamp")
+	errMissingPostageStamp  = errors.New("missing postage stamp")
+	errStampedWithBatch     = errors.New("postage batch id not allowed for stamped chunks")
 	errBatchUnusable        = errors.New("batch not usable")
 )
 
@@ -114,15 +125,26 @@ type authenticator interface {
 	Enforce(string, string, string) (bool, error)
 }
 
+// localStorer exposes the maintenance operations
+// of the local storage to the debug API.
+type localStorer interface {
+	Compact(context.Context, func(localstore.CompactionProgress)) error
+	Backup(context.Context, localstore.BackupWriter) error
+	Stats(context.Context, uint8) (*localstore.Stats, error)
+	DiskSpaceState() localstore.DiskSpaceState
+}
+
 type Service struct {
 	auth            authenticator
 	tags            *tags.Tags
 	storer          storage.Storer
+	localStore      localStorer
 	resolver        resolver.Interface
 	pss             pss.Interface
 	traversal       traversal.Traverser
 	pinning         pinning.Interface
 	steward         steward.Interface
+	uploads         *upload.Service
 	logger          log.Logger
 	loggerV1        log.Logger
 	tracer          *tracing.Tracer
@@ -130,6 +152,8 @@ type Service struct {
 	signer          crypto.Signer
 	post            postage.Service
 	postageContract postagecontract.Interface
+	postagePolicy   *policy.Service
+	batchNotifier   *notifier.Notifier
 	chunkPushC      chan *pusher.Op
 	metricsRegistry *prometheus.Registry
 	Options
@@ -139,6 +163,8 @@ type Service struct {
 
 	metrics metrics
 
+	compaction compaction
+
 	wsWg sync.WaitGroup // wait for all websockets to close on exit
 	quit chan struct{}
 
@@ -192,6 +218,9 @@ type Options struct {
 	GatewayMode        bool
 	WsPingPeriod       time.Duration
 	Restricted         bool
+	TempDir            string // directory of the temporary files, the default one of the os if empty
+	ZipMaxArchiveSize  int64  // max size of the uploaded zip archives, the default one if not positive
+	ZipMaxSize         int64  // max total size of the files in the uploaded zip archives, the default one if not positive
 }
 
 type ExtraOptions struct {
@@ -205,6 +234,7 @@ type ExtraOptions struct {
 	BlockTime        *big.Int
 	Tags             *tags.Tags
 	Storer           storage.Storer
+	LocalStore       localStorer
 	Resolver         resolver.Interface
 	Pss              pss.Interface
 	TraversalService traversal.Traverser
@@ -212,7 +242,10 @@ type ExtraOptions struct {
 	FeedFactory      feeds.Factory
 	Post             postage.Service
 	PostageContract  postagecontract.Interface
+	PostagePolicy    *policy.Service
+	BatchNotifier    *notifier.Notifier
 	Steward          steward.Interface
+	Uploads          *upload.Service
 	SyncStatus       func() (bool, error)
 }
 
@@ -249,6 +282,7 @@ func (s *Service) Configure(signer crypto.Signer, auth authenticator, tracer *tr
 
 	s.tags = e.Tags
 	s.storer = e.Storer
+	s.localStore = e.LocalStore
 	s.resolver = e.Resolver
 	s.pss = e.Pss
 	s.traversal = e.TraversalService
@@ -256,7 +290,10 @@ func (s *Service) Configure(signer crypto.Signer, auth authenticator, tracer *tr
 	s.feedFactory = e.FeedFactory
 	s.post = e.Post
 	s.postageContract = e.PostageContract
+	s.postagePolicy = e.PostagePolicy
+	s.batchNotifier = e.BatchNotifier
 	s.steward = e.Steward
+	s.uploads = e.Uploads
 
 	s.pingpong = e.Pingpong
 	s.topologyDriver = e.TopologyDriver
@@ -379,6 +416,20 @@ func requestPostageBatchId(r *http.Request) ([]byte, error) {
 	return nil, errInvalidPostageBatch
 }
 
+// requestPostageStamp returns the serialized postage stamp of a chunk
+// stamped by the client or nil if the request has none.
+func requestPostageStamp(r *http.Request) ([]byte, error) {
+	h := r.Header.Get(SwarmPostageStampHeader)
+	if h == "" {
+		return nil, nil
+	}
+	b, err := hex.DecodeString(h)
+	if err != nil || len(b) != postage.StampSize {
+		return nil, errInvalidPostageStamp
+	}
+	return b, nil
+}
+
 type securityTokenRsp struct {
 	Key string `json:"key"`
 }
@@ -561,8 +612,8 @@ func (s *Service) corsHandler(h http.Handler) http.Handler {
 		if o := r.Header.Get("Origin"); o != "" && s.checkOrigin(r) {
 			w.Header().Set("Access-Control-Allow-Credentials", "true")
 			w.Header().Set("Access-Control-Allow-Origin", o)
-			w.Header().Set("Access-Control-Allow-Headers", "User-Agent, Origin, Accept, Authorization, Content-Type, X-Requested-With, Decompressed-Content-Length, Access-Control-Request-Headers, Access-Control-Request-Method, Swarm-Tag, Swarm-Pin, Swarm-Encrypt, Swarm-Index-Document, Swarm-Error-Document, Swarm-Collection, Swarm-Postage-Batch-Id, Gas-Price, Range, Accept-Ranges, Content-Encoding")
-			w.Header().Set("Access-Control-Allow-Methods", "GET, HEAD, OPTIONS, POST, PUT, DELETE")
+			w.Header().Set("Access-Control-Allow-Headers", "User-Agent, Origin, Accept, Authorization, Content-Type, X-Requested-With, Decompressed-Content-Length, Access-Control-Request-Headers, Access-Control-Request-Method, Swarm-Tag, Swarm-Pin, Swarm-Encrypt, Swarm-Index-Document, Swarm-Error-Document, Swarm-Collection, Swarm-Postage-Batch-Id, Swarm-Postage-Stamp, Swarm-Postage-Stamped, Swarm-Upload-Offset, Gas-Price, Range, Accept-Ranges, Content-Encoding")
+			w.Header().Set("Access-Control-Allow-Methods", "GET, HEAD, OPTIONS, POST, PUT, PATCH, DELETE")
 			w.Header().Set("Access-Control-Max-Age", "3600")
 		}
 		h.ServeHTTP(w, r)
@@ -628,6 +679,11 @@ func (s *Service) newStamperPutter(r *http.Request) (storage.Storer, func() erro
 		return nil, noopWaitFn, fmt.Errorf("request deferred: %w", err)
 	}
 
+	return s.newBatchPutter(batch, deferred)
+}
+
+// newBatchPutter returns a putter of the chunks stamped with the usable batch.
+func (s *Service) newBatchPutter(batch []byte, deferred bool) (storage.Storer, func() error, error) {
 	exists, err := s.batchStore.Exists(batch)
 	if err != nil {
 		return nil, noopWaitFn, fmt.Errorf("batch exists: %w", err)
@@ -642,12 +698,45 @@ func (s *Service) newStamperPutter(r *http.Request) (storage.Storer, func() erro
 		return nil, noopWaitFn, errBatchUnusable
 	}
 
+	return s.newPutter(deferred, postage.NewStamper(issuer, s.signer))
+}
+
+// newStampedPutter returns a putter of the chunks stamped by the client,
+// which must be validated against the batch store before being put.
+func (s *Service) newStampedPutter(r *http.Request) (storage.Storer, func() error, error) {
+	deferred, err := requestDeferred(r)
+	if err != nil {
+		return nil, noopWaitFn, fmt.Errorf("request deferred: %w", err)
+	}
+
+	return s.newPutter(deferred, nil)
+}
+
+// newPutter returns a storingStamperPutter for the deferred uploads and
+// a pushStamperPutter otherwise. The nil stamper leaves the stamps of
+// the chunks stamped by the client as they are.
+func (s *Service) newPutter(deferred bool, stamper postage.Stamper) (storage.Storer, func() error, error) {
 	if deferred {
-		p, err := newStoringStamperPutter(s.storer, s.post, s.signer, batch)
-		return p, noopWaitFn, err
+		return newStoringStamperPutter(s.storer, stamper), noopWaitFn, nil
 	}
-	p, err := newPushStamperPutter(s.storer, s.post, s.signer, batch, s.chunkPushC)
-	return p, p.eg.Wait, err
+	p := newPushStamperPutter(s.storer, stamper, s.chunkPushC)
+	return p, p.eg.Wait, nil
+}
+
+// stampChunk returns the chunk stamped by the stamper. If the stamper
+// is nil, the chunk must already have a stamp.
+func stampChunk(stamper postage.Stamper, c swarm.Chunk) (swarm.Chunk, error) {
+	if stamper == nil {
+		if c.Stamp() == nil {
+			return nil, errMissingPostageStamp
+		}
+		return c, nil
+	}
+	stamp, err := stamper.Stamp(c.Address())
+	if err != nil {
+		return nil, err
+	}
+	return c.WithStamp(stamp), nil
 }
 
 type pushStamperPutter struct {
@@ -658,14 +747,8 @@ type pushStamperPutter struct {
 	sem     chan struct{}
 }
 
-func newPushStamperPutter(s storage.Storer, post postage.Service, signer crypto.Signer, batch []byte, cc chan *pusher.Op) (*pushStamperPutter, error) {
-	i, err := post.GetStampIssuer(batch)
-	if err != nil {
-		return nil, fmt.Errorf("stamp issuer: %w", err)
-	}
-
-	stamper := postage.NewStamper(i, signer)
-	return &pushStamperPutter{Storer: s, stamper: stamper, c: cc, sem: make(chan struct{}, uploadSem)}, nil
+func newPushStamperPutter(s storage.Storer, stamper postage.Stamper, cc chan *pusher.Op) *pushStamperPutter {
+	return &pushStamperPutter{Storer: s, stamper: stamper, c: cc, sem: make(chan struct{}, uploadSem)}
 }
 
 func (p *pushStamperPutter) Put(ctx context.Context, mode storage.ModePut, chs ...swarm.Chunk) (exists []bool, err error) {
@@ -681,7 +764,7 @@ func (p *pushStamperPutter) Put(ctx context.Context, mode storage.ModePut, chs .
 			exists[i] = true
 			continue
 		}
-		stamp, err := p.stamper.Stamp(c.Address())
+		c, err = stampChunk(p.stamper, c)
 		if err != nil {
 			return nil, err
 		}
@@ -715,7 +798,7 @@ func (p *pushStamperPutter) Put(ctx context.Context, mode storage.ModePut, chs .
 					return ctx.Err()
 				}
 			})
-		}(c.WithStamp(stamp))
+		}(c)
 	}
 	return exists, nil
 }
@@ -725,14 +808,8 @@ type stamperPutter struct {
 	stamper postage.Stamper
 }
 
-func newStoringStamperPutter(s storage.Storer, post postage.Service, signer crypto.Signer, batch []byte) (*stamperPutter, error) {
-	i, err := post.GetStampIssuer(batch)
-	if err != nil {
-		return nil, fmt.Errorf("stamp issuer: %w", err)
-	}
-
-	stamper := postage.NewStamper(i, signer)
-	return &stamperPutter{Storer: s, stamper: stamper}, nil
+func newStoringStamperPutter(s storage.Storer, stamper postage.Stamper) *stamperPutter {
+	return &stamperPutter{Storer: s, stamper: stamper}
 }
 
 func (p *stamperPutter) Put(ctx context.Context, mode storage.ModePut, chs ...swarm.Chunk) (exists []bool, err error) {
@@ -751,11 +828,10 @@ func (p *stamperPutter) Put(ctx context.Context, mode storage.ModePut, chs ...sw
 			exists[i] = true
 			continue
 		}
-		stamp, err := p.stamper.Stamp(c.Address())
+		chs[i], err = stampChunk(p.stamper, c)
 		if err != nil {
 			return nil, err
 		}
-		chs[i] = c.WithStamp(stamp)
 		ctp = append(ctp, chs[i])
 		idx = append(idx, i)
 	}
@@ -30,6 +30,7 @@ import (
 	"github.com/ethersphere/bee/pkg/file/pipeline"
 	"github.com/ethersphere/bee/pkg/file/pipeline/builder"
 	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/localstore"
 	"github.com/ethersphere/bee/pkg/log"
 	p2pmock "github.com/ethersphere/bee/pkg/p2p/mock"
 	"github.com/ethersphere/bee/pkg/pingpong"
@@ -37,6 +38,8 @@ import (
 	"github.com/ethersphere/bee/pkg/postage"
 	mockbatchstore "github.com/ethersphere/bee/pkg/postage/batchstore/mock"
 	mockpost "github.com/ethersphere/bee/pkg/postage/mock"
+	"github.com/ethersphere/bee/pkg/postage/notifier"
+	"github.com/ethersphere/bee/pkg/postage/policy"
 	"github.com/ethersphere/bee/pkg/postage/postagecontract"
 	"github.com/ethersphere/bee/pkg/pss"
 	"github.com/ethersphere/bee/pkg/pusher"
@@ -59,6 +62,7 @@ import (
 	"github.com/ethersphere/bee/pkg/transaction/backendmock"
 	transactionmock "github.com/ethersphere/bee/pkg/transaction/mock"
 	"github.com/ethersphere/bee/pkg/traversal"
+	"github.com/ethersphere/bee/pkg/upload"
 	"github.com/gorilla/websocket"
 	"resenje.org/web"
 )
@@ -78,6 +82,7 @@ func init() {
 
 type testServerOptions struct {
 	Storer             storage.Storer
+	LocalStore         *localstore.DB
 	Resolver           resolver.Interface
 	Pss                pss.Interface
 	Traversal          traversal.Traverser
@@ -92,12 +97,18 @@ type testServerOptions struct {
 	CORSAllowedOrigins []string
 	PostageContract    postagecontract.Interface
 	Post               postage.Service
+	PostagePolicy      *policy.Service
+	BatchNotifier      *notifier.Notifier
 	Steward            steward.Interface
+	Uploads            *upload.Service
 	WsHeaders          http.Header
 	Authenticator      *mockauth.Auth
 	DebugAPI           bool
 	Restricted         bool
 	DirectUpload       bool
+	TempDir            string
+	ZipMaxArchiveSize  int64
+	ZipMaxSize         int64
 
 	Overlay         swarm.Address
 	PublicKey       ecdsa.PublicKey
@@ -144,6 +155,10 @@ func newTestServer(t *testing.T, o testServerOptions) (*http.Client, *websocket.
 	if o.SyncStatus == nil {
 		o.SyncStatus = func() (bool, error) { return true, nil }
 	}
+	if o.Uploads == nil {
+		o.Uploads = upload.New(statestore.NewStateStore(), o.Logger, upload.Options{})
+		t.Cleanup(func() { _ = o.Uploads.Close() })
+	}
 	if o.Authenticator == nil {
 		o.Authenticator = &mockauth.Auth{
 			EnforceFunc: func(_, _, _ string) (bool, error) {
@@ -184,10 +199,17 @@ func newTestServer(t *testing.T, o testServerOptions) (*http.Client, *websocket.
 		FeedFactory:      o.Feeds,
 		Post:             o.Post,
 		PostageContract:  o.PostageContract,
+		PostagePolicy:    o.PostagePolicy,
+		BatchNotifier:    o.BatchNotifier,
 		Steward:          o.Steward,
+		Uploads:          o.Uploads,
 		SyncStatus:       o.SyncStatus,
 	}
 
+	if o.LocalStore != nil {
+		extraOpts.LocalStore = o.LocalStore
+	}
+
 	s := api.New(o.PublicKey, o.PSSPublicKey, o.EthereumAddress, o.Logger, transaction, o.BatchStore, o.GatewayMode, api.FullMode, true, true, o.CORSAllowedOrigins)
 
 	s.SetP2P(o.P2P)
@@ -204,6 +226,9 @@ func newTestServer(t *testing.T, o testServerOptions) (*http.Client, *websocket.
 		GatewayMode:        o.GatewayMode,
 		WsPingPeriod:       o.WsPingPeriod,
 		Restricted:         o.Restricted,
+		TempDir:            o.TempDir,
+		ZipMaxArchiveSize:  o.ZipMaxArchiveSize,
+		ZipMaxSize:         o.ZipMaxSize,
 	}, extraOpts, 1, backend, erc20)
 
 	if o.DebugAPI {
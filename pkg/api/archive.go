@@ -0,0 +1,391 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/file/joiner"
+	"github.com/ethersphere/bee/pkg/file/loadsave"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/manifest"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/tracing"
+	"github.com/ethersphere/langos"
+)
+
+const (
+	archiveFormatTar = "tar"
+	archiveFormatZip = "zip"
+
+	// archiveFetchConcurrency is the number of the files of the
+	// archive fetched ahead of the file written to the response.
+	archiveFetchConcurrency = 8
+	// archiveBufferFileSize is the size up to which the fetched files
+	// are read into memory, the larger files are streamed when written.
+	archiveBufferFileSize = 64 * swarm.ChunkSize
+
+	// tarMetadataPrefix prefixes the keys of the manifest
+	// metadata of the files in the tar PAX records.
+	tarMetadataPrefix = "SWARM."
+	// zipMetadataExtraID is the header id of the zip extra
+	// field with the manifest metadata of the files.
+	zipMetadataExtraID = 0x5753
+)
+
+var (
+	errInvalidArchiveFormat = errors.New("invalid archive format")
+	errArchiveManifest      = errors.New("read manifest")
+	errArchiveEmpty         = errors.New("no files under the path")
+)
+
+// requestArchiveFormat returns the archive format requested with the
+// archive query parameter or, if it is absent, with the Accept header,
+// in which case negotiated is true. The empty format is returned if no
+// archive is requested.
+func requestArchiveFormat(r *http.Request) (format string, negotiated bool, err error) {
+	if v, ok := r.URL.Query()["archive"]; ok {
+		switch format := strings.ToLower(v[0]); format {
+		case archiveFormatTar, archiveFormatZip:
+			return format, false, nil
+		default:
+			return "", false, errInvalidArchiveFormat
+		}
+	}
+
+	for _, accept := range strings.Split(strings.Join(r.Header.Values("Accept"), ","), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(accept))
+		if err != nil {
+			continue
+		}
+		// the media types with the zero quality are not acceptable
+		if q, err := strconv.ParseFloat(params["q"], 64); err == nil && q <= 0 {
+			continue
+		}
+		switch mediaType {
+		case contentTypeTar:
+			return archiveFormatTar, true, nil
+		case contentTypeZip:
+			return archiveFormatZip, true, nil
+		}
+	}
+	return "", false, nil
+}
+
+// archiveWriter writes the files of a collection to an archive.
+type archiveWriter interface {
+	// Create adds the file with the path, the size and the manifest
+	// metadata to the archive and returns the writer of its data.
+	Create(path string, size int64, metadata map[string]string) (io.Writer, error)
+	Close() error
+}
+
+type tarArchiveWriter struct {
+	w *tar.Writer
+}
+
+func (a *tarArchiveWriter) Create(path string, size int64, metadata map[string]string) (io.Writer, error) {
+	h := &tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     path,
+		Mode:     0644,
+		Size:     size,
+	}
+	if len(metadata) > 0 {
+		h.Format = tar.FormatPAX
+		h.PAXRecords = make(map[string]string, len(metadata))
+		for k, v := range metadata {
+			h.PAXRecords[tarMetadataPrefix+k] = v
+		}
+	}
+	if err := a.w.WriteHeader(h); err != nil {
+		return nil, err
+	}
+	return a.w, nil
+}
+
+func (a *tarArchiveWriter) Close() error {
+	return a.w.Close()
+}
+
+type zipArchiveWriter struct {
+	w *zip.Writer
+}
+
+func (a *zipArchiveWriter) Create(path string, _ int64, metadata map[string]string) (io.Writer, error) {
+	h := &zip.FileHeader{
+		Name:   path,
+		Method: zip.Deflate,
+		Extra:  zipMetadataExtra(metadata),
+	}
+	h.SetMode(0644)
+	return a.w.CreateHeader(h)
+}
+
+func (a *zipArchiveWriter) Close() error {
+	return a.w.Close()
+}
+
+// zipMetadataExtra encodes the manifest metadata as the zip extra field.
+// The data of the field is the metadata encoded as the url query.
+func zipMetadataExtra(metadata map[string]string) []byte {
+	if len(metadata) == 0 {
+		return nil
+	}
+	values := make(url.Values, len(metadata))
+	for k, v := range metadata {
+		values.Set(k, v)
+	}
+	data := values.Encode()
+	if len(data) > math.MaxUint16-4 {
+		return nil
+	}
+
+	extra := make([]byte, 4+len(data))
+	binary.LittleEndian.PutUint16(extra, zipMetadataExtraID)
+	binary.LittleEndian.PutUint16(extra[2:], uint16(len(data)))
+	copy(extra[4:], data)
+	return extra
+}
+
+// zipExtraMetadata decodes the manifest metadata from the zip extra
+// fields, as encoded by zipMetadataExtra. The nil map is returned if the
+// extra fields have no metadata.
+func zipExtraMetadata(extra []byte) map[string]string {
+	for len(extra) >= 4 {
+		id := binary.LittleEndian.Uint16(extra)
+		size := int(binary.LittleEndian.Uint16(extra[2:]))
+		if len(extra) < 4+size {
+			return nil
+		}
+		if id == zipMetadataExtraID {
+			values, err := url.ParseQuery(string(extra[4 : 4+size]))
+			if err != nil {
+				return nil
+			}
+			metadata := make(map[string]string, len(values))
+			for k := range values {
+				metadata[k] = values.Get(k)
+			}
+			return metadata
+		}
+		extra = extra[4+size:]
+	}
+	return nil
+}
+
+// archiveFile is a file of the collection fetched for the archive.
+type archiveFile struct {
+	path     string
+	metadata map[string]string
+	size     int64
+	reader   io.Reader
+	err      error
+}
+
+// fetchArchiveFile fetches the file of the manifest entry on the path.
+func (s *Service) fetchArchiveFile(ctx context.Context, path string, entry manifest.Entry) archiveFile {
+	reader, size, err := joiner.New(ctx, s.storer, entry.Reference())
+	if err != nil {
+		return archiveFile{err: fmt.Errorf("file %s: %w", path, err)}
+	}
+
+	f := archiveFile{
+		path:     path,
+		metadata: entry.Metadata(),
+		size:     size,
+	}
+	if size > archiveBufferFileSize {
+		f.reader = langos.NewBufferedLangos(reader, lookaheadBufferSize(size))
+		return f
+	}
+
+	data := make([]byte, size)
+	if _, err := io.ReadFull(reader, data); err != nil {
+		return archiveFile{err: fmt.Errorf("file %s: %w", path, err)}
+	}
+	f.reader = bytes.NewReader(data)
+	return f
+}
+
+// serveArchive streams the files of the collection on the address under
+// the prefix as the archive of the format. The prefix without the trailing
+// slash is the name of a directory or a file, so that the prefix "img" is
+// the file "img" or the files in "img/", but not the file "imgs.txt". The
+// files are fetched concurrently ahead of the file being written, in the
+// order of their paths in the manifest. If the archive is negotiated with
+// the Accept header and the prefix is a file, the file is served instead.
+func (s *Service) serveArchive(address swarm.Address, prefix, format string, negotiated bool, w http.ResponseWriter, r *http.Request) {
+	logger := tracing.NewLoggerWithTraceID(r.Context(), s.logger)
+	reference := address
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	ls := loadsave.NewReadonly(s.storer)
+	m, err := manifest.NewDefaultManifestReference(address, ls)
+	if err != nil {
+		logger.Debug("bzz archive: not manifest", "address", address, "error", err)
+		logger.Error(nil, "bzz archive: not manifest")
+		jsonhttp.NotFound(w, nil)
+		return
+	}
+
+	// the archive of the feed manifest is the archive of its latest update
+	var feedIndex string
+	if l, err := s.manifestFeed(ctx, m); err == nil {
+		ch, cur, _, err := l.At(ctx, time.Now().Unix(), 0)
+		if err != nil || ch == nil {
+			logger.Debug("bzz archive: feed lookup failed", "error", err)
+			logger.Error(nil, "bzz archive: feed lookup failed")
+			jsonhttp.NotFound(w, "feed not found")
+			return
+		}
+		ref, _, err := parseFeedUpdate(ch)
+		if err != nil {
+			logger.Debug("bzz archive: parse feed update failed", "error", err)
+			logger.Error(nil, "bzz archive: parse feed update failed")
+			jsonhttp.InternalServerError(w, "parse feed update")
+			return
+		}
+		curBytes, err := cur.MarshalBinary()
+		if err != nil {
+			logger.Debug("bzz archive: marshal feed index failed", "error", err)
+			logger.Error(nil, "bzz archive: marshal feed index failed")
+			jsonhttp.InternalServerError(w, "marshal index")
+			return
+		}
+		if m, err = manifest.NewDefaultManifestReference(ref, ls); err != nil {
+			logger.Debug("bzz archive: not manifest", "address", ref, "error", err)
+			logger.Error(nil, "bzz archive: not manifest")
+			jsonhttp.NotFound(w, nil)
+			return
+		}
+		address = ref
+		feedIndex = hex.EncodeToString(curBytes)
+	}
+
+	dir := prefix
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		if negotiated {
+			if _, err := m.Lookup(ctx, prefix); err == nil {
+				s.serveReference(reference, prefix, w, r)
+				return
+			}
+		}
+		dir = prefix + "/"
+	}
+
+	if feedIndex != "" {
+		w.Header().Set(SwarmFeedIndexHeader, feedIndex)
+		w.Header().Set("Access-Control-Expose-Headers", SwarmFeedIndexHeader)
+	}
+
+	files := make(chan chan archiveFile, archiveFetchConcurrency)
+	go func() {
+		defer close(files)
+
+		err := m.IterateEntries(ctx, prefix, func(path string, entry manifest.Entry) error {
+			// the siblings sharing the name of the directory are skipped
+			if path != prefix && !strings.HasPrefix(path, dir) {
+				return nil
+			}
+			// the paths which cannot be extracted safely are skipped
+			if !fs.ValidPath(path) {
+				logger.Debug("bzz archive: skipping invalid path", "path", path)
+				return nil
+			}
+			fileC := make(chan archiveFile, 1)
+			select {
+			case files <- fileC:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			go func() { fileC <- s.fetchArchiveFile(ctx, path, entry) }()
+			return nil
+		})
+		if err != nil {
+			fileC := make(chan archiveFile, 1)
+			fileC <- archiveFile{err: fmt.Errorf("%w: %v", errArchiveManifest, err)}
+			select {
+			case files <- fileC:
+			case <-ctx.Done():
+			}
+		}
+	}()
+
+	var (
+		tw = &writeTracker{ResponseWriter: w}
+		aw archiveWriter
+	)
+	switch format {
+	case archiveFormatZip:
+		w.Header().Set(contentTypeHeader, contentTypeZip)
+		aw = &zipArchiveWriter{w: zip.NewWriter(tw)}
+	default:
+		w.Header().Set(contentTypeHeader, contentTypeTar)
+		aw = &tarArchiveWriter{w: tar.NewWriter(tw)}
+	}
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s.%s\"", reference, format))
+	w.Header().Add("Access-Control-Expose-Headers", "Content-Disposition")
+
+	err = func() error {
+		count := 0
+		for fileC := range files {
+			f := <-fileC
+			if f.err != nil {
+				return f.err
+			}
+			fw, err := aw.Create(f.path, f.size, f.metadata)
+			if err != nil {
+				return fmt.Errorf("file %s: %w", f.path, err)
+			}
+			if _, err := io.Copy(fw, f.reader); err != nil {
+				return fmt.Errorf("file %s: %w", f.path, err)
+			}
+			count++
+		}
+		if count == 0 {
+			return errArchiveEmpty
+		}
+		return aw.Close()
+	}()
+	if err == nil {
+		return
+	}
+
+	logger.Debug("bzz archive: write archive failed", "address", address, "prefix", prefix, "error", err)
+	logger.Error(nil, "bzz archive: write archive failed")
+	if tw.written {
+		// abort the response so that the incomplete archive is not mistaken for a complete one
+		panic(http.ErrAbortHandler)
+	}
+	w.Header().Del("Content-Disposition")
+	switch {
+	case errors.Is(err, errArchiveEmpty):
+		jsonhttp.NotFound(w, "path address not found")
+	case errors.Is(err, errArchiveManifest), errors.Is(err, storage.ErrNotFound):
+		jsonhttp.NotFound(w, nil)
+	default:
+		jsonhttp.InternalServerError(w, "write archive failed")
+	}
+}
@@ -0,0 +1,251 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"io"
+	"net/http"
+	"path"
+	"reflect"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/log"
+	mockpost "github.com/ethersphere/bee/pkg/postage/mock"
+	statestore "github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/storage/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/tags"
+	"gitlab.com/nolash/go-mockbytes"
+)
+
+func TestBzzArchive(t *testing.T) {
+	large, err := mockbytes.New(0, mockbytes.MockTypeStandard).WithModulus(255).SequentialBytes(swarm.ChunkSize*64 + 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		client, _, _, _ = newTestServer(t, testServerOptions{
+			Storer: mock.NewStorer(),
+			Tags:   tags.NewTags(statestore.NewStateStore(), log.Noop),
+			Post:   mockpost.New(mockpost.WithAcceptAll()),
+		})
+		files = []f{
+			{data: []byte("<h1>Swarm"), name: "index.html"},
+			{data: []byte("robots"), name: "robots.txt"},
+			{data: []byte("first image"), name: "1.png", dir: "img"},
+			{data: []byte("second image"), name: "2.png", dir: "img"},
+			{data: large, name: "large.bin", dir: "video"},
+			{data: []byte("image list"), name: "imgs.txt"},
+		}
+		// the files in the order of their paths
+		sorted = []f{files[2], files[3], files[5], files[0], files[1], files[4]}
+		upload = func(t *testing.T, contentType string, archive io.Reader) swarm.Address {
+			t.Helper()
+			var res api.BzzUploadResponse
+			jsonhttptest.Request(t, client, http.MethodPost, "/bzz", http.StatusCreated,
+				jsonhttptest.WithRequestHeader(api.SwarmPostageBatchIdHeader, batchOkStr),
+				jsonhttptest.WithRequestHeader(api.SwarmCollectionHeader, "true"),
+				jsonhttptest.WithRequestHeader(api.SwarmIndexDocumentHeader, "index.html"),
+				jsonhttptest.WithRequestHeader("Content-Type", contentType),
+				jsonhttptest.WithRequestBody(archive),
+				jsonhttptest.WithUnmarshalJSONResponse(&res),
+			)
+			return res.Reference
+		}
+		reference = upload(t, api.ContentTypeTar, tarFiles(t, files))
+		archive   []byte
+	)
+
+	t.Run("tar", func(t *testing.T) {
+		header := jsonhttptest.Request(t, client, http.MethodGet, "/bzz/"+reference.String()+"?archive=tar", http.StatusOK,
+			jsonhttptest.WithPutResponseBody(&archive),
+		)
+		if got := header.Get("Content-Type"); got != "application/x-tar" {
+			t.Fatalf("got content type %q, want %q", got, "application/x-tar")
+		}
+
+		tr := tar.NewReader(bytes.NewReader(archive))
+		for _, file := range sorted {
+			h, err := tr.Next()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := path.Join(file.dir, file.name); h.Name != want {
+				t.Fatalf("got file %q, want %q", h.Name, want)
+			}
+			data, err := io.ReadAll(tr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(data, file.data) {
+				t.Fatalf("file %s: data mismatch", h.Name)
+			}
+			if got := h.PAXRecords["SWARM.Filename"]; got != file.name {
+				t.Fatalf("file %s: got filename %q, want %q", h.Name, got, file.name)
+			}
+		}
+		if h, err := tr.Next(); err != io.EOF {
+			t.Fatalf("unexpected file %v, error %v", h, err)
+		}
+	})
+
+	t.Run("tar round trip", func(t *testing.T) {
+		// the metadata of the files is preserved by the archive, the files
+		// are added to the manifest in the order of their paths
+		want := upload(t, api.ContentTypeTar, tarFiles(t, sorted))
+		if got := upload(t, api.ContentTypeTar, bytes.NewReader(archive)); !got.Equal(want) {
+			t.Fatalf("got reference %s, want %s", got, want)
+		}
+	})
+
+	t.Run("zip round trip", func(t *testing.T) {
+		var body []byte
+		jsonhttptest.Request(t, client, http.MethodGet, "/bzz/"+reference.String()+"?archive=zip", http.StatusOK,
+			jsonhttptest.WithPutResponseBody(&body),
+		)
+
+		want := upload(t, api.ContentTypeTar, tarFiles(t, sorted))
+		if got := upload(t, api.ContentTypeZip, bytes.NewReader(body)); !got.Equal(want) {
+			t.Fatalf("got reference %s, want %s", got, want)
+		}
+	})
+
+	t.Run("zip prefix", func(t *testing.T) {
+		var body []byte
+		header := jsonhttptest.Request(t, client, http.MethodGet, "/bzz/"+reference.String()+"/img/", http.StatusOK,
+			jsonhttptest.WithRequestHeader("Accept", "application/zip"),
+			jsonhttptest.WithPutResponseBody(&body),
+		)
+		if got := header.Get("Content-Type"); got != "application/zip" {
+			t.Fatalf("got content type %q, want %q", got, "application/zip")
+		}
+
+		zr, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(zr.File) != 2 {
+			t.Fatalf("got %d files, want 2", len(zr.File))
+		}
+		for i, file := range sorted[:2] {
+			zf := zr.File[i]
+			if want := path.Join(file.dir, file.name); zf.Name != want {
+				t.Fatalf("got file %q, want %q", zf.Name, want)
+			}
+			if !bytes.Contains(zf.Extra, []byte("Content-Type=image%2Fpng")) {
+				t.Fatalf("file %s: content type not in extra field %q", zf.Name, zf.Extra)
+			}
+			rc, err := zf.Open()
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, err := io.ReadAll(rc)
+			rc.Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(data, file.data) {
+				t.Fatalf("file %s: data mismatch", zf.Name)
+			}
+		}
+	})
+
+	t.Run("prefix without slash", func(t *testing.T) {
+		// the prefix is the directory, without the siblings sharing its name
+		var body []byte
+		jsonhttptest.Request(t, client, http.MethodGet, "/bzz/"+reference.String()+"/img?archive=tar", http.StatusOK,
+			jsonhttptest.WithPutResponseBody(&body),
+		)
+		if got, want := tarNames(t, body), []string{"img/1.png", "img/2.png"}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("got files %q, want %q", got, want)
+		}
+	})
+
+	t.Run("file", func(t *testing.T) {
+		var body []byte
+		jsonhttptest.Request(t, client, http.MethodGet, "/bzz/"+reference.String()+"/robots.txt?archive=tar", http.StatusOK,
+			jsonhttptest.WithPutResponseBody(&body),
+		)
+		if got, want := tarNames(t, body), []string{"robots.txt"}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("got files %q, want %q", got, want)
+		}
+	})
+
+	t.Run("file accept", func(t *testing.T) {
+		// the file is served as it is regardless of the Accept header
+		jsonhttptest.Request(t, client, http.MethodGet, "/bzz/"+reference.String()+"/robots.txt", http.StatusOK,
+			jsonhttptest.WithRequestHeader("Accept", "application/x-tar"),
+			jsonhttptest.WithExpectedResponse([]byte("robots")),
+		)
+	})
+
+	t.Run("accept zero quality", func(t *testing.T) {
+		header := jsonhttptest.Request(t, client, http.MethodGet, "/bzz/"+reference.String()+"/img/", http.StatusOK,
+			jsonhttptest.WithRequestHeader("Accept", "application/zip;q=0, application/x-tar;q=0.5"),
+		)
+		if got := header.Get("Content-Type"); got != "application/x-tar" {
+			t.Fatalf("got content type %q, want %q", got, "application/x-tar")
+		}
+
+		// no archive is acceptable, the index document is served
+		jsonhttptest.Request(t, client, http.MethodGet, "/bzz/"+reference.String()+"/", http.StatusOK,
+			jsonhttptest.WithRequestHeader("Accept", "application/x-tar;q=0.0, application/zip; q=0"),
+			jsonhttptest.WithExpectedResponse(files[0].data),
+		)
+	})
+
+	t.Run("invalid format", func(t *testing.T) {
+		jsonhttptest.Request(t, client, http.MethodGet, "/bzz/"+reference.String()+"?archive=rar", http.StatusBadRequest,
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Message: "invalid archive format",
+				Code:    http.StatusBadRequest,
+			}),
+		)
+	})
+
+	t.Run("prefix not found", func(t *testing.T) {
+		jsonhttptest.Request(t, client, http.MethodGet, "/bzz/"+reference.String()+"/audio/?archive=tar", http.StatusNotFound,
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Message: "path address not found",
+				Code:    http.StatusNotFound,
+			}),
+		)
+	})
+
+	t.Run("not manifest", func(t *testing.T) {
+		var res api.BytesPostResponse
+		jsonhttptest.Request(t, client, http.MethodPost, "/bytes", http.StatusCreated,
+			jsonhttptest.WithRequestHeader(api.SwarmPostageBatchIdHeader, batchOkStr),
+			jsonhttptest.WithRequestBody(bytes.NewReader([]byte("not a manifest"))),
+			jsonhttptest.WithUnmarshalJSONResponse(&res),
+		)
+		jsonhttptest.Request(t, client, http.MethodGet, "/bzz/"+res.Reference.String()+"?archive=zip", http.StatusNotFound)
+	})
+}
+
+// tarNames returns the names of the files in the tar archive.
+func tarNames(t *testing.T, archive []byte) []string {
+	t.Helper()
+
+	var names []string
+	tr := tar.NewReader(bytes.NewReader(archive))
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			return names
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, h.Name)
+	}
+}
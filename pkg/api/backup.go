@@ -0,0 +1,52 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"archive/tar"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/localstore"
+)
+
+// writeTracker records whether anything was written to the response.
+type writeTracker struct {
+	http.ResponseWriter
+	written bool
+}
+
+func (w *writeTracker) Write(b []byte) (int, error) {
+	w.written = true
+	return w.ResponseWriter.Write(b)
+}
+
+// backupHandler streams a consistent backup of the local storage
+// and of the state store as a tar archive.
+func (s *Service) backupHandler(w http.ResponseWriter, r *http.Request) {
+	name := fmt.Sprintf("bee-backup-%s.tar", time.Now().UTC().Format("20060102T150405Z"))
+	w.Header().Set("Content-Type", contentTypeTar)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+
+	tw := &writeTracker{ResponseWriter: w}
+	archive := tar.NewWriter(tw)
+	err := s.localStore.Backup(r.Context(), localstore.NewTarBackupWriter(archive))
+	if err == nil {
+		err = archive.Close()
+	}
+	if err != nil {
+		s.logger.Debug("backup failed", "error", err)
+		s.logger.Error(nil, "backup failed")
+		if !tw.written {
+			w.Header().Del("Content-Disposition")
+			jsonhttp.InternalServerError(w, "backup failed")
+			return
+		}
+		// abort the response so that the incomplete archive is not mistaken for a complete one
+		panic(http.ErrAbortHandler)
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/localstore"
+	"github.com/ethersphere/bee/pkg/log"
+	"github.com/ethersphere/bee/pkg/storage"
+	testingc "github.com/ethersphere/bee/pkg/storage/testing"
+)
+
+func TestBackup(t *testing.T) {
+	storer, err := localstore.New("", make([]byte, 32), nil, nil, log.Noop)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = storer.Close() })
+
+	ch := testingc.GenerateTestRandomChunk()
+	if _, err := storer.Put(context.Background(), storage.ModePutUpload, ch); err != nil {
+		t.Fatal(err)
+	}
+
+	client, _, _, _ := newTestServer(t, testServerOptions{
+		DebugAPI:   true,
+		LocalStore: storer,
+	})
+
+	var body []byte
+	jsonhttptest.Request(t, client, http.MethodGet, "/backup", http.StatusOK,
+		jsonhttptest.WithPutResponseBody(&body),
+	)
+
+	dataDir := t.TempDir()
+	r := localstore.NewTarBackupReader(tar.NewReader(bytes.NewReader(body)))
+	if err := localstore.Restore(context.Background(), r, dataDir); err != nil {
+		t.Fatal(err)
+	}
+
+	restored, err := localstore.New(filepath.Join(dataDir, "localstore"), make([]byte, 32), nil, nil, log.Noop)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer restored.Close()
+
+	got, err := restored.Get(context.Background(), storage.ModeGetLookup, ch.Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got.Data(), ch.Data()) {
+		t.Fatal("restored chunk data mismatch")
+	}
+}
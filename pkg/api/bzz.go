@@ -247,6 +247,14 @@ func (s *Service) bzzDownloadHandler(w http.ResponseWriter, r *http.Request) {
 		pathVar += "/"
 	}
 
+	archiveFormat, negotiated, err := requestArchiveFormat(r)
+	if err != nil {
+		logger.Debug("bzz download: parse archive format failed", "error", err)
+		logger.Error(nil, "bzz download: parse archive format failed")
+		jsonhttp.BadRequest(w, errInvalidArchiveFormat)
+		return
+	}
+
 	address, err := s.resolveNameOrAddress(nameOrHex)
 	if err != nil {
 		logger.Debug("bzz download: parse address string failed", "string", nameOrHex, "error", err)
@@ -255,6 +263,11 @@ func (s *Service) bzzDownloadHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if archiveFormat != "" {
+		s.serveArchive(address, pathVar, archiveFormat, negotiated, w, r)
+		return
+	}
+
 	s.serveReference(address, pathVar, w, r)
 }
 
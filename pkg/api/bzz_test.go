@@ -642,4 +642,10 @@ func TestFeedIndirection(t *testing.T) {
 	jsonhttptest.Request(t, client, http.MethodGet, bzzDownloadResource(manifRef.String(), ""), http.StatusOK,
 		jsonhttptest.WithExpectedResponse(updateData),
 	)
+
+	// the archive is named after the requested reference, not the feed update
+	header := jsonhttptest.Request(t, client, http.MethodGet, bzzDownloadResource(manifRef.String(), "?archive=tar"), http.StatusOK)
+	if got, want := header.Get("Content-Disposition"), fmt.Sprintf("attachment; filename=\"%s.tar\"", manifRef); got != want {
+		t.Fatalf("got content disposition %q, want %q", got, want)
+	}
 }
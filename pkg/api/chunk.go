@@ -28,8 +28,12 @@ type chunkAddressResponse struct {
 	Reference swarm.Address `json:"reference"`
 }
 
+// processUploadRequest prepares the upload of the chunks. The chunks
+// stamped by the client are put as they are, all the other chunks
+// are stamped with the batch of the request.
 func (s *Service) processUploadRequest(
 	r *http.Request,
+	stamped bool,
 ) (ctx context.Context, tag *tags.Tag, putter storage.Putter, waitFn func() error, err error) {
 
 	if h := r.Header.Get(SwarmTagHeader); h != "" {
@@ -46,7 +50,12 @@ func (s *Service) processUploadRequest(
 		ctx = r.Context()
 	}
 
-	putter, wait, err := s.newStamperPutter(r)
+	var wait func() error
+	if stamped {
+		putter, wait, err = s.newStampedPutter(r)
+	} else {
+		putter, wait, err = s.newStamperPutter(r)
+	}
 	if err != nil {
 		s.logger.Debug("chunk upload: putter failed", "error", err)
 		s.logger.Error(nil, "chunk upload: putter failed")
@@ -63,7 +72,15 @@ func (s *Service) processUploadRequest(
 }
 
 func (s *Service) chunkUploadHandler(w http.ResponseWriter, r *http.Request) {
-	ctx, tag, putter, wait, err := s.processUploadRequest(r)
+	stamp, err := requestPostageStamp(r)
+	if err != nil {
+		s.logger.Debug("chunk upload: parse postage stamp failed", "error", err)
+		s.logger.Error(nil, "chunk upload: parse postage stamp failed")
+		jsonhttp.BadRequest(w, err.Error())
+		return
+	}
+
+	ctx, tag, putter, wait, err := s.processUploadRequest(r, stamp != nil)
 	if err != nil {
 		jsonhttp.BadRequest(w, err.Error())
 		return
@@ -105,6 +122,21 @@ func (s *Service) chunkUploadHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if stamp != nil {
+		stamped, err := postage.ValidStamp(s.batchStore)(chunk, stamp)
+		if err != nil {
+			s.logger.Debug("chunk upload: invalid postage stamp", "chunk_address", chunk.Address(), "error", err)
+			s.logger.Error(nil, "chunk upload: invalid postage stamp")
+			if errors.Is(err, postage.ErrNotFound) {
+				jsonhttp.BadRequest(w, "batch not found")
+				return
+			}
+			jsonhttp.BadRequest(w, errInvalidPostageStamp.Error())
+			return
+		}
+		chunk = stamped
+	}
+
 	seen, err := putter.Put(ctx, requestModePut(r), chunk)
 	if err != nil {
 		s.logger.Debug("chunk upload: write chunk failed", "chunk_address", chunk.Address(), "error", err)
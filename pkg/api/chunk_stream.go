@@ -25,9 +25,17 @@ const streamReadTimeout = 15 * time.Minute
 
 var successWsMsg = []byte{}
 
+// chunkUploadStreamHandler uploads the chunks sent as binary websocket
+// messages. The stream opened with the stamped header uploads the chunks
+// stamped by the client, each message starting with the serialized stamp.
 func (s *Service) chunkUploadStreamHandler(w http.ResponseWriter, r *http.Request) {
+	stamped := strings.ToLower(r.Header.Get(SwarmPostageStampedHeader)) == "true"
+	if stamped && r.Header.Get(SwarmPostageBatchIdHeader) != "" {
+		jsonhttp.BadRequest(w, errStampedWithBatch)
+		return
+	}
 
-	_, tag, putter, wait, err := s.processUploadRequest(r)
+	_, tag, putter, wait, err := s.processUploadRequest(r, stamped)
 	if err != nil {
 		jsonhttp.BadRequest(w, err.Error())
 		return
@@ -60,6 +68,7 @@ func (s *Service) chunkUploadStreamHandler(w http.ResponseWriter, r *http.Reques
 		putter,
 		requestModePut(r),
 		strings.ToLower(r.Header.Get(SwarmPinHeader)) == "true",
+		stamped,
 		wait,
 	)
 }
@@ -71,6 +80,7 @@ func (s *Service) handleUploadStream(
 	putter storage.Putter,
 	mode storage.ModePut,
 	pin bool,
+	stamped bool,
 	wait func() error,
 ) {
 	defer s.wsWg.Done()
@@ -104,6 +114,8 @@ func (s *Service) handleUploadStream(
 		return nil
 	}
 
+	validStamp := postage.ValidStamp(s.batchStore)
+
 	sendErrorClose := func(code int, errmsg string) {
 		err := conn.WriteControl(
 			websocket.CloseMessage,
@@ -161,6 +173,17 @@ func (s *Service) handleUploadStream(
 			}
 		}
 
+		var stamp []byte
+		if stamped {
+			if len(msg) < postage.StampSize {
+				s.logger.Debug("chunk upload stream: missing postage stamp")
+				s.logger.Error(nil, "chunk upload stream: missing postage stamp")
+				sendErrorClose(websocket.CloseUnsupportedData, errMissingPostageStamp.Error())
+				return
+			}
+			stamp, msg = msg[:postage.StampSize], msg[postage.StampSize:]
+		}
+
 		if len(msg) < swarm.SpanSize {
 			s.logger.Debug("chunk upload stream: insufficient data")
 			s.logger.Error(nil, "chunk upload stream: insufficient data")
@@ -174,6 +197,20 @@ func (s *Service) handleUploadStream(
 			return
 		}
 
+		if stamped {
+			chunk, err = validStamp(chunk, stamp)
+			if err != nil {
+				s.logger.Debug("chunk upload stream: invalid postage stamp", "error", err)
+				s.logger.Error(nil, "chunk upload stream: invalid postage stamp")
+				if errors.Is(err, postage.ErrNotFound) {
+					sendErrorClose(websocket.CloseUnsupportedData, "batch not found")
+					return
+				}
+				sendErrorClose(websocket.CloseUnsupportedData, errInvalidPostageStamp.Error())
+				return
+			}
+		}
+
 		seen, err := putter.Put(ctx, mode, chunk)
 		if err != nil {
 			s.logger.Debug("chunk upload stream: write chunk failed", "address", chunk.Address(), "error", err)
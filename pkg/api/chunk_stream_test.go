@@ -7,11 +7,14 @@ package api_test
 import (
 	"bytes"
 	"context"
+	"errors"
 	"net/http"
 	"testing"
 	"time"
 
 	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
 	"github.com/ethersphere/bee/pkg/log"
 	pinning "github.com/ethersphere/bee/pkg/pinning/mock"
 	mockpost "github.com/ethersphere/bee/pkg/postage/mock"
@@ -116,3 +119,103 @@ func TestChunkUploadStream(t *testing.T) {
 		}
 	})
 }
+
+// TestChunkUploadStreamStamped tests the upload of the chunks
+// stamped by the client with the stamps in the messages.
+func TestChunkUploadStreamStamped(t *testing.T) {
+	wsHeaders := http.Header{}
+	wsHeaders.Set(api.SwarmDeferredUploadHeader, "true")
+	wsHeaders.Set("Content-Type", "application/octet-stream")
+	wsHeaders.Set(api.SwarmPostageStampedHeader, "true")
+
+	var (
+		batchStore, chunks = newStampedChunks(t, 5)
+		storerMock         = mock.NewStorer()
+		_, wsConn, _, _    = newTestServer(t, testServerOptions{
+			Storer:     storerMock,
+			Tags:       tags.NewTags(statestore.NewStateStore(), log.Noop),
+			Post:       mockpost.New(),
+			BatchStore: batchStore,
+			WsPath:     "/chunks/stream",
+			WsHeaders:  wsHeaders,
+		})
+	)
+
+	write := func(t *testing.T, msg []byte) {
+		t.Helper()
+
+		if err := wsConn.SetWriteDeadline(time.Now().Add(time.Second)); err != nil {
+			t.Fatal(err)
+		}
+		if err := wsConn.WriteMessage(websocket.BinaryMessage, msg); err != nil {
+			t.Fatal(err)
+		}
+		if err := wsConn.SetReadDeadline(time.Now().Add(time.Second)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Run("upload and verify", func(t *testing.T) {
+		for _, ch := range chunks[:4] {
+			write(t, append(mustMarshalStamp(t, ch), ch.Data()...))
+
+			mt, msg, err := wsConn.ReadMessage()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if mt != websocket.BinaryMessage || !bytes.Equal(msg, api.SuccessWsMsg) {
+				t.Fatal("invalid response", mt, string(msg))
+			}
+		}
+
+		for _, c := range chunks[:4] {
+			ch, err := storerMock.Get(context.Background(), storage.ModeGetRequest, c.Address())
+			if err != nil {
+				t.Fatal("failed to get chunk after upload", err)
+			}
+			if !ch.Equal(c) {
+				t.Fatal("invalid chunk read")
+			}
+		}
+	})
+
+	t.Run("close on invalid stamp", func(t *testing.T) {
+		write(t, append(mustMarshalStamp(t, chunks[0]), chunks[4].Data()...))
+
+		_, _, err := wsConn.ReadMessage()
+		var closeErr *websocket.CloseError
+		if !errors.As(err, &closeErr) || closeErr.Text != "invalid postage stamp" {
+			t.Fatalf("got error %v, want close with invalid postage stamp", err)
+		}
+	})
+}
+
+// TestChunkUploadStreamHeaders tests that the chunks stamped by the
+// client are uploaded only if the stream is opened for them explicitly.
+func TestChunkUploadStreamHeaders(t *testing.T) {
+	client, _, _, _ := newTestServer(t, testServerOptions{
+		Storer: mock.NewStorer(),
+		Tags:   tags.NewTags(statestore.NewStateStore(), log.Noop),
+		Post:   mockpost.New(mockpost.WithAcceptAll()),
+	})
+
+	t.Run("missing batch", func(t *testing.T) {
+		jsonhttptest.Request(t, client, http.MethodGet, "/chunks/stream", http.StatusBadRequest,
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Message: "postage batch id: invalid postage batch id",
+				Code:    http.StatusBadRequest,
+			}),
+		)
+	})
+
+	t.Run("stamped with batch", func(t *testing.T) {
+		jsonhttptest.Request(t, client, http.MethodGet, "/chunks/stream", http.StatusBadRequest,
+			jsonhttptest.WithRequestHeader(api.SwarmPostageStampedHeader, "true"),
+			jsonhttptest.WithRequestHeader(api.SwarmPostageBatchIdHeader, batchOkStr),
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Message: "postage batch id not allowed for stamped chunks",
+				Code:    http.StatusBadRequest,
+			}),
+		)
+	})
+}
@@ -7,13 +7,18 @@ package api_test
 import (
 	"bytes"
 	"context"
+	"encoding/hex"
 	"io"
 	"net/http"
 	"testing"
 
+	"github.com/ethersphere/bee/pkg/crypto"
 	"github.com/ethersphere/bee/pkg/log"
 	pinning "github.com/ethersphere/bee/pkg/pinning/mock"
+	"github.com/ethersphere/bee/pkg/postage"
+	mockbatchstore "github.com/ethersphere/bee/pkg/postage/batchstore/mock"
 	mockpost "github.com/ethersphere/bee/pkg/postage/mock"
+	postagetesting "github.com/ethersphere/bee/pkg/postage/testing"
 	statestore "github.com/ethersphere/bee/pkg/statestore/mock"
 
 	"github.com/ethersphere/bee/pkg/tags"
@@ -206,3 +211,109 @@ func TestHasChunkHandler(t *testing.T) {
 		}
 	})
 }
+
+// newStampedChunks returns a batch store with a batch of a new owner
+// and n random chunks stamped by the owner with the batch.
+func newStampedChunks(t *testing.T, n int) (postage.Storer, []swarm.Chunk) {
+	t.Helper()
+
+	key, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := crypto.NewDefaultSigner(key)
+	owner, err := signer.EthereumAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	batch := postagetesting.MustNewBatch(postagetesting.WithOwner(owner.Bytes()))
+	issuer := postage.NewStampIssuer("", "", batch.ID, batch.Value, batch.Depth, batch.BucketDepth, 0, batch.Immutable)
+	stamper := postage.NewStamper(issuer, signer)
+
+	chunks := make([]swarm.Chunk, n)
+	for i := range chunks {
+		ch := testingc.GenerateTestRandomChunk()
+		stamp, err := stamper.Stamp(ch.Address())
+		if err != nil {
+			t.Fatal(err)
+		}
+		chunks[i] = ch.WithStamp(stamp)
+	}
+	return mockbatchstore.New(mockbatchstore.WithBatch(batch)), chunks
+}
+
+func mustMarshalStamp(t *testing.T, ch swarm.Chunk) []byte {
+	t.Helper()
+
+	stamp, err := ch.Stamp().MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return stamp
+}
+
+// TestChunkUploadStamped tests the upload of the chunks stamped by the client.
+func TestChunkUploadStamped(t *testing.T) {
+	var (
+		batchStore, chunks = newStampedChunks(t, 2)
+		storerMock         = mock.NewStorer()
+		client, _, _, _    = newTestServer(t, testServerOptions{
+			Storer:     storerMock,
+			Tags:       tags.NewTags(statestore.NewStateStore(), log.Noop),
+			Post:       mockpost.New(),
+			BatchStore: batchStore,
+		})
+	)
+
+	t.Run("ok", func(t *testing.T) {
+		ch := chunks[0]
+		jsonhttptest.Request(t, client, http.MethodPost, "/chunks", http.StatusCreated,
+			jsonhttptest.WithRequestHeader(api.SwarmDeferredUploadHeader, "true"),
+			jsonhttptest.WithRequestHeader(api.SwarmPostageStampHeader, hex.EncodeToString(mustMarshalStamp(t, ch))),
+			jsonhttptest.WithRequestBody(bytes.NewReader(ch.Data())),
+			jsonhttptest.WithExpectedJSONResponse(api.ChunkAddressResponse{Reference: ch.Address()}),
+		)
+
+		got, err := storerMock.Get(context.Background(), storage.ModeGetRequest, ch.Address())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(mustMarshalStamp(t, got), mustMarshalStamp(t, ch)) {
+			t.Fatal("stored chunk stamp mismatch")
+		}
+	})
+
+	t.Run("malformed stamp", func(t *testing.T) {
+		jsonhttptest.Request(t, client, http.MethodPost, "/chunks", http.StatusBadRequest,
+			jsonhttptest.WithRequestHeader(api.SwarmPostageStampHeader, "abcd"),
+			jsonhttptest.WithRequestBody(bytes.NewReader(chunks[1].Data())),
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Message: "invalid postage stamp",
+				Code:    http.StatusBadRequest,
+			}),
+		)
+	})
+
+	t.Run("stamp of another chunk", func(t *testing.T) {
+		jsonhttptest.Request(t, client, http.MethodPost, "/chunks", http.StatusBadRequest,
+			jsonhttptest.WithRequestHeader(api.SwarmPostageStampHeader, hex.EncodeToString(mustMarshalStamp(t, chunks[0]))),
+			jsonhttptest.WithRequestBody(bytes.NewReader(chunks[1].Data())),
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Message: "invalid postage stamp",
+				Code:    http.StatusBadRequest,
+			}),
+		)
+	})
+
+	t.Run("unknown batch", func(t *testing.T) {
+		_, other := newStampedChunks(t, 1)
+		jsonhttptest.Request(t, client, http.MethodPost, "/chunks", http.StatusBadRequest,
+			jsonhttptest.WithRequestHeader(api.SwarmPostageStampHeader, hex.EncodeToString(mustMarshalStamp(t, other[0]))),
+			jsonhttptest.WithRequestBody(bytes.NewReader(other[0].Data())),
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Message: "batch not found",
+				Code:    http.StatusBadRequest,
+			}),
+		)
+	})
+}
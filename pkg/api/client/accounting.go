@@ -0,0 +1,57 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// Balances returns the balances with all the peers,
+// including the surplus balance.
+func (c *Client) Balances(ctx context.Context) (res api.BalancesResponse, err error) {
+	err = c.request(ctx, http.MethodGet, "/balances", nil, nil, nil, &res)
+	return res, err
+}
+
+// PeerBalance returns the balance with the peer,
+// including the surplus balance.
+func (c *Client) PeerBalance(ctx context.Context, peer swarm.Address) (res api.BalanceResponse, err error) {
+	err = c.request(ctx, http.MethodGet, "/balances/"+peer.String(), nil, nil, nil, &res)
+	return res, err
+}
+
+// ConsumedBalances returns the past due consumption balances with all the peers.
+func (c *Client) ConsumedBalances(ctx context.Context) (res api.BalancesResponse, err error) {
+	err = c.request(ctx, http.MethodGet, "/consumed", nil, nil, nil, &res)
+	return res, err
+}
+
+// ConsumedPeerBalance returns the past due consumption balance with the peer.
+func (c *Client) ConsumedPeerBalance(ctx context.Context, peer swarm.Address) (res api.BalanceResponse, err error) {
+	err = c.request(ctx, http.MethodGet, "/consumed/"+peer.String(), nil, nil, nil, &res)
+	return res, err
+}
+
+// Settlements returns the settlements with all the peers.
+func (c *Client) Settlements(ctx context.Context) (res api.SettlementsResponse, err error) {
+	err = c.request(ctx, http.MethodGet, "/settlements", nil, nil, nil, &res)
+	return res, err
+}
+
+// PeerSettlement returns the settlement with the peer.
+func (c *Client) PeerSettlement(ctx context.Context, peer swarm.Address) (res api.SettlementResponse, err error) {
+	err = c.request(ctx, http.MethodGet, "/settlements/"+peer.String(), nil, nil, nil, &res)
+	return res, err
+}
+
+// TimeSettlements returns the time based settlements with all the peers.
+func (c *Client) TimeSettlements(ctx context.Context) (res api.SettlementsResponse, err error) {
+	err = c.request(ctx, http.MethodGet, "/timesettlements", nil, nil, nil, &res)
+	return res, err
+}
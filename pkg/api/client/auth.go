@@ -0,0 +1,50 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/ethersphere/bee/pkg/api"
+)
+
+// Auth requests a new security token with the role valid for the expiry
+// seconds, authorized with the admin password of the node. The token is
+// used by the client returned from the WithToken method.
+func (c *Client) Auth(ctx context.Context, password, role string, expiry int) (res api.SecurityTokenResponse, err error) {
+	body, err := json.Marshal(api.SecurityTokenRequest{
+		Role:   role,
+		Expiry: expiry,
+	})
+	if err != nil {
+		return res, err
+	}
+
+	r, err := c.newRequest(ctx, http.MethodPost, "/auth", nil, bytes.NewReader(body))
+	if err != nil {
+		return res, err
+	}
+	r.Header.Set("Content-Type", "application/json")
+	r.SetBasicAuth("", password)
+
+	resp, err := c.send(r)
+	if err != nil {
+		return res, err
+	}
+	defer resp.Body.Close()
+
+	err = decodeJSON(resp.Body, &res)
+	return res, err
+}
+
+// Refresh requests a new security token valid for the expiry seconds
+// in place of the token of the client.
+func (c *Client) Refresh(ctx context.Context, expiry int) (res api.SecurityTokenResponse, err error) {
+	err = c.request(ctx, http.MethodPost, "/refresh", nil, nil, api.SecurityTokenRequest{Expiry: expiry}, &res)
+	return res, err
+}
@@ -0,0 +1,33 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// UploadBytes uploads the raw data streamed from the reader.
+func (c *Client) UploadBytes(ctx context.Context, data io.Reader, o *UploadOptions) (res api.BytesPostResponse, err error) {
+	header := o.header()
+	header.Set("Content-Type", "application/octet-stream")
+	err = c.request(ctx, http.MethodPost, "/bytes", nil, header, data, &res)
+	return res, err
+}
+
+// DownloadBytes returns the reader of the raw data on the address.
+// The caller is responsible for closing it.
+func (c *Client) DownloadBytes(ctx context.Context, address swarm.Address) (io.ReadCloser, error) {
+	return c.download(ctx, "/bytes/"+address.String(), nil)
+}
+
+// HasBytes reports whether the root chunk of the raw data is available.
+func (c *Client) HasBytes(ctx context.Context, address swarm.Address) (bool, error) {
+	return c.has(ctx, http.MethodHead, "/bytes/"+address.String())
+}
@@ -0,0 +1,204 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package client_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/api/client"
+	pinningmock "github.com/ethersphere/bee/pkg/pinning/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"gitlab.com/nolash/go-mockbytes"
+)
+
+func TestBytes(t *testing.T) {
+	pinning := pinningmock.NewServiceMock()
+	c := newTestServer(t, testServerOptions{
+		Pinning: pinning,
+	})
+	ctx := context.Background()
+
+	content, err := mockbytes.New(0, mockbytes.MockTypeStandard).WithModulus(255).SequentialBytes(swarm.ChunkSize * 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := c.UploadBytes(ctx, bytes.NewReader(content), &client.UploadOptions{
+		BatchID: batchOk,
+		Pin:     true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := swarm.MustParseHexAddress("29a5fb121ce96194ba8b7b823a1f9c6af87e1791f824940a53b5a7efe3f790d9"); !res.Reference.Equal(want) {
+		t.Fatalf("got reference %s, want %s", res.Reference, want)
+	}
+
+	pins, err := pinning.Pins()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pins) != 1 || !pins[0].Equal(res.Reference) {
+		t.Fatalf("unexpected pins %v", pins)
+	}
+
+	has, err := c.HasBytes(ctx, res.Reference)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !has {
+		t.Fatal("uploaded bytes not found")
+	}
+
+	has, err = c.HasBytes(ctx, swarm.MustParseHexAddress("abcd"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if has {
+		t.Fatal("unexpected bytes found")
+	}
+
+	r, err := c.DownloadBytes(ctx, res.Reference)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, content) {
+		t.Fatal("downloaded content differs from the uploaded one")
+	}
+}
+
+func TestBzz(t *testing.T) {
+	c := newTestServer(t, testServerOptions{})
+	ctx := context.Background()
+
+	t.Run("file", func(t *testing.T) {
+		content := []byte("hello swarm")
+
+		res, err := c.UploadFile(ctx, bytes.NewReader(content), "hello.txt", "text/plain", &client.UploadOptions{BatchID: batchOk})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		r, err := c.DownloadFile(ctx, res.Reference, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer r.Close()
+
+		got, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, content) {
+			t.Fatalf("got %q, want %q", got, content)
+		}
+	})
+
+	t.Run("collection", func(t *testing.T) {
+		files := map[string]string{
+			"index.html":     "<h1>index</h1>",
+			"img/logo.svg":   "<svg/>",
+			"docs/about.txt": "about",
+		}
+
+		var buf bytes.Buffer
+		tw := tar.NewWriter(&buf)
+		for _, name := range []string{"index.html", "img/logo.svg", "docs/about.txt"} {
+			if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0600, Size: int64(len(files[name]))}); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := tw.Write([]byte(files[name])); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := tw.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		res, err := c.UploadCollection(ctx, &buf, &client.CollectionOptions{
+			UploadOptions: client.UploadOptions{BatchID: batchOk},
+			IndexDocument: "index.html",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// the index document is served on the root path
+		files[""] = files["index.html"]
+		for path, want := range files {
+			r, err := c.DownloadFile(ctx, res.Reference, path)
+			if err != nil {
+				t.Fatalf("download %q: %v", path, err)
+			}
+			got, err := io.ReadAll(r)
+			r.Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != want {
+				t.Fatalf("download %q: got %q, want %q", path, got, want)
+			}
+		}
+
+		if _, err := c.DownloadFile(ctx, res.Reference, "missing.txt"); !isStatus(err, http.StatusNotFound) {
+			t.Fatalf("expected not found error, got %v", err)
+		}
+
+		r, err := c.DownloadCollection(ctx, res.Reference, "docs/", client.ArchiveTar)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer r.Close()
+		tr := tar.NewReader(r)
+		h, err := tr.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if h.Name != "docs/about.txt" || string(got) != files["docs/about.txt"] {
+			t.Fatalf("unexpected archived file %q with %q", h.Name, got)
+		}
+		if _, err := tr.Next(); err != io.EOF {
+			t.Fatalf("got error %v, want %v", err, io.EOF)
+		}
+
+		// the zip archive of the collection is uploaded as the same collection
+		zr, err := c.DownloadCollection(ctx, res.Reference, "", client.ArchiveZip)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer zr.Close()
+		zipRes, err := c.UploadCollection(ctx, zr, &client.CollectionOptions{
+			UploadOptions: client.UploadOptions{BatchID: batchOk},
+			IndexDocument: "index.html",
+			Archive:       client.ArchiveZip,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, err = c.DownloadFile(ctx, zipRes.Reference, "img/logo.svg")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer r.Close()
+		if got, err := io.ReadAll(r); err != nil || string(got) != files["img/logo.svg"] {
+			t.Fatalf("got %q, error %v, want %q", got, err, files["img/logo.svg"])
+		}
+	})
+}
@@ -0,0 +1,99 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+const (
+	contentTypeTar = "application/x-tar"
+	contentTypeZip = "application/zip"
+)
+
+// The formats of the collection archives.
+const (
+	ArchiveTar = "tar"
+	ArchiveZip = "zip"
+)
+
+// CollectionOptions holds the options of the collection uploads.
+type CollectionOptions struct {
+	UploadOptions
+	// IndexDocument is the path of the default document of the collection.
+	IndexDocument string
+	// ErrorDocument is the path of the document returned
+	// for the paths not found in the collection.
+	ErrorDocument string
+	// Archive is the format of the uploaded archive, ArchiveTar if empty.
+	Archive string
+}
+
+func (o *CollectionOptions) header() http.Header {
+	if o == nil {
+		return (*UploadOptions)(nil).header()
+	}
+	h := o.UploadOptions.header()
+	if o.IndexDocument != "" {
+		h.Set(api.SwarmIndexDocumentHeader, o.IndexDocument)
+	}
+	if o.ErrorDocument != "" {
+		h.Set(api.SwarmErrorDocumentHeader, o.ErrorDocument)
+	}
+	return h
+}
+
+// UploadFile uploads the file streamed from the reader
+// with the name and the content type.
+func (c *Client) UploadFile(ctx context.Context, data io.Reader, name, contentType string, o *UploadOptions) (res api.BzzUploadResponse, err error) {
+	header := o.header()
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	header.Set("Content-Type", contentType)
+
+	query := make(url.Values)
+	if name != "" {
+		query.Set("name", name)
+	}
+	err = c.request(ctx, http.MethodPost, "/bzz", query, header, data, &res)
+	return res, err
+}
+
+// UploadCollection uploads the collection of files streamed from
+// the reader as a tar archive or, with the zip archive option, as
+// a zip archive.
+func (c *Client) UploadCollection(ctx context.Context, archive io.Reader, o *CollectionOptions) (res api.BzzUploadResponse, err error) {
+	header := o.header()
+	header.Set("Content-Type", contentTypeTar)
+	if o != nil && o.Archive == ArchiveZip {
+		header.Set("Content-Type", contentTypeZip)
+	}
+	header.Set(api.SwarmCollectionHeader, "true")
+	err = c.request(ctx, http.MethodPost, "/bzz", nil, header, archive, &res)
+	return res, err
+}
+
+// DownloadFile returns the reader of the file on the path of the manifest
+// on the address. The caller is responsible for closing it.
+func (c *Client) DownloadFile(ctx context.Context, address swarm.Address, path string) (io.ReadCloser, error) {
+	return c.download(ctx, "/bzz/"+address.String()+"/"+strings.TrimPrefix(path, "/"), nil)
+}
+
+// DownloadCollection returns the reader of the archive, in the tar or the
+// zip format, of the files of the collection on the address with the paths
+// beginning with the prefix. The caller is responsible for closing it.
+func (c *Client) DownloadCollection(ctx context.Context, address swarm.Address, prefix, format string) (io.ReadCloser, error) {
+	query := make(url.Values)
+	query.Set("archive", format)
+	return c.download(ctx, "/bzz/"+address.String()+"/"+strings.TrimPrefix(prefix, "/"), query)
+}
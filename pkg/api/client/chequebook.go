@@ -0,0 +1,71 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+	"net/url"
+
+	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// ChequebookBalance returns the balance of the chequebook.
+func (c *Client) ChequebookBalance(ctx context.Context) (res api.ChequebookBalanceResponse, err error) {
+	err = c.request(ctx, http.MethodGet, "/chequebook/balance", nil, nil, nil, &res)
+	return res, err
+}
+
+// ChequebookAddress returns the address of the chequebook contract.
+func (c *Client) ChequebookAddress(ctx context.Context) (res api.ChequebookAddressResponse, err error) {
+	err = c.request(ctx, http.MethodGet, "/chequebook/address", nil, nil, nil, &res)
+	return res, err
+}
+
+// ChequebookDeposit deposits the amount into the chequebook.
+func (c *Client) ChequebookDeposit(ctx context.Context, amount *big.Int) (res api.ChequebookTxResponse, err error) {
+	query := url.Values{"amount": {amount.String()}}
+	err = c.request(ctx, http.MethodPost, "/chequebook/deposit", query, nil, nil, &res)
+	return res, err
+}
+
+// ChequebookWithdraw withdraws the amount from the chequebook.
+func (c *Client) ChequebookWithdraw(ctx context.Context, amount *big.Int) (res api.ChequebookTxResponse, err error) {
+	query := url.Values{"amount": {amount.String()}}
+	err = c.request(ctx, http.MethodPost, "/chequebook/withdraw", query, nil, nil, &res)
+	return res, err
+}
+
+// LastCheques returns the last cheques sent to and received from all the peers.
+func (c *Client) LastCheques(ctx context.Context) (res api.ChequebookLastChequesResponse, err error) {
+	err = c.request(ctx, http.MethodGet, "/chequebook/cheque", nil, nil, nil, &res)
+	return res, err
+}
+
+// PeerLastCheques returns the last cheques sent to and received from the peer.
+func (c *Client) PeerLastCheques(ctx context.Context, peer swarm.Address) (res api.ChequebookLastChequesPeerResponse, err error) {
+	err = c.request(ctx, http.MethodGet, "/chequebook/cheque/"+peer.String(), nil, nil, nil, &res)
+	return res, err
+}
+
+// CashoutStatus returns the status of the last cashout of the cheques of the peer.
+func (c *Client) CashoutStatus(ctx context.Context, peer swarm.Address) (res api.SwapCashoutStatusResponse, err error) {
+	err = c.request(ctx, http.MethodGet, "/chequebook/cashout/"+peer.String(), nil, nil, nil, &res)
+	return res, err
+}
+
+// Cashout cashes out the last cheque received from the peer.
+func (c *Client) Cashout(ctx context.Context, peer swarm.Address) (res api.SwapCashoutResponse, err error) {
+	err = c.request(ctx, http.MethodPost, "/chequebook/cashout/"+peer.String(), nil, nil, nil, &res)
+	return res, err
+}
+
+// Wallet returns the balances of the wallet of the node.
+func (c *Client) Wallet(ctx context.Context) (res api.WalletResponse, err error) {
+	err = c.request(ctx, http.MethodGet, "/wallet", nil, nil, nil, &res)
+	return res, err
+}
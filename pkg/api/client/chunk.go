@@ -0,0 +1,126 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/gorilla/websocket"
+)
+
+// UploadChunk uploads the chunk data, consisting of the span and the
+// payload. If the stamp is not nil, the chunk is stored with the stamp
+// signed by the client instead of being stamped with the batch of the
+// options.
+func (c *Client) UploadChunk(ctx context.Context, data, stamp []byte, o *UploadOptions) (res api.ChunkAddressResponse, err error) {
+	header := o.header()
+	header.Set("Content-Type", "application/octet-stream")
+	if stamp != nil {
+		header.Set(api.SwarmPostageStampHeader, hex.EncodeToString(stamp))
+	}
+	err = c.request(ctx, http.MethodPost, "/chunks", nil, header, bytes.NewReader(data), &res)
+	return res, err
+}
+
+// DownloadChunk returns the data of the chunk on the address.
+func (c *Client) DownloadChunk(ctx context.Context, address swarm.Address) ([]byte, error) {
+	r, err := c.download(ctx, "/chunks/"+address.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return io.ReadAll(r)
+}
+
+// HasChunk reports whether the chunk is in the local store. It
+// can be called on the clients of both the API and the debug API.
+func (c *Client) HasChunk(ctx context.Context, address swarm.Address) (bool, error) {
+	path := "/chunks/" + address.String()
+	has, err := c.has(ctx, http.MethodHead, path)
+	var e *Error
+	if errors.As(err, &e) && e.Code == http.StatusMethodNotAllowed {
+		// the debug API checks the chunk with the GET method
+		return c.has(ctx, http.MethodGet, path)
+	}
+	return has, err
+}
+
+// RemoveChunk removes the chunk from the local store.
+func (c *Client) RemoveChunk(ctx context.Context, address swarm.Address) error {
+	return c.request(ctx, http.MethodDelete, "/chunks/"+address.String(), nil, nil, nil, nil)
+}
+
+// ChunkStream uploads the chunks over a single websocket connection.
+type ChunkStream struct {
+	mu      sync.Mutex
+	conn    *websocket.Conn
+	stamped bool
+}
+
+// OpenChunkStream opens the stream of chunk uploads. If the batch of the
+// options is not set, every chunk has to be uploaded with its stamp.
+func (c *Client) OpenChunkStream(ctx context.Context, o *UploadOptions) (*ChunkStream, error) {
+	stamped := o == nil || o.BatchID == nil
+	header := o.header()
+	if stamped {
+		header.Set(api.SwarmPostageStampedHeader, "true")
+	}
+	conn, err := c.dial(ctx, "/chunks/stream", header)
+	if err != nil {
+		return nil, err
+	}
+	return &ChunkStream{
+		conn:    conn,
+		stamped: stamped,
+	}, nil
+}
+
+// Upload uploads the chunk data, consisting of the span and the payload,
+// and waits until it is stored. The stamp is required only if the stream
+// was opened without a batch.
+func (s *ChunkStream) Upload(data, stamp []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	msg := data
+	if s.stamped {
+		if stamp == nil {
+			return errors.New("chunk stream: missing postage stamp")
+		}
+		msg = append(append(make([]byte, 0, len(stamp)+len(data)), stamp...), data...)
+	}
+
+	if err := s.conn.WriteMessage(websocket.BinaryMessage, msg); err != nil {
+		return fmt.Errorf("chunk stream: write: %w", err)
+	}
+
+	mt, res, err := s.conn.ReadMessage()
+	if err != nil {
+		return fmt.Errorf("chunk stream: %w", err)
+	}
+	if mt != websocket.BinaryMessage || len(res) != 0 {
+		return errors.New("chunk stream: unexpected response")
+	}
+	return nil
+}
+
+// Close closes the stream.
+func (s *ChunkStream) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_ = s.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+	return s.conn.Close()
+}
@@ -0,0 +1,113 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package client_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/api/client"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/storage/mock"
+	testingc "github.com/ethersphere/bee/pkg/storage/testing"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+func TestChunk(t *testing.T) {
+	var (
+		storer = mock.NewStorer()
+		c      = newTestServer(t, testServerOptions{Storer: storer})
+		debugC = newTestServer(t, testServerOptions{Storer: storer, DebugAPI: true})
+		ctx    = context.Background()
+		chunk  = testingc.GenerateTestRandomChunk()
+	)
+
+	res, err := c.UploadChunk(ctx, chunk.Data(), nil, &client.UploadOptions{BatchID: batchOk})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Reference.Equal(chunk.Address()) {
+		t.Fatalf("got reference %s, want %s", res.Reference, chunk.Address())
+	}
+
+	data, err := c.DownloadChunk(ctx, chunk.Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, chunk.Data()) {
+		t.Fatal("downloaded chunk data differs from the uploaded one")
+	}
+
+	for _, c := range []*client.Client{c, debugC} {
+		has, err := c.HasChunk(ctx, chunk.Address())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !has {
+			t.Fatal("uploaded chunk not found")
+		}
+	}
+
+	if err := debugC.RemoveChunk(ctx, chunk.Address()); err != nil {
+		t.Fatal(err)
+	}
+
+	has, err := c.HasChunk(ctx, chunk.Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if has {
+		t.Fatal("removed chunk found")
+	}
+}
+
+func TestChunkStream(t *testing.T) {
+	var (
+		storer = mock.NewStorer()
+		c      = newTestServer(t, testServerOptions{Storer: storer})
+		ctx    = context.Background()
+		chunks = make([]swarm.Chunk, 5)
+	)
+	for i := range chunks {
+		chunks[i] = testingc.GenerateTestRandomChunk()
+	}
+
+	stream, err := c.OpenChunkStream(ctx, &client.UploadOptions{BatchID: batchOk})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, ch := range chunks {
+		if err := stream.Upload(ch.Data(), nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := stream.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, ch := range chunks {
+		got, err := storer.Get(ctx, storage.ModeGetRequest, ch.Address())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got.Data(), ch.Data()) {
+			t.Fatal("stored chunk data differs from the uploaded one")
+		}
+	}
+
+	t.Run("missing stamp", func(t *testing.T) {
+		stream, err := c.OpenChunkStream(ctx, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer stream.Close()
+
+		if err := stream.Upload(chunks[0].Data(), nil); err == nil {
+			t.Fatal("expected error")
+		}
+	})
+}
@@ -0,0 +1,344 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package client provides a typed client for the Bee HTTP API and the
+// Bee debug HTTP API.
+//
+// The same Client type is used for both APIs, the methods of the debug API
+// can be called only on the client created with the debug API address.
+// The request and response bodies are the ones exported by the api package.
+// Failed requests are returned as the *Error with the HTTP status code and
+// the message of the response.
+//
+// The gas price and the gas limit of the requests which send transactions
+// are taken from the context, as set by the sctx package.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/sctx"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	gasPriceHeader = "Gas-Price"
+	gasLimitHeader = "Gas-Limit"
+)
+
+// Options holds the optional parameters of the client.
+type Options struct {
+	// HTTPClient is used to send the requests. If nil,
+	// the http.DefaultClient is used.
+	HTTPClient *http.Client
+	// Token is the security token sent with every request
+	// to the API which runs in the restricted mode.
+	Token string
+}
+
+// Client is a client of the Bee HTTP API or the Bee debug HTTP API.
+type Client struct {
+	baseURL    *url.URL
+	httpClient *http.Client
+	token      string
+}
+
+// New creates a new client of the API served on the baseURL.
+func New(baseURL string, o *Options) (*Client, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("parse base url: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported base url scheme %q", u.Scheme)
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/")
+
+	if o == nil {
+		o = new(Options)
+	}
+	httpClient := o.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	return &Client{
+		baseURL:    u,
+		httpClient: httpClient,
+		token:      o.Token,
+	}, nil
+}
+
+// WithToken returns a copy of the client which
+// sends the given security token with the requests.
+func (c *Client) WithToken(token string) *Client {
+	cc := *c
+	cc.token = token
+	return &cc
+}
+
+// Error is the error response of the API.
+type Error struct {
+	Code    int
+	Message string
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("%d %s", e.Code, http.StatusText(e.Code))
+	}
+	return fmt.Sprintf("%d %s: %s", e.Code, http.StatusText(e.Code), e.Message)
+}
+
+// UploadOptions holds the options of the content uploads.
+type UploadOptions struct {
+	// BatchID is the postage batch used to stamp the uploaded chunks.
+	BatchID []byte
+	// Tag is the existing tag to which the upload is accounted.
+	// A new tag is created by the API if it is zero.
+	Tag uint32
+	// Pin pins the uploaded content locally.
+	Pin bool
+	// Encrypt encrypts the uploaded content.
+	Encrypt bool
+	// Direct uploads the content directly to the network,
+	// instead of storing it locally first and syncing it later.
+	Direct bool
+}
+
+func (o *UploadOptions) header() http.Header {
+	h := make(http.Header)
+	if o == nil {
+		return h
+	}
+	if o.BatchID != nil {
+		h.Set(api.SwarmPostageBatchIdHeader, fmt.Sprintf("%x", o.BatchID))
+	}
+	if o.Tag != 0 {
+		h.Set(api.SwarmTagHeader, strconv.FormatUint(uint64(o.Tag), 10))
+	}
+	if o.Pin {
+		h.Set(api.SwarmPinHeader, "true")
+	}
+	if o.Encrypt {
+		h.Set(api.SwarmEncryptHeader, "true")
+	}
+	if o.Direct {
+		h.Set(api.SwarmDeferredUploadHeader, "false")
+	}
+	return h
+}
+
+// endpoint returns the url of the path with the query parameters.
+func (c *Client) endpoint(path string, query url.Values) string {
+	u := *c.baseURL
+	u.Path += path
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// newRequest creates a new request with the authorization and the
+// transaction headers set.
+func (c *Client) newRequest(ctx context.Context, method, path string, query url.Values, body io.Reader) (*http.Request, error) {
+	r, err := http.NewRequestWithContext(ctx, method, c.endpoint(path, query), body)
+	if err != nil {
+		return nil, err
+	}
+	if c.token != "" {
+		r.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if price := sctx.GetGasPrice(ctx); price != nil {
+		r.Header.Set(gasPriceHeader, price.String())
+	}
+	if limit := sctx.GetGasLimit(ctx); limit != 0 {
+		r.Header.Set(gasLimitHeader, strconv.FormatUint(limit, 10))
+	}
+	return r, nil
+}
+
+// send sends the request and returns the response if its status code is
+// successful. The caller is responsible for closing the response body.
+func (c *Client) send(r *http.Request) (*http.Response, error) {
+	resp, err := c.httpClient.Do(r)
+	if err != nil {
+		return nil, err
+	}
+	if err := responseError(resp); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	return resp, nil
+}
+
+// request sends the request with the body and the headers and decodes the
+// json response into res, unless it is nil. The body is sent as json,
+// unless it is an io.Reader.
+func (c *Client) request(ctx context.Context, method, path string, query url.Values, header http.Header, body, res interface{}) error {
+	var (
+		reader      io.Reader
+		contentType string
+	)
+	switch b := body.(type) {
+	case nil:
+	case io.Reader:
+		reader = b
+	default:
+		data, err := json.Marshal(b)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+		contentType = jsonhttp.DefaultContentTypeHeader
+	}
+
+	r, err := c.newRequest(ctx, method, path, query, reader)
+	if err != nil {
+		return err
+	}
+	if contentType != "" {
+		r.Header.Set("Content-Type", contentType)
+	}
+	for k, v := range header {
+		r.Header[k] = v
+	}
+
+	resp, err := c.send(r)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if res == nil {
+		_, err = io.Copy(io.Discard, resp.Body)
+		return err
+	}
+	return decodeJSON(resp.Body, res)
+}
+
+func decodeJSON(r io.Reader, v interface{}) error {
+	if err := json.NewDecoder(r).Decode(v); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	return nil
+}
+
+// download sends the GET request for the path
+// and returns the body of the response.
+func (c *Client) download(ctx context.Context, path string, query url.Values) (io.ReadCloser, error) {
+	r, err := c.newRequest(ctx, http.MethodGet, path, query, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.send(r)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// has sends the bodiless request for the path
+// and reports whether the resource exists.
+func (c *Client) has(ctx context.Context, method, path string) (bool, error) {
+	err := c.request(ctx, method, path, nil, nil, nil, nil)
+	var e *Error
+	if errors.As(err, &e) && e.Code == http.StatusNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// dial opens the websocket connection to the path. The connection is
+// not made with the http client of the options, but with the default
+// websocket dialer.
+func (c *Client) dial(ctx context.Context, path string, header http.Header) (*websocket.Conn, error) {
+	u := *c.baseURL
+	u.Path += path
+	switch u.Scheme {
+	case "https":
+		u.Scheme = "wss"
+	default:
+		u.Scheme = "ws"
+	}
+
+	if header == nil {
+		header = make(http.Header)
+	}
+	if c.token != "" {
+		header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	conn, resp, err := websocket.DefaultDialer.DialContext(ctx, u.String(), header)
+	if err != nil {
+		if resp != nil {
+			if err := responseError(resp); err != nil {
+				return nil, err
+			}
+		}
+		return nil, err
+	}
+	return conn, nil
+}
+
+// readMessages passes the websocket messages of the type to the returned
+// channel until the connection is lost or the returned function is called.
+func readMessages(conn *websocket.Conn, messageType int) (<-chan []byte, func()) {
+	var (
+		msgC = make(chan []byte)
+		quit = make(chan struct{})
+		once sync.Once
+	)
+	go func() {
+		defer close(msgC)
+		for {
+			mt, msg, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if mt != messageType {
+				continue
+			}
+			select {
+			case msgC <- msg:
+			case <-quit:
+				return
+			}
+		}
+	}()
+
+	return msgC, func() {
+		once.Do(func() {
+			close(quit)
+			_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+			_ = conn.Close()
+		})
+	}
+}
+
+// responseError returns the *Error if the response status is not successful.
+func responseError(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	e := &Error{Code: resp.StatusCode}
+	var res jsonhttp.StatusResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<16)).Decode(&res); err == nil {
+		e.Message = res.Message
+	}
+	return e
+}
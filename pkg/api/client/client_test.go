@@ -0,0 +1,250 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package client_test
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	accountingmock "github.com/ethersphere/bee/pkg/accounting/mock"
+	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/api/client"
+	mockauth "github.com/ethersphere/bee/pkg/auth/mock"
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/feeds"
+	"github.com/ethersphere/bee/pkg/log"
+	p2pmock "github.com/ethersphere/bee/pkg/p2p/mock"
+	"github.com/ethersphere/bee/pkg/pinning"
+	pinningmock "github.com/ethersphere/bee/pkg/pinning/mock"
+	"github.com/ethersphere/bee/pkg/postage"
+	mockbatchstore "github.com/ethersphere/bee/pkg/postage/batchstore/mock"
+	mockpost "github.com/ethersphere/bee/pkg/postage/mock"
+	"github.com/ethersphere/bee/pkg/postage/notifier"
+	"github.com/ethersphere/bee/pkg/postage/postagecontract"
+	"github.com/ethersphere/bee/pkg/pss"
+	resolvermock "github.com/ethersphere/bee/pkg/resolver/mock"
+	"github.com/ethersphere/bee/pkg/settlement/pseudosettle"
+	chequebookmock "github.com/ethersphere/bee/pkg/settlement/swap/chequebook/mock"
+	erc20mock "github.com/ethersphere/bee/pkg/settlement/swap/erc20/mock"
+	swapmock "github.com/ethersphere/bee/pkg/settlement/swap/mock"
+	statestore "github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/storage/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/tags"
+	"github.com/ethersphere/bee/pkg/topology/lightnode"
+	topologymock "github.com/ethersphere/bee/pkg/topology/mock"
+	"github.com/ethersphere/bee/pkg/tracing"
+	"github.com/ethersphere/bee/pkg/transaction/backendmock"
+	transactionmock "github.com/ethersphere/bee/pkg/transaction/mock"
+	"github.com/ethersphere/bee/pkg/upload"
+)
+
+var batchOk = make([]byte, 32)
+
+func init() {
+	_, _ = rand.Read(batchOk)
+}
+
+type testServerOptions struct {
+	Storer          storage.Storer
+	Tags            *tags.Tags
+	Pss             pss.Interface
+	Pinning         pinning.Interface
+	Feeds           feeds.Factory
+	Post            postage.Service
+	PostageContract postagecontract.Interface
+	BatchStore      postage.Storer
+	BatchNotifier   *notifier.Notifier
+	Authenticator   *mockauth.Auth
+	DebugAPI        bool
+	Restricted      bool
+
+	Overlay         swarm.Address
+	EthereumAddress common.Address
+	P2P             *p2pmock.Service
+	TopologyOpts    []topologymock.Option
+	AccountingOpts  []accountingmock.Option
+	TransactionOpts []transactionmock.Option
+}
+
+// newTestServer serves the API, or the debug API, of the api.Service
+// with the mocked dependencies and returns the client of the server.
+func newTestServer(t *testing.T, o testServerOptions) *client.Client {
+	t.Helper()
+
+	pk, _ := crypto.GenerateSecp256k1Key()
+	signer := crypto.NewDefaultSigner(pk)
+
+	if o.Storer == nil {
+		o.Storer = mock.NewStorer()
+	}
+	if o.Tags == nil {
+		o.Tags = tags.NewTags(statestore.NewStateStore(), log.Noop)
+	}
+	if o.Pinning == nil {
+		o.Pinning = pinningmock.NewServiceMock()
+	}
+	if o.Post == nil {
+		o.Post = mockpost.New(mockpost.WithAcceptAll())
+	}
+	if o.BatchStore == nil {
+		o.BatchStore = mockbatchstore.New(mockbatchstore.WithAcceptAllExistsFunc())
+	}
+	if o.Authenticator == nil {
+		o.Authenticator = &mockauth.Auth{
+			EnforceFunc: func(_, _, _ string) (bool, error) {
+				return true, nil
+			},
+		}
+	}
+	if o.P2P == nil {
+		o.P2P = p2pmock.New()
+	}
+
+	uploads := upload.New(statestore.NewStateStore(), log.Noop, upload.Options{})
+	t.Cleanup(func() { _ = uploads.Close() })
+
+	extraOpts := api.ExtraOptions{
+		TopologyDriver:  topologymock.NewTopologyDriver(o.TopologyOpts...),
+		Accounting:      accountingmock.NewAccounting(o.AccountingOpts...),
+		Pseudosettle:    pseudosettle.New(nil, log.Noop, statestore.NewStateStore(), nil, big.NewInt(10000), big.NewInt(10000), o.P2P),
+		LightNodes:      lightnode.NewContainer(o.Overlay),
+		Swap:            swapmock.New(),
+		Chequebook:      chequebookmock.NewChequebook(),
+		Tags:            o.Tags,
+		Storer:          o.Storer,
+		Resolver:        resolvermock.NewResolver(),
+		Pss:             o.Pss,
+		Pinning:         o.Pinning,
+		FeedFactory:     o.Feeds,
+		Post:            o.Post,
+		PostageContract: o.PostageContract,
+		BatchNotifier:   o.BatchNotifier,
+		Uploads:         uploads,
+		SyncStatus:      func() (bool, error) { return true, nil },
+	}
+
+	s := api.New(pk.PublicKey, pk.PublicKey, o.EthereumAddress, log.Noop, transactionmock.New(o.TransactionOpts...), o.BatchStore, false, api.FullMode, true, true, nil)
+	s.SetP2P(o.P2P)
+	s.SetSwarmAddress(&o.Overlay)
+
+	tracer, tracerCloser, _ := tracing.NewTracer(&tracing.Options{Enabled: false})
+	t.Cleanup(func() { _ = tracerCloser.Close() })
+
+	_ = s.Configure(signer, o.Authenticator, tracer, api.Options{
+		WsPingPeriod: 60 * time.Second,
+		Restricted:   o.Restricted,
+	}, extraOpts, 1, backendmock.New(), erc20mock.New())
+
+	switch {
+	case o.DebugAPI:
+		s.MountTechnicalDebug()
+		s.MountDebug(false)
+	case o.Restricted:
+		s.MountAPI()
+		s.MountDebug(true)
+	default:
+		s.MountAPI()
+	}
+
+	ts := httptest.NewServer(s)
+	t.Cleanup(func() {
+		ts.Close()
+		_ = s.Close()
+	})
+
+	c, err := client.New(ts.URL, &client.Options{HTTPClient: ts.Client()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestNew(t *testing.T) {
+	for _, baseURL := range []string{"localhost:1633", "ftp://localhost:1633", ":"} {
+		if _, err := client.New(baseURL, nil); err == nil {
+			t.Fatalf("expected error for base url %q", baseURL)
+		}
+	}
+}
+
+func TestError(t *testing.T) {
+	c := newTestServer(t, testServerOptions{})
+
+	_, err := c.GetTag(context.Background(), 1)
+
+	var e *client.Error
+	if !errors.As(err, &e) {
+		t.Fatalf("expected client error, got %v", err)
+	}
+	if e.Code != http.StatusNotFound || e.Message != "tag not present" {
+		t.Fatalf("unexpected error %+v", e)
+	}
+}
+
+func TestRestricted(t *testing.T) {
+	const (
+		password = "secret"
+		token    = "token"
+	)
+
+	c := newTestServer(t, testServerOptions{
+		Restricted: true,
+		Authenticator: &mockauth.Auth{
+			AuthorizeFunc: func(p string) bool {
+				return p == password
+			},
+			GenerateKeyFunc: func(string) (string, error) {
+				return token, nil
+			},
+			EnforceFunc: func(key, _, _ string) (bool, error) {
+				return key == token, nil
+			},
+		},
+	})
+	ctx := context.Background()
+
+	if _, err := c.Auth(ctx, "wrong", "consumer", 60); !isStatus(err, http.StatusUnauthorized) {
+		t.Fatalf("expected unauthorized error, got %v", err)
+	}
+
+	if _, err := c.Peers(ctx); !isStatus(err, http.StatusForbidden) {
+		t.Fatalf("expected forbidden error, got %v", err)
+	}
+
+	res, err := c.Auth(ctx, password, "consumer", 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Key != token {
+		t.Fatalf("got token %q, want %q", res.Key, token)
+	}
+
+	c = c.WithToken(res.Key)
+	if _, err := c.Peers(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err = c.Refresh(ctx, 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Key != token {
+		t.Fatalf("got refreshed token %q, want %q", res.Key, token)
+	}
+}
+
+func isStatus(err error, code int) bool {
+	var e *client.Error
+	return errors.As(err, &e) && e.Code == code
+}
@@ -0,0 +1,152 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package client_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	accountingmock "github.com/ethersphere/bee/pkg/accounting/mock"
+	"github.com/ethersphere/bee/pkg/log"
+	"github.com/ethersphere/bee/pkg/p2p"
+	p2pmock "github.com/ethersphere/bee/pkg/p2p/mock"
+	"github.com/ethersphere/bee/pkg/postage"
+	mockbatchstore "github.com/ethersphere/bee/pkg/postage/batchstore/mock"
+	mockpost "github.com/ethersphere/bee/pkg/postage/mock"
+	"github.com/ethersphere/bee/pkg/postage/notifier"
+	postagetesting "github.com/ethersphere/bee/pkg/postage/testing"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/multiformats/go-multiaddr"
+)
+
+func TestNode(t *testing.T) {
+	var (
+		overlay   = swarm.MustParseHexAddress("ca1e9f3938cc1425c6061b96ad9eb93e134dfe8734ad490164ef20af9d1cf59c")
+		ethereum  = common.HexToAddress("abcd")
+		peer      = swarm.MustParseHexAddress("1234")
+		underlay  = multiaddr.StringCast("/ip4/127.0.0.1/tcp/1634")
+		balances  = map[string]*big.Int{peer.String(): big.NewInt(42)}
+		ctx       = context.Background()
+		debugTest = newTestServer(t, testServerOptions{
+			DebugAPI:        true,
+			Overlay:         overlay,
+			EthereumAddress: ethereum,
+			P2P: p2pmock.New(
+				p2pmock.WithAddressesFunc(func() ([]multiaddr.Multiaddr, error) {
+					return []multiaddr.Multiaddr{underlay}, nil
+				}),
+				p2pmock.WithPeersFunc(func() []p2p.Peer {
+					return []p2p.Peer{{Address: peer, FullNode: true}}
+				}),
+			),
+			AccountingOpts: []accountingmock.Option{
+				accountingmock.WithCompensatedBalancesFunc(func() (map[string]*big.Int, error) {
+					return balances, nil
+				}),
+			},
+		})
+	)
+
+	node, err := debugTest.Node(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if node.BeeMode != "full" {
+		t.Fatalf("got bee mode %q, want %q", node.BeeMode, "full")
+	}
+
+	addresses, err := debugTest.Addresses(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !addresses.Overlay.Equal(overlay) || addresses.Ethereum != ethereum {
+		t.Fatalf("unexpected addresses %+v", addresses)
+	}
+	if len(addresses.Underlay) != 1 || !addresses.Underlay[0].Equal(underlay) {
+		t.Fatalf("unexpected underlay addresses %v", addresses.Underlay)
+	}
+
+	peers, err := debugTest.Peers(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(peers.Peers) != 1 || !peers.Peers[0].Address.Equal(peer) || !peers.Peers[0].FullNode {
+		t.Fatalf("unexpected peers %+v", peers.Peers)
+	}
+
+	res, err := debugTest.Balances(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Balances) != 1 || res.Balances[0].Peer != peer.String() || res.Balances[0].Balance.Int64() != 42 {
+		t.Fatalf("unexpected balances %+v", res.Balances)
+	}
+}
+
+func TestStamps(t *testing.T) {
+	batch := postagetesting.MustNewBatch(postagetesting.WithValue(20))
+	issuer := postage.NewStampIssuer("label", "keyID", batch.ID, big.NewInt(3), 20, 16, 1000, true)
+	c := newTestServer(t, testServerOptions{
+		DebugAPI: true,
+		Post:     mockpost.New(mockpost.WithIssuer(issuer)),
+		BatchStore: mockbatchstore.New(
+			mockbatchstore.WithAcceptAllExistsFunc(),
+			mockbatchstore.WithBatch(batch),
+			// the batches with no current price never expire
+			mockbatchstore.WithChainState(&postage.ChainState{TotalAmount: big.NewInt(0), CurrentPrice: big.NewInt(0)}),
+		),
+	})
+	ctx := context.Background()
+
+	stamps, err := c.Stamps(ctx, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stamps.Stamps) != 1 {
+		t.Fatalf("got %d stamps, want 1", len(stamps.Stamps))
+	}
+
+	stamp, err := c.Stamp(ctx, batch.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(stamp.BatchID, batch.ID) || stamp.Label != "label" || stamp.Depth != 20 || !stamp.ImmutableFlag {
+		t.Fatalf("unexpected stamp %+v", stamp)
+	}
+}
+
+func TestBatchEvents(t *testing.T) {
+	post := mockpost.New()
+	batchNotifier := notifier.New(post, mockbatchstore.New(), post, big.NewInt(5), log.Noop, notifier.Options{})
+	t.Cleanup(func() { _ = batchNotifier.Close() })
+
+	c := newTestServer(t, testServerOptions{
+		DebugAPI:      true,
+		Post:          post,
+		BatchNotifier: batchNotifier,
+	})
+
+	events, unsubscribe, err := c.SubscribeBatchEvents(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unsubscribe()
+
+	batchNotifier.HandleTopUp(batchOk, big.NewInt(10))
+
+	select {
+	case e := <-events:
+		if e.Type != notifier.EventTopUp || e.BatchID != hex.EncodeToString(batchOk) || e.Amount.Int64() != 10 {
+			t.Fatalf("unexpected event %+v", e)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the event")
+	}
+}
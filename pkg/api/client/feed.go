@@ -0,0 +1,77 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/api"
+)
+
+// FeedUpdate is the latest update of the feed found at the requested time.
+type FeedUpdate struct {
+	api.FeedReferenceResponse
+	// Index is the index of the found update.
+	Index []byte
+	// NextIndex is the index of the next update.
+	NextIndex []byte
+}
+
+// UploadSOC uploads the single owner chunk with the data, consisting of
+// the span and the payload, signed by the owner with the signature.
+func (c *Client) UploadSOC(ctx context.Context, owner common.Address, id, signature, data []byte, o *UploadOptions) (res api.SocPostResponse, err error) {
+	header := o.header()
+	header.Set("Content-Type", "application/octet-stream")
+	path := fmt.Sprintf("/soc/%x/%x", owner.Bytes(), id)
+	query := url.Values{"sig": {hex.EncodeToString(signature)}}
+	err = c.request(ctx, http.MethodPost, path, query, header, bytes.NewReader(data), &res)
+	return res, err
+}
+
+// CreateFeedManifest creates the manifest of the sequence feed
+// of the owner with the topic.
+func (c *Client) CreateFeedManifest(ctx context.Context, owner common.Address, topic []byte, o *UploadOptions) (res api.FeedReferenceResponse, err error) {
+	path := fmt.Sprintf("/feeds/%x/%x", owner.Bytes(), topic)
+	err = c.request(ctx, http.MethodPost, path, nil, o.header(), nil, &res)
+	return res, err
+}
+
+// FindFeedUpdate looks up the latest update of the feed of the owner with
+// the topic at the unix time. The current time is used if the time is zero.
+func (c *Client) FindFeedUpdate(ctx context.Context, owner common.Address, topic []byte, at int64) (*FeedUpdate, error) {
+	query := make(url.Values)
+	if at != 0 {
+		query.Set("at", strconv.FormatInt(at, 10))
+	}
+
+	r, err := c.newRequest(ctx, http.MethodGet, fmt.Sprintf("/feeds/%x/%x", owner.Bytes(), topic), query, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.send(r)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	u := new(FeedUpdate)
+	if err := decodeJSON(resp.Body, &u.FeedReferenceResponse); err != nil {
+		return nil, err
+	}
+	if u.Index, err = hex.DecodeString(resp.Header.Get(api.SwarmFeedIndexHeader)); err != nil {
+		return nil, fmt.Errorf("decode feed index: %w", err)
+	}
+	if u.NextIndex, err = hex.DecodeString(resp.Header.Get(api.SwarmFeedIndexNextHeader)); err != nil {
+		return nil, fmt.Errorf("decode next feed index: %w", err)
+	}
+	return u, nil
+}
@@ -0,0 +1,38 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/localstore"
+)
+
+// LocalstoreStats returns the statistics of the local store.
+func (c *Client) LocalstoreStats(ctx context.Context) (res localstore.Stats, err error) {
+	err = c.request(ctx, http.MethodGet, "/debug/localstore", nil, nil, nil, &res)
+	return res, err
+}
+
+// Compact starts the compaction of the local store.
+func (c *Client) Compact(ctx context.Context) (res api.CompactionResponse, err error) {
+	err = c.request(ctx, http.MethodPost, "/compact", nil, nil, nil, &res)
+	return res, err
+}
+
+// CompactionStatus returns the state of the last compaction of the local store.
+func (c *Client) CompactionStatus(ctx context.Context) (res api.CompactionResponse, err error) {
+	err = c.request(ctx, http.MethodGet, "/compact", nil, nil, nil, &res)
+	return res, err
+}
+
+// Backup returns the reader of the backup of the local store and the state
+// store as a tar archive. The caller is responsible for closing it.
+func (c *Client) Backup(ctx context.Context) (io.ReadCloser, error) {
+	return c.download(ctx, "/backup", nil)
+}
@@ -0,0 +1,99 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/multiformats/go-multiaddr"
+)
+
+// Readiness returns the status of the node once it is ready.
+func (c *Client) Readiness(ctx context.Context) (res api.StatusResponse, err error) {
+	err = c.request(ctx, http.MethodGet, "/readiness", nil, nil, nil, &res)
+	return res, err
+}
+
+// Health returns the status of the node.
+func (c *Client) Health(ctx context.Context) (res api.StatusResponse, err error) {
+	err = c.request(ctx, http.MethodGet, "/health", nil, nil, nil, &res)
+	return res, err
+}
+
+// Node returns the configuration of the node.
+func (c *Client) Node(ctx context.Context) (res api.NodeResponse, err error) {
+	err = c.request(ctx, http.MethodGet, "/node", nil, nil, nil, &res)
+	return res, err
+}
+
+// Addresses returns the overlay, underlay
+// and blockchain addresses of the node.
+func (c *Client) Addresses(ctx context.Context) (res api.AddressesResponse, err error) {
+	// the underlay addresses are decoded from their string form
+	var addresses struct {
+		Overlay      *swarm.Address `json:"overlay"`
+		Underlay     []string       `json:"underlay"`
+		Ethereum     common.Address `json:"ethereum"`
+		PublicKey    string         `json:"publicKey"`
+		PSSPublicKey string         `json:"pssPublicKey"`
+	}
+	if err := c.request(ctx, http.MethodGet, "/addresses", nil, nil, nil, &addresses); err != nil {
+		return res, err
+	}
+
+	res = api.AddressesResponse{
+		Overlay:      addresses.Overlay,
+		Underlay:     make([]multiaddr.Multiaddr, 0, len(addresses.Underlay)),
+		Ethereum:     addresses.Ethereum,
+		PublicKey:    addresses.PublicKey,
+		PSSPublicKey: addresses.PSSPublicKey,
+	}
+	for _, u := range addresses.Underlay {
+		addr, err := multiaddr.NewMultiaddr(u)
+		if err != nil {
+			return res, fmt.Errorf("parse underlay address: %w", err)
+		}
+		res.Underlay = append(res.Underlay, addr)
+	}
+	return res, nil
+}
+
+// ChainState returns the state of the postage contract
+// as seen by the node.
+func (c *Client) ChainState(ctx context.Context) (res api.ChainStateResponse, err error) {
+	err = c.request(ctx, http.MethodGet, "/chainstate", nil, nil, nil, &res)
+	return res, err
+}
+
+// Loggers returns the loggers which match the expression.
+// All the loggers are returned if the expression is empty.
+func (c *Client) Loggers(ctx context.Context, exp string) (res api.LoggersResponse, err error) {
+	path := "/loggers"
+	if exp != "" {
+		path += "/" + base64.URLEncoding.EncodeToString([]byte(exp))
+	}
+	err = c.request(ctx, http.MethodGet, path, nil, nil, nil, &res)
+	return res, err
+}
+
+// SetLoggerVerbosity sets the verbosity of the
+// loggers which match the expression.
+func (c *Client) SetLoggerVerbosity(ctx context.Context, exp, verbosity string) error {
+	path := "/loggers/" + base64.URLEncoding.EncodeToString([]byte(exp)) + "/" + verbosity
+	return c.request(ctx, http.MethodPut, path, nil, nil, nil, nil)
+}
+
+// Metrics returns the reader of the metrics of the node in the
+// Prometheus text format. The caller is responsible for closing it.
+func (c *Client) Metrics(ctx context.Context) (io.ReadCloser, error) {
+	return c.download(ctx, "/metrics", nil)
+}
@@ -0,0 +1,61 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/topology"
+	"github.com/multiformats/go-multiaddr"
+)
+
+// Peers returns the connected peers.
+func (c *Client) Peers(ctx context.Context) (res api.PeersResponse, err error) {
+	err = c.request(ctx, http.MethodGet, "/peers", nil, nil, nil, &res)
+	return res, err
+}
+
+// Blocklist returns the blocklisted peers.
+func (c *Client) Blocklist(ctx context.Context) (res api.PeersResponse, err error) {
+	err = c.request(ctx, http.MethodGet, "/blocklist", nil, nil, nil, &res)
+	return res, err
+}
+
+// Connect connects to the peer on the underlay address.
+func (c *Client) Connect(ctx context.Context, addr multiaddr.Multiaddr) (res api.PeerConnectResponse, err error) {
+	err = c.request(ctx, http.MethodPost, "/connect"+addr.String(), nil, nil, nil, &res)
+	return res, err
+}
+
+// Disconnect disconnects from the peer with the overlay address.
+func (c *Client) Disconnect(ctx context.Context, peer swarm.Address) error {
+	return c.request(ctx, http.MethodDelete, "/peers/"+peer.String(), nil, nil, nil, nil)
+}
+
+// Ping pings the connected peer and returns the round trip time.
+func (c *Client) Ping(ctx context.Context, peer swarm.Address) (res api.PingpongResponse, err error) {
+	err = c.request(ctx, http.MethodPost, "/pingpong/"+peer.String(), nil, nil, nil, &res)
+	return res, err
+}
+
+// Topology returns the state of the kademlia topology of the node.
+func (c *Client) Topology(ctx context.Context) (res topology.KadParams, err error) {
+	err = c.request(ctx, http.MethodGet, "/topology", nil, nil, nil, &res)
+	return res, err
+}
+
+// WelcomeMessage returns the message sent to the peers in the handshake.
+func (c *Client) WelcomeMessage(ctx context.Context) (res api.WelcomeMessageResponse, err error) {
+	err = c.request(ctx, http.MethodGet, "/welcome-message", nil, nil, nil, &res)
+	return res, err
+}
+
+// SetWelcomeMessage sets the message sent to the peers in the handshake.
+func (c *Client) SetWelcomeMessage(ctx context.Context, message string) error {
+	return c.request(ctx, http.MethodPost, "/welcome-message", nil, nil, api.WelcomeMessageRequest{WelcomeMesssage: message}, nil)
+}
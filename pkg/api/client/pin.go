@@ -0,0 +1,34 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// Pin pins the content with the root reference.
+func (c *Client) Pin(ctx context.Context, reference swarm.Address) error {
+	return c.request(ctx, http.MethodPost, "/pins/"+reference.String(), nil, nil, nil, nil)
+}
+
+// Unpin removes the pin of the content with the root reference.
+func (c *Client) Unpin(ctx context.Context, reference swarm.Address) error {
+	return c.request(ctx, http.MethodDelete, "/pins/"+reference.String(), nil, nil, nil, nil)
+}
+
+// IsPinned reports whether the content with the root reference is pinned.
+func (c *Client) IsPinned(ctx context.Context, reference swarm.Address) (bool, error) {
+	return c.has(ctx, http.MethodGet, "/pins/"+reference.String())
+}
+
+// ListPins returns the root references of the pinned content.
+func (c *Client) ListPins(ctx context.Context) (res api.PinsResponse, err error) {
+	err = c.request(ctx, http.MethodGet, "/pins", nil, nil, nil, &res)
+	return res, err
+}
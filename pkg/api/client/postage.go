@@ -0,0 +1,198 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/postage/notifier"
+	"github.com/gorilla/websocket"
+)
+
+const immutableHeader = "Immutable"
+
+// BatchOptions holds the options of the postage batch creation.
+type BatchOptions struct {
+	// Label is the local label of the batch.
+	Label string
+	// Immutable creates the batch which can not be overissued.
+	Immutable bool
+}
+
+// Stamps returns the usable postage batches of the node,
+// or all of them, including the expired ones, if all is true.
+func (c *Client) Stamps(ctx context.Context, all bool) (res api.PostageStampsResponse, err error) {
+	query := make(url.Values)
+	if all {
+		query.Set("all", "true")
+	}
+	err = c.request(ctx, http.MethodGet, "/stamps", query, nil, nil, &res)
+	return res, err
+}
+
+// Stamp returns the postage batch of the node with the id.
+func (c *Client) Stamp(ctx context.Context, id []byte) (res api.PostageStampResponse, err error) {
+	err = c.request(ctx, http.MethodGet, "/stamps/"+hex.EncodeToString(id), nil, nil, nil, &res)
+	return res, err
+}
+
+// StampBuckets returns the collisions in the buckets of the postage batch.
+func (c *Client) StampBuckets(ctx context.Context, id []byte) (res api.PostageStampBucketsResponse, err error) {
+	err = c.request(ctx, http.MethodGet, "/stamps/"+hex.EncodeToString(id)+"/buckets", nil, nil, nil, &res)
+	return res, err
+}
+
+// Batches returns all the valid postage batches known to the node.
+func (c *Client) Batches(ctx context.Context) (res api.PostageBatchesResponse, err error) {
+	err = c.request(ctx, http.MethodGet, "/batches", nil, nil, nil, &res)
+	return res, err
+}
+
+// CreatePostageBatch buys a new postage batch with the amount
+// per chunk and the depth.
+func (c *Client) CreatePostageBatch(ctx context.Context, amount *big.Int, depth uint8, o *BatchOptions) (res api.PostageCreateResponse, err error) {
+	query := make(url.Values)
+	header := make(http.Header)
+	if o != nil {
+		if o.Label != "" {
+			query.Set("label", o.Label)
+		}
+		if o.Immutable {
+			header.Set(immutableHeader, "true")
+		}
+	}
+	path := fmt.Sprintf("/stamps/%s/%d", amount, depth)
+	err = c.request(ctx, http.MethodPost, path, query, header, nil, &res)
+	return res, err
+}
+
+// TopUpBatch tops up the postage batch with the amount per chunk.
+func (c *Client) TopUpBatch(ctx context.Context, id []byte, amount *big.Int) (res api.PostageCreateResponse, err error) {
+	path := fmt.Sprintf("/stamps/topup/%x/%s", id, amount)
+	err = c.request(ctx, http.MethodPatch, path, nil, nil, nil, &res)
+	return res, err
+}
+
+// DiluteBatch increases the depth of the postage batch.
+func (c *Client) DiluteBatch(ctx context.Context, id []byte, depth uint8) (res api.PostageCreateResponse, err error) {
+	path := fmt.Sprintf("/stamps/dilute/%x/%d", id, depth)
+	err = c.request(ctx, http.MethodPatch, path, nil, nil, nil, &res)
+	return res, err
+}
+
+// CheckBytes checks whether the raw data streamed
+// from the reader fits into the postage batch.
+func (c *Client) CheckBytes(ctx context.Context, id []byte, data io.Reader, encrypt bool) (res api.PostageCheckResponse, err error) {
+	header := (&UploadOptions{Encrypt: encrypt}).header()
+	header.Set("Content-Type", "application/octet-stream")
+	err = c.request(ctx, http.MethodPost, fmt.Sprintf("/stamps/%x/check/bytes", id), nil, header, data, &res)
+	return res, err
+}
+
+// CheckFile checks whether the file with the content type
+// streamed from the reader fits into the postage batch.
+func (c *Client) CheckFile(ctx context.Context, id []byte, data io.Reader, contentType string, encrypt bool) (res api.PostageCheckResponse, err error) {
+	header := (&UploadOptions{Encrypt: encrypt}).header()
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	header.Set("Content-Type", contentType)
+	err = c.request(ctx, http.MethodPost, fmt.Sprintf("/stamps/%x/check/bzz", id), nil, header, data, &res)
+	return res, err
+}
+
+// CheckCollection checks whether the collection of files streamed
+// from the reader as a tar archive fits into the postage batch.
+func (c *Client) CheckCollection(ctx context.Context, id []byte, tar io.Reader, o *CollectionOptions) (res api.PostageCheckResponse, err error) {
+	header := o.header()
+	header.Set("Content-Type", contentTypeTar)
+	header.Set(api.SwarmCollectionHeader, "true")
+	err = c.request(ctx, http.MethodPost, fmt.Sprintf("/stamps/%x/check/bzz", id), nil, header, tar, &res)
+	return res, err
+}
+
+// ReserveState returns the state of the reserve of the node.
+func (c *Client) ReserveState(ctx context.Context) (res api.ReserveStateResponse, err error) {
+	err = c.request(ctx, http.MethodGet, "/reservestate", nil, nil, nil, &res)
+	return res, err
+}
+
+// PostagePolicies returns the automatic top up and dilution
+// policies of the postage batches and the spent budget.
+func (c *Client) PostagePolicies(ctx context.Context) (res api.PostagePoliciesResponse, err error) {
+	err = c.request(ctx, http.MethodGet, "/stamps/policies", nil, nil, nil, &res)
+	return res, err
+}
+
+// PostagePolicyActions returns the recent actions taken by the policies.
+func (c *Client) PostagePolicyActions(ctx context.Context) (res api.PostagePolicyActionsResponse, err error) {
+	err = c.request(ctx, http.MethodGet, "/stamps/policies/actions", nil, nil, nil, &res)
+	return res, err
+}
+
+// PostagePolicy returns the policy of the postage batch.
+func (c *Client) PostagePolicy(ctx context.Context, id []byte) (res api.PostagePolicyResponse, err error) {
+	err = c.request(ctx, http.MethodGet, fmt.Sprintf("/stamps/%x/policy", id), nil, nil, nil, &res)
+	return res, err
+}
+
+// SetPostagePolicy sets the policy of the postage batch.
+func (c *Client) SetPostagePolicy(ctx context.Context, id []byte, policy api.PostagePolicyRequest) (res api.PostagePolicyResponse, err error) {
+	err = c.request(ctx, http.MethodPut, fmt.Sprintf("/stamps/%x/policy", id), nil, nil, policy, &res)
+	return res, err
+}
+
+// DeletePostagePolicy deletes the policy of the postage batch.
+func (c *Client) DeletePostagePolicy(ctx context.Context, id []byte) error {
+	return c.request(ctx, http.MethodDelete, fmt.Sprintf("/stamps/%x/policy", id), nil, nil, nil, nil)
+}
+
+// SubscribeBatchEvents subscribes to the lifecycle events of the postage
+// batches. The returned channel is closed when the subscription ends,
+// either by calling the returned function or by losing the connection.
+func (c *Client) SubscribeBatchEvents(ctx context.Context) (<-chan notifier.Event, func(), error) {
+	conn, err := c.dial(ctx, "/stamps/events", nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	msgC, cancel := readMessages(conn, websocket.TextMessage)
+
+	var (
+		eventC = make(chan notifier.Event)
+		quit   = make(chan struct{})
+		once   sync.Once
+	)
+	go func() {
+		defer close(eventC)
+		for msg := range msgC {
+			var e notifier.Event
+			if err := json.Unmarshal(msg, &e); err != nil {
+				continue
+			}
+			select {
+			case eventC <- e:
+			case <-quit:
+				return
+			}
+		}
+	}()
+
+	return eventC, func() {
+		once.Do(func() {
+			close(quit)
+			cancel()
+		})
+	}, nil
+}
@@ -0,0 +1,54 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/pss"
+	"github.com/gorilla/websocket"
+)
+
+// SendPSS sends the message with the topic to the targets, stamped with
+// the batch. The message is encrypted for the recipient if it is not nil,
+// otherwise with the key derived from the topic.
+func (c *Client) SendPSS(ctx context.Context, topic string, targets pss.Targets, recipient *ecdsa.PublicKey, data, batchID []byte) error {
+	t := make([]string, 0, len(targets))
+	for _, target := range targets {
+		t = append(t, hex.EncodeToString(target))
+	}
+
+	query := make(url.Values)
+	if recipient != nil {
+		query.Set("recipient", hex.EncodeToString(crypto.EncodeSecp256k1PublicKey(recipient)))
+	}
+
+	header := make(http.Header)
+	header.Set(api.SwarmPostageBatchIdHeader, hex.EncodeToString(batchID))
+	path := fmt.Sprintf("/pss/send/%s/%s", url.PathEscape(topic), strings.Join(t, ","))
+	return c.request(ctx, http.MethodPost, path, query, header, bytes.NewReader(data), nil)
+}
+
+// SubscribePSS subscribes to the messages with the topic received by the
+// node. The returned channel is closed when the subscription ends, either
+// by calling the returned function or by losing the connection.
+func (c *Client) SubscribePSS(ctx context.Context, topic string) (<-chan []byte, func(), error) {
+	conn, err := c.dial(ctx, "/pss/subscribe/"+url.PathEscape(topic), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	msgC, cancel := readMessages(conn, websocket.BinaryMessage)
+	return msgC, cancel, nil
+}
@@ -0,0 +1,75 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package client_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/log"
+	"github.com/ethersphere/bee/pkg/pss"
+	"github.com/ethersphere/bee/pkg/pushsync"
+	pushsyncmock "github.com/ethersphere/bee/pkg/pushsync/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+func TestPss(t *testing.T) {
+	key, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the messages sent by the node are delivered back to it
+	p := pss.New(key, log.Noop)
+	p.SetPushSyncer(pushsyncmock.New(func(_ context.Context, ch swarm.Chunk) (*pushsync.Receipt, error) {
+		p.TryUnwrap(ch)
+		return &pushsync.Receipt{Address: ch.Address()}, nil
+	}))
+
+	c := newTestServer(t, testServerOptions{Pss: p})
+	ctx := context.Background()
+
+	msgC, unsubscribe, err := c.SubscribePSS(ctx, "topic")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	payload := []byte("hello pss")
+	// the message is sent until received, as the subscription
+	// is registered asynchronously after the connection is made
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+	timeout := time.After(10 * time.Second)
+	for received := false; !received; {
+		if err := c.SendPSS(ctx, "topic", pss.Targets{{0x1}}, &key.PublicKey, payload, batchOk); err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case msg := <-msgC:
+			if !bytes.Equal(msg, payload) {
+				t.Fatalf("got message %q, want %q", msg, payload)
+			}
+			received = true
+		case <-ticker.C:
+		case <-timeout:
+			t.Fatal("timed out waiting for the message")
+		}
+	}
+
+	unsubscribe()
+	select {
+	case _, ok := <-msgC:
+		if ok {
+			// drain the message which might have been sent again
+			for range msgC {
+			}
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("subscription not closed")
+	}
+}
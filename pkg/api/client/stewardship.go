@@ -0,0 +1,26 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// Reupload uploads the locally pinned content
+// with the root reference to the network again.
+func (c *Client) Reupload(ctx context.Context, reference swarm.Address) error {
+	return c.request(ctx, http.MethodPut, "/stewardship/"+reference.String(), nil, nil, nil, nil)
+}
+
+// IsRetrievable checks whether the content
+// with the root reference is retrievable.
+func (c *Client) IsRetrievable(ctx context.Context, reference swarm.Address) (res api.IsRetrievableResponse, err error) {
+	err = c.request(ctx, http.MethodGet, "/stewardship/"+reference.String(), nil, nil, nil, &res)
+	return res, err
+}
@@ -0,0 +1,61 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// CreateTag creates a new tag.
+func (c *Client) CreateTag(ctx context.Context) (res api.TagResponse, err error) {
+	err = c.request(ctx, http.MethodPost, "/tags", nil, nil, api.TagRequest{}, &res)
+	return res, err
+}
+
+// GetTag returns the tag with the uid.
+func (c *Client) GetTag(ctx context.Context, uid uint32) (res api.TagResponse, err error) {
+	err = c.request(ctx, http.MethodGet, fmt.Sprintf("/tags/%d", uid), nil, nil, nil, &res)
+	return res, err
+}
+
+// ListTags returns the page of the tags starting at the offset
+// with at most limit tags. The defaults of the API are used
+// for the zero values.
+func (c *Client) ListTags(ctx context.Context, offset, limit int) (res api.ListTagsResponse, err error) {
+	query := make(url.Values)
+	if offset > 0 {
+		query.Set("offset", strconv.Itoa(offset))
+	}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+	err = c.request(ctx, http.MethodGet, "/tags", query, nil, nil, &res)
+	return res, err
+}
+
+// DeleteTag deletes the tag with the uid.
+func (c *Client) DeleteTag(ctx context.Context, uid uint32) error {
+	return c.request(ctx, http.MethodDelete, fmt.Sprintf("/tags/%d", uid), nil, nil, nil, nil)
+}
+
+// DoneSplit marks the splitting of the content
+// with the root address accounted to the tag as done.
+func (c *Client) DoneSplit(ctx context.Context, uid uint32, address swarm.Address) error {
+	return c.request(ctx, http.MethodPatch, fmt.Sprintf("/tags/%d", uid), nil, nil, api.TagRequest{Address: address}, nil)
+}
+
+// GetDebugTag returns the detailed state of the tag
+// with the uid. It is served by the debug API.
+func (c *Client) GetDebugTag(ctx context.Context, uid uint32) (res api.DebugTagResponse, err error) {
+	err = c.request(ctx, http.MethodGet, fmt.Sprintf("/tags/%d", uid), nil, nil, nil, &res)
+	return res, err
+}
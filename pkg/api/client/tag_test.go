@@ -0,0 +1,97 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package client_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/api/client"
+	"github.com/ethersphere/bee/pkg/storage/mock"
+)
+
+func TestTags(t *testing.T) {
+	c := newTestServer(t, testServerOptions{})
+	ctx := context.Background()
+
+	tag, err := c.CreateTag(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.UploadBytes(ctx, bytes.NewReader([]byte("tagged content")), &client.UploadOptions{
+		BatchID: batchOk,
+		Tag:     tag.Uid,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := c.GetTag(ctx, tag.Uid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Uid != tag.Uid || got.Total == 0 {
+		t.Fatalf("unexpected tag %+v", got)
+	}
+
+	list, err := c.ListTags(ctx, 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Tags) != 1 || list.Tags[0].Uid != tag.Uid {
+		t.Fatalf("unexpected tags %+v", list.Tags)
+	}
+
+	if err := c.DeleteTag(ctx, tag.Uid); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetTag(ctx, tag.Uid); !isStatus(err, http.StatusNotFound) {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+}
+
+func TestPins(t *testing.T) {
+	c := newTestServer(t, testServerOptions{Storer: mock.NewStorer()})
+	ctx := context.Background()
+
+	res, err := c.UploadBytes(ctx, bytes.NewReader([]byte("pinned content")), &client.UploadOptions{BatchID: batchOk})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.Pin(ctx, res.Reference); err != nil {
+		t.Fatal(err)
+	}
+
+	pinned, err := c.IsPinned(ctx, res.Reference)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pinned {
+		t.Fatal("reference not pinned")
+	}
+
+	pins, err := c.ListPins(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pins.References) != 1 || !pins.References[0].Equal(res.Reference) {
+		t.Fatalf("unexpected pins %v", pins.References)
+	}
+
+	if err := c.Unpin(ctx, res.Reference); err != nil {
+		t.Fatal(err)
+	}
+
+	pinned, err = c.IsPinned(ctx, res.Reference)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pinned {
+		t.Fatal("reference still pinned")
+	}
+}
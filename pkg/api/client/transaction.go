@@ -0,0 +1,38 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/api"
+)
+
+// PendingTransactions returns the pending transactions sent by the node.
+func (c *Client) PendingTransactions(ctx context.Context) (res api.TransactionPendingList, err error) {
+	err = c.request(ctx, http.MethodGet, "/transactions", nil, nil, nil, &res)
+	return res, err
+}
+
+// Transaction returns the transaction with the hash sent by the node.
+func (c *Client) Transaction(ctx context.Context, hash common.Hash) (res api.TransactionInfo, err error) {
+	err = c.request(ctx, http.MethodGet, "/transactions/"+hash.String(), nil, nil, nil, &res)
+	return res, err
+}
+
+// ResendTransaction resends the pending transaction with the hash.
+func (c *Client) ResendTransaction(ctx context.Context, hash common.Hash) (res api.TransactionHashResponse, err error) {
+	err = c.request(ctx, http.MethodPost, "/transactions/"+hash.String(), nil, nil, nil, &res)
+	return res, err
+}
+
+// CancelTransaction cancels the pending transaction with the hash
+// and returns the hash of the cancellation transaction.
+func (c *Client) CancelTransaction(ctx context.Context, hash common.Hash) (res api.TransactionHashResponse, err error) {
+	err = c.request(ctx, http.MethodDelete, "/transactions/"+hash.String(), nil, nil, nil, &res)
+	return res, err
+}
@@ -0,0 +1,74 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/ethersphere/bee/pkg/api"
+)
+
+// CreateUploadSession creates a session of a resumable upload. The pin
+// option is ignored, the content is pinned when the upload is finished.
+func (c *Client) CreateUploadSession(ctx context.Context, o *UploadOptions) (res api.UploadSessionResponse, err error) {
+	err = c.request(ctx, http.MethodPost, "/uploads", nil, o.header(), nil, &res)
+	return res, err
+}
+
+// UploadSessions returns the sessions of the resumable uploads.
+func (c *Client) UploadSessions(ctx context.Context) (res api.UploadSessionsResponse, err error) {
+	err = c.request(ctx, http.MethodGet, "/uploads", nil, nil, nil, &res)
+	return res, err
+}
+
+// UploadSession returns the session of the resumable upload with the
+// offset from which the upload is continued.
+func (c *Client) UploadSession(ctx context.Context, id string) (res api.UploadSessionResponse, err error) {
+	err = c.request(ctx, http.MethodGet, "/uploads/"+url.PathEscape(id), nil, nil, nil, &res)
+	return res, err
+}
+
+// AppendUpload appends the data streamed from the reader to the upload
+// at the offset, which must be the offset of the session.
+func (c *Client) AppendUpload(ctx context.Context, id string, offset int64, data io.Reader) (res api.UploadSessionResponse, err error) {
+	header := make(http.Header)
+	header.Set("Content-Type", "application/octet-stream")
+	header.Set(api.SwarmUploadOffsetHeader, strconv.FormatInt(offset, 10))
+	err = c.request(ctx, http.MethodPatch, "/uploads/"+url.PathEscape(id), nil, header, data, &res)
+	return res, err
+}
+
+// DeleteUploadSession removes the session of the resumable upload.
+func (c *Client) DeleteUploadSession(ctx context.Context, id string) error {
+	return c.request(ctx, http.MethodDelete, "/uploads/"+url.PathEscape(id), nil, nil, nil, nil)
+}
+
+// FinishUploadBytes finishes the resumable upload as the raw data.
+func (c *Client) FinishUploadBytes(ctx context.Context, id string, pin bool) (res api.BytesPostResponse, err error) {
+	header := (&UploadOptions{Pin: pin}).header()
+	err = c.request(ctx, http.MethodPost, "/uploads/"+url.PathEscape(id)+"/bytes", nil, header, nil, &res)
+	return res, err
+}
+
+// FinishUploadFile finishes the resumable upload as
+// the file with the name and the content type.
+func (c *Client) FinishUploadFile(ctx context.Context, id, name, contentType string, pin bool) (res api.BzzUploadResponse, err error) {
+	header := (&UploadOptions{Pin: pin}).header()
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	header.Set("Content-Type", contentType)
+
+	query := make(url.Values)
+	if name != "" {
+		query.Set("name", name)
+	}
+	err = c.request(ctx, http.MethodPost, "/uploads/"+url.PathEscape(id)+"/bzz", query, header, nil, &res)
+	return res, err
+}
@@ -0,0 +1,80 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package client_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/api/client"
+	pinningmock "github.com/ethersphere/bee/pkg/pinning/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"gitlab.com/nolash/go-mockbytes"
+)
+
+func TestUploadSession(t *testing.T) {
+	pinning := pinningmock.NewServiceMock()
+	c := newTestServer(t, testServerOptions{
+		Pinning: pinning,
+	})
+	ctx := context.Background()
+
+	content, err := mockbytes.New(0, mockbytes.MockTypeStandard).WithModulus(255).SequentialBytes(swarm.ChunkSize * 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	session, err := c.CreateUploadSession(ctx, &client.UploadOptions{BatchID: batchOk})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	split := swarm.ChunkSize + 100
+	res, err := c.AppendUpload(ctx, session.ID, 0, bytes.NewReader(content[:split]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Offset != int64(split) {
+		t.Fatalf("got offset %d, want %d", res.Offset, split)
+	}
+
+	_, err = c.AppendUpload(ctx, session.ID, 0, bytes.NewReader(content[split:]))
+	var e *client.Error
+	if !errors.As(err, &e) || e.Code != http.StatusConflict {
+		t.Fatalf("got error %v, want status %d", err, http.StatusConflict)
+	}
+
+	if _, err := c.AppendUpload(ctx, session.ID, res.Offset, bytes.NewReader(content[split:])); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := c.FinishUploadBytes(ctx, session.ID, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the reference of the same content uploaded at once
+	if want := swarm.MustParseHexAddress("29a5fb121ce96194ba8b7b823a1f9c6af87e1791f824940a53b5a7efe3f790d9"); !got.Reference.Equal(want) {
+		t.Fatalf("got reference %s, want %s", got.Reference, want)
+	}
+
+	pins, err := pinning.Pins()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pins) != 1 || !pins[0].Equal(got.Reference) {
+		t.Fatalf("unexpected pins %v", pins)
+	}
+
+	sessions, err := c.UploadSessions(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions.Sessions) != 0 {
+		t.Fatalf("unexpected sessions %+v", sessions.Sessions)
+	}
+}
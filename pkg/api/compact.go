@@ -0,0 +1,105 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/localstore"
+)
+
+// compaction tracks the state of the last triggered compaction.
+type compaction struct {
+	mu        sync.Mutex
+	running   bool
+	startedAt time.Time
+	progress  localstore.CompactionProgress
+	err       error
+}
+
+type compactionResponse struct {
+	Running   bool      `json:"running"`
+	StartedAt time.Time `json:"startedAt"`
+	Shard     uint8     `json:"shard"`
+	Shards    int       `json:"shards"`
+	Moved     uint64    `json:"moved"`
+	Reclaimed int64     `json:"reclaimed"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// response must be called under the compaction lock.
+func (c *compaction) response() compactionResponse {
+	res := compactionResponse{
+		Running:   c.running,
+		StartedAt: c.startedAt,
+		Shard:     c.progress.Shard,
+		Shards:    c.progress.Shards,
+		Moved:     c.progress.Moved,
+		Reclaimed: c.progress.Reclaimed,
+	}
+	if c.err != nil {
+		res.Error = c.err.Error()
+	}
+	return res
+}
+
+// compactStartHandler triggers the compaction of the local storage
+// in the background, the progress is reported by compactStatusHandler.
+func (s *Service) compactStartHandler(w http.ResponseWriter, _ *http.Request) {
+	s.compaction.mu.Lock()
+	defer s.compaction.mu.Unlock()
+
+	if s.compaction.running {
+		jsonhttp.Conflict(w, "compaction already running")
+		return
+	}
+
+	s.compaction.running = true
+	s.compaction.startedAt = time.Now()
+	s.compaction.progress = localstore.CompactionProgress{}
+	s.compaction.err = nil
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		select {
+		case <-s.quit:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	go func() {
+		defer cancel()
+
+		err := s.localStore.Compact(ctx, func(p localstore.CompactionProgress) {
+			s.compaction.mu.Lock()
+			s.compaction.progress = p
+			s.compaction.mu.Unlock()
+		})
+		if err != nil {
+			s.logger.Debug("compaction failed", "error", err)
+			s.logger.Error(nil, "compaction failed")
+		}
+
+		s.compaction.mu.Lock()
+		s.compaction.running = false
+		s.compaction.err = err
+		s.compaction.mu.Unlock()
+	}()
+
+	jsonhttp.Accepted(w, s.compaction.response())
+}
+
+// compactStatusHandler reports the progress of the last triggered compaction.
+func (s *Service) compactStatusHandler(w http.ResponseWriter, _ *http.Request) {
+	s.compaction.mu.Lock()
+	defer s.compaction.mu.Unlock()
+
+	jsonhttp.OK(w, s.compaction.response())
+}
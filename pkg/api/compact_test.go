@@ -0,0 +1,52 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/localstore"
+	"github.com/ethersphere/bee/pkg/log"
+)
+
+func TestCompact(t *testing.T) {
+	storer, err := localstore.New("", make([]byte, 32), nil, nil, log.Noop)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = storer.Close() })
+
+	client, _, _, _ := newTestServer(t, testServerOptions{
+		DebugAPI:   true,
+		LocalStore: storer,
+	})
+
+	jsonhttptest.Request(t, client, http.MethodPost, "/compact", http.StatusAccepted)
+
+	var res api.CompactionResponse
+	for deadline := time.Now().Add(5 * time.Second); ; {
+		jsonhttptest.Request(t, client, http.MethodGet, "/compact", http.StatusOK,
+			jsonhttptest.WithUnmarshalJSONResponse(&res),
+		)
+		if !res.Running {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("compaction did not finish in time")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if res.Error != "" {
+		t.Fatalf("unexpected error: %s", res.Error)
+	}
+	if res.Shards == 0 || int(res.Shard) != res.Shards-1 {
+		t.Fatalf("got shard %d of %d, want all shards compacted", res.Shard, res.Shards)
+	}
+}
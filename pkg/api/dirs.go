@@ -6,13 +6,18 @@ package api
 
 import (
 	"archive/tar"
+	"archive/zip"
 	"context"
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
+	"math"
 	"mime"
 	"mime/multipart"
 	"net/http"
+	"os"
+	"path"
 	"path/filepath"
 	"runtime"
 	"strconv"
@@ -31,9 +36,22 @@ import (
 	"github.com/ethersphere/bee/pkg/tracing"
 )
 
-var errEmptyDir = errors.New("no files in root directory")
+var (
+	errEmptyDir       = errors.New("no files in root directory")
+	errZipTooLarge    = errors.New("zip archive too large")
+	errZipInvalidPath = errors.New("invalid path in zip archive")
+)
+
+// DefaultZipMaxArchiveSize and DefaultZipMaxSize are the default limits of
+// the size of the zip archive uploaded as a directory and of the total
+// uncompressed size of its files. The archive is stored in a temporary
+// file, as zip is read from the central directory at its end.
+const (
+	DefaultZipMaxArchiveSize int64 = 1 << 30
+	DefaultZipMaxSize        int64 = 4 << 30
+)
 
-// dirUploadHandler uploads a directory supplied as a tar in an HTTP request
+// dirUploadHandler uploads a directory supplied as a tar, zip or multipart in an HTTP request
 func (s *Service) dirUploadHandler(w http.ResponseWriter, r *http.Request, storer storage.Storer, waitFn func() error) {
 	logger := tracing.NewLoggerWithTraceID(r.Context(), s.logger)
 	if r.Body == http.NoBody {
@@ -54,6 +72,28 @@ func (s *Service) dirUploadHandler(w http.ResponseWriter, r *http.Request, store
 	switch mediaType {
 	case contentTypeTar:
 		dReader = &tarReader{r: tar.NewReader(r.Body), logger: s.logger}
+	case contentTypeZip:
+		zr, err := s.newZipReader(r.Body)
+		if err != nil {
+			logger.Debug("bzz upload dir: read zip archive failed", "error", err)
+			logger.Error(nil, "bzz upload dir: read zip archive failed")
+			if jsonhttp.HandleBodyReadError(err, w) {
+				return
+			}
+			switch {
+			case errors.Is(err, errZipTooLarge):
+				jsonhttp.RequestEntityTooLarge(w, errZipTooLarge)
+			case errors.Is(err, errZipInvalidPath):
+				jsonhttp.BadRequest(w, "invalid filename in zip archive")
+			case errors.Is(err, zip.ErrFormat), errors.Is(err, zip.ErrAlgorithm):
+				jsonhttp.BadRequest(w, "invalid zip archive")
+			default:
+				jsonhttp.InternalServerError(w, "read zip archive failed")
+			}
+			return
+		}
+		defer zr.Close()
+		dReader = zr
 	case multiPartFormData:
 		dReader = &multipartReader{r: multipart.NewReader(r.Body, params["boundary"])}
 	default:
@@ -96,6 +136,8 @@ func (s *Service) dirUploadHandler(w http.ResponseWriter, r *http.Request, store
 			jsonhttp.BadRequest(w, errEmptyDir)
 		case errors.Is(err, tar.ErrHeader):
 			jsonhttp.BadRequest(w, "invalid filename in tar archive")
+		case errors.Is(err, zip.ErrFormat), errors.Is(err, zip.ErrAlgorithm), errors.Is(err, zip.ErrChecksum):
+			jsonhttp.BadRequest(w, "invalid zip archive")
 		default:
 			jsonhttp.InternalServerError(w, errDirectoryStore)
 		}
@@ -134,7 +176,7 @@ func (s *Service) dirUploadHandler(w http.ResponseWriter, r *http.Request, store
 	})
 }
 
-// storeDir stores all files recursively contained in the directory given as a tar/multipart
+// storeDir stores all files recursively contained in the directory given as a tar/zip/multipart
 // it returns the hash for the uploaded manifest corresponding to the uploaded dir
 func storeDir(
 	ctx context.Context,
@@ -273,6 +315,13 @@ func (t *tarReader) Next() (*FileInfo, error) {
 
 		fileName := fileHeader.FileInfo().Name()
 		contentType := mime.TypeByExtension(filepath.Ext(fileHeader.Name))
+		// the metadata of the files in the archives downloaded from bzz
+		if v, ok := fileHeader.PAXRecords[tarMetadataPrefix+manifest.EntryMetadataFilenameKey]; ok {
+			fileName = v
+		}
+		if v, ok := fileHeader.PAXRecords[tarMetadataPrefix+manifest.EntryMetadataContentTypeKey]; ok {
+			contentType = v
+		}
 		fileSize := fileHeader.FileInfo().Size()
 		filePath := filepath.Clean(fileHeader.Name)
 
@@ -300,6 +349,147 @@ func (t *tarReader) Next() (*FileInfo, error) {
 	}
 }
 
+// zipReader returns the regular files of the zip archive in the order
+// of the central directory.
+type zipReader struct {
+	f      *os.File
+	files  []zipFile
+	rc     io.ReadCloser
+	logger log.Logger
+}
+
+// zipFile is a regular file of the zip archive with its cleaned path.
+type zipFile struct {
+	path string
+	file *zip.File
+}
+
+// newZipReader stores the zip archive read from r in a temporary file and
+// validates its central directory. The paths of all files must not lead
+// out of the directory and the size of the archive and of its files must
+// be in the limits.
+func (s *Service) newZipReader(r io.Reader) (_ *zipReader, err error) {
+	maxArchiveSize, maxSize := s.ZipMaxArchiveSize, s.ZipMaxSize
+	if maxArchiveSize <= 0 {
+		maxArchiveSize = DefaultZipMaxArchiveSize
+	}
+	if maxSize <= 0 {
+		maxSize = DefaultZipMaxSize
+	}
+
+	f, err := os.CreateTemp(s.TempDir, "bee-zip-upload-")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			f.Close()
+			os.Remove(f.Name())
+		}
+	}()
+
+	// read one byte over the limit to detect the larger archives
+	limit := maxArchiveSize
+	if limit < math.MaxInt64 {
+		limit++
+	}
+	size, err := io.Copy(f, io.LimitReader(r, limit))
+	if err != nil {
+		return nil, err
+	}
+	if size > maxArchiveSize {
+		return nil, errZipTooLarge
+	}
+
+	zr, err := zip.NewReader(f, size)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		files []zipFile
+		total uint64
+	)
+	for _, file := range zr.File {
+		filePath := path.Clean(file.Name)
+		if strings.ContainsRune(file.Name, '\\') || (filePath != "." && !fs.ValidPath(filePath)) {
+			return nil, fmt.Errorf("%w: %q", errZipInvalidPath, file.Name)
+		}
+		if file.Mode().IsDir() {
+			continue
+		}
+		// only store regular files
+		if !file.Mode().IsRegular() {
+			s.logger.Warning("bzz upload dir: skipping file upload as it is not a regular file", "file_path", filePath)
+			continue
+		}
+		if filePath == "." {
+			s.logger.Warning("skipping file upload empty path")
+			continue
+		}
+		// the size of the decompressed data is checked against
+		// the declared size of the file when it is read
+		if file.UncompressedSize64 > uint64(maxSize)-total {
+			return nil, errZipTooLarge
+		}
+		total += file.UncompressedSize64
+		files = append(files, zipFile{path: filePath, file: file})
+	}
+
+	return &zipReader{f: f, files: files, logger: s.logger}, nil
+}
+
+func (z *zipReader) Next() (*FileInfo, error) {
+	if z.rc != nil {
+		if err := z.rc.Close(); err != nil {
+			return nil, err
+		}
+		z.rc = nil
+	}
+	if len(z.files) == 0 {
+		return nil, io.EOF
+	}
+	zf := z.files[0]
+	z.files = z.files[1:]
+
+	fileName := path.Base(zf.path)
+	contentType := mime.TypeByExtension(path.Ext(zf.path))
+	// the metadata of the files in the archives downloaded from bzz
+	metadata := zipExtraMetadata(zf.file.Extra)
+	if v, ok := metadata[manifest.EntryMetadataFilenameKey]; ok {
+		fileName = v
+	}
+	if v, ok := metadata[manifest.EntryMetadataContentTypeKey]; ok {
+		contentType = v
+	}
+
+	rc, err := zf.file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("file %s: %w", zf.path, err)
+	}
+	z.rc = rc
+
+	return &FileInfo{
+		Path:        zf.path,
+		Name:        fileName,
+		ContentType: contentType,
+		Size:        int64(zf.file.UncompressedSize64),
+		Reader:      rc,
+	}, nil
+}
+
+// Close closes the file being read and removes the temporary archive file.
+func (z *zipReader) Close() error {
+	if z.rc != nil {
+		z.rc.Close()
+	}
+	err := z.f.Close()
+	if rmErr := os.Remove(z.f.Name()); err == nil {
+		err = rmErr
+	}
+	return err
+}
+
 // multipart reader returns files added as a multipart form. We will ensure all the
 // part headers are passed correctly
 type multipartReader struct {
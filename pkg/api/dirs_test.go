@@ -6,14 +6,18 @@ package api_test
 
 import (
 	"archive/tar"
+	"archive/zip"
 	"bytes"
 	"context"
 	"fmt"
 	"io"
+	"math"
 	"mime/multipart"
 	"net/http"
 	"net/textproto"
+	"os"
 	"path"
+	"path/filepath"
 	"strconv"
 	"testing"
 
@@ -108,6 +112,7 @@ func TestDirs(t *testing.T) {
 		indexFilenameOption jsonhttptest.Option
 		errorFilenameOption jsonhttptest.Option
 		doMultipart         bool
+		doZip               bool
 		files               []f // files in dir for test case
 	}{
 		{
@@ -134,6 +139,7 @@ func TestDirs(t *testing.T) {
 		},
 		{
 			name:              "nested files with extension",
+			doZip:             true,
 			doMultipart:       true,
 			expectedReference: swarm.MustParseHexAddress("4c9c76d63856102e54092c38a7cd227d769752d768b7adc8c3542e3dd9fcf295"),
 			files: []f{
@@ -197,6 +203,7 @@ func TestDirs(t *testing.T) {
 		},
 		{
 			name:                "nested index filename",
+			doZip:               true,
 			expectedReference:   swarm.MustParseHexAddress("3e2f008a578c435efa7a1fce146e21c4ae8c20b80fbb4c4e0c1c87ca08fef414"),
 			wantIndexFilename:   "index.html",
 			indexFilenameOption: jsonhttptest.WithRequestHeader(api.SwarmIndexDocumentHeader, "index.html"),
@@ -213,6 +220,7 @@ func TestDirs(t *testing.T) {
 		},
 		{
 			name:                "explicit index and error filename",
+			doZip:               true,
 			expectedReference:   swarm.MustParseHexAddress("2cd9a6ac11eefbb71b372fb97c3ef64109c409955964a294fdc183c1014b3844"),
 			wantIndexFilename:   "index.html",
 			wantErrorFilename:   "error.html",
@@ -240,6 +248,7 @@ func TestDirs(t *testing.T) {
 		},
 		{
 			name:              "invalid archive paths",
+			doZip:             true,
 			expectedReference: swarm.MustParseHexAddress("133c92414c047708f3d6a8561571a0cc96512899ff0edbd9690c857f01ab6883"),
 			files: []f{
 				{
@@ -445,6 +454,41 @@ func TestDirs(t *testing.T) {
 						t.Fatalf("expected file reference, did not got any")
 					}
 
+					verify(t, resp)
+				})
+			}
+			if tc.doZip {
+				t.Run("zip_upload", func(t *testing.T) {
+					// zip all the test case files
+					zipReader := zipFiles(t, tc.files)
+
+					var resp api.BzzUploadResponse
+
+					options := []jsonhttptest.Option{
+						jsonhttptest.WithRequestHeader(api.SwarmDeferredUploadHeader, "true"),
+						jsonhttptest.WithRequestHeader(api.SwarmPostageBatchIdHeader, batchOkStr),
+						jsonhttptest.WithRequestBody(zipReader),
+						jsonhttptest.WithRequestHeader(api.SwarmCollectionHeader, "True"),
+						jsonhttptest.WithRequestHeader("Content-Type", api.ContentTypeZip),
+						jsonhttptest.WithUnmarshalJSONResponse(&resp),
+					}
+					if tc.indexFilenameOption != nil {
+						options = append(options, tc.indexFilenameOption)
+					}
+					if tc.errorFilenameOption != nil {
+						options = append(options, tc.errorFilenameOption)
+					}
+					if tc.encrypt {
+						options = append(options, jsonhttptest.WithRequestHeader(api.SwarmEncryptHeader, "true"))
+					}
+
+					// verify directory zip upload response
+					jsonhttptest.Request(t, client, http.MethodPost, dirUploadResource, http.StatusCreated, options...)
+
+					if resp.Reference.String() == "" {
+						t.Fatalf("expected file reference, did not got any")
+					}
+
 					verify(t, resp)
 				})
 			}
@@ -482,6 +526,118 @@ func TestEmtpyDir(t *testing.T) {
 	)
 }
 
+func TestZipDir(t *testing.T) {
+	var (
+		dirUploadResource = "/bzz"
+		newClient         = func(t *testing.T, o testServerOptions) *http.Client {
+			t.Helper()
+			o.Storer = mock.NewStorer()
+			o.Tags = tags.NewTags(statestore.NewStateStore(), log.Noop)
+			o.Logger = log.Noop
+			o.PreventRedirect = true
+			o.Post = mockpost.New(mockpost.WithAcceptAll())
+			client, _, _, _ := newTestServer(t, o)
+			return client
+		}
+		client       = newClient(t, testServerOptions{})
+		uploadClient = func(t *testing.T, client *http.Client, body io.Reader, status int, message string) {
+			t.Helper()
+			jsonhttptest.Request(t, client, http.MethodPost, dirUploadResource, status,
+				jsonhttptest.WithRequestHeader(api.SwarmPostageBatchIdHeader, batchOkStr),
+				jsonhttptest.WithRequestHeader(api.SwarmCollectionHeader, "true"),
+				jsonhttptest.WithRequestHeader("Content-Type", api.ContentTypeZip),
+				jsonhttptest.WithRequestBody(body),
+				jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+					Message: message,
+					Code:    status,
+				}),
+			)
+		}
+		upload = func(t *testing.T, body io.Reader, status int, message string) {
+			t.Helper()
+			uploadClient(t, client, body, status, message)
+		}
+		files = []f{
+			{data: []byte("<h1>Swarm"), name: "index.html"},
+			{data: bytes.Repeat([]byte("swarm"), 1000), name: "data.bin", dir: "dir"},
+		}
+	)
+
+	t.Run("path traversal", func(t *testing.T) {
+		for _, filePath := range []string{"../index.html", "dir/../../index.html", "/etc/passwd", "dir\\..\\..\\index.html"} {
+			upload(t, zipFiles(t, []f{{data: []byte("<h1>Swarm"), filePath: filePath}}),
+				http.StatusBadRequest, "invalid filename in zip archive")
+		}
+	})
+
+	t.Run("invalid zip", func(t *testing.T) {
+		upload(t, bytes.NewReader([]byte("some data")), http.StatusBadRequest, "invalid zip archive")
+	})
+
+	t.Run("empty zip", func(t *testing.T) {
+		upload(t, zipFiles(t, nil), http.StatusBadRequest, api.EmptyDir.Error())
+	})
+
+	t.Run("archive too large", func(t *testing.T) {
+		archive := zipFiles(t, files)
+		client := newClient(t, testServerOptions{
+			ZipMaxArchiveSize: int64(archive.Len() - 1),
+			ZipMaxSize:        1 << 20,
+		})
+
+		uploadClient(t, client, archive, http.StatusRequestEntityTooLarge, api.ZipTooLarge.Error())
+	})
+
+	t.Run("files too large", func(t *testing.T) {
+		// the compressed archive is in the limit, the files are not
+		archive := zipFiles(t, files)
+		client := newClient(t, testServerOptions{
+			ZipMaxArchiveSize: int64(archive.Len()),
+			ZipMaxSize:        5000,
+		})
+
+		uploadClient(t, client, archive, http.StatusRequestEntityTooLarge, api.ZipTooLarge.Error())
+	})
+
+	t.Run("max limits", func(t *testing.T) {
+		client := newClient(t, testServerOptions{
+			ZipMaxArchiveSize: math.MaxInt64,
+			ZipMaxSize:        math.MaxInt64,
+		})
+
+		jsonhttptest.Request(t, client, http.MethodPost, dirUploadResource, http.StatusCreated,
+			jsonhttptest.WithRequestHeader(api.SwarmPostageBatchIdHeader, batchOkStr),
+			jsonhttptest.WithRequestHeader(api.SwarmCollectionHeader, "true"),
+			jsonhttptest.WithRequestHeader("Content-Type", api.ContentTypeZip),
+			jsonhttptest.WithRequestBody(zipFiles(t, files)),
+		)
+	})
+
+	t.Run("temp dir", func(t *testing.T) {
+		dir := t.TempDir()
+		client := newClient(t, testServerOptions{TempDir: dir})
+
+		jsonhttptest.Request(t, client, http.MethodPost, dirUploadResource, http.StatusCreated,
+			jsonhttptest.WithRequestHeader(api.SwarmPostageBatchIdHeader, batchOkStr),
+			jsonhttptest.WithRequestHeader(api.SwarmCollectionHeader, "true"),
+			jsonhttptest.WithRequestHeader("Content-Type", api.ContentTypeZip),
+			jsonhttptest.WithRequestBody(zipFiles(t, files)),
+		)
+		// the archive is removed after the upload
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) != 0 {
+			t.Fatalf("got %d files in the temp dir, want none", len(entries))
+		}
+
+		// the archive can not be stored in a missing temp dir
+		client = newClient(t, testServerOptions{TempDir: filepath.Join(dir, "missing")})
+		uploadClient(t, client, zipFiles(t, files), http.StatusInternalServerError, "read zip archive failed")
+	})
+}
+
 // tarFiles receives an array of test case files and creates a new tar with those files as a collection
 // it returns a bytes.Buffer which can be used to read the created tar
 func tarFiles(t *testing.T, files []f) *bytes.Buffer {
@@ -520,6 +676,39 @@ func tarFiles(t *testing.T, files []f) *bytes.Buffer {
 	return &buf
 }
 
+// zipFiles receives an array of test case files and creates a new zip with those files as a collection
+// it returns a bytes.Buffer which can be used to read the created zip
+func zipFiles(t *testing.T, files []f) *bytes.Buffer {
+	t.Helper()
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+
+	for _, file := range files {
+		filePath := path.Join(file.dir, file.name)
+		if file.filePath != "" {
+			filePath = file.filePath
+		}
+
+		w, err := zw.CreateHeader(&zip.FileHeader{
+			Name:   filePath,
+			Method: zip.Deflate,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write(file.data); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return &buf
+}
+
 func tarEmptyDir(t *testing.T) *bytes.Buffer {
 	t.Helper()
 
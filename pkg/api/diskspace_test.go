@@ -0,0 +1,76 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api_test
+
+import (
+	"bytes"
+	"math"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/localstore"
+	"github.com/ethersphere/bee/pkg/log"
+	mockpost "github.com/ethersphere/bee/pkg/postage/mock"
+	"github.com/ethersphere/bee/pkg/storage/mock"
+)
+
+func TestDiskSpace(t *testing.T) {
+	// no filesystem has this much free space,
+	// so the disk is considered full
+	storer, err := localstore.New(t.TempDir(), make([]byte, 32), nil, &localstore.Options{
+		FreeSpaceHardLimit: math.MaxUint64,
+	}, log.Noop)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = storer.Close() })
+
+	for start := time.Now(); storer.DiskSpaceState() != localstore.DiskSpaceFull; {
+		if time.Since(start) > 10*time.Second {
+			t.Fatal("disk space state not updated")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Run("upload", func(t *testing.T) {
+		client, _, _, _ := newTestServer(t, testServerOptions{
+			Storer:     mock.NewStorer(),
+			LocalStore: storer,
+			Post:       mockpost.New(mockpost.WithAcceptAll()),
+		})
+
+		jsonhttptest.Request(t, client, http.MethodPost, "/bytes", http.StatusInsufficientStorage,
+			jsonhttptest.WithRequestHeader(api.SwarmPostageBatchIdHeader, batchOkStr),
+			jsonhttptest.WithRequestBody(bytes.NewReader([]byte("data"))),
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Message: "disk full: not enough free space to store the upload",
+				Code:    http.StatusInsufficientStorage,
+			}),
+		)
+	})
+
+	t.Run("health", func(t *testing.T) {
+		client, _, _, _ := newTestServer(t, testServerOptions{
+			DebugAPI:   true,
+			LocalStore: storer,
+		})
+
+		var res api.StatusResponse
+		jsonhttptest.Request(t, client, http.MethodGet, "/health", http.StatusOK,
+			jsonhttptest.WithUnmarshalJSONResponse(&res),
+		)
+
+		if res.Status != "ok" {
+			t.Fatalf("got status %q, want %q", res.Status, "ok")
+		}
+		if res.DiskSpace != "full" {
+			t.Fatalf("got disk space %q, want %q", res.DiskSpace, "full")
+		}
+	})
+}
@@ -9,30 +9,17 @@ import (
 	"github.com/ethersphere/bee/pkg/swarm"
 )
 
-type (
-	BytesPostResponse     = bytesPostResponse
-	ChunkAddressResponse  = chunkAddressResponse
-	SocPostResponse       = socPostResponse
-	FeedReferenceResponse = feedReferenceResponse
-	BzzUploadResponse     = bzzUploadResponse
-	TagResponse           = tagResponse
-	DebugTagResponse      = debugTagResponse
-	TagRequest            = tagRequest
-	ListTagsResponse      = listTagsResponse
-	IsRetrievableResponse = isRetrievableResponse
-	SecurityTokenResponse = securityTokenRsp
-	SecurityTokenRequest  = securityTokenReq
-)
-
 var (
 	InvalidContentType  = errInvalidContentType
 	InvalidRequest      = errInvalidRequest
 	DirectoryStoreError = errDirectoryStore
 	EmptyDir            = errEmptyDir
+	ZipTooLarge         = errZipTooLarge
 )
 
 var (
 	ContentTypeTar    = contentTypeTar
+	ContentTypeZip    = contentTypeZip
 	ContentTypeHeader = contentTypeHeader
 )
 
@@ -62,42 +49,6 @@ func CalculateNumberOfChunks(contentLength int64, isEncrypted bool) int64 {
 	return calculateNumberOfChunks(contentLength, isEncrypted)
 }
 
-type (
-	StatusResponse                    = statusResponse
-	NodeResponse                      = nodeResponse
-	PingpongResponse                  = pingpongResponse
-	PeerConnectResponse               = peerConnectResponse
-	PeersResponse                     = peersResponse
-	AddressesResponse                 = addressesResponse
-	WelcomeMessageRequest             = welcomeMessageRequest
-	WelcomeMessageResponse            = welcomeMessageResponse
-	BalancesResponse                  = balancesResponse
-	BalanceResponse                   = balanceResponse
-	SettlementResponse                = settlementResponse
-	SettlementsResponse               = settlementsResponse
-	ChequebookBalanceResponse         = chequebookBalanceResponse
-	ChequebookAddressResponse         = chequebookAddressResponse
-	ChequebookLastChequePeerResponse  = chequebookLastChequePeerResponse
-	ChequebookLastChequesResponse     = chequebookLastChequesResponse
-	ChequebookLastChequesPeerResponse = chequebookLastChequesPeerResponse
-	ChequebookTxResponse              = chequebookTxResponse
-	SwapCashoutResponse               = swapCashoutResponse
-	SwapCashoutStatusResponse         = swapCashoutStatusResponse
-	SwapCashoutStatusResult           = swapCashoutStatusResult
-	TransactionInfo                   = transactionInfo
-	TransactionPendingList            = transactionPendingList
-	TransactionHashResponse           = transactionHashResponse
-	ReserveStateResponse              = reserveStateResponse
-	ChainStateResponse                = chainStateResponse
-	PostageCreateResponse             = postageCreateResponse
-	PostageStampResponse              = postageStampResponse
-	PostageStampsResponse             = postageStampsResponse
-	PostageBatchResponse              = postageBatchResponse
-	PostageStampBucketsResponse       = postageStampBucketsResponse
-	BucketData                        = bucketData
-	WalletResponse                    = walletResponse
-)
-
 var (
 	ErrCantBalance           = errCantBalance
 	ErrCantBalances          = errCantBalances
@@ -124,3 +75,10 @@ var (
 
 func ReplaceLogRegistryIterateFn(fn LogRegistryIterateFn)   { logRegistryIterate = fn }
 func ReplaceLogSetVerbosityByExp(fn LogSetVerbosityByExpFn) { logSetVerbosityByExp = fn }
+
+// SetFitCheckMaxSeen sets the number of the chunk addresses
+// remembered by the postage check and returns the previous one.
+func SetFitCheckMaxSeen(n int) (previous int) {
+	previous, fitCheckMaxSeen = fitCheckMaxSeen, n
+	return previous
+}
@@ -0,0 +1,27 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+)
+
+// localstoreStatsHandler reports the distribution of the stored chunks
+// and the usage of the storage relative to the current storage radius.
+func (s *Service) localstoreStatsHandler(w http.ResponseWriter, r *http.Request) {
+	radius := s.batchStore.GetReserveState().StorageRadius
+
+	stats, err := s.localStore.Stats(r.Context(), radius)
+	if err != nil {
+		s.logger.Debug("localstore stats failed", "error", err)
+		s.logger.Error(nil, "localstore stats failed")
+		jsonhttp.InternalServerError(w, "localstore stats failed")
+		return
+	}
+
+	jsonhttp.OK(w, stats)
+}
@@ -0,0 +1,57 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/localstore"
+	"github.com/ethersphere/bee/pkg/log"
+	"github.com/ethersphere/bee/pkg/postage"
+	mockbatchstore "github.com/ethersphere/bee/pkg/postage/batchstore/mock"
+	"github.com/ethersphere/bee/pkg/storage"
+	testingc "github.com/ethersphere/bee/pkg/storage/testing"
+)
+
+func TestLocalstoreStats(t *testing.T) {
+	storer, err := localstore.New("", make([]byte, 32), nil, nil, log.Noop)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = storer.Close() })
+
+	for i := 0; i < 10; i++ {
+		if _, err := storer.Put(context.Background(), storage.ModePutUpload, testingc.GenerateTestRandomChunk()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	client, _, _, _ := newTestServer(t, testServerOptions{
+		DebugAPI:   true,
+		LocalStore: storer,
+		BatchStore: mockbatchstore.New(mockbatchstore.WithReserveState(&postage.ReserveState{StorageRadius: 3})),
+	})
+
+	var res localstore.Stats
+	jsonhttptest.Request(t, client, http.MethodGet, "/debug/localstore", http.StatusOK,
+		jsonhttptest.WithUnmarshalJSONResponse(&res),
+	)
+
+	if res.Chunks != 10 {
+		t.Fatalf("got %d chunks, want %d", res.Chunks, 10)
+	}
+	if res.StorageRadius != 3 {
+		t.Fatalf("got storage radius %d, want %d", res.StorageRadius, 3)
+	}
+	if len(res.Batches) != 10 {
+		t.Fatalf("got %d batches, want %d", len(res.Batches), 10)
+	}
+	if len(res.Shards) == 0 {
+		t.Fatal("got no shard stats")
+	}
+}
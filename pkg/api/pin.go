@@ -14,6 +14,14 @@ import (
 	"github.com/gorilla/mux"
 )
 
+type pinResponse struct {
+	Reference swarm.Address `json:"reference"`
+}
+
+type pinsResponse struct {
+	References []swarm.Address `json:"references"`
+}
+
 // pinRootHash pins root hash of given reference. This method is idempotent.
 func (s *Service) pinRootHash(w http.ResponseWriter, r *http.Request) {
 	ref, err := swarm.ParseHexAddress(mux.Vars(r)["reference"])
@@ -105,9 +113,7 @@ func (s *Service) getPinnedRootHash(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	jsonhttp.OK(w, struct {
-		Reference swarm.Address `json:"reference"`
-	}{
+	jsonhttp.OK(w, pinResponse{
 		Reference: ref,
 	})
 }
@@ -122,9 +128,7 @@ func (s *Service) listPinnedRootHashes(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	jsonhttp.OK(w, struct {
-		References []swarm.Address `json:"references"`
-	}{
+	jsonhttp.OK(w, pinsResponse{
 		References: pinned,
 	})
 }
@@ -67,6 +67,19 @@ func (b hexByte) MarshalJSON() ([]byte, error) {
 	return json.Marshal(hex.EncodeToString(b))
 }
 
+func (b *hexByte) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	v, err := hex.DecodeString(s)
+	if err != nil {
+		return err
+	}
+	*b = v
+	return nil
+}
+
 type postageCreateResponse struct {
 	BatchID hexByte `json:"batchID"`
 }
@@ -169,6 +182,10 @@ type postageBatchResponse struct {
 	BatchTTL      int64          `json:"batchTTL"`
 }
 
+type postageBatchesResponse struct {
+	Batches []postageBatchResponse `json:"batches"`
+}
+
 type postageStampBucketsResponse struct {
 	Depth            uint8        `json:"depth"`
 	BucketDepth      uint8        `json:"bucketDepth"`
@@ -249,13 +266,9 @@ func (s *Service) postageGetAllStampsHandler(w http.ResponseWriter, _ *http.Requ
 		return
 	}
 
-	batchesRes := struct {
-		Batches []postageBatchResponse `json:"batches"`
-	}{
+	jsonhttp.OK(w, postageBatchesResponse{
 		Batches: batches,
-	}
-
-	jsonhttp.OK(w, batchesRes)
+	})
 }
 
 func (s *Service) postageGetStampBucketsHandler(w http.ResponseWriter, r *http.Request) {
@@ -425,21 +438,7 @@ func (s *Service) estimateBatchTTLFromID(id []byte) (int64, error) {
 // estimateBatchTTL estimates the time remaining until the batch expires.
 // The -1 signals that the batch never expires.
 func (s *Service) estimateBatchTTL(batch *postage.Batch) (int64, error) {
-	state := s.batchStore.GetChainState()
-	if len(state.CurrentPrice.Bits()) == 0 {
-		return -1, nil
-	}
-
-	var (
-		normalizedBalance = batch.Value
-		cumulativePayout  = state.TotalAmount
-		pricePerBlock     = state.CurrentPrice
-	)
-	ttl := new(big.Int).Sub(normalizedBalance, cumulativePayout)
-	ttl = ttl.Mul(ttl, s.blockTime)
-	ttl = ttl.Div(ttl, pricePerBlock)
-
-	return ttl.Int64(), nil
+	return batch.TTL(s.batchStore.GetChainState(), s.blockTime), nil
 }
 
 func (s *Service) postageTopUpHandler(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,230 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"archive/tar"
+	"context"
+	"encoding/hex"
+	"errors"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/ethersphere/bee/pkg/file/loadsave"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/manifest"
+	"github.com/ethersphere/bee/pkg/postage"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/gorilla/mux"
+)
+
+type postageCheckResponse struct {
+	BatchID          hexByte `json:"batchID"`
+	Fits             bool    `json:"fits"`
+	Chunks           int64   `json:"chunks"`
+	Depth            uint8   `json:"depth"`
+	BucketDepth      uint8   `json:"bucketDepth"`
+	BucketUpperBound uint32  `json:"bucketUpperBound"`
+	Utilization      uint32  `json:"utilization"`
+	MaxBucketCount   uint32  `json:"maxBucketCount"`
+	MinDepth         uint8   `json:"minDepth"`
+}
+
+// fitCheckMaxSeen is the number of distinct chunk addresses a fitCheckStore
+// remembers. The chunks put after the limit is reached are all counted, so
+// the content is never reported to fit when it does not.
+var fitCheckMaxSeen = 1 << 18
+
+// fitCheckStore is a store which only counts the chunks put
+// into it with a fit checker, without storing them. The chunks
+// put more than once are counted once, as they are stamped once,
+// as long as the number of remembered addresses is in the limit.
+type fitCheckStore struct {
+	fc   *postage.FitChecker
+	mu   sync.Mutex
+	seen map[string]struct{}
+}
+
+func newFitCheckStore(fc *postage.FitChecker) *fitCheckStore {
+	return &fitCheckStore{
+		fc:   fc,
+		seen: make(map[string]struct{}),
+	}
+}
+
+func (s *fitCheckStore) Put(_ context.Context, _ storage.ModePut, chs ...swarm.Chunk) ([]bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	exists := make([]bool, len(chs))
+	for i, ch := range chs {
+		key := ch.Address().ByteString()
+		if _, ok := s.seen[key]; ok {
+			exists[i] = true
+			continue
+		}
+		if len(s.seen) < fitCheckMaxSeen {
+			s.seen[key] = struct{}{}
+		}
+		s.fc.Inc(ch.Address())
+	}
+	return exists, nil
+}
+
+func (s *fitCheckStore) Get(context.Context, storage.ModeGet, swarm.Address) (swarm.Chunk, error) {
+	return nil, storage.ErrNotFound
+}
+
+// postageCheckBytesHandler checks whether the content uploaded
+// as to the /bytes endpoint fits into the batch.
+func (s *Service) postageCheckBytesHandler(w http.ResponseWriter, r *http.Request) {
+	s.postageCheck(w, r, false)
+}
+
+// postageCheckBzzHandler checks whether the file or the collection
+// uploaded as to the /bzz endpoint fits into the batch.
+func (s *Service) postageCheckBzzHandler(w http.ResponseWriter, r *http.Request) {
+	s.postageCheck(w, r, true)
+}
+
+func (s *Service) postageCheck(w http.ResponseWriter, r *http.Request, bzz bool) {
+	idStr := mux.Vars(r)["id"]
+	id, err := hex.DecodeString(idStr)
+	if err != nil || len(id) != 32 {
+		s.logger.Debug("check postage batch: decode batch id string failed", "string", idStr, "error", err)
+		s.logger.Error(nil, "check postage batch: decode batch id string failed")
+		jsonhttp.BadRequest(w, "invalid batchID")
+		return
+	}
+
+	issuer, err := s.post.GetStampIssuer(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, postage.ErrNotFound):
+			jsonhttp.NotFound(w, "issuer does not exist")
+			return
+		case errors.Is(err, postage.ErrNotUsable):
+			jsonhttp.BadRequest(w, "batch not usable yet")
+			return
+		}
+		s.logger.Debug("check postage batch: get issuer failed", "batch_id", idStr, "error", err)
+		s.logger.Error(nil, "check postage batch: get issuer failed")
+		jsonhttp.InternalServerError(w, "cannot get batch")
+		return
+	}
+
+	contentType := r.Header.Get(contentTypeHeader)
+	mediaType, params, _ := mime.ParseMediaType(contentType)
+	if bzz && mediaType == "" {
+		s.logger.Error(nil, "check postage batch: parse content type header string failed", "string", contentType)
+		jsonhttp.BadRequest(w, errInvalidContentType)
+		return
+	}
+	if !bzz && mediaType == multiPartFormData {
+		s.logger.Error(nil, "check postage batch: multipart uploads are not supported on bytes")
+		jsonhttp.BadRequest(w, "multipart uploads not supported")
+		return
+	}
+
+	fc := postage.NewFitChecker(issuer)
+	store := newFitCheckStore(fc)
+	ctx := r.Context()
+	p := requestPipelineFn(store, r)
+
+	switch {
+	case !bzz:
+		_, err = p(ctx, r.Body)
+	case strings.ToLower(r.Header.Get(SwarmCollectionHeader)) == "true" || mediaType == multiPartFormData:
+		var dReader dirReader
+		switch mediaType {
+		case contentTypeTar:
+			dReader = &tarReader{r: tar.NewReader(r.Body), logger: s.logger}
+		case multiPartFormData:
+			dReader = &multipartReader{r: multipart.NewReader(r.Body, params["boundary"])}
+		default:
+			s.logger.Error(nil, "check postage batch: invalid content-type for directory upload")
+			jsonhttp.BadRequest(w, errInvalidContentType)
+			return
+		}
+		_, err = storeDir(
+			ctx,
+			requestEncrypt(r),
+			dReader,
+			s.logger,
+			p,
+			loadsave.New(store, requestPipelineFactory(ctx, store, r)),
+			r.Header.Get(SwarmIndexDocumentHeader),
+			r.Header.Get(SwarmErrorDocumentHeader),
+			nil,
+			true,
+		)
+	default:
+		err = checkFile(ctx, r, store, p)
+	}
+	if err != nil {
+		s.logger.Debug("check postage batch: split content failed", "batch_id", idStr, "error", err)
+		s.logger.Error(nil, "check postage batch: split content failed")
+		switch {
+		case errors.Is(err, errEmptyDir):
+			jsonhttp.BadRequest(w, errEmptyDir)
+		case errors.Is(err, tar.ErrHeader):
+			jsonhttp.BadRequest(w, "invalid filename in tar archive")
+		default:
+			jsonhttp.InternalServerError(w, "cannot check content")
+		}
+		return
+	}
+
+	jsonhttp.OK(w, postageCheckResponse{
+		BatchID:          id,
+		Fits:             fc.Fits(),
+		Chunks:           fc.Chunks(),
+		Depth:            issuer.Depth(),
+		BucketDepth:      issuer.BucketDepth(),
+		BucketUpperBound: issuer.BucketUpperBound(),
+		Utilization:      issuer.Utilization(),
+		MaxBucketCount:   fc.MaxBucketCount(),
+		MinDepth:         fc.MinDepth(),
+	})
+}
+
+// checkFile splits the file of the request body and
+// its manifest the same way the file upload does.
+func checkFile(ctx context.Context, r *http.Request, store *fitCheckStore, p pipelineFunc) error {
+	fr, err := p(ctx, r.Body)
+	if err != nil {
+		return err
+	}
+
+	fileName := r.URL.Query().Get("name")
+	if fileName == "" {
+		fileName = fr.String()
+	}
+
+	l := loadsave.New(store, requestPipelineFactory(ctx, store, r))
+	m, err := manifest.NewDefaultManifest(l, requestEncrypt(r))
+	if err != nil {
+		return err
+	}
+	err = m.Add(ctx, manifest.RootPath, manifest.NewEntry(swarm.ZeroAddress, map[string]string{
+		manifest.WebsiteIndexDocumentSuffixKey: fileName,
+	}))
+	if err != nil {
+		return err
+	}
+	err = m.Add(ctx, fileName, manifest.NewEntry(fr, map[string]string{
+		manifest.EntryMetadataContentTypeKey: r.Header.Get(contentTypeHeader),
+		manifest.EntryMetadataFilenameKey:    fileName,
+	}))
+	if err != nil {
+		return err
+	}
+	_, err = m.Store(ctx)
+	return err
+}
@@ -0,0 +1,182 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"math/big"
+	"math/bits"
+	"math/rand"
+	"net/http"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/file/pipeline/builder"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/postage"
+	mockpost "github.com/ethersphere/bee/pkg/postage/mock"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/storage/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+func TestPostageCheck(t *testing.T) {
+	// 4 buckets with the bucket upper bound of 2
+	si := postage.NewStampIssuer("", "", batchOk, big.NewInt(3), 3, 2, 1000, true)
+	storer := mock.NewStorer()
+	ts, _, _, _ := newTestServer(t, testServerOptions{
+		Storer:   storer,
+		Post:     mockpost.New(mockpost.WithIssuer(si)),
+		DebugAPI: true,
+	})
+
+	t.Run("bytes", func(t *testing.T) {
+		// 16 data chunks and the root chunk
+		data := make([]byte, 16*swarm.ChunkSize)
+		if _, err := rand.New(rand.NewSource(1)).Read(data); err != nil {
+			t.Fatal(err)
+		}
+
+		var resp api.PostageCheckResponse
+		jsonhttptest.Request(t, ts, http.MethodPost, "/stamps/"+batchOkStr+"/check/bytes", http.StatusOK,
+			jsonhttptest.WithRequestBody(bytes.NewReader(data)),
+			jsonhttptest.WithUnmarshalJSONResponse(&resp),
+		)
+
+		if resp.Chunks != 17 {
+			t.Fatalf("got %d chunks, want 17", resp.Chunks)
+		}
+		// at least one of the 4 buckets holds 5 of the 17 chunks
+		if resp.Fits || resp.MaxBucketCount < 5 {
+			t.Fatalf("got fits %t with max bucket count %d, want overflow", resp.Fits, resp.MaxBucketCount)
+		}
+		if want := 2 + uint8(bits.Len32(resp.MaxBucketCount-1)); resp.MinDepth != want {
+			t.Fatalf("got min depth %d, want %d", resp.MinDepth, want)
+		}
+		if resp.Depth != 3 || resp.BucketDepth != 2 || resp.BucketUpperBound != 2 || resp.Utilization != 0 {
+			t.Fatalf("unexpected batch in response %+v", resp)
+		}
+
+		if si.Utilization() != 0 {
+			t.Fatalf("got issuer utilization %d, want 0", si.Utilization())
+		}
+		ref := splitRef(t, data)
+		if has, err := storer.Has(context.Background(), ref); err != nil || has {
+			t.Fatalf("content stored: %t, %v", has, err)
+		}
+	})
+
+	t.Run("repeated content", func(t *testing.T) {
+		// 16 equal data chunks are stamped once, with the root chunk
+		data := bytes.Repeat([]byte{1, 2, 3, 4}, 16*swarm.ChunkSize/4)
+
+		var resp api.PostageCheckResponse
+		jsonhttptest.Request(t, ts, http.MethodPost, "/stamps/"+batchOkStr+"/check/bytes", http.StatusOK,
+			jsonhttptest.WithRequestBody(bytes.NewReader(data)),
+			jsonhttptest.WithUnmarshalJSONResponse(&resp),
+		)
+
+		if resp.Chunks != 2 {
+			t.Fatalf("got %d chunks, want 2", resp.Chunks)
+		}
+		if !resp.Fits || resp.MaxBucketCount > 2 {
+			t.Fatalf("got fits %t with max bucket count %d, want fit", resp.Fits, resp.MaxBucketCount)
+		}
+	})
+
+	t.Run("repeated content beyond the limit", func(t *testing.T) {
+		defer api.SetFitCheckMaxSeen(api.SetFitCheckMaxSeen(0))
+
+		// no address is remembered, all the chunks are counted
+		data := bytes.Repeat([]byte{1, 2, 3, 4}, 16*swarm.ChunkSize/4)
+
+		var resp api.PostageCheckResponse
+		jsonhttptest.Request(t, ts, http.MethodPost, "/stamps/"+batchOkStr+"/check/bytes", http.StatusOK,
+			jsonhttptest.WithRequestBody(bytes.NewReader(data)),
+			jsonhttptest.WithUnmarshalJSONResponse(&resp),
+		)
+
+		if resp.Chunks != 17 {
+			t.Fatalf("got %d chunks, want 17", resp.Chunks)
+		}
+	})
+
+	t.Run("bzz file", func(t *testing.T) {
+		var resp api.PostageCheckResponse
+		jsonhttptest.Request(t, ts, http.MethodPost, "/stamps/"+batchOkStr+"/check/bzz?name=file.txt", http.StatusOK,
+			jsonhttptest.WithRequestBody(bytes.NewReader([]byte("some data"))),
+			jsonhttptest.WithRequestHeader("Content-Type", "text/plain"),
+			jsonhttptest.WithUnmarshalJSONResponse(&resp),
+		)
+		// the data chunk and the manifest chunks
+		if resp.Chunks < 2 {
+			t.Fatalf("got %d chunks, want the file and its manifest", resp.Chunks)
+		}
+	})
+
+	t.Run("bzz collection", func(t *testing.T) {
+		tr := tarFiles(t, []f{
+			{data: []byte("robots text"), name: "robots.txt"},
+			{data: []byte("image 1"), name: "1.png", dir: "img"},
+		})
+		var resp api.PostageCheckResponse
+		jsonhttptest.Request(t, ts, http.MethodPost, "/stamps/"+batchOkStr+"/check/bzz", http.StatusOK,
+			jsonhttptest.WithRequestBody(tr),
+			jsonhttptest.WithRequestHeader("Content-Type", api.ContentTypeTar),
+			jsonhttptest.WithRequestHeader(api.SwarmCollectionHeader, "true"),
+			jsonhttptest.WithUnmarshalJSONResponse(&resp),
+		)
+		// the data chunks and the manifest chunks
+		if resp.Chunks < 3 {
+			t.Fatalf("got %d chunks, want the files and the manifest", resp.Chunks)
+		}
+	})
+
+	t.Run("bzz without content type", func(t *testing.T) {
+		jsonhttptest.Request(t, ts, http.MethodPost, "/stamps/"+batchOkStr+"/check/bzz", http.StatusBadRequest,
+			jsonhttptest.WithRequestBody(bytes.NewReader([]byte("some data"))),
+			jsonhttptest.WithExpectedJSONResponse(&jsonhttp.StatusResponse{
+				Code:    http.StatusBadRequest,
+				Message: api.InvalidContentType.Error(),
+			}),
+		)
+	})
+
+	t.Run("unknown issuer", func(t *testing.T) {
+		jsonhttptest.Request(t, ts, http.MethodPost, "/stamps/"+hex.EncodeToString(make([]byte, 32))+"/check/bytes", http.StatusNotFound,
+			jsonhttptest.WithRequestBody(bytes.NewReader([]byte("some data"))),
+			jsonhttptest.WithExpectedJSONResponse(&jsonhttp.StatusResponse{
+				Code:    http.StatusNotFound,
+				Message: "issuer does not exist",
+			}),
+		)
+	})
+
+	t.Run("bad batch", func(t *testing.T) {
+		jsonhttptest.Request(t, ts, http.MethodPost, "/stamps/0102/check/bytes", http.StatusBadRequest,
+			jsonhttptest.WithRequestBody(bytes.NewReader([]byte("some data"))),
+			jsonhttptest.WithExpectedJSONResponse(&jsonhttp.StatusResponse{
+				Code:    http.StatusBadRequest,
+				Message: "invalid batchID",
+			}),
+		)
+	})
+}
+
+// splitRef returns the reference of the data split into chunks.
+func splitRef(t *testing.T, data []byte) swarm.Address {
+	t.Helper()
+
+	ctx := context.Background()
+	pipe := builder.NewPipelineBuilder(ctx, mock.NewStorer(), storage.ModePutUpload, false)
+	ref, err := builder.FeedPipeline(ctx, pipe, bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ref
+}
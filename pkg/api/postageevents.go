@@ -0,0 +1,108 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/postage/notifier"
+	"github.com/gorilla/websocket"
+)
+
+// postageEventsWsHandler streams the lifecycle events
+// of the batches of the node as json text messages.
+func (s *Service) postageEventsWsHandler(w http.ResponseWriter, r *http.Request) {
+	upgrader := websocket.Upgrader{
+		CheckOrigin: s.checkOrigin,
+	}
+
+	// subscribe before the upgrade, so no event is
+	// missed once the connection is established
+	events, unsubscribe := s.batchNotifier.SubscribeEvents()
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		unsubscribe()
+		s.logger.Debug("postage events ws: upgrade failed", "error", err)
+		s.logger.Error(nil, "postage events ws: upgrade failed")
+		jsonhttp.InternalServerError(w, "postage events ws: upgrade failed")
+		return
+	}
+
+	s.wsWg.Add(1)
+	go s.pumpBatchEvents(conn, events, unsubscribe)
+}
+
+func (s *Service) pumpBatchEvents(conn *websocket.Conn, events <-chan notifier.Event, unsubscribe func()) {
+	defer s.wsWg.Done()
+	defer unsubscribe()
+
+	var (
+		gone   = make(chan struct{})
+		ticker = time.NewTicker(s.WsPingPeriod)
+		err    error
+	)
+	defer func() {
+		ticker.Stop()
+		_ = conn.Close()
+	}()
+
+	// the client is not expected to send messages, the messages are
+	// read only for the control ones and to notice the client is gone
+	go func() {
+		defer close(gone)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				s.logger.Debug("postage events ws: client gone", "error", err)
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case e := <-events:
+			err = conn.SetWriteDeadline(time.Now().Add(writeDeadline))
+			if err != nil {
+				s.logger.Debug("postage events ws: set write deadline failed", "error", err)
+				return
+			}
+
+			err = conn.WriteJSON(e)
+			if err != nil {
+				s.logger.Debug("postage events ws: write message failed", "error", err)
+				return
+			}
+
+		case <-s.quit:
+			// shutdown
+			err = conn.SetWriteDeadline(time.Now().Add(writeDeadline))
+			if err != nil {
+				s.logger.Debug("postage events ws: set write deadline failed", "error", err)
+				return
+			}
+			err = conn.WriteMessage(websocket.CloseMessage, []byte{})
+			if err != nil {
+				s.logger.Debug("postage events ws: write close message failed", "error", err)
+			}
+			return
+		case <-gone:
+			// client gone
+			return
+		case <-ticker.C:
+			err = conn.SetWriteDeadline(time.Now().Add(writeDeadline))
+			if err != nil {
+				s.logger.Debug("postage events ws: set write deadline failed", "error", err)
+				return
+			}
+			if err = conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				// error encountered while pinging client. client probably gone
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,43 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api_test
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/log"
+	mockbatchstore "github.com/ethersphere/bee/pkg/postage/batchstore/mock"
+	mockpost "github.com/ethersphere/bee/pkg/postage/mock"
+	"github.com/ethersphere/bee/pkg/postage/notifier"
+)
+
+func TestPostageEvents(t *testing.T) {
+	post := mockpost.New()
+	batchNotifier := notifier.New(post, mockbatchstore.New(), post, big.NewInt(5), log.Noop, notifier.Options{})
+	t.Cleanup(func() { _ = batchNotifier.Close() })
+
+	_, wsConn, _, _ := newTestServer(t, testServerOptions{
+		DebugAPI:      true,
+		BatchNotifier: batchNotifier,
+		Post:          post,
+		WsPath:        "/stamps/events",
+	})
+
+	batchNotifier.HandleTopUp(batchOk, big.NewInt(10))
+
+	if err := wsConn.SetReadDeadline(time.Now().Add(5 * time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	var e notifier.Event
+	if err := wsConn.ReadJSON(&e); err != nil {
+		t.Fatal(err)
+	}
+	if e.Type != notifier.EventTopUp || e.BatchID != hex.EncodeToString(batchOk) || e.Amount.Int64() != 10 {
+		t.Fatalf("unexpected event %+v", e)
+	}
+}
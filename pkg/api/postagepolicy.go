@@ -0,0 +1,235 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/bigint"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/postage/policy"
+	"github.com/gorilla/mux"
+)
+
+type postagePolicyRequest struct {
+	TopUpTTL          int64          `json:"topUpTTL"` // in seconds
+	TopUpAmount       *bigint.BigInt `json:"topUpAmount"`
+	DiluteUtilization uint8          `json:"diluteUtilization"`
+	MaxDepth          uint8          `json:"maxDepth"`
+}
+
+type postagePolicyResponse struct {
+	BatchID           hexByte        `json:"batchID"`
+	TopUpTTL          int64          `json:"topUpTTL"`
+	TopUpAmount       *bigint.BigInt `json:"topUpAmount"`
+	DiluteUtilization uint8          `json:"diluteUtilization"`
+	MaxDepth          uint8          `json:"maxDepth"`
+}
+
+type postagePoliciesResponse struct {
+	Budget   *bigint.BigInt          `json:"budget"`
+	Spent    *bigint.BigInt          `json:"spent"`
+	Policies []postagePolicyResponse `json:"policies"`
+}
+
+type postagePolicyActionResponse struct {
+	Timestamp int64          `json:"timestamp"`
+	BatchID   hexByte        `json:"batchID"`
+	Type      string         `json:"type"`
+	Amount    *bigint.BigInt `json:"amount,omitempty"`
+	Depth     uint8          `json:"depth"`
+	Cost      *bigint.BigInt `json:"cost,omitempty"`
+	Error     string         `json:"error,omitempty"`
+}
+
+type postagePolicyActionsResponse struct {
+	Actions []postagePolicyActionResponse `json:"actions"`
+}
+
+func newPostagePolicyResponse(batchID []byte, p policy.Policy) postagePolicyResponse {
+	resp := postagePolicyResponse{
+		BatchID:           batchID,
+		TopUpTTL:          int64(p.TopUpTTL / time.Second),
+		DiluteUtilization: p.DiluteUtilization,
+		MaxDepth:          p.MaxDepth,
+	}
+	if p.TopUpAmount != nil {
+		resp.TopUpAmount = bigint.Wrap(p.TopUpAmount)
+	}
+	return resp
+}
+
+func (s *Service) postageGetPolicyHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := mux.Vars(r)["id"]
+	id, err := hex.DecodeString(idStr)
+	if err != nil || len(id) != 32 {
+		s.logger.Debug("get postage policy: decode batch id string failed", "string", idStr, "error", err)
+		s.logger.Error(nil, "get postage policy: decode batch id string failed")
+		jsonhttp.BadRequest(w, "invalid batchID")
+		return
+	}
+
+	p, err := s.postagePolicy.Policy(id)
+	if err != nil {
+		if errors.Is(err, policy.ErrNotFound) {
+			jsonhttp.NotFound(w, "policy not found")
+			return
+		}
+		s.logger.Debug("get postage policy: get policy failed", "batch_id", idStr, "error", err)
+		s.logger.Error(nil, "get postage policy: get policy failed")
+		jsonhttp.InternalServerError(w, "cannot get policy")
+		return
+	}
+
+	jsonhttp.OK(w, newPostagePolicyResponse(id, p))
+}
+
+func (s *Service) postageSetPolicyHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := mux.Vars(r)["id"]
+	id, err := hex.DecodeString(idStr)
+	if err != nil || len(id) != 32 {
+		s.logger.Debug("set postage policy: decode batch id string failed", "string", idStr, "error", err)
+		s.logger.Error(nil, "set postage policy: decode batch id string failed")
+		jsonhttp.BadRequest(w, "invalid batchID")
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		if jsonhttp.HandleBodyReadError(err, w) {
+			return
+		}
+		s.logger.Debug("set postage policy: read request body failed", "error", err)
+		s.logger.Error(nil, "set postage policy: read request body failed")
+		jsonhttp.InternalServerError(w, "cannot read request")
+		return
+	}
+
+	var req postagePolicyRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		s.logger.Debug("set postage policy: unmarshal request body failed", "error", err)
+		s.logger.Error(nil, "set postage policy: unmarshal request body failed")
+		jsonhttp.BadRequest(w, "invalid request body")
+		return
+	}
+
+	p := policy.Policy{
+		TopUpTTL:          time.Duration(req.TopUpTTL) * time.Second,
+		DiluteUtilization: req.DiluteUtilization,
+		MaxDepth:          req.MaxDepth,
+	}
+	if req.TopUpAmount != nil {
+		p.TopUpAmount = req.TopUpAmount.Int
+	}
+
+	err = s.postagePolicy.SetPolicy(id, p)
+	if err != nil {
+		switch {
+		case errors.Is(err, policy.ErrInvalidPolicy):
+			s.logger.Debug("set postage policy: invalid policy", "batch_id", idStr, "error", err)
+			s.logger.Error(nil, "set postage policy: invalid policy")
+			jsonhttp.BadRequest(w, err.Error())
+		case errors.Is(err, policy.ErrUnknownBatch):
+			jsonhttp.NotFound(w, "issuer does not exist")
+		default:
+			s.logger.Debug("set postage policy: set policy failed", "batch_id", idStr, "error", err)
+			s.logger.Error(nil, "set postage policy: set policy failed")
+			jsonhttp.InternalServerError(w, "cannot set policy")
+		}
+		return
+	}
+
+	jsonhttp.OK(w, newPostagePolicyResponse(id, p))
+}
+
+func (s *Service) postageDeletePolicyHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := mux.Vars(r)["id"]
+	id, err := hex.DecodeString(idStr)
+	if err != nil || len(id) != 32 {
+		s.logger.Debug("delete postage policy: decode batch id string failed", "string", idStr, "error", err)
+		s.logger.Error(nil, "delete postage policy: decode batch id string failed")
+		jsonhttp.BadRequest(w, "invalid batchID")
+		return
+	}
+
+	err = s.postagePolicy.DeletePolicy(id)
+	if err != nil {
+		if errors.Is(err, policy.ErrNotFound) {
+			jsonhttp.NotFound(w, "policy not found")
+			return
+		}
+		s.logger.Debug("delete postage policy: delete policy failed", "batch_id", idStr, "error", err)
+		s.logger.Error(nil, "delete postage policy: delete policy failed")
+		jsonhttp.InternalServerError(w, "cannot delete policy")
+		return
+	}
+
+	jsonhttp.OK(w, nil)
+}
+
+func (s *Service) postageGetPoliciesHandler(w http.ResponseWriter, _ *http.Request) {
+	policies, err := s.postagePolicy.Policies()
+	if err != nil {
+		s.logger.Debug("get postage policies: get policies failed", "error", err)
+		s.logger.Error(nil, "get postage policies: get policies failed")
+		jsonhttp.InternalServerError(w, "cannot get policies")
+		return
+	}
+	spent, err := s.postagePolicy.Spent()
+	if err != nil {
+		s.logger.Debug("get postage policies: get spent amount failed", "error", err)
+		s.logger.Error(nil, "get postage policies: get spent amount failed")
+		jsonhttp.InternalServerError(w, "cannot get spent amount")
+		return
+	}
+
+	resp := postagePoliciesResponse{
+		Budget:   bigint.Wrap(s.postagePolicy.Budget()),
+		Spent:    bigint.Wrap(spent),
+		Policies: make([]postagePolicyResponse, 0, len(policies)),
+	}
+	for _, p := range policies {
+		resp.Policies = append(resp.Policies, newPostagePolicyResponse(p.BatchID, p.Policy))
+	}
+
+	jsonhttp.OK(w, resp)
+}
+
+func (s *Service) postageGetPolicyActionsHandler(w http.ResponseWriter, _ *http.Request) {
+	actions, err := s.postagePolicy.Actions()
+	if err != nil {
+		s.logger.Debug("get postage policy actions: get actions failed", "error", err)
+		s.logger.Error(nil, "get postage policy actions: get actions failed")
+		jsonhttp.InternalServerError(w, "cannot get actions")
+		return
+	}
+
+	resp := postagePolicyActionsResponse{
+		Actions: make([]postagePolicyActionResponse, 0, len(actions)),
+	}
+	for _, a := range actions {
+		ar := postagePolicyActionResponse{
+			Timestamp: a.Timestamp,
+			BatchID:   a.BatchID,
+			Type:      a.Type,
+			Depth:     a.Depth,
+			Error:     a.Error,
+		}
+		if a.Amount != nil {
+			ar.Amount = bigint.Wrap(a.Amount)
+		}
+		if a.Cost != nil {
+			ar.Cost = bigint.Wrap(a.Cost)
+		}
+		resp.Actions = append(resp.Actions, ar)
+	}
+
+	jsonhttp.OK(w, resp)
+}
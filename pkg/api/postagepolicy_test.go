@@ -0,0 +1,131 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api_test
+
+import (
+	"encoding/hex"
+	"math/big"
+	"net/http"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/bigint"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/log"
+	"github.com/ethersphere/bee/pkg/postage"
+	"github.com/ethersphere/bee/pkg/postage/batchstore/mock"
+	mockpost "github.com/ethersphere/bee/pkg/postage/mock"
+	"github.com/ethersphere/bee/pkg/postage/policy"
+	contractMock "github.com/ethersphere/bee/pkg/postage/postagecontract/mock"
+	statestore "github.com/ethersphere/bee/pkg/statestore/mock"
+)
+
+func TestPostagePolicy(t *testing.T) {
+	si := postage.NewStampIssuer("", "", batchOk, big.NewInt(3), 11, 10, 1000, true)
+	svc := policy.New(
+		statestore.NewStateStore(),
+		mock.New(),
+		mockpost.New(mockpost.WithIssuer(si)),
+		contractMock.New(),
+		big.NewInt(5),
+		log.Noop,
+		policy.Options{Budget: big.NewInt(1000)},
+	)
+	t.Cleanup(func() { _ = svc.Close() })
+	ts, _, _, _ := newTestServer(t, testServerOptions{
+		Post:          mockpost.New(mockpost.WithIssuer(si)),
+		PostagePolicy: svc,
+		DebugAPI:      true,
+	})
+
+	policyPath := "/stamps/" + batchOkStr + "/policy"
+	want := api.PostagePolicyResponse{
+		BatchID:           batchOk,
+		TopUpTTL:          3600,
+		TopUpAmount:       bigint.Wrap(big.NewInt(10)),
+		DiluteUtilization: 80,
+		MaxDepth:          20,
+	}
+
+	t.Run("not found", func(t *testing.T) {
+		jsonhttptest.Request(t, ts, http.MethodGet, policyPath, http.StatusNotFound,
+			jsonhttptest.WithExpectedJSONResponse(&jsonhttp.StatusResponse{
+				Code:    http.StatusNotFound,
+				Message: "policy not found",
+			}),
+		)
+	})
+
+	t.Run("bad batch", func(t *testing.T) {
+		jsonhttptest.Request(t, ts, http.MethodPut, "/stamps/"+hex.EncodeToString([]byte{0, 1, 2})+"/policy", http.StatusBadRequest,
+			jsonhttptest.WithJSONRequestBody(api.PostagePolicyRequest{}),
+			jsonhttptest.WithExpectedJSONResponse(&jsonhttp.StatusResponse{
+				Code:    http.StatusBadRequest,
+				Message: "invalid batchID",
+			}),
+		)
+	})
+
+	t.Run("unknown batch", func(t *testing.T) {
+		jsonhttptest.Request(t, ts, http.MethodPut, "/stamps/"+hex.EncodeToString(make([]byte, 32))+"/policy", http.StatusNotFound,
+			jsonhttptest.WithJSONRequestBody(api.PostagePolicyRequest{
+				TopUpTTL:    3600,
+				TopUpAmount: bigint.Wrap(big.NewInt(10)),
+			}),
+			jsonhttptest.WithExpectedJSONResponse(&jsonhttp.StatusResponse{
+				Code:    http.StatusNotFound,
+				Message: "issuer does not exist",
+			}),
+		)
+	})
+
+	t.Run("invalid policy", func(t *testing.T) {
+		jsonhttptest.Request(t, ts, http.MethodPut, policyPath, http.StatusBadRequest,
+			jsonhttptest.WithJSONRequestBody(api.PostagePolicyRequest{TopUpTTL: 3600}),
+			jsonhttptest.WithExpectedJSONResponse(&jsonhttp.StatusResponse{
+				Code:    http.StatusBadRequest,
+				Message: "invalid policy: top-up amount not positive",
+			}),
+		)
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		jsonhttptest.Request(t, ts, http.MethodPut, policyPath, http.StatusOK,
+			jsonhttptest.WithJSONRequestBody(api.PostagePolicyRequest{
+				TopUpTTL:          3600,
+				TopUpAmount:       bigint.Wrap(big.NewInt(10)),
+				DiluteUtilization: 80,
+				MaxDepth:          20,
+			}),
+			jsonhttptest.WithExpectedJSONResponse(&want),
+		)
+		jsonhttptest.Request(t, ts, http.MethodGet, policyPath, http.StatusOK,
+			jsonhttptest.WithExpectedJSONResponse(&want),
+		)
+		jsonhttptest.Request(t, ts, http.MethodGet, "/stamps/policies", http.StatusOK,
+			jsonhttptest.WithExpectedJSONResponse(&api.PostagePoliciesResponse{
+				Budget:   bigint.Wrap(big.NewInt(1000)),
+				Spent:    bigint.Wrap(big.NewInt(0)),
+				Policies: []api.PostagePolicyResponse{want},
+			}),
+		)
+		jsonhttptest.Request(t, ts, http.MethodGet, "/stamps/policies/actions", http.StatusOK,
+			jsonhttptest.WithExpectedJSONResponse(&api.PostagePolicyActionsResponse{
+				Actions: []api.PostagePolicyActionResponse{},
+			}),
+		)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		jsonhttptest.Request(t, ts, http.MethodDelete, policyPath, http.StatusOK)
+		jsonhttptest.Request(t, ts, http.MethodDelete, policyPath, http.StatusNotFound,
+			jsonhttptest.WithExpectedJSONResponse(&jsonhttp.StatusResponse{
+				Code:    http.StatusNotFound,
+				Message: "policy not found",
+			}),
+		)
+	})
+}
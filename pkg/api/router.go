@@ -13,6 +13,7 @@ import (
 
 	"github.com/ethersphere/bee/pkg/auth"
 	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/localstore"
 	"github.com/ethersphere/bee/pkg/log/httpaccess"
 	"github.com/ethersphere/bee/pkg/swarm"
 	"github.com/gorilla/handlers"
@@ -126,7 +127,7 @@ func (s *Service) mountTechnicalDebug() {
 
 	s.router.Handle("/health", web.ChainHandlers(
 		httpaccess.NewHTTPAccessSuppressLogHandler(),
-		web.FinalHandlerFunc(statusHandler),
+		web.FinalHandlerFunc(s.healthHandler),
 	))
 
 	s.router.Handle("/loggers", jsonhttp.MethodHandler{
@@ -175,6 +176,7 @@ func (s *Service) mountAPI() {
 
 	handle("/bytes", jsonhttp.MethodHandler{
 		"POST": web.ChainHandlers(
+			s.diskSpaceHandler,
 			s.contentLengthMetricMiddleware(),
 			s.newTracingHandler("bytes-upload"),
 			web.FinalHandlerFunc(s.bytesUploadHandler),
@@ -193,14 +195,63 @@ func (s *Service) mountAPI() {
 		),
 	})
 
+	handle("/uploads", web.ChainHandlers(
+		s.gatewayModeForbidEndpointHandler,
+		web.FinalHandler(jsonhttp.MethodHandler{
+			"GET": http.HandlerFunc(s.uploadSessionsHandler),
+			"POST": web.ChainHandlers(
+				s.diskSpaceHandler,
+				web.FinalHandlerFunc(s.uploadSessionCreateHandler),
+			),
+		})),
+	)
+
+	handle("/uploads/{id}", web.ChainHandlers(
+		s.gatewayModeForbidEndpointHandler,
+		web.FinalHandler(jsonhttp.MethodHandler{
+			"GET": http.HandlerFunc(s.uploadSessionGetHandler),
+			"PATCH": web.ChainHandlers(
+				s.diskSpaceHandler,
+				s.contentLengthMetricMiddleware(),
+				s.newTracingHandler("upload-session-append"),
+				web.FinalHandlerFunc(s.uploadSessionAppendHandler),
+			),
+			"DELETE": http.HandlerFunc(s.uploadSessionDeleteHandler),
+		})),
+	)
+
+	handle("/uploads/{id}/bytes", web.ChainHandlers(
+		s.gatewayModeForbidEndpointHandler,
+		web.FinalHandler(jsonhttp.MethodHandler{
+			"POST": web.ChainHandlers(
+				s.diskSpaceHandler,
+				s.newTracingHandler("upload-session-bytes"),
+				web.FinalHandlerFunc(s.uploadSessionBytesHandler),
+			),
+		})),
+	)
+
+	handle("/uploads/{id}/bzz", web.ChainHandlers(
+		s.gatewayModeForbidEndpointHandler,
+		web.FinalHandler(jsonhttp.MethodHandler{
+			"POST": web.ChainHandlers(
+				s.diskSpaceHandler,
+				s.newTracingHandler("upload-session-bzz"),
+				web.FinalHandlerFunc(s.uploadSessionBzzHandler),
+			),
+		})),
+	)
+
 	handle("/chunks", jsonhttp.MethodHandler{
 		"POST": web.ChainHandlers(
+			s.diskSpaceHandler,
 			jsonhttp.NewMaxBodyBytesHandler(swarm.ChunkWithSpanSize),
 			web.FinalHandlerFunc(s.chunkUploadHandler),
 		),
 	})
 
 	handle("/chunks/stream", web.ChainHandlers(
+		s.diskSpaceHandler,
 		s.newTracingHandler("chunks-stream-upload"),
 		web.FinalHandlerFunc(s.chunkUploadStreamHandler),
 	))
@@ -213,6 +264,7 @@ func (s *Service) mountAPI() {
 
 	handle("/soc/{owner}/{id}", jsonhttp.MethodHandler{
 		"POST": web.ChainHandlers(
+			s.diskSpaceHandler,
 			jsonhttp.NewMaxBodyBytesHandler(swarm.ChunkWithSpanSize),
 			web.FinalHandlerFunc(s.socUploadHandler),
 		),
@@ -221,6 +273,7 @@ func (s *Service) mountAPI() {
 	handle("/feeds/{owner}/{topic}", jsonhttp.MethodHandler{
 		"GET": http.HandlerFunc(s.feedGetHandler),
 		"POST": web.ChainHandlers(
+			s.diskSpaceHandler,
 			jsonhttp.NewMaxBodyBytesHandler(swarm.ChunkWithSpanSize),
 			web.FinalHandlerFunc(s.feedPostHandler),
 		),
@@ -228,25 +281,33 @@ func (s *Service) mountAPI() {
 
 	handle("/bzz", jsonhttp.MethodHandler{
 		"POST": web.ChainHandlers(
+			s.diskSpaceHandler,
 			s.contentLengthMetricMiddleware(),
 			s.newTracingHandler("bzz-upload"),
 			web.FinalHandlerFunc(s.bzzUploadHandler),
 		),
 	})
 
+	bzzDownloadHandler := jsonhttp.MethodHandler{
+		"GET": web.ChainHandlers(
+			s.contentLengthMetricMiddleware(),
+			s.newTracingHandler("bzz-download"),
+			web.FinalHandlerFunc(s.bzzDownloadHandler),
+		),
+	}
+
 	handle("/bzz/{address}", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the whole collection is downloaded as an archive
+		if format, _, err := requestArchiveFormat(r); format != "" || err != nil {
+			bzzDownloadHandler.ServeHTTP(w, r)
+			return
+		}
 		u := r.URL
 		u.Path += "/"
 		http.Redirect(w, r, u.String(), http.StatusPermanentRedirect)
 	}))
 
-	handle("/bzz/{address}/{path:.*}", jsonhttp.MethodHandler{
-		"GET": web.ChainHandlers(
-			s.contentLengthMetricMiddleware(),
-			s.newTracingHandler("bzz-download"),
-			web.FinalHandlerFunc(s.bzzDownloadHandler),
-		),
-	})
+	handle("/bzz/{address}/{path:.*}", bzzDownloadHandler)
 
 	handle("/pss/send/{topic}/{targets}", web.ChainHandlers(
 		s.gatewayModeForbidEndpointHandler,
@@ -363,6 +424,21 @@ func (s *Service) mountBusinessDebug(restricted bool) {
 		"GET": http.HandlerFunc(s.reserveStateHandler),
 	})
 
+	if s.localStore != nil {
+		handle("/compact", jsonhttp.MethodHandler{
+			"GET":  http.HandlerFunc(s.compactStatusHandler),
+			"POST": http.HandlerFunc(s.compactStartHandler),
+		})
+
+		handle("/backup", jsonhttp.MethodHandler{
+			"GET": http.HandlerFunc(s.backupHandler),
+		})
+
+		handle("/debug/localstore", jsonhttp.MethodHandler{
+			"GET": http.HandlerFunc(s.localstoreStatsHandler),
+		})
+	}
+
 	handle("/connect/{multi-address:.+}", jsonhttp.MethodHandler{
 		"POST": http.HandlerFunc(s.peerConnectHandler),
 	})
@@ -457,6 +533,28 @@ func (s *Service) mountBusinessDebug(restricted bool) {
 		})
 	}
 
+	if s.postagePolicy != nil {
+		handle("/stamps/policies", jsonhttp.MethodHandler{
+			"GET": http.HandlerFunc(s.postageGetPoliciesHandler),
+		})
+
+		handle("/stamps/policies/actions", jsonhttp.MethodHandler{
+			"GET": http.HandlerFunc(s.postageGetPolicyActionsHandler),
+		})
+
+		handle("/stamps/{id}/policy", jsonhttp.MethodHandler{
+			"GET":    http.HandlerFunc(s.postageGetPolicyHandler),
+			"PUT":    http.HandlerFunc(s.postageSetPolicyHandler),
+			"DELETE": http.HandlerFunc(s.postageDeletePolicyHandler),
+		})
+	}
+
+	if s.batchNotifier != nil {
+		handle("/stamps/events", web.ChainHandlers(
+			web.FinalHandlerFunc(s.postageEventsWsHandler),
+		))
+	}
+
 	handle("/stamps", web.ChainHandlers(
 		s.postageSyncStatusCheckHandler,
 		web.FinalHandler(jsonhttp.MethodHandler{
@@ -478,6 +576,14 @@ func (s *Service) mountBusinessDebug(restricted bool) {
 		})),
 	)
 
+	handle("/stamps/{id}/check/bytes", jsonhttp.MethodHandler{
+		"POST": http.HandlerFunc(s.postageCheckBytesHandler),
+	})
+
+	handle("/stamps/{id}/check/bzz", jsonhttp.MethodHandler{
+		"POST": http.HandlerFunc(s.postageCheckBzzHandler),
+	})
+
 	handle("/stamps/{amount}/{depth}", web.ChainHandlers(
 		s.postageAccessHandler,
 		s.postageSyncStatusCheckHandler,
@@ -524,6 +630,20 @@ func (s *Service) gatewayModeForbidEndpointHandler(h http.Handler) http.Handler
 	})
 }
 
+// diskSpaceHandler rejects the uploads while the free
+// space on the disk of the local storage is below the hard limit.
+func (s *Service) diskSpaceHandler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.localStore != nil && s.localStore.DiskSpaceState() == localstore.DiskSpaceFull {
+			s.logger.Debug("upload: disk full", "url", r.URL)
+			s.logger.Error(nil, "upload: disk full")
+			jsonhttp.InsufficientStorage(w, "disk full: not enough free space to store the upload")
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
 func (s *Service) gatewayModeForbidHeadersHandler(h http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if s.GatewayMode {
@@ -16,6 +16,7 @@ type statusResponse struct {
 	Version         string `json:"version"`
 	APIVersion      string `json:"apiVersion"`
 	DebugAPIVersion string `json:"debugApiVersion"`
+	DiskSpace       string `json:"diskSpace,omitempty"`
 }
 
 func statusHandler(w http.ResponseWriter, r *http.Request) {
@@ -26,3 +27,18 @@ func statusHandler(w http.ResponseWriter, r *http.Request) {
 		DebugAPIVersion: Version,
 	})
 }
+
+// healthHandler extends the status response
+// with the state of the free disk space.
+func (s *Service) healthHandler(w http.ResponseWriter, r *http.Request) {
+	resp := statusResponse{
+		Status:          "ok",
+		Version:         bee.Version,
+		APIVersion:      Version,
+		DebugAPIVersion: Version,
+	}
+	if s.localStore != nil {
+		resp.DiskSpace = s.localStore.DiskSpaceState().String()
+	}
+	jsonhttp.OK(w, resp)
+}
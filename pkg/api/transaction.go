@@ -32,11 +32,14 @@ type transactionInfo struct {
 	To              *common.Address `json:"to"`
 	Nonce           uint64          `json:"nonce"`
 	GasPrice        *bigint.BigInt  `json:"gasPrice"`
+	GasFeeCap       *bigint.BigInt  `json:"gasFeeCap,omitempty"`
+	GasTipCap       *bigint.BigInt  `json:"gasTipCap,omitempty"`
 	GasLimit        uint64          `json:"gasLimit"`
 	Data            string          `json:"data"`
 	Created         time.Time       `json:"created"`
 	Description     string          `json:"description"`
 	Value           *bigint.BigInt  `json:"value"`
+	ReplacedBy      *common.Hash    `json:"replacedBy,omitempty"`
 }
 
 type transactionPendingList struct {
@@ -62,18 +65,7 @@ func (s *Service) transactionListHandler(w http.ResponseWriter, r *http.Request)
 			return
 		}
 
-		transactionInfos = append(transactionInfos, transactionInfo{
-			TransactionHash: txHash,
-			To:              storedTransaction.To,
-			Nonce:           storedTransaction.Nonce,
-			GasPrice:        bigint.Wrap(storedTransaction.GasPrice),
-			GasLimit:        storedTransaction.GasLimit,
-			Data:            hexutil.Encode(storedTransaction.Data),
-			Created:         time.Unix(storedTransaction.Created, 0),
-			Description:     storedTransaction.Description,
-			Value:           bigint.Wrap(storedTransaction.Value),
-		})
-
+		transactionInfos = append(transactionInfos, newTransactionInfo(txHash, storedTransaction))
 	}
 
 	jsonhttp.OK(w, transactionPendingList{
@@ -97,7 +89,11 @@ func (s *Service) transactionDetailHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	jsonhttp.OK(w, transactionInfo{
+	jsonhttp.OK(w, newTransactionInfo(txHash, storedTransaction))
+}
+
+func newTransactionInfo(txHash common.Hash, storedTransaction *transaction.StoredTransaction) transactionInfo {
+	info := transactionInfo{
 		TransactionHash: txHash,
 		To:              storedTransaction.To,
 		Nonce:           storedTransaction.Nonce,
@@ -107,7 +103,16 @@ func (s *Service) transactionDetailHandler(w http.ResponseWriter, r *http.Reques
 		Created:         time.Unix(storedTransaction.Created, 0),
 		Description:     storedTransaction.Description,
 		Value:           bigint.Wrap(storedTransaction.Value),
-	})
+		ReplacedBy:      storedTransaction.ReplacedBy,
+	}
+	// only dynamic fee transactions have the fee caps
+	if storedTransaction.GasFeeCap != nil {
+		info.GasFeeCap = bigint.Wrap(storedTransaction.GasFeeCap)
+	}
+	if storedTransaction.GasTipCap != nil {
+		info.GasTipCap = bigint.Wrap(storedTransaction.GasTipCap)
+	}
+	return info
 }
 
 type transactionHashResponse struct {
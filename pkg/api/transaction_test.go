@@ -69,6 +69,46 @@ func TestTransactionStoredTransaction(t *testing.T) {
 		)
 	})
 
+	t.Run("dynamic fee", func(t *testing.T) {
+		gasFeeCap := big.NewInt(30)
+		gasTipCap := big.NewInt(2)
+		testServer, _, _, _ := newTestServer(t, testServerOptions{
+			DebugAPI: true,
+			TransactionOpts: []mock.Option{
+				mock.WithStoredTransactionFunc(func(txHash common.Hash) (*transaction.StoredTransaction, error) {
+					return &transaction.StoredTransaction{
+						To:          &recipient,
+						Created:     created,
+						Data:        data,
+						GasPrice:    gasFeeCap,
+						GasFeeCap:   gasFeeCap,
+						GasTipCap:   gasTipCap,
+						GasLimit:    gasLimit,
+						Value:       value,
+						Nonce:       nonce,
+						Description: description,
+					}, nil
+				}),
+			},
+		})
+
+		jsonhttptest.Request(t, testServer, http.MethodGet, "/transactions/"+txHashStr, http.StatusOK,
+			jsonhttptest.WithExpectedJSONResponse(api.TransactionInfo{
+				TransactionHash: txHash,
+				Created:         time.Unix(created, 0),
+				Data:            "0x" + dataStr,
+				To:              &recipient,
+				GasPrice:        bigint.Wrap(gasFeeCap),
+				GasFeeCap:       bigint.Wrap(gasFeeCap),
+				GasTipCap:       bigint.Wrap(gasTipCap),
+				GasLimit:        gasLimit,
+				Value:           bigint.Wrap(value),
+				Nonce:           nonce,
+				Description:     description,
+			}),
+		)
+	})
+
 	t.Run("not found", func(t *testing.T) {
 		testServer, _, _, _ := newTestServer(t, testServerOptions{
 			DebugAPI: true,
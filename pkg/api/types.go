@@ -0,0 +1,71 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+// The request and response bodies of the API endpoints are
+// exported for the clients of the API, like the client package.
+type (
+	BytesPostResponse      = bytesPostResponse
+	ChunkAddressResponse   = chunkAddressResponse
+	SocPostResponse        = socPostResponse
+	FeedReferenceResponse  = feedReferenceResponse
+	BzzUploadResponse      = bzzUploadResponse
+	TagResponse            = tagResponse
+	DebugTagResponse       = debugTagResponse
+	TagRequest             = tagRequest
+	ListTagsResponse       = listTagsResponse
+	PinResponse            = pinResponse
+	PinsResponse           = pinsResponse
+	IsRetrievableResponse  = isRetrievableResponse
+	SecurityTokenResponse  = securityTokenRsp
+	SecurityTokenRequest   = securityTokenReq
+	UploadSessionResponse  = uploadSessionResponse
+	UploadSessionsResponse = uploadSessionsResponse
+)
+
+type (
+	StatusResponse                    = statusResponse
+	NodeResponse                      = nodeResponse
+	PingpongResponse                  = pingpongResponse
+	PeerConnectResponse               = peerConnectResponse
+	PeersResponse                     = peersResponse
+	AddressesResponse                 = addressesResponse
+	WelcomeMessageRequest             = welcomeMessageRequest
+	WelcomeMessageResponse            = welcomeMessageResponse
+	BalancesResponse                  = balancesResponse
+	BalanceResponse                   = balanceResponse
+	SettlementResponse                = settlementResponse
+	SettlementsResponse               = settlementsResponse
+	ChequebookBalanceResponse         = chequebookBalanceResponse
+	ChequebookAddressResponse         = chequebookAddressResponse
+	ChequebookLastChequePeerResponse  = chequebookLastChequePeerResponse
+	ChequebookLastChequesResponse     = chequebookLastChequesResponse
+	ChequebookLastChequesPeerResponse = chequebookLastChequesPeerResponse
+	ChequebookTxResponse              = chequebookTxResponse
+	SwapCashoutResponse               = swapCashoutResponse
+	SwapCashoutStatusResponse         = swapCashoutStatusResponse
+	SwapCashoutStatusResult           = swapCashoutStatusResult
+	TransactionInfo                   = transactionInfo
+	TransactionPendingList            = transactionPendingList
+	TransactionHashResponse           = transactionHashResponse
+	ReserveStateResponse              = reserveStateResponse
+	ChainStateResponse                = chainStateResponse
+	PostageCreateResponse             = postageCreateResponse
+	PostageStampResponse              = postageStampResponse
+	PostageStampsResponse             = postageStampsResponse
+	PostageBatchResponse              = postageBatchResponse
+	PostageBatchesResponse            = postageBatchesResponse
+	PostageStampBucketsResponse       = postageStampBucketsResponse
+	PostageCheckResponse              = postageCheckResponse
+	BucketData                        = bucketData
+	PostagePolicyRequest              = postagePolicyRequest
+	PostagePolicyResponse             = postagePolicyResponse
+	PostagePoliciesResponse           = postagePoliciesResponse
+	PostagePolicyActionResponse       = postagePolicyActionResponse
+	PostagePolicyActionsResponse      = postagePolicyActionsResponse
+	WalletResponse                    = walletResponse
+	CompactionResponse                = compactionResponse
+	LoggersResponse                   = loggerResult
+)
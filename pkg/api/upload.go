@@ -0,0 +1,497 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ethersphere/bee/pkg/file/loadsave"
+	"github.com/ethersphere/bee/pkg/file/pipeline"
+	"github.com/ethersphere/bee/pkg/file/pipeline/builder"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/log"
+	"github.com/ethersphere/bee/pkg/manifest"
+	"github.com/ethersphere/bee/pkg/postage"
+	"github.com/ethersphere/bee/pkg/sctx"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/tags"
+	"github.com/ethersphere/bee/pkg/tracing"
+	"github.com/ethersphere/bee/pkg/upload"
+	"github.com/gorilla/mux"
+)
+
+type uploadSessionResponse struct {
+	ID        string  `json:"id"`
+	Tag       uint32  `json:"tag"`
+	BatchID   hexByte `json:"batchID"`
+	Encrypt   bool    `json:"encrypt"`
+	Offset    int64   `json:"offset"`
+	CreatedAt int64   `json:"createdAt"`
+	UpdatedAt int64   `json:"updatedAt"`
+}
+
+type uploadSessionsResponse struct {
+	Sessions []uploadSessionResponse `json:"sessions"`
+}
+
+func newUploadSessionResponse(s upload.Session) uploadSessionResponse {
+	return uploadSessionResponse{
+		ID:        s.ID,
+		Tag:       s.TagID,
+		BatchID:   s.BatchID,
+		Encrypt:   s.Encrypt,
+		Offset:    s.Offset,
+		CreatedAt: s.CreatedAt,
+		UpdatedAt: s.UpdatedAt,
+	}
+}
+
+// uploadSessionCreateHandler creates a session of a resumable upload
+// bound to the tag and the postage batch of the request.
+func (s *Service) uploadSessionCreateHandler(w http.ResponseWriter, r *http.Request) {
+	logger := tracing.NewLoggerWithTraceID(r.Context(), s.logger)
+
+	batch, err := requestPostageBatchId(r)
+	if err != nil {
+		logger.Debug("upload session: postage batch id failed", "error", err)
+		logger.Error(nil, "upload session: postage batch id failed")
+		jsonhttp.BadRequest(w, "invalid postage batch id")
+		return
+	}
+
+	deferred, err := requestDeferred(r)
+	if err != nil {
+		logger.Debug("upload session: parse deferred header failed", "error", err)
+		logger.Error(nil, "upload session: parse deferred header failed")
+		jsonhttp.BadRequest(w, "invalid deferred value")
+		return
+	}
+
+	// the batch is checked at the creation of the session
+	// not to start an upload which cannot be stamped
+	if _, _, err := s.newBatchPutter(batch, deferred); err != nil {
+		logger.Debug("upload session: get putter failed", "error", err)
+		logger.Error(nil, "upload session: get putter failed")
+		writeBatchPutterError(w, err)
+		return
+	}
+
+	tag, created, err := s.getOrCreateTag(r.Header.Get(SwarmTagHeader))
+	if err != nil {
+		logger.Debug("upload session: get or create tag failed", "error", err)
+		logger.Error(nil, "upload session: get or create tag failed")
+		jsonhttp.InternalServerError(w, "cannot get or create tag")
+		return
+	}
+
+	session, err := s.uploads.Create(upload.Session{
+		TagID:      tag.Uid,
+		TagCreated: created,
+		BatchID:    batch,
+		Encrypt:    requestEncrypt(r),
+		Deferred:   deferred,
+	})
+	if err != nil {
+		logger.Debug("upload session: create session failed", "error", err)
+		logger.Error(nil, "upload session: create session failed")
+		jsonhttp.InternalServerError(w, "cannot create upload session")
+		return
+	}
+
+	w.Header().Set(SwarmTagHeader, fmt.Sprint(tag.Uid))
+	w.Header().Set(SwarmUploadOffsetHeader, "0")
+	w.Header().Set("Access-Control-Expose-Headers", strings.Join([]string{SwarmTagHeader, SwarmUploadOffsetHeader}, ", "))
+	jsonhttp.Created(w, newUploadSessionResponse(session))
+}
+
+func (s *Service) uploadSessionsHandler(w http.ResponseWriter, _ *http.Request) {
+	sessions, err := s.uploads.Sessions()
+	if err != nil {
+		s.logger.Debug("upload sessions: get sessions failed", "error", err)
+		s.logger.Error(nil, "upload sessions: get sessions failed")
+		jsonhttp.InternalServerError(w, "cannot get upload sessions")
+		return
+	}
+
+	res := uploadSessionsResponse{Sessions: make([]uploadSessionResponse, 0, len(sessions))}
+	for _, session := range sessions {
+		res.Sessions = append(res.Sessions, newUploadSessionResponse(session))
+	}
+	jsonhttp.OK(w, res)
+}
+
+func (s *Service) uploadSessionGetHandler(w http.ResponseWriter, r *http.Request) {
+	session, err := s.uploads.Session(mux.Vars(r)["id"])
+	if err != nil {
+		s.logger.Debug("upload session: get session failed", "error", err)
+		s.logger.Error(nil, "upload session: get session failed")
+		writeUploadSessionError(w, err)
+		return
+	}
+
+	w.Header().Set(SwarmUploadOffsetHeader, strconv.FormatInt(session.Offset, 10))
+	w.Header().Set("Access-Control-Expose-Headers", SwarmUploadOffsetHeader)
+	jsonhttp.OK(w, newUploadSessionResponse(session))
+}
+
+func (s *Service) uploadSessionDeleteHandler(w http.ResponseWriter, r *http.Request) {
+	if err := s.uploads.Delete(mux.Vars(r)["id"]); err != nil {
+		s.logger.Debug("upload session: delete session failed", "error", err)
+		s.logger.Error(nil, "upload session: delete session failed")
+		writeUploadSessionError(w, err)
+		return
+	}
+	jsonhttp.NoContent(w)
+}
+
+// uploadSessionAppendHandler appends the data of the request to the upload
+// at the offset given in the request header, which must match the offset of
+// the data committed so far. The data read before the connection is dropped
+// is committed, so that the upload can be resumed from the returned offset.
+func (s *Service) uploadSessionAppendHandler(w http.ResponseWriter, r *http.Request) {
+	logger := tracing.NewLoggerWithTraceID(r.Context(), s.logger)
+
+	offset, err := strconv.ParseInt(r.Header.Get(SwarmUploadOffsetHeader), 10, 64)
+	if err != nil || offset < 0 {
+		logger.Debug("upload session append: parse offset failed", "string", r.Header.Get(SwarmUploadOffsetHeader), "error", err)
+		logger.Error(nil, "upload session append: parse offset failed")
+		jsonhttp.BadRequest(w, "invalid upload offset")
+		return
+	}
+
+	u, ok := s.openUploadSession(w, r, logger)
+	if !ok {
+		return
+	}
+	defer u.release()
+
+	if offset != u.session.Offset {
+		logger.Debug("upload session append: offset mismatch", "offset", offset, "session_offset", u.session.Offset)
+		logger.Error(nil, "upload session append: offset mismatch")
+		w.Header().Set(SwarmUploadOffsetHeader, strconv.FormatInt(u.session.Offset, 10))
+		w.Header().Set("Access-Control-Expose-Headers", SwarmUploadOffsetHeader)
+		jsonhttp.Conflict(w, "upload offset mismatch")
+		return
+	}
+
+	var (
+		data    = make([]byte, swarm.ChunkSize)
+		written int64
+		readErr error
+	)
+	for readErr == nil {
+		var n int
+		n, readErr = r.Body.Read(data)
+		if n == 0 {
+			continue
+		}
+		if _, err := u.pipe.Write(data[:n]); err != nil {
+			// the state of the pipeline is not consistent after a failed
+			// write, the data of the request is not committed at all
+			logger.Debug("upload session append: write failed", "error", err)
+			logger.Error(nil, "upload session append: write failed")
+			writePipelineError(w, err)
+			return
+		}
+		written += int64(n)
+	}
+	if errors.Is(readErr, io.EOF) {
+		readErr = nil
+	}
+
+	if written > 0 {
+		if err := u.wait(); err != nil {
+			logger.Debug("upload session append: sync chunks failed", "error", err)
+			logger.Error(nil, "upload session append: sync chunks failed")
+			jsonhttp.InternalServerError(w, "sync chunks failed")
+			return
+		}
+
+		state, err := u.pipe.State()
+		if err != nil {
+			logger.Debug("upload session append: pipeline state failed", "error", err)
+			logger.Error(nil, "upload session append: pipeline state failed")
+			jsonhttp.InternalServerError(w, "cannot save upload state")
+			return
+		}
+		u.session.Offset += written
+		u.session.State = state
+		if err := s.uploads.Commit(&u.session); err != nil {
+			logger.Debug("upload session append: commit session failed", "error", err)
+			logger.Error(nil, "upload session append: commit session failed")
+			jsonhttp.InternalServerError(w, "cannot save upload state")
+			return
+		}
+	}
+
+	w.Header().Set(SwarmUploadOffsetHeader, strconv.FormatInt(u.session.Offset, 10))
+	w.Header().Set("Access-Control-Expose-Headers", SwarmUploadOffsetHeader)
+
+	if readErr != nil {
+		logger.Debug("upload session append: read data failed", "offset", u.session.Offset, "error", readErr)
+		logger.Error(nil, "upload session append: read data failed")
+		if jsonhttp.HandleBodyReadError(readErr, w) {
+			return
+		}
+		jsonhttp.BadRequest(w, "cannot read data")
+		return
+	}
+
+	jsonhttp.OK(w, newUploadSessionResponse(u.session))
+}
+
+// uploadSessionBytesHandler finishes the upload and
+// returns the reference of the uploaded bytes.
+func (s *Service) uploadSessionBytesHandler(w http.ResponseWriter, r *http.Request) {
+	logger := tracing.NewLoggerWithTraceID(r.Context(), s.logger)
+
+	u, ok := s.openUploadSession(w, r, logger)
+	if !ok {
+		return
+	}
+	defer u.release()
+
+	reference, ok := s.sumUploadSession(w, u, logger)
+	if !ok {
+		return
+	}
+
+	if !s.finishUploadSession(w, r, u, reference, logger) {
+		return
+	}
+
+	w.Header().Set(SwarmTagHeader, fmt.Sprint(u.tag.Uid))
+	w.Header().Set("Access-Control-Expose-Headers", SwarmTagHeader)
+	jsonhttp.Created(w, bytesPostResponse{
+		Reference: reference,
+	})
+}
+
+// uploadSessionBzzHandler finishes the upload and returns the reference
+// of the manifest of the uploaded file, like the file uploads to bzz.
+func (s *Service) uploadSessionBzzHandler(w http.ResponseWriter, r *http.Request) {
+	logger := tracing.NewLoggerWithTraceID(r.Context(), s.logger)
+
+	u, ok := s.openUploadSession(w, r, logger)
+	if !ok {
+		return
+	}
+	defer u.release()
+
+	reference, ok := s.sumUploadSession(w, u, logger)
+	if !ok {
+		return
+	}
+
+	fileName := r.URL.Query().Get("name")
+	if fileName == "" {
+		fileName = reference.String()
+	}
+	contentType := r.Header.Get(contentTypeHeader)
+
+	factory := func() pipeline.Interface {
+		return builder.NewPipelineBuilder(u.ctx, u.putter, storage.ModePutUpload, u.session.Encrypt)
+	}
+	m, err := manifest.NewDefaultManifest(loadsave.New(u.putter, factory), u.session.Encrypt)
+	if err != nil {
+		logger.Debug("upload session bzz: create manifest failed", "file_name", fileName, "error", err)
+		logger.Error(nil, "upload session bzz: create manifest failed", "file_name", fileName)
+		jsonhttp.InternalServerError(w, "create manifest failed")
+		return
+	}
+
+	rootMetadata := map[string]string{
+		manifest.WebsiteIndexDocumentSuffixKey: fileName,
+	}
+	if err := m.Add(u.ctx, manifest.RootPath, manifest.NewEntry(swarm.ZeroAddress, rootMetadata)); err != nil {
+		logger.Debug("upload session bzz: adding metadata to manifest failed", "file_name", fileName, "error", err)
+		logger.Error(nil, "upload session bzz: adding metadata to manifest failed", "file_name", fileName)
+		jsonhttp.InternalServerError(w, "add metadata failed")
+		return
+	}
+
+	fileMtdt := map[string]string{
+		manifest.EntryMetadataContentTypeKey: contentType,
+		manifest.EntryMetadataFilenameKey:    fileName,
+	}
+	if err := m.Add(u.ctx, fileName, manifest.NewEntry(reference, fileMtdt)); err != nil {
+		logger.Debug("upload session bzz: adding file to manifest failed", "file_name", fileName, "error", err)
+		logger.Error(nil, "upload session bzz: adding file to manifest failed", "file_name", fileName)
+		jsonhttp.InternalServerError(w, "add file failed")
+		return
+	}
+
+	manifestReference, err := m.Store(u.ctx)
+	if err != nil {
+		logger.Debug("upload session bzz: manifest store failed", "file_name", fileName, "error", err)
+		logger.Error(nil, "upload session bzz: manifest store failed", "file_name", fileName)
+		writePipelineError(w, err)
+		return
+	}
+
+	if !s.finishUploadSession(w, r, u, manifestReference, logger) {
+		return
+	}
+
+	w.Header().Set("ETag", fmt.Sprintf("%q", manifestReference.String()))
+	w.Header().Set(SwarmTagHeader, fmt.Sprint(u.tag.Uid))
+	w.Header().Set("Access-Control-Expose-Headers", SwarmTagHeader)
+	jsonhttp.Created(w, bzzUploadResponse{
+		Reference: manifestReference,
+	})
+}
+
+// sessionUpload is the upload of a session acquired by a request.
+type sessionUpload struct {
+	session upload.Session
+	release func()
+	tag     *tags.Tag
+	ctx     context.Context
+	putter  storage.Storer
+	wait    func() error
+	pipe    pipeline.Resumable
+}
+
+// openUploadSession acquires the session of the request and restores its
+// pipeline. The error response is written if the session cannot be opened.
+func (s *Service) openUploadSession(w http.ResponseWriter, r *http.Request, logger log.Logger) (*sessionUpload, bool) {
+	session, release, err := s.uploads.Acquire(mux.Vars(r)["id"])
+	if err != nil {
+		logger.Debug("upload session: acquire session failed", "error", err)
+		logger.Error(nil, "upload session: acquire session failed")
+		writeUploadSessionError(w, err)
+		return nil, false
+	}
+
+	u := &sessionUpload{session: session, release: release}
+	ok := false
+	defer func() {
+		if !ok {
+			release()
+		}
+	}()
+
+	u.tag, err = s.tags.Get(session.TagID)
+	if err != nil {
+		logger.Debug("upload session: get tag failed", "tag_id", session.TagID, "error", err)
+		logger.Error(nil, "upload session: get tag failed")
+		if errors.Is(err, tags.ErrNotFound) {
+			jsonhttp.NotFound(w, "tag not present")
+			return nil, false
+		}
+		jsonhttp.InternalServerError(w, "cannot get tag")
+		return nil, false
+	}
+
+	u.putter, u.wait, err = s.newBatchPutter(session.BatchID, session.Deferred)
+	if err != nil {
+		logger.Debug("upload session: get putter failed", "error", err)
+		logger.Error(nil, "upload session: get putter failed")
+		writeBatchPutterError(w, err)
+		return nil, false
+	}
+
+	// the chunks are put independently of the request context in order
+	// not to leave the pipeline in an inconsistent state when the client
+	// disconnects in the middle of a write
+	u.ctx = sctx.SetTag(context.Background(), u.tag)
+	u.pipe, err = builder.NewResumablePipelineBuilder(u.ctx, u.putter, storage.ModePutUpload, session.Encrypt, session.State)
+	if err != nil {
+		logger.Debug("upload session: restore pipeline failed", "error", err)
+		logger.Error(nil, "upload session: restore pipeline failed")
+		jsonhttp.InternalServerError(w, "cannot restore upload state")
+		return nil, false
+	}
+
+	ok = true
+	return u, true
+}
+
+// sumUploadSession returns the reference of the data uploaded in the session.
+func (s *Service) sumUploadSession(w http.ResponseWriter, u *sessionUpload, logger log.Logger) (swarm.Address, bool) {
+	sum, err := u.pipe.Sum()
+	if err != nil {
+		logger.Debug("upload session: pipeline sum failed", "error", err)
+		logger.Error(nil, "upload session: pipeline sum failed")
+		writePipelineError(w, err)
+		return swarm.ZeroAddress, false
+	}
+	return swarm.NewAddress(sum), true
+}
+
+// finishUploadSession syncs the chunks of the finished upload, pins the
+// reference if requested and removes the session.
+func (s *Service) finishUploadSession(w http.ResponseWriter, r *http.Request, u *sessionUpload, reference swarm.Address, logger log.Logger) bool {
+	if err := u.wait(); err != nil {
+		logger.Debug("upload session: sync chunks failed", "error", err)
+		logger.Error(nil, "upload session: sync chunks failed")
+		jsonhttp.InternalServerError(w, "sync chunks failed")
+		return false
+	}
+
+	if u.session.TagCreated {
+		if _, err := u.tag.DoneSplit(reference); err != nil {
+			logger.Debug("upload session: done split failed", "error", err)
+			logger.Error(nil, "upload session: done split failed")
+			jsonhttp.InternalServerError(w, "done split failed")
+			return false
+		}
+	}
+
+	if strings.ToLower(r.Header.Get(SwarmPinHeader)) == "true" {
+		if err := s.pinning.CreatePin(u.ctx, reference, false); err != nil {
+			logger.Debug("upload session: pin creation failed", "address", reference, "error", err)
+			logger.Error(nil, "upload session: pin creation failed")
+			jsonhttp.InternalServerError(w, "create pin failed")
+			return false
+		}
+	}
+
+	if err := s.uploads.Finish(u.session.ID); err != nil {
+		logger.Debug("upload session: finish session failed", "error", err)
+		logger.Error(nil, "upload session: finish session failed")
+		jsonhttp.InternalServerError(w, "cannot finish upload session")
+		return false
+	}
+	return true
+}
+
+func writeUploadSessionError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, upload.ErrNotFound):
+		jsonhttp.NotFound(w, "upload session not found")
+	case errors.Is(err, upload.ErrBusy):
+		jsonhttp.Conflict(w, "upload session busy")
+	default:
+		jsonhttp.InternalServerError(w, "upload session failed")
+	}
+}
+
+func writeBatchPutterError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, postage.ErrNotFound):
+		jsonhttp.BadRequest(w, "batch not found")
+	case errors.Is(err, errBatchUnusable), errors.Is(err, postage.ErrNotUsable):
+		jsonhttp.BadRequest(w, "batch not usable")
+	default:
+		jsonhttp.InternalServerError(w, "cannot get putter")
+	}
+}
+
+func writePipelineError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, postage.ErrBucketFull):
+		jsonhttp.PaymentRequired(w, "batch is overissued")
+	default:
+		jsonhttp.InternalServerError(w, "split write failed")
+	}
+}
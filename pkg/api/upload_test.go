@@ -0,0 +1,309 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/log"
+	pinning "github.com/ethersphere/bee/pkg/pinning/mock"
+	mockpost "github.com/ethersphere/bee/pkg/postage/mock"
+	statestore "github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/storage/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/tags"
+	"github.com/ethersphere/bee/pkg/upload"
+	"gitlab.com/nolash/go-mockbytes"
+)
+
+// TestUploadSession tests that the data uploaded in parts, also across
+// the restarts of the node, has the same reference as the data uploaded
+// at once.
+func TestUploadSession(t *testing.T) {
+	const (
+		resource = "/uploads"
+		expHash  = "29a5fb121ce96194ba8b7b823a1f9c6af87e1791f824940a53b5a7efe3f790d9"
+	)
+
+	var (
+		storerMock  = mock.NewStorer()
+		stateStore  = statestore.NewStateStore()
+		tag         = tags.NewTags(stateStore, log.Noop)
+		pinningMock = pinning.NewServiceMock()
+		// newServer starts the node which loads the
+		// upload sessions from the state store
+		newServer = func() *http.Client {
+			uploads := upload.New(stateStore, log.Noop, upload.Options{})
+			t.Cleanup(func() { _ = uploads.Close() })
+			client, _, _, _ := newTestServer(t, testServerOptions{
+				Storer:  storerMock,
+				Tags:    tag,
+				Pinning: pinningMock,
+				Post:    mockpost.New(mockpost.WithAcceptAll()),
+				Uploads: uploads,
+			})
+			return client
+		}
+		client  = newServer()
+		session api.UploadSessionResponse
+	)
+
+	content, err := mockbytes.New(0, mockbytes.MockTypeStandard).WithModulus(255).SequentialBytes(swarm.ChunkSize * 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	split := swarm.ChunkSize + 100
+
+	t.Run("create", func(t *testing.T) {
+		header := jsonhttptest.Request(t, client, http.MethodPost, resource, http.StatusCreated,
+			jsonhttptest.WithRequestHeader(api.SwarmPostageBatchIdHeader, batchOkStr),
+			jsonhttptest.WithUnmarshalJSONResponse(&session),
+		)
+		if session.ID == "" || session.Offset != 0 {
+			t.Fatalf("unexpected session %+v", session)
+		}
+		if got := header.Get(api.SwarmTagHeader); got != strconv.FormatUint(uint64(session.Tag), 10) {
+			t.Fatalf("got tag header %q, want %d", got, session.Tag)
+		}
+		if got := header.Get(api.SwarmUploadOffsetHeader); got != "0" {
+			t.Fatalf("got offset header %q, want %q", got, "0")
+		}
+	})
+
+	t.Run("create invalid batch", func(t *testing.T) {
+		jsonhttptest.Request(t, client, http.MethodPost, resource, http.StatusBadRequest,
+			jsonhttptest.WithRequestHeader(api.SwarmPostageBatchIdHeader, "abcd"),
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Message: "invalid postage batch id",
+				Code:    http.StatusBadRequest,
+			}),
+		)
+	})
+
+	t.Run("append", func(t *testing.T) {
+		var res api.UploadSessionResponse
+		header := jsonhttptest.Request(t, client, http.MethodPatch, resource+"/"+session.ID, http.StatusOK,
+			jsonhttptest.WithRequestHeader(api.SwarmUploadOffsetHeader, "0"),
+			jsonhttptest.WithRequestBody(bytes.NewReader(content[:split])),
+			jsonhttptest.WithUnmarshalJSONResponse(&res),
+		)
+		if res.Offset != int64(split) {
+			t.Fatalf("got offset %d, want %d", res.Offset, split)
+		}
+		if got := header.Get(api.SwarmUploadOffsetHeader); got != strconv.Itoa(split) {
+			t.Fatalf("got offset header %q, want %d", got, split)
+		}
+	})
+
+	t.Run("append offset mismatch", func(t *testing.T) {
+		header := jsonhttptest.Request(t, client, http.MethodPatch, resource+"/"+session.ID, http.StatusConflict,
+			jsonhttptest.WithRequestHeader(api.SwarmUploadOffsetHeader, "0"),
+			jsonhttptest.WithRequestBody(bytes.NewReader(content[split:])),
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Message: "upload offset mismatch",
+				Code:    http.StatusConflict,
+			}),
+		)
+		if got := header.Get(api.SwarmUploadOffsetHeader); got != strconv.Itoa(split) {
+			t.Fatalf("got offset header %q, want %d", got, split)
+		}
+	})
+
+	t.Run("append invalid offset", func(t *testing.T) {
+		jsonhttptest.Request(t, client, http.MethodPatch, resource+"/"+session.ID, http.StatusBadRequest,
+			jsonhttptest.WithRequestHeader(api.SwarmUploadOffsetHeader, "-1"),
+			jsonhttptest.WithRequestBody(bytes.NewReader(content[split:])),
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Message: "invalid upload offset",
+				Code:    http.StatusBadRequest,
+			}),
+		)
+	})
+
+	t.Run("resume after restart", func(t *testing.T) {
+		client = newServer()
+
+		var res api.UploadSessionResponse
+		jsonhttptest.Request(t, client, http.MethodGet, resource+"/"+session.ID, http.StatusOK,
+			jsonhttptest.WithUnmarshalJSONResponse(&res),
+		)
+		if res.Offset != int64(split) {
+			t.Fatalf("got offset %d, want %d", res.Offset, split)
+		}
+
+		jsonhttptest.Request(t, client, http.MethodPatch, resource+"/"+session.ID, http.StatusOK,
+			jsonhttptest.WithRequestHeader(api.SwarmUploadOffsetHeader, strconv.FormatInt(res.Offset, 10)),
+			jsonhttptest.WithRequestBody(bytes.NewReader(content[res.Offset:])),
+		)
+	})
+
+	t.Run("finish", func(t *testing.T) {
+		jsonhttptest.Request(t, client, http.MethodPost, resource+"/"+session.ID+"/bytes", http.StatusCreated,
+			jsonhttptest.WithRequestHeader(api.SwarmPinHeader, "true"),
+			jsonhttptest.WithExpectedJSONResponse(api.BytesPostResponse{
+				Reference: swarm.MustParseHexAddress(expHash),
+			}),
+		)
+
+		refs, err := pinningMock.Pins()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(refs) != 1 || refs[0].String() != expHash {
+			t.Fatalf("unexpected pins %v", refs)
+		}
+
+		jsonhttptest.Request(t, client, http.MethodGet, "/bytes/"+expHash, http.StatusOK,
+			jsonhttptest.WithExpectedResponse(content),
+		)
+		jsonhttptest.Request(t, client, http.MethodGet, resource+"/"+session.ID, http.StatusNotFound,
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Message: "upload session not found",
+				Code:    http.StatusNotFound,
+			}),
+		)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		var res api.UploadSessionResponse
+		jsonhttptest.Request(t, client, http.MethodPost, resource, http.StatusCreated,
+			jsonhttptest.WithRequestHeader(api.SwarmPostageBatchIdHeader, batchOkStr),
+			jsonhttptest.WithUnmarshalJSONResponse(&res),
+		)
+		jsonhttptest.Request(t, client, http.MethodGet, resource, http.StatusOK,
+			jsonhttptest.WithExpectedJSONResponse(api.UploadSessionsResponse{
+				Sessions: []api.UploadSessionResponse{res},
+			}),
+		)
+		jsonhttptest.Request(t, client, http.MethodDelete, resource+"/"+res.ID, http.StatusNoContent)
+		jsonhttptest.Request(t, client, http.MethodDelete, resource+"/"+res.ID, http.StatusNotFound)
+		jsonhttptest.Request(t, client, http.MethodGet, resource, http.StatusOK,
+			jsonhttptest.WithExpectedJSONResponse(api.UploadSessionsResponse{
+				Sessions: []api.UploadSessionResponse{},
+			}),
+		)
+	})
+}
+
+// TestUploadSessionDropped tests that the upload is continued
+// from the offset committed before the connection was dropped.
+func TestUploadSessionDropped(t *testing.T) {
+	const expHash = "29a5fb121ce96194ba8b7b823a1f9c6af87e1791f824940a53b5a7efe3f790d9"
+
+	client, _, _, _ := newTestServer(t, testServerOptions{
+		Storer: mock.NewStorer(),
+		Tags:   tags.NewTags(statestore.NewStateStore(), log.Noop),
+		Post:   mockpost.New(mockpost.WithAcceptAll()),
+	})
+
+	content, err := mockbytes.New(0, mockbytes.MockTypeStandard).WithModulus(255).SequentialBytes(swarm.ChunkSize * 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var session api.UploadSessionResponse
+	jsonhttptest.Request(t, client, http.MethodPost, "/uploads", http.StatusCreated,
+		jsonhttptest.WithRequestHeader(api.SwarmPostageBatchIdHeader, batchOkStr),
+		jsonhttptest.WithUnmarshalJSONResponse(&session),
+	)
+
+	body := io.MultiReader(bytes.NewReader(content[:swarm.ChunkSize+100]), errorReader{})
+	req, err := http.NewRequest(http.MethodPatch, "/uploads/"+session.ID, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set(api.SwarmUploadOffsetHeader, "0")
+	if _, err := client.Do(req); err == nil {
+		t.Fatal("expected the request to fail")
+	}
+
+	// the session is busy until the dropped request is handled
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		var res api.UploadSessionResponse
+		jsonhttptest.Request(t, client, http.MethodGet, "/uploads/"+session.ID, http.StatusOK,
+			jsonhttptest.WithUnmarshalJSONResponse(&res),
+		)
+		if res.Offset > int64(len(content)) {
+			t.Fatalf("got offset %d, want at most %d", res.Offset, len(content))
+		}
+
+		req, err := http.NewRequest(http.MethodPatch, "/uploads/"+session.ID, bytes.NewReader(content[res.Offset:]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set(api.SwarmUploadOffsetHeader, strconv.FormatInt(res.Offset, 10))
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode == http.StatusOK {
+			break
+		}
+		if resp.StatusCode != http.StatusConflict || time.Now().After(deadline) {
+			t.Fatalf("got status %d, want %d", resp.StatusCode, http.StatusOK)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	jsonhttptest.Request(t, client, http.MethodPost, "/uploads/"+session.ID+"/bytes", http.StatusCreated,
+		jsonhttptest.WithExpectedJSONResponse(api.BytesPostResponse{
+			Reference: swarm.MustParseHexAddress(expHash),
+		}),
+	)
+}
+
+// TestUploadSessionBzz tests that the finished upload
+// is served as a file with the name and the content type.
+func TestUploadSessionBzz(t *testing.T) {
+	var (
+		fileName        = "my-pictures.jpeg"
+		contentType     = "image/jpeg"
+		content         = []byte("some data of a picture")
+		client, _, _, _ = newTestServer(t, testServerOptions{
+			Storer: mock.NewStorer(),
+			Tags:   tags.NewTags(statestore.NewStateStore(), log.Noop),
+			Post:   mockpost.New(mockpost.WithAcceptAll()),
+		})
+		session api.UploadSessionResponse
+		res     api.BzzUploadResponse
+	)
+
+	jsonhttptest.Request(t, client, http.MethodPost, "/uploads", http.StatusCreated,
+		jsonhttptest.WithRequestHeader(api.SwarmPostageBatchIdHeader, batchOkStr),
+		jsonhttptest.WithUnmarshalJSONResponse(&session),
+	)
+	jsonhttptest.Request(t, client, http.MethodPatch, "/uploads/"+session.ID, http.StatusOK,
+		jsonhttptest.WithRequestHeader(api.SwarmUploadOffsetHeader, "0"),
+		jsonhttptest.WithRequestBody(bytes.NewReader(content)),
+	)
+	jsonhttptest.Request(t, client, http.MethodPost, "/uploads/"+session.ID+"/bzz?name="+fileName, http.StatusCreated,
+		jsonhttptest.WithRequestHeader("Content-Type", contentType),
+		jsonhttptest.WithUnmarshalJSONResponse(&res),
+	)
+
+	header := jsonhttptest.Request(t, client, http.MethodGet, "/bzz/"+res.Reference.String()+"/", http.StatusOK,
+		jsonhttptest.WithExpectedResponse(content),
+	)
+	if got := header.Get("Content-Type"); got != contentType {
+		t.Fatalf("got content type %q, want %q", got, contentType)
+	}
+}
+
+type errorReader struct{}
+
+func (errorReader) Read([]byte) (int, error) {
+	return 0, errors.New("connection dropped")
+}
@@ -237,6 +237,9 @@ func applyPolicies(e *casbin.Enforcer) error {
 	_, err := e.AddPolicies([][]string{
 		{"consumer", "/bytes/*", "GET"},
 		{"creator", "/bytes", "POST"},
+		{"creator", "/uploads", "(GET)|(POST)"},
+		{"creator", "/uploads/*", "(GET)|(PATCH)|(DELETE)"},
+		{"creator", "/uploads/*/*", "POST"},
 		{"consumer", "/chunks/*", "GET"},
 		{"creator", "/chunks", "POST"},
 		{"consumer", "/bzz/*", "GET"},
@@ -260,6 +263,7 @@ func applyPolicies(e *casbin.Enforcer) error {
 		{"maintainer", "/stamps/*/*", "POST"},
 		{"maintainer", "/stamps/topup/*/*", "PATCH"},
 		{"maintainer", "/stamps/dilute/*/*", "PATCH"},
+		{"maintainer", "/stamps/*/policy", "(PUT)|(DELETE)"},
 		{"maintainer", "/addresses", "GET"},
 		{"maintainer", "/blocklist", "GET"},
 		{"maintainer", "/connect/*", "POST"},
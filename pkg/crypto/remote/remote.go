@@ -0,0 +1,341 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package remote provides a signer which delegates the signing to a remote
+// HTTP signing service, so that the private key never leaves that service.
+//
+// The service is expected to expose the following JSON endpoints:
+//
+//	GET  /accounts         -> {"accounts": ["0x..."]}
+//	POST /sign             {"address", "data"} -> {"signature"}
+//	POST /sign-tx          {"address", "chainId", "transaction"} -> {"transaction"}
+//	POST /sign-typed-data  {"address", "typedData"} -> {"signature"}
+//
+// Data is signed with the standard Ethereum prefix and transactions are
+// exchanged in their binary encoding, all as 0x prefixed hex strings.
+// Failed requests should be answered with a non 2xx status code and an
+// optional {"message"} body.
+package remote
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/crypto/eip712"
+)
+
+var (
+	ErrNoAccounts          = errors.New("no accounts found in remote signer")
+	ErrAccountNotAvailable = errors.New("account not available in remote signer")
+	remoteRecoveryMessage  = []byte("public key recovery message")
+)
+
+// DefaultTimeout is the request timeout used if none is configured.
+const DefaultTimeout = 30 * time.Second
+
+// maxResponseSize limits the size of the responses read from the service.
+const maxResponseSize = 1 << 20
+
+// Options configures the connection to the remote signing service.
+type Options struct {
+	// Endpoint is the base URL of the signing service.
+	Endpoint string
+	// EthereumAddress selects the account to use. If nil, the first
+	// account of the service is used.
+	EthereumAddress *common.Address
+	// Timeout is the timeout of a single request.
+	Timeout time.Duration
+	// TLSCertFile and TLSKeyFile are the paths of the PEM encoded client
+	// certificate and key presented to the service.
+	TLSCertFile string
+	TLSKeyFile  string
+	// TLSCAFile is the path of the PEM encoded certificates used to verify
+	// the service, in addition to the system ones.
+	TLSCAFile string
+}
+
+type remoteSigner struct {
+	client   *http.Client
+	endpoint string
+	address  common.Address   // the account this signer will use
+	pubKey   *ecdsa.PublicKey // the public key for the account
+}
+
+type accountsResponse struct {
+	Accounts []common.Address `json:"accounts"`
+}
+
+type signRequest struct {
+	Address common.Address `json:"address"`
+	Data    hexutil.Bytes  `json:"data"`
+}
+
+type signTxRequest struct {
+	Address     common.Address `json:"address"`
+	ChainID     *hexutil.Big   `json:"chainId"`
+	Transaction hexutil.Bytes  `json:"transaction"`
+}
+
+type signTypedDataRequest struct {
+	Address   common.Address    `json:"address"`
+	TypedData *eip712.TypedData `json:"typedData"`
+}
+
+type signatureResponse struct {
+	Signature hexutil.Bytes `json:"signature"`
+}
+
+type transactionResponse struct {
+	Transaction hexutil.Bytes `json:"transaction"`
+}
+
+type errorResponse struct {
+	Message string `json:"message"`
+}
+
+// NewSigner creates a new signer backed by the signing service at the
+// configured endpoint. It verifies that the requested account is available
+// and, as the service does not expose public keys, signs a test message to
+// recover the public key of the account.
+func NewSigner(o Options, recoverFunc crypto.RecoverFunc) (crypto.Signer, error) {
+	if o.Endpoint == "" {
+		return nil, errors.New("remote signer endpoint not provided")
+	}
+
+	client, err := newHTTPClient(o)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &remoteSigner{
+		client:   client,
+		endpoint: strings.TrimSuffix(o.Endpoint, "/"),
+	}
+
+	s.address, err = s.selectAccount(o.EthereumAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	sig, err := s.Sign(remoteRecoveryMessage)
+	if err != nil {
+		return nil, err
+	}
+
+	s.pubKey, err = recoverFunc(sig, remoteRecoveryMessage)
+	if err != nil {
+		return nil, err
+	}
+
+	address, err := crypto.NewEthereumAddress(*s.pubKey)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(address, s.address.Bytes()) {
+		return nil, fmt.Errorf("misconfigured signer: signature recovered to address %x; wanted %s", address, s.address)
+	}
+
+	return s, nil
+}
+
+func newHTTPClient(o Options) (*http.Client, error) {
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if o.TLSCertFile != "" || o.TLSKeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(o.TLSCertFile, o.TLSKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if o.TLSCAFile != "" {
+		pem, err := os.ReadFile(o.TLSCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read ca certificate: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.New("no ca certificates found")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	timeout := o.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   timeout,
+	}, nil
+}
+
+func (s *remoteSigner) selectAccount(ethAddress *common.Address) (common.Address, error) {
+	var res accountsResponse
+	if err := s.do(http.MethodGet, "/accounts", nil, &res); err != nil {
+		return common.Address{}, err
+	}
+
+	if len(res.Accounts) == 0 {
+		return common.Address{}, ErrNoAccounts
+	}
+
+	if ethAddress == nil {
+		// pick the first account as the one we use
+		return res.Accounts[0], nil
+	}
+
+	for _, address := range res.Accounts {
+		if address == *ethAddress {
+			return address, nil
+		}
+	}
+	return common.Address{}, ErrAccountNotAvailable
+}
+
+// do sends the request to the path of the signing service
+// and decodes the json response into res.
+func (s *remoteSigner) do(method, path string, req, res interface{}) error {
+	var body io.Reader
+	if req != nil {
+		b, err := json.Marshal(req)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(b)
+	}
+
+	r, err := http.NewRequest(method, s.endpoint+path, body)
+	if err != nil {
+		return err
+	}
+	if req != nil {
+		r.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := s.client.Do(r)
+	if err != nil {
+		return fmt.Errorf("remote signer: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return fmt.Errorf("remote signer: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var e errorResponse
+		if err := json.Unmarshal(data, &e); err == nil && e.Message != "" {
+			return fmt.Errorf("remote signer: %s: %s", resp.Status, e.Message)
+		}
+		return fmt.Errorf("remote signer: %s", resp.Status)
+	}
+
+	if err := json.Unmarshal(data, res); err != nil {
+		return fmt.Errorf("remote signer: decode response: %w", err)
+	}
+	return nil
+}
+
+// PublicKey returns the public key recovered during creation.
+func (s *remoteSigner) PublicKey() (*ecdsa.PublicKey, error) {
+	return s.pubKey, nil
+}
+
+// Sign signs data with the standard Ethereum prefix method.
+func (s *remoteSigner) Sign(data []byte) ([]byte, error) {
+	var res signatureResponse
+	err := s.do(http.MethodPost, "/sign", signRequest{
+		Address: s.address,
+		Data:    data,
+	}, &res)
+	if err != nil {
+		return nil, err
+	}
+
+	return res.Signature, nil
+}
+
+// SignTx signs an ethereum transaction.
+func (s *remoteSigner) SignTx(transaction *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	b, err := transaction.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	var res transactionResponse
+	err = s.do(http.MethodPost, "/sign-tx", signTxRequest{
+		Address:     s.address,
+		ChainID:     (*hexutil.Big)(chainID),
+		Transaction: b,
+	}, &res)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(res.Transaction); err != nil {
+		return nil, fmt.Errorf("remote signer: decode transaction: %w", err)
+	}
+
+	// make sure the service signed the transaction we asked for
+	txSigner := types.NewLondonSigner(chainID)
+	if txSigner.Hash(tx) != txSigner.Hash(transaction) {
+		return nil, errors.New("misconfigured signer: signed transaction differs from the requested one")
+	}
+	sender, err := types.Sender(txSigner, tx)
+	if err != nil {
+		return nil, fmt.Errorf("remote signer: recover sender: %w", err)
+	}
+	if sender != s.address {
+		return nil, fmt.Errorf("misconfigured signer: transaction signed by %s; wanted %s", sender, s.address)
+	}
+
+	return tx, nil
+}
+
+// EthereumAddress returns the ethereum address this signer uses.
+func (s *remoteSigner) EthereumAddress() (common.Address, error) {
+	return s.address, nil
+}
+
+// SignTypedData signs data according to eip712.
+func (s *remoteSigner) SignTypedData(typedData *eip712.TypedData) ([]byte, error) {
+	var res signatureResponse
+	err := s.do(http.MethodPost, "/sign-typed-data", signTypedDataRequest{
+		Address:   s.address,
+		TypedData: typedData,
+	}, &res)
+	if err != nil {
+		return nil, err
+	}
+
+	return res.Signature, nil
+}
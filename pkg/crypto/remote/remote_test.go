@@ -0,0 +1,399 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package remote_test
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/crypto/eip712"
+	"github.com/ethersphere/bee/pkg/crypto/remote"
+)
+
+var testTypedData = &eip712.TypedData{
+	Domain: eip712.TypedDataDomain{
+		Name:    "test",
+		Version: "1.0",
+	},
+	Types: eip712.Types{
+		"EIP712Domain": {
+			{
+				Name: "name",
+				Type: "string",
+			},
+			{
+				Name: "version",
+				Type: "string",
+			},
+		},
+		"Cheque": {
+			{
+				Name: "beneficiary",
+				Type: "address",
+			},
+			{
+				Name: "cumulativePayout",
+				Type: "uint256",
+			},
+		},
+	},
+	Message: eip712.TypedDataMessage{
+		"beneficiary":      "0x31415b599f636129ad03c196cef9f8f8b184d5c7",
+		"cumulativePayout": "1000",
+	},
+	PrimaryType: "Cheque",
+}
+
+// signingService is a stand-in for the remote signing service
+// which signs with the default signer of its key.
+type signingService struct {
+	signer crypto.Signer
+	// tamper makes the service sign a transaction different from the requested one.
+	tamper bool
+	delay  time.Duration
+}
+
+func newSigningService(t *testing.T) *signingService {
+	t.Helper()
+
+	key, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &signingService{signer: crypto.NewDefaultSigner(key)}
+}
+
+func (s *signingService) address(t *testing.T) common.Address {
+	t.Helper()
+
+	address, err := s.signer.EthereumAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return address
+}
+
+func (s *signingService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	time.Sleep(s.delay)
+
+	address, _ := s.signer.EthereumAddress()
+	var req struct {
+		Address     common.Address    `json:"address"`
+		Data        hexutil.Bytes     `json:"data"`
+		ChainID     *hexutil.Big      `json:"chainId"`
+		Transaction hexutil.Bytes     `json:"transaction"`
+		TypedData   *eip712.TypedData `json:"typedData"`
+	}
+	if r.Method == http.MethodPost {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		if req.Address != address {
+			writeError(w, http.StatusNotFound, errors.New("unknown account"))
+			return
+		}
+	}
+
+	var (
+		res interface{}
+		err error
+	)
+	switch r.URL.Path {
+	case "/accounts":
+		res = map[string]interface{}{"accounts": []common.Address{address}}
+	case "/sign":
+		var sig []byte
+		sig, err = s.signer.Sign(req.Data)
+		res = map[string]interface{}{"signature": hexutil.Bytes(sig)}
+	case "/sign-tx":
+		tx := new(types.Transaction)
+		if err = tx.UnmarshalBinary(req.Transaction); err != nil {
+			break
+		}
+		if s.tamper {
+			tx = types.NewTx(&types.LegacyTx{Nonce: tx.Nonce() + 1, GasPrice: tx.GasPrice(), Gas: tx.Gas(), To: tx.To()})
+		}
+		if tx, err = s.signer.SignTx(tx, req.ChainID.ToInt()); err != nil {
+			break
+		}
+		var b []byte
+		b, err = tx.MarshalBinary()
+		res = map[string]interface{}{"transaction": hexutil.Bytes(b)}
+	case "/sign-typed-data":
+		var sig []byte
+		sig, err = s.signer.SignTypedData(req.TypedData)
+		res = map[string]interface{}{"signature": hexutil.Bytes(sig)}
+	default:
+		writeError(w, http.StatusNotFound, errors.New("not found"))
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(res)
+}
+
+func writeError(w http.ResponseWriter, code int, err error) {
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(map[string]string{"message": err.Error()})
+}
+
+// newServer starts a TLS server for the service which requires
+// the client certificate and returns the signer options to connect to it.
+func newServer(t *testing.T, s *signingService) remote.Options {
+	t.Helper()
+
+	dir := t.TempDir()
+	certFile, keyFile, clientCert := writeCertificate(t, dir)
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCert)
+
+	server := httptest.NewUnstartedServer(s)
+	server.TLS = &tls.Config{
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  clientCAs,
+	}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	caFile := filepath.Join(dir, "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caFile, caPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	return remote.Options{
+		Endpoint:    server.URL,
+		Timeout:     5 * time.Second,
+		TLSCertFile: certFile,
+		TLSKeyFile:  keyFile,
+		TLSCAFile:   caFile,
+	}
+}
+
+// writeCertificate writes a self-signed client certificate and its key into dir.
+func writeCertificate(t *testing.T, dir string) (certFile, keyFile string, cert *x509.Certificate) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "bee"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err = x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certFile = filepath.Join(dir, "client.pem")
+	keyFile = filepath.Join(dir, "client-key.pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile, cert
+}
+
+func TestNewSigner(t *testing.T) {
+	service := newSigningService(t)
+	o := newServer(t, service)
+
+	signer, err := remote.NewSigner(o, crypto.Recover)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	address, err := signer.EthereumAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := service.address(t); address != want {
+		t.Fatalf("wrong address. wanted %x, got %x", want, address)
+	}
+
+	publicKey, err := signer.PublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantKey, err := service.signer.PublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !publicKey.Equal(wantKey) {
+		t.Fatal("wrong public key")
+	}
+
+	t.Run("account not available", func(t *testing.T) {
+		o := o
+		o.EthereumAddress = &common.Address{1}
+		_, err := remote.NewSigner(o, crypto.Recover)
+		if !errors.Is(err, remote.ErrAccountNotAvailable) {
+			t.Fatalf("expected error %v, got %v", remote.ErrAccountNotAvailable, err)
+		}
+	})
+
+	t.Run("no client certificate", func(t *testing.T) {
+		o := o
+		o.TLSCertFile, o.TLSKeyFile = "", ""
+		if _, err := remote.NewSigner(o, crypto.Recover); err == nil {
+			t.Fatal("expected error")
+		}
+	})
+
+	t.Run("untrusted service", func(t *testing.T) {
+		o := o
+		o.TLSCAFile = ""
+		if _, err := remote.NewSigner(o, crypto.Recover); err == nil {
+			t.Fatal("expected error")
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		service := newSigningService(t)
+		service.delay = time.Second
+		o := newServer(t, service)
+		o.Timeout = 100 * time.Millisecond
+		if _, err := remote.NewSigner(o, crypto.Recover); err == nil {
+			t.Fatal("expected error")
+		}
+	})
+}
+
+func TestSign(t *testing.T) {
+	service := newSigningService(t)
+	signer, err := remote.NewSigner(newServer(t, service), crypto.Recover)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testData := []byte("hello world")
+	signature, err := signer.Sign(testData)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	publicKey, err := crypto.Recover(signature, testData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantKey, err := service.signer.PublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !publicKey.Equal(wantKey) {
+		t.Fatal("signature recovered to the wrong public key")
+	}
+}
+
+func TestSignTx(t *testing.T) {
+	chainID := big.NewInt(10)
+	to := common.HexToAddress("0x31415b599f636129ad03c196cef9f8f8b184d5c7")
+	tx := types.NewTx(&types.DynamicFeeTx{
+		ChainID:   chainID,
+		Nonce:     1,
+		GasTipCap: big.NewInt(2),
+		GasFeeCap: big.NewInt(20),
+		Gas:       21000,
+		To:        &to,
+		Value:     big.NewInt(1),
+	})
+
+	service := newSigningService(t)
+	signer, err := remote.NewSigner(newServer(t, service), crypto.Recover)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signedTx, err := signer.SignTx(tx, chainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sender, err := types.Sender(types.NewLondonSigner(chainID), signedTx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := service.address(t); sender != want {
+		t.Fatalf("wrong sender. wanted %x, got %x", want, sender)
+	}
+	if signedTx.Nonce() != tx.Nonce() || signedTx.GasFeeCap().Cmp(tx.GasFeeCap()) != 0 {
+		t.Fatal("signed transaction differs from the requested one")
+	}
+
+	t.Run("tampered", func(t *testing.T) {
+		service := newSigningService(t)
+		service.tamper = true
+		signer, err := remote.NewSigner(newServer(t, service), crypto.Recover)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := signer.SignTx(tx, chainID); err == nil {
+			t.Fatal("expected error")
+		}
+	})
+}
+
+func TestSignTypedData(t *testing.T) {
+	service := newSigningService(t)
+	signer, err := remote.NewSigner(newServer(t, service), crypto.Recover)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sig, err := signer.SignTypedData(testTypedData)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected, err := service.signer.SignTypedData(testTypedData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(expected, sig) {
+		t.Fatalf("wrong signature. expected %x, got %x", expected, sig)
+	}
+}
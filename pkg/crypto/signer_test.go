@@ -134,6 +134,46 @@ func TestDefaultSignerSignTx(t *testing.T) {
 	}
 }
 
+func TestDefaultSignerSignDynamicFeeTx(t *testing.T) {
+	privKey, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signer := crypto.NewDefaultSigner(privKey)
+	beneficiary := common.HexToAddress("8d3766440f0d7b949a5e32995d09619a7f86e632")
+
+	chainID := big.NewInt(10)
+
+	tx, err := signer.SignTx(types.NewTx(&types.DynamicFeeTx{
+		ChainID:   chainID,
+		Nonce:     0,
+		GasTipCap: big.NewInt(1),
+		GasFeeCap: big.NewInt(2),
+		Gas:       21000,
+		To:        &beneficiary,
+		Value:     big.NewInt(0),
+		Data:      []byte{1},
+	}), chainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sender, err := types.Sender(types.NewLondonSigner(chainID), tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected, err := signer.EthereumAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if sender != expected {
+		t.Fatalf("wrong sender. expected %x, got %x", expected, sender)
+	}
+}
+
 var testTypedData = &eip712.TypedData{
 	Domain: eip712.TypedDataDomain{
 		Name:    "test",
@@ -6,6 +6,9 @@ package builder
 
 import (
 	"context"
+	"encoding"
+	"encoding/binary"
+	"errors"
 	"fmt"
 	"io"
 
@@ -20,6 +23,8 @@ import (
 	"github.com/ethersphere/bee/pkg/swarm"
 )
 
+var errInvalidState = errors.New("invalid pipeline state")
+
 // NewPipelineBuilder returns the appropriate pipeline according to the specified parameters
 func NewPipelineBuilder(ctx context.Context, s storage.Putter, mode storage.ModePut, encrypt bool) pipeline.Interface {
 	if encrypt {
@@ -28,14 +33,91 @@ func NewPipelineBuilder(ctx context.Context, s storage.Putter, mode storage.Mode
 	return newPipeline(ctx, s, mode)
 }
 
+// NewResumablePipelineBuilder returns the pipeline like NewPipelineBuilder, but with
+// the state which can be saved in order to resume the writes later. The state
+// previously returned by the pipeline is restored, while the nil state starts
+// a new pipeline. The state must be restored with the same encrypt parameter.
+func NewResumablePipelineBuilder(ctx context.Context, s storage.Putter, mode storage.ModePut, encrypt bool, state []byte) (pipeline.Resumable, error) {
+	var p *resumablePipeline
+	if encrypt {
+		p = newEncryptionPipeline(ctx, s, mode)
+	} else {
+		p = newPipeline(ctx, s, mode)
+	}
+	if state == nil {
+		return p, nil
+	}
+	if err := p.restore(state); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// stateWriter is a writer of the pipeline which keeps the state of the writes.
+type stateWriter interface {
+	encoding.BinaryMarshaler
+	encoding.BinaryUnmarshaler
+}
+
+// resumablePipeline is a pipeline which state consists of the data pending in
+// the chunk feeder and the intermediate levels of the hash trie, as the other
+// writers of the pipeline pass the data on without keeping any of it.
+type resumablePipeline struct {
+	pipeline.Interface
+	feeder stateWriter
+	trie   stateWriter
+}
+
+func newResumablePipeline(f pipeline.Interface, tw pipeline.ChainWriter) *resumablePipeline {
+	return &resumablePipeline{
+		Interface: f,
+		feeder:    f.(stateWriter),
+		trie:      tw.(stateWriter),
+	}
+}
+
+// State implements the pipeline.Resumable interface. The state is the length
+// prefixed state of the chunk feeder followed by the state of the hash trie.
+func (p *resumablePipeline) State() ([]byte, error) {
+	f, err := p.feeder.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	t, err := p.trie.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	state := make([]byte, 4, 4+len(f)+len(t))
+	binary.BigEndian.PutUint32(state, uint32(len(f)))
+	state = append(state, f...)
+	return append(state, t...), nil
+}
+
+func (p *resumablePipeline) restore(state []byte) error {
+	if len(state) < 4 {
+		return errInvalidState
+	}
+	n := binary.BigEndian.Uint32(state)
+	if uint64(len(state)-4) < uint64(n) {
+		return errInvalidState
+	}
+	if err := p.feeder.UnmarshalBinary(state[4 : 4+n]); err != nil {
+		return fmt.Errorf("%w: %v", errInvalidState, err)
+	}
+	if err := p.trie.UnmarshalBinary(state[4+n:]); err != nil {
+		return fmt.Errorf("%w: %v", errInvalidState, err)
+	}
+	return nil
+}
+
 // newPipeline creates a standard pipeline that only hashes content with BMT to create
 // a merkle-tree of hashes that represent the given arbitrary size byte stream. Partial
 // writes are supported. The pipeline flow is: Data -> Feeder -> BMT -> Storage -> HashTrie.
-func newPipeline(ctx context.Context, s storage.Putter, mode storage.ModePut) pipeline.Interface {
+func newPipeline(ctx context.Context, s storage.Putter, mode storage.ModePut) *resumablePipeline {
 	tw := hashtrie.NewHashTrieWriter(swarm.ChunkSize, swarm.Branches, swarm.HashSize, newShortPipelineFunc(ctx, s, mode))
 	lsw := store.NewStoreWriter(ctx, s, mode, tw)
 	b := bmt.NewBmtWriter(lsw)
-	return feeder.NewChunkFeederWriter(swarm.ChunkSize, b)
+	return newResumablePipeline(feeder.NewChunkFeederWriter(swarm.ChunkSize, b), tw)
 }
 
 // newShortPipelineFunc returns a constructor function for an ephemeral hashing pipeline
@@ -52,12 +134,12 @@ func newShortPipelineFunc(ctx context.Context, s storage.Putter, mode storage.Mo
 // writes are supported. The pipeline flow is: Data -> Feeder -> Encryption -> BMT -> Storage -> HashTrie.
 // Note that the encryption writer will mutate the data to contain the encrypted span, but the span field
 // with the unencrypted span is preserved.
-func newEncryptionPipeline(ctx context.Context, s storage.Putter, mode storage.ModePut) pipeline.Interface {
+func newEncryptionPipeline(ctx context.Context, s storage.Putter, mode storage.ModePut) *resumablePipeline {
 	tw := hashtrie.NewHashTrieWriter(swarm.ChunkSize, 64, swarm.HashSize+encryption.KeyLength, newShortEncryptionPipelineFunc(ctx, s, mode))
 	lsw := store.NewStoreWriter(ctx, s, mode, tw)
 	b := bmt.NewBmtWriter(lsw)
 	enc := enc.NewEncryptionWriter(encryption.NewChunkEncrypter(), b)
-	return feeder.NewChunkFeederWriter(swarm.ChunkSize, enc)
+	return newResumablePipeline(feeder.NewChunkFeederWriter(swarm.ChunkSize, enc), tw)
 }
 
 // newShortEncryptionPipelineFunc returns a constructor function for an ephemeral hashing pipeline
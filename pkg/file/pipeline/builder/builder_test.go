@@ -10,9 +10,11 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"fmt"
+	"io"
 	"strconv"
 	"testing"
 
+	"github.com/ethersphere/bee/pkg/file/joiner"
 	"github.com/ethersphere/bee/pkg/file/pipeline/builder"
 	test "github.com/ethersphere/bee/pkg/file/testing"
 	"github.com/ethersphere/bee/pkg/storage"
@@ -100,6 +102,87 @@ func TestAllVectors(t *testing.T) {
 	}
 }
 
+// TestResumable tests that the writes resumed in the pipelines built with
+// the state of the previous pipeline result in the same hash as the writes
+// in a single pipeline.
+func TestResumable(t *testing.T) {
+	for i := 1; i <= 20; i++ {
+		data, expect := test.GetVector(t, i)
+		t.Run(fmt.Sprintf("data length %d, vector %d", len(data), i), func(t *testing.T) {
+			m := mock.NewStorer()
+			sum := writeResumable(t, m, false, data)
+			if a := swarm.NewAddress(sum); !a.Equal(expect) {
+				t.Fatalf("failed run %d, expected address %s but got %s", i, expect.String(), a.String())
+			}
+		})
+	}
+
+	t.Run("encrypted", func(t *testing.T) {
+		data, _ := test.GetVector(t, 18)
+		m := mock.NewStorer()
+		sum := writeResumable(t, m, true, data)
+
+		j, l, err := joiner.New(context.Background(), m, swarm.NewAddress(sum))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if l != int64(len(data)) {
+			t.Fatalf("got length %d, want %d", l, len(data))
+		}
+		got, err := io.ReadAll(j)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, data) {
+			t.Fatal("joined data differs from the written data")
+		}
+	})
+
+	t.Run("invalid state", func(t *testing.T) {
+		m := mock.NewStorer()
+		for _, state := range [][]byte{{}, {0, 0, 0, 9, 1}, make([]byte, 100)} {
+			if _, err := builder.NewResumablePipelineBuilder(context.Background(), m, storage.ModePutUpload, false, state); err == nil {
+				t.Fatalf("expected error for state %x", state)
+			}
+		}
+	})
+}
+
+// writeResumable writes the data in parts of varying sizes, each of them
+// to a new pipeline built with the state of the previous one.
+func writeResumable(t *testing.T, s storage.Putter, encrypt bool, data []byte) []byte {
+	t.Helper()
+
+	var state []byte
+	for i, n := 0, 0; len(data) > 0; i, data = i+1, data[n:] {
+		p, err := builder.NewResumablePipelineBuilder(context.Background(), s, storage.ModePutUpload, encrypt, state)
+		if err != nil {
+			t.Fatal(err)
+		}
+		n = []int{1, 1000, swarm.ChunkSize, 3 * swarm.ChunkSize / 2, 100000}[i%5]
+		if n > len(data) {
+			n = len(data)
+		}
+		if _, err := p.Write(data[:n]); err != nil {
+			t.Fatal(err)
+		}
+		state, err = p.State()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	p, err := builder.NewResumablePipelineBuilder(context.Background(), s, storage.ModePutUpload, encrypt, state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum, err := p.Sum()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sum
+}
+
 /*
 go test -v -bench=. -run Bench -benchmem
 goos: linux
@@ -6,6 +6,7 @@ package feeder
 
 import (
 	"encoding/binary"
+	"errors"
 
 	"github.com/ethersphere/bee/pkg/file/pipeline"
 	"github.com/ethersphere/bee/pkg/swarm"
@@ -13,6 +14,8 @@ import (
 
 const span = swarm.SpanSize
 
+var errInvalidState = errors.New("feeder: invalid state")
+
 type chunkFeeder struct {
 	size      int
 	next      pipeline.ChainWriter
@@ -119,3 +122,23 @@ func (f *chunkFeeder) Sum() ([]byte, error) {
 
 	return f.next.Sum()
 }
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+// The state of the feeder is the number of bytes flushed to the
+// subsequent writers followed by the data pending in the buffer.
+func (f *chunkFeeder) MarshalBinary() ([]byte, error) {
+	b := make([]byte, 8+f.bufferIdx)
+	binary.BigEndian.PutUint64(b, uint64(f.wrote))
+	copy(b[8:], f.buffer[:f.bufferIdx])
+	return b, nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+func (f *chunkFeeder) UnmarshalBinary(b []byte) error {
+	if len(b) < 8 || len(b)-8 >= f.size {
+		return errInvalidState
+	}
+	f.wrote = int64(binary.BigEndian.Uint64(b))
+	f.bufferIdx = copy(f.buffer, b[8:])
+	return nil
+}
@@ -15,6 +15,7 @@ import (
 var (
 	errInconsistentRefs = errors.New("inconsistent references")
 	errTrieFull         = errors.New("trie full")
+	errInvalidState     = errors.New("invalid state")
 )
 
 const maxLevel = 8
@@ -190,3 +191,43 @@ func (h *hashTrieWriter) Sum() ([]byte, error) {
 	data := h.buffer[0:h.cursors[8]]
 	return data[8:], nil
 }
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+// The state of the trie is the full flag and the level cursors followed
+// by the data of the levels kept in the buffer.
+func (h *hashTrieWriter) MarshalBinary() ([]byte, error) {
+	b := make([]byte, 1+4*len(h.cursors)+h.cursors[1])
+	if h.full {
+		b[0] = 1
+	}
+	for i, c := range h.cursors {
+		binary.BigEndian.PutUint32(b[1+4*i:], uint32(c))
+	}
+	copy(b[1+4*len(h.cursors):], h.buffer[:h.cursors[1]])
+	return b, nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+func (h *hashTrieWriter) UnmarshalBinary(b []byte) error {
+	n := 1 + 4*len(h.cursors)
+	if len(b) < n {
+		return errInvalidState
+	}
+	cursors := make([]int, len(h.cursors))
+	for i := range cursors {
+		cursors[i] = int(binary.BigEndian.Uint32(b[1+4*i:]))
+	}
+	// the higher levels are kept before the lower ones in the buffer
+	for i := 1; i < maxLevel; i++ {
+		if cursors[i] < cursors[i+1] {
+			return errInvalidState
+		}
+	}
+	if len(b)-n != cursors[1] || cursors[1] > len(h.buffer) {
+		return errInvalidState
+	}
+	h.full = b[0] == 1
+	h.cursors = cursors
+	copy(h.buffer, b[n:])
+	return nil
+}
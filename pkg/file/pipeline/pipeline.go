@@ -26,6 +26,14 @@ type Interface interface {
 	Sum() ([]byte, error)
 }
 
+// Resumable is a pipeline the state of which can be saved, in order to
+// resume the writes of the data in a new pipeline built with the state.
+type Resumable interface {
+	Interface
+	// State returns the state of the pipeline with the data written so far.
+	State() ([]byte, error)
+}
+
 // PipeWriteArgs are passed between different ChainWriters.
 type PipeWriteArgs struct {
 	Ref  []byte // reference, generated by bmt
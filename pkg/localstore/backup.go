@@ -0,0 +1,609 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package localstore
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/sharky"
+	"github.com/ethersphere/bee/pkg/shed"
+	statestore "github.com/ethersphere/bee/pkg/statestore/leveldb"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+const (
+	// name of the file holding the backup manifest, always the first one
+	backupManifestFilename = "backup.json"
+	// current backup format version
+	currentBackupVersion = 1
+	// size above which the key value pairs are written to a new file
+	backupSegmentSize = 16 * 1024 * 1024
+
+	backupLocalstoreDir = "localstore"
+	backupStatestoreDir = "statestore"
+	backupSharkyDir     = "sharky"
+)
+
+var (
+	// ErrBackupSchema is returned by Restore if the backup was made
+	// with a localstore schema different from the current one or with
+	// a statestore schema that can not be migrated to the current one.
+	ErrBackupSchema = errors.New("backup schema mismatch")
+	// ErrBackupInvalid is returned by Restore if the backup is malformed.
+	ErrBackupInvalid = errors.New("invalid backup")
+)
+
+// backupManifest describes the content of a backup.
+type backupManifest struct {
+	Version   int       `json:"version"`
+	Schema    string    `json:"schema"`
+	Shards    int       `json:"shards"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// BackupWriter stores the files of a backup.
+type BackupWriter interface {
+	WriteFile(name string, size int64, r io.Reader) error
+}
+
+// BackupReader returns the files of a backup in the order they were
+// written. It returns io.EOF when there are no more files.
+type BackupReader interface {
+	Next() (name string, size int64, r io.Reader, err error)
+}
+
+// Backup writes a consistent point-in-time copy of the database and of its
+// state store to the writer while the database is in use. The copy consists
+// of the key value pairs of LevelDB snapshots, the sharky free slots derived
+// from the snapshot and the content of the shard files, with the reuse of
+// released sharky slots suspended until the copy is done.
+func (db *DB) Backup(ctx context.Context, w BackupWriter) (err error) {
+	db.metrics.BackupCounter.Inc()
+	defer func(start time.Time) {
+		if err != nil {
+			db.metrics.BackupErrorCounter.Inc()
+		}
+		totalTimeMetric(db.metrics.TotalTimeBackup, start)
+	}(time.Now())
+
+	snapshot, stateSnapshot, unhold, err := db.snapshot()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		snapshot.Release()
+		if stateSnapshot != nil {
+			stateSnapshot.Release()
+		}
+		if err := unhold(context.Background()); err != nil {
+			db.logger.Warning("failed releasing held sharky locations", "error", err)
+		}
+	}()
+
+	schemaName, err := db.schemaName.Get()
+	if err != nil {
+		return fmt.Errorf("get schema name: %w", err)
+	}
+	manifest, err := json.Marshal(backupManifest{
+		Version:   currentBackupVersion,
+		Schema:    schemaName,
+		Shards:    sharkyNoOfShards,
+		CreatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		return err
+	}
+	if err := w.WriteFile(backupManifestFilename, int64(len(manifest)), bytes.NewReader(manifest)); err != nil {
+		return fmt.Errorf("write manifest: %w", err)
+	}
+
+	if err := writeSnapshot(ctx, w, backupLocalstoreDir, snapshot); err != nil {
+		return fmt.Errorf("write localstore: %w", err)
+	}
+
+	// the slots referred to by the snapshot are used, all the others are free
+	used := make([][]byte, sharkyNoOfShards)
+	slots := make([]uint32, sharkyNoOfShards)
+	err = db.retrievalDataIndex.Iterate(func(item shed.Item) (stop bool, err error) {
+		loc, err := sharky.LocationFromBinary(item.Location)
+		if err != nil {
+			return true, err
+		}
+		for uint32(len(used[loc.Shard])*8) <= loc.Slot {
+			used[loc.Shard] = append(used[loc.Shard], 0)
+		}
+		used[loc.Shard][loc.Slot/8] |= 1 << (loc.Slot % 8)
+		if loc.Slot >= slots[loc.Shard] {
+			slots[loc.Shard] = loc.Slot + 1
+		}
+		return false, nil
+	}, &shed.IterateOptions{Snapshot: snapshot})
+	if err != nil {
+		return fmt.Errorf("iterate retrieval data index: %w", err)
+	}
+
+	for shard := range used {
+		free := make([]byte, len(used[shard]))
+		for i, b := range used[shard] {
+			free[i] = ^b
+		}
+		name := path.Join(backupSharkyDir, fmt.Sprintf("free_%03d", shard))
+		if err := w.WriteFile(name, int64(len(free)), bytes.NewReader(free)); err != nil {
+			return fmt.Errorf("write sharky free slots: %w", err)
+		}
+		if err := db.writeShard(w, uint8(shard), slots[shard]); err != nil {
+			return fmt.Errorf("write sharky shard: %w", err)
+		}
+	}
+
+	if stateSnapshot != nil {
+		if err := writeSnapshot(ctx, w, backupStatestoreDir, stateSnapshot); err != nil {
+			return fmt.Errorf("write statestore: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// snapshot takes the snapshots of the database and of the state store, if it
+// is backed by LevelDB, and suspends the reuse of the released sharky slots.
+func (db *DB) snapshot() (snapshot, stateSnapshot *leveldb.Snapshot, unhold func(context.Context) error, err error) {
+	// no chunk can be stored or removed while the snapshot is taken
+	db.batchMu.Lock()
+	defer db.batchMu.Unlock()
+
+	snapshot, err = db.shed.GetSnapshot()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("localstore snapshot: %w", err)
+	}
+	if db.stateStore != nil && db.stateStore.DB() != nil {
+		stateSnapshot, err = db.stateStore.DB().GetSnapshot()
+		if err != nil {
+			snapshot.Release()
+			return nil, nil, nil, fmt.Errorf("statestore snapshot: %w", err)
+		}
+	}
+	return snapshot, stateSnapshot, db.sharky.Hold(), nil
+}
+
+// writeShard writes the content of the first slots of the sharky shard.
+func (db *DB) writeShard(w BackupWriter, shard uint8, slots uint32) error {
+	pr, pw := io.Pipe()
+	go func() {
+		_, err := db.sharky.CopyShard(pw, shard, slots)
+		pw.CloseWithError(err)
+	}()
+
+	name := path.Join(backupSharkyDir, fmt.Sprintf("shard_%03d", shard))
+	err := w.WriteFile(name, int64(slots)*swarm.SocMaxChunkSize, pr)
+	pr.CloseWithError(err)
+	return err
+}
+
+// writeSnapshot writes all key value pairs of the snapshot to files in the
+// directory. Every pair is written as a length prefixed key and value.
+func writeSnapshot(ctx context.Context, w BackupWriter, dir string, snapshot *leveldb.Snapshot) error {
+	it := snapshot.NewIterator(nil, nil)
+	defer it.Release()
+
+	var (
+		buf     bytes.Buffer
+		segment int
+		varint  [binary.MaxVarintLen64]byte
+	)
+	flush := func() error {
+		if buf.Len() == 0 {
+			return nil
+		}
+		name := path.Join(dir, fmt.Sprintf("%06d.kv", segment))
+		segment++
+		err := w.WriteFile(name, int64(buf.Len()), &buf)
+		buf.Reset()
+		return err
+	}
+
+	for it.Next() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		for _, b := range [][]byte{it.Key(), it.Value()} {
+			n := binary.PutUvarint(varint[:], uint64(len(b)))
+			buf.Write(varint[:n])
+			buf.Write(b)
+		}
+		if buf.Len() >= backupSegmentSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+	return flush()
+}
+
+// Restore writes the database and the state store from the backup into the
+// data directory, in the localstore and statestore directories respectively.
+// The backup is first restored into a temporary directory and swapped in
+// only if it is complete and its schema names are supported. The current
+// directories are moved back if the swap fails. The database and the state
+// store must not be in use during the restore.
+func Restore(ctx context.Context, r BackupReader, dataDir string) (err error) {
+	tmp := filepath.Join(dataDir, ".restore")
+	if err := os.RemoveAll(tmp); err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+
+	name, _, f, err := r.Next()
+	if err != nil {
+		return fmt.Errorf("read manifest: %w", err)
+	}
+	if name != backupManifestFilename {
+		return fmt.Errorf("%w: missing manifest", ErrBackupInvalid)
+	}
+	var manifest backupManifest
+	if err := json.NewDecoder(f).Decode(&manifest); err != nil {
+		return fmt.Errorf("%w: decode manifest: %v", ErrBackupInvalid, err)
+	}
+	if manifest.Version != currentBackupVersion {
+		return fmt.Errorf("%w: unsupported version %d", ErrBackupInvalid, manifest.Version)
+	}
+	if manifest.Schema != DBSchemaCurrent {
+		return fmt.Errorf("%w: got %q, want %q", ErrBackupSchema, manifest.Schema, DBSchemaCurrent)
+	}
+
+	localstorePath := filepath.Join(tmp, backupLocalstoreDir)
+	sharkyPath := filepath.Join(localstorePath, backupSharkyDir)
+	if err := os.MkdirAll(sharkyPath, 0775); err != nil {
+		return err
+	}
+	ldb, err := leveldb.OpenFile(localstorePath, nil)
+	if err != nil {
+		return fmt.Errorf("open localstore: %w", err)
+	}
+	defer func() {
+		if ldb != nil {
+			ldb.Close()
+		}
+	}()
+	var sdb *leveldb.DB
+	defer func() {
+		if sdb != nil {
+			sdb.Close()
+		}
+	}()
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		name, _, f, err := r.Next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return err
+		}
+
+		dir, base := path.Split(path.Clean(name))
+		switch strings.TrimSuffix(dir, "/") {
+		case backupLocalstoreDir:
+			err = readSnapshot(ldb, f)
+		case backupStatestoreDir:
+			if sdb == nil {
+				sdb, err = leveldb.OpenFile(filepath.Join(tmp, backupStatestoreDir), nil)
+				if err != nil {
+					return fmt.Errorf("open statestore: %w", err)
+				}
+			}
+			err = readSnapshot(sdb, f)
+		case backupSharkyDir:
+			if !strings.HasPrefix(base, "shard_") && !strings.HasPrefix(base, "free_") {
+				return fmt.Errorf("%w: unexpected file %q", ErrBackupInvalid, name)
+			}
+			err = writeFile(filepath.Join(sharkyPath, base), f)
+		default:
+			return fmt.Errorf("%w: unexpected file %q", ErrBackupInvalid, name)
+		}
+		if err != nil {
+			return fmt.Errorf("restore %s: %w", name, err)
+		}
+	}
+
+	if err := ldb.Close(); err != nil {
+		return err
+	}
+	ldb = nil
+	if sdb != nil {
+		schemaName, err := statestore.DBSchemaName(sdb)
+		if err != nil {
+			return fmt.Errorf("get restored statestore schema name: %w", err)
+		}
+		if !statestore.IsKnownSchema(schemaName) {
+			return fmt.Errorf("%w: unknown statestore schema %q", ErrBackupSchema, schemaName)
+		}
+		if err := sdb.Close(); err != nil {
+			return err
+		}
+		sdb = nil
+	}
+
+	// check the schema name of the restored database itself
+	sdbr, err := shed.NewDB(localstorePath, nil)
+	if err != nil {
+		return fmt.Errorf("open restored localstore: %w", err)
+	}
+	schemaField, err := sdbr.NewStringField("schema-name")
+	if err != nil {
+		sdbr.Close()
+		return err
+	}
+	schemaName, err := schemaField.Get()
+	sdbr.Close()
+	if err != nil {
+		return fmt.Errorf("get restored schema name: %w", err)
+	}
+	if schemaName != DBSchemaCurrent {
+		return fmt.Errorf("%w: got %q, want %q", ErrBackupSchema, schemaName, DBSchemaCurrent)
+	}
+
+	return swapDirs(tmp, dataDir, []string{backupLocalstoreDir, backupStatestoreDir})
+}
+
+// swapDirs replaces the directories in the data directory with the ones of
+// the same name found in the restore directory. The current directories are
+// first moved aside and are moved back if any of the restored ones can not
+// be moved in, so that the data directory is either fully restored or left
+// as it was.
+func swapDirs(restoreDir, dataDir string, dirs []string) (err error) {
+	oldDir := filepath.Join(dataDir, ".restore-old")
+	if err := os.RemoveAll(oldDir); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(oldDir, 0775); err != nil {
+		return err
+	}
+
+	var aside, restored []string
+	defer func() {
+		if err != nil {
+			for _, dir := range restored {
+				if e := os.RemoveAll(filepath.Join(dataDir, dir)); e != nil {
+					err = fmt.Errorf("%w; remove restored %s: %v", err, dir, e)
+					return
+				}
+			}
+			for _, dir := range aside {
+				if e := os.Rename(filepath.Join(oldDir, dir), filepath.Join(dataDir, dir)); e != nil {
+					// keep the moved aside directories for a manual recovery
+					err = fmt.Errorf("%w; move back %s from %s: %v", err, dir, oldDir, e)
+					return
+				}
+			}
+		}
+		if e := os.RemoveAll(oldDir); e != nil && err == nil {
+			err = e
+		}
+	}()
+
+	var swap []string
+	for _, dir := range dirs {
+		if _, err := os.Stat(filepath.Join(restoreDir, dir)); err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return err
+		}
+		swap = append(swap, dir)
+	}
+	for _, dir := range swap {
+		to := filepath.Join(dataDir, dir)
+		if _, err := os.Stat(to); err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return err
+		}
+		if err := os.Rename(to, filepath.Join(oldDir, dir)); err != nil {
+			return err
+		}
+		aside = append(aside, dir)
+	}
+	for _, dir := range swap {
+		if err := renameDir(filepath.Join(restoreDir, dir), filepath.Join(dataDir, dir)); err != nil {
+			return err
+		}
+		restored = append(restored, dir)
+	}
+	return nil
+}
+
+// renameDir is the function used to move directories, replaced in tests.
+var renameDir = os.Rename
+
+// readSnapshot writes the key value pairs written by writeSnapshot to the database.
+func readSnapshot(db *leveldb.DB, r io.Reader) error {
+	br := bufio.NewReader(r)
+	batch := new(leveldb.Batch)
+	read := func() ([]byte, error) {
+		n, err := binary.ReadUvarint(br)
+		if err != nil {
+			return nil, err
+		}
+		b := make([]byte, n)
+		_, err = io.ReadFull(br, b)
+		return b, err
+	}
+	for {
+		key, err := read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return err
+		}
+		value, err := read()
+		if err != nil {
+			return fmt.Errorf("%w: truncated value", ErrBackupInvalid)
+		}
+		batch.Put(key, value)
+		if batch.Len() >= 1000 {
+			if err := db.Write(batch, nil); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+	}
+	return db.Write(batch, nil)
+}
+
+// writeFile creates the file with the content read from the reader.
+func writeFile(name string, r io.Reader) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// tarBackupWriter writes the backup files to a tar archive.
+type tarBackupWriter struct {
+	tw *tar.Writer
+}
+
+// NewTarBackupWriter returns a BackupWriter which writes the backup files to the tar writer.
+func NewTarBackupWriter(tw *tar.Writer) BackupWriter {
+	return &tarBackupWriter{tw: tw}
+}
+
+func (w *tarBackupWriter) WriteFile(name string, size int64, r io.Reader) error {
+	if err := w.tw.WriteHeader(&tar.Header{
+		Name: name,
+		Mode: 0644,
+		Size: size,
+	}); err != nil {
+		return err
+	}
+	_, err := io.CopyN(w.tw, r, size)
+	return err
+}
+
+// tarBackupReader reads the backup files from a tar archive.
+type tarBackupReader struct {
+	tr *tar.Reader
+}
+
+// NewTarBackupReader returns a BackupReader which reads the backup files from the tar reader.
+func NewTarBackupReader(tr *tar.Reader) BackupReader {
+	return &tarBackupReader{tr: tr}
+}
+
+func (r *tarBackupReader) Next() (string, int64, io.Reader, error) {
+	for {
+		hdr, err := r.tr.Next()
+		if err != nil {
+			return "", 0, nil, err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		return hdr.Name, hdr.Size, r.tr, nil
+	}
+}
+
+// dirBackupWriter writes the backup files to a directory.
+type dirBackupWriter struct {
+	dir string
+}
+
+// NewDirBackupWriter returns a BackupWriter which writes the backup files to the directory.
+func NewDirBackupWriter(dir string) BackupWriter {
+	return &dirBackupWriter{dir: dir}
+}
+
+func (w *dirBackupWriter) WriteFile(name string, size int64, r io.Reader) error {
+	p := filepath.Join(w.dir, filepath.FromSlash(path.Clean("/"+name)))
+	if err := os.MkdirAll(filepath.Dir(p), 0775); err != nil {
+		return err
+	}
+	return writeFile(p, io.LimitReader(r, size))
+}
+
+// dirBackupReader reads the backup files from a directory.
+type dirBackupReader struct {
+	dir   string
+	names []string
+	f     *os.File
+}
+
+// NewDirBackupReader returns a BackupReader which reads the backup files from the directory.
+func NewDirBackupReader(dir string) (BackupReader, error) {
+	var names []string
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		name, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		names = append(names, filepath.ToSlash(name))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	// the manifest must come first
+	sort.SliceStable(names, func(i, j int) bool {
+		return names[i] == backupManifestFilename && names[j] != backupManifestFilename
+	})
+	return &dirBackupReader{dir: dir, names: names}, nil
+}
+
+func (r *dirBackupReader) Next() (string, int64, io.Reader, error) {
+	if r.f != nil {
+		r.f.Close()
+		r.f = nil
+	}
+	if len(r.names) == 0 {
+		return "", 0, nil, io.EOF
+	}
+	name := r.names[0]
+	r.names = r.names[1:]
+
+	f, err := os.Open(filepath.Join(r.dir, filepath.FromSlash(name)))
+	if err != nil {
+		return "", 0, nil, err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return "", 0, nil, err
+	}
+	r.f = f
+	return name, fi.Size(), f, nil
+}
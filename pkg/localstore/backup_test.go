@@ -0,0 +1,278 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package localstore
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/log"
+	"github.com/ethersphere/bee/pkg/postage"
+	statestore "github.com/ethersphere/bee/pkg/statestore/leveldb"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// changingBackupWriter calls the change function before the first file
+// of the database is written, that is after the snapshot is taken.
+type changingBackupWriter struct {
+	BackupWriter
+	change func()
+}
+
+func (w *changingBackupWriter) WriteFile(name string, size int64, r io.Reader) error {
+	if w.change != nil && strings.HasPrefix(name, backupLocalstoreDir) {
+		w.change()
+		w.change = nil
+	}
+	return w.BackupWriter.WriteFile(name, size, r)
+}
+
+// TestBackupRestore validates that the restored database contains exactly
+// the chunks and the state present when the backup started, even if the
+// database is changed while the backup is in progress.
+func TestBackupRestore(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		backup func(t *testing.T, db *DB, change func()) BackupReader
+	}{
+		{
+			name: "tar",
+			backup: func(t *testing.T, db *DB, change func()) BackupReader {
+				var buf bytes.Buffer
+				tw := tar.NewWriter(&buf)
+				if err := db.Backup(context.Background(), &changingBackupWriter{NewTarBackupWriter(tw), change}); err != nil {
+					t.Fatal(err)
+				}
+				if err := tw.Close(); err != nil {
+					t.Fatal(err)
+				}
+				return NewTarBackupReader(tar.NewReader(&buf))
+			},
+		},
+		{
+			name: "dir",
+			backup: func(t *testing.T, db *DB, change func()) BackupReader {
+				dir := t.TempDir()
+				if err := db.Backup(context.Background(), &changingBackupWriter{NewDirBackupWriter(dir), change}); err != nil {
+					t.Fatal(err)
+				}
+				r, err := NewDirBackupReader(dir)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return r
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+
+			ss, err := statestore.NewInMemoryStateStore(log.Noop)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := ss.Put("backup-test", "value"); err != nil {
+				t.Fatal(err)
+			}
+			o := &Options{UnreserveFunc: func(postage.UnreserveIteratorFn) error { return nil }}
+			baseKey := swarm.MustParseHexAddress("ca1e9f3938cc1425c6061b96ad9eb93e134dfe8734ad490164ef20af9d1cf59c").Bytes()
+			db, err := New("", baseKey, ss, o, log.Noop)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+
+			chunks := make([]swarm.Chunk, 100)
+			for i := range chunks {
+				chunks[i] = generateTestRandomChunk()
+			}
+			if _, err := db.Put(ctx, storage.ModePutUpload, chunks...); err != nil {
+				t.Fatal(err)
+			}
+
+			// remove the chunks and store new ones in their slots during the backup
+			added := make([]swarm.Chunk, 100)
+			r := tc.backup(t, db, func() {
+				if err := db.Set(ctx, storage.ModeSetRemove, chunkAddresses(chunks)...); err != nil {
+					t.Fatal(err)
+				}
+				for i := range added {
+					added[i] = generateTestRandomChunk()
+				}
+				if _, err := db.Put(ctx, storage.ModePutUpload, added...); err != nil {
+					t.Fatal(err)
+				}
+				if err := ss.Delete("backup-test"); err != nil {
+					t.Fatal(err)
+				}
+			})
+
+			dataDir := t.TempDir()
+			if err := Restore(ctx, r, dataDir); err != nil {
+				t.Fatal(err)
+			}
+
+			rss, err := statestore.NewStateStore(filepath.Join(dataDir, "statestore"), log.Noop)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer rss.Close()
+			var v string
+			if err := rss.Get("backup-test", &v); err != nil || v != "value" {
+				t.Fatalf("got state %q, %v, want %q", v, err, "value")
+			}
+
+			rdb, err := New(filepath.Join(dataDir, "localstore"), baseKey, rss, o, log.Noop)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer rdb.Close()
+
+			for _, ch := range chunks {
+				got, err := rdb.Get(ctx, storage.ModeGetLookup, ch.Address())
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !bytes.Equal(got.Data(), ch.Data()) {
+					t.Fatalf("chunk %s data mismatch", ch.Address())
+				}
+			}
+			for _, ch := range added {
+				if _, err := rdb.Get(ctx, storage.ModeGetLookup, ch.Address()); !errors.Is(err, storage.ErrNotFound) {
+					t.Fatalf("got error %v for chunk stored after the backup, want %v", err, storage.ErrNotFound)
+				}
+			}
+
+			res, err := rdb.Validate(ctx, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Total != uint64(len(chunks)) || res.Invalid != 0 || res.Unreadable != 0 {
+				t.Fatalf("unexpected validation result %+v", res)
+			}
+		})
+	}
+}
+
+func TestRestoreSchemaMismatch(t *testing.T) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	manifest := []byte(`{"version":1,"schema":"unknown","shards":32}`)
+	if err := NewTarBackupWriter(tw).WriteFile(backupManifestFilename, int64(len(manifest)), bytes.NewReader(manifest)); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	err := Restore(context.Background(), NewTarBackupReader(tar.NewReader(&buf)), t.TempDir())
+	if !errors.Is(err, ErrBackupSchema) {
+		t.Fatalf("got error %v, want %v", err, ErrBackupSchema)
+	}
+}
+
+// newTestBackup returns a tar backup of a database with a chunk and of an
+// in memory state store which is changed by the function before the backup.
+func newTestBackup(t *testing.T, change func(ss *statestore.Store)) BackupReader {
+	t.Helper()
+
+	ss, err := statestore.NewInMemoryStateStore(log.Noop)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if change != nil {
+		change(ss)
+	}
+	o := &Options{UnreserveFunc: func(postage.UnreserveIteratorFn) error { return nil }}
+	db, err := New("", swarm.MustParseHexAddress("ca1e9f3938cc1425c6061b96ad9eb93e134dfe8734ad490164ef20af9d1cf59c").Bytes(), ss, o, log.Noop)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := db.Put(context.Background(), storage.ModePutUpload, generateTestRandomChunk()); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	if err := db.Backup(context.Background(), NewTarBackupWriter(tw)); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return NewTarBackupReader(tar.NewReader(&buf))
+}
+
+func TestRestoreStatestoreSchemaMismatch(t *testing.T) {
+	r := newTestBackup(t, func(ss *statestore.Store) {
+		if err := ss.DB().Put([]byte("statestore_schema"), []byte("unknown"), nil); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	dataDir := t.TempDir()
+	err := Restore(context.Background(), r, dataDir)
+	if !errors.Is(err, ErrBackupSchema) {
+		t.Fatalf("got error %v, want %v", err, ErrBackupSchema)
+	}
+	if _, err := os.Stat(filepath.Join(dataDir, "statestore")); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("got statestore directory error %v, want %v", err, os.ErrNotExist)
+	}
+}
+
+// TestRestoreRollback validates that the current directories are left as
+// they were if the restored ones can not be moved in.
+func TestRestoreRollback(t *testing.T) {
+	r := newTestBackup(t, nil)
+
+	dataDir := t.TempDir()
+	for _, dir := range []string{"localstore", "statestore"} {
+		if err := os.MkdirAll(filepath.Join(dataDir, dir), 0775); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dataDir, dir, "current"), []byte(dir), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	errRename := errors.New("rename")
+	defer func(f func(string, string) error) { renameDir = f }(renameDir)
+	renameDir = func(from, to string) error {
+		if filepath.Base(from) == "statestore" {
+			return errRename
+		}
+		return os.Rename(from, to)
+	}
+
+	if err := Restore(context.Background(), r, dataDir); !errors.Is(err, errRename) {
+		t.Fatalf("got error %v, want %v", err, errRename)
+	}
+
+	for _, dir := range []string{"localstore", "statestore"} {
+		got, err := os.ReadFile(filepath.Join(dataDir, dir, "current"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != dir {
+			t.Fatalf("got %q in %s, want %q", got, dir, dir)
+		}
+	}
+	entries, err := os.ReadDir(dataDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries in the data directory, want 2", len(entries))
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package localstore
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"sync"
+
+	"github.com/ethersphere/bee/pkg/shed"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/hashicorp/golang-lru/simplelru"
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+// chunkCache is an in-memory cache of the requested chunks, limited by
+// the total size of the cached items in bytes. The least recently used
+// items are evicted first. A nil chunkCache is a disabled cache.
+type chunkCache struct {
+	mu      sync.Mutex
+	lru     *simplelru.LRU
+	size    uint64
+	limit   uint64
+	metrics *metrics
+}
+
+func newChunkCache(limit uint64, metrics *metrics) *chunkCache {
+	c := &chunkCache{
+		limit:   limit,
+		metrics: metrics,
+	}
+	// the number of items is limited by the size of the cache only
+	c.lru, _ = simplelru.NewLRU(math.MaxInt32, func(_, value interface{}) {
+		c.size -= chunkCacheItemSize(value.(shed.Item))
+	})
+	return c
+}
+
+// chunkCacheItemSize returns the approximate memory footprint of the cached item.
+func chunkCacheItemSize(item shed.Item) uint64 {
+	return uint64(len(item.Address) + len(item.Data) + len(item.Location) +
+		len(item.BatchID) + len(item.Index) + len(item.Timestamp) + len(item.Sig))
+}
+
+// get returns the cached item of the chunk with a copy of its data.
+func (c *chunkCache) get(addr swarm.Address) (shed.Item, bool) {
+	if c == nil {
+		return shed.Item{}, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	v, ok := c.lru.Get(addr.ByteString())
+	if !ok {
+		c.metrics.ChunkCacheMisses.Inc()
+		return shed.Item{}, false
+	}
+	c.metrics.ChunkCacheHits.Inc()
+
+	item := v.(shed.Item)
+	item.Data = append([]byte(nil), item.Data...)
+	return item, true
+}
+
+// add caches the item and evicts the least recently
+// used items if the size limit is exceeded.
+func (c *chunkCache) add(item shed.Item) {
+	if c == nil {
+		return
+	}
+	size := chunkCacheItemSize(item)
+	if size > c.limit {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := swarm.NewAddress(item.Address).ByteString()
+	if c.lru.Contains(key) {
+		c.lru.Remove(key)
+	}
+	c.lru.Add(key, item)
+	c.size += size
+	for c.size > c.limit {
+		c.lru.RemoveOldest()
+	}
+	c.metrics.ChunkCacheSize.Set(float64(c.size))
+}
+
+// remove drops the items of the chunks from the cache.
+func (c *chunkCache) remove(addrs ...swarm.Address) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, addr := range addrs {
+		c.lru.Remove(addr.ByteString())
+	}
+	c.metrics.ChunkCacheSize.Set(float64(c.size))
+}
+
+// cacheChunk adds the requested item to the chunk cache if it is still
+// stored. It must be called under the batchMu lock, which is also held
+// while the chunks are removed from the database and the cache, so that
+// a removed chunk is never added back.
+func (db *DB) cacheChunk(item shed.Item) error {
+	if db.chunkCache == nil || item.Data == nil {
+		return nil
+	}
+	i, err := db.retrievalDataIndex.Get(item)
+	if err != nil {
+		if errors.Is(err, leveldb.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+	if i.StoreTimestamp != item.StoreTimestamp || !bytes.Equal(i.Location, item.Location) {
+		// stored again or repaired in the meantime
+		return nil
+	}
+	i.Data = append([]byte(nil), item.Data...)
+	db.chunkCache.add(i)
+	return nil
+}
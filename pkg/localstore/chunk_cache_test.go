@@ -0,0 +1,125 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package localstore
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/shed"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+func TestChunkCache(t *testing.T) {
+	m := newMetrics()
+	chunks := []swarm.Chunk{generateTestRandomChunk(), generateTestRandomChunk(), generateTestRandomChunk()}
+	items := make([]shed.Item, len(chunks))
+	for i, ch := range chunks {
+		items[i] = chunkToItem(ch)
+	}
+	size := chunkCacheItemSize(items[0])
+
+	c := newChunkCache(2*size, &m)
+	c.add(items[0])
+	c.add(items[1])
+
+	// make the first item the most recently used one
+	if _, ok := c.get(chunks[0].Address()); !ok {
+		t.Fatal("first chunk not cached")
+	}
+
+	c.add(items[2])
+	if c.size != 2*size {
+		t.Fatalf("got size %d, want %d", c.size, 2*size)
+	}
+	if _, ok := c.get(chunks[1].Address()); ok {
+		t.Fatal("least recently used chunk not evicted")
+	}
+
+	item, ok := c.get(chunks[2].Address())
+	if !ok {
+		t.Fatal("last chunk not cached")
+	}
+	if !bytes.Equal(item.Data, chunks[2].Data()) {
+		t.Fatal("cached data mismatch")
+	}
+
+	c.remove(chunks[0].Address(), chunks[2].Address())
+	if c.size != 0 {
+		t.Fatalf("got size %d, want 0", c.size)
+	}
+	if _, ok := c.get(chunks[0].Address()); ok {
+		t.Fatal("removed chunk is cached")
+	}
+
+	var disabled *chunkCache
+	disabled.add(items[0])
+	if _, ok := disabled.get(chunks[0].Address()); ok {
+		t.Fatal("disabled cache returned a chunk")
+	}
+}
+
+// TestDB_chunkCache checks that the requested chunks are cached
+// and removed from the cache with the chunks removed from the database.
+func TestDB_chunkCache(t *testing.T) {
+	db := newTestDB(t, &Options{
+		ChunkCacheSize: 1024 * 1024,
+	})
+
+	updated := make(chan struct{}, 10)
+	t.Cleanup(setTestHookUpdateGC(func() {
+		updated <- struct{}{}
+	}))
+
+	ctx := context.Background()
+	chunks := []swarm.Chunk{generateTestRandomChunk(), generateTestRandomChunk()}
+	unreserveChunkBatch(t, db, 0, chunks...)
+	if _, err := db.Put(ctx, storage.ModePutUpload, chunks...); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := db.Get(ctx, storage.ModeGetLookup, chunks[0].Address()); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := db.chunkCache.get(chunks[0].Address()); ok {
+		t.Fatal("chunk cached on lookup")
+	}
+
+	if _, err := db.Get(ctx, storage.ModeGetRequest, chunks[0].Address()); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-updated:
+	case <-time.After(10 * time.Second):
+		t.Fatal("updateGC was not called after getting chunk with ModeGetRequest")
+	}
+	if _, ok := db.chunkCache.get(chunks[0].Address()); !ok {
+		t.Fatal("requested chunk not cached")
+	}
+
+	got, err := db.GetMulti(ctx, storage.ModeGetLookup, chunks[1].Address(), chunks[0].Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, ch := range []swarm.Chunk{chunks[1], chunks[0]} {
+		if !got[i].Equal(ch) {
+			t.Fatalf("got chunk %s, want %s", got[i].Address(), ch.Address())
+		}
+	}
+
+	if err := db.Set(ctx, storage.ModeSetRemove, chunks[0].Address()); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := db.chunkCache.get(chunks[0].Address()); ok {
+		t.Fatal("removed chunk is cached")
+	}
+	if _, err := db.Get(ctx, storage.ModeGetRequest, chunks[0].Address()); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("got error %v, want %v", err, storage.ErrNotFound)
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package localstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/sharky"
+	"github.com/ethersphere/bee/pkg/shed"
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+// CompactionProgress reports the state of a running compaction.
+type CompactionProgress struct {
+	// Shard is the index of the last compacted shard.
+	Shard uint8 `json:"shard"`
+	// Shards is the total number of shards.
+	Shards int `json:"shards"`
+	// Moved is the number of chunks relocated so far.
+	Moved uint64 `json:"moved"`
+	// Reclaimed is the number of bytes released to the filesystem so far.
+	Reclaimed int64 `json:"reclaimed"`
+}
+
+// compactionCandidate is a chunk with its current sharky slot.
+type compactionCandidate struct {
+	address []byte
+	slot    uint32
+}
+
+// Compact relocates the chunks stored at the end of every sharky shard to
+// the free slots closest to the beginning of the shard and truncates the
+// shard files afterwards. The retrieval data index is updated along with
+// every relocation, so the compaction can run while the database is in use.
+// The progress function, if not nil, is called after every compacted shard.
+func (db *DB) Compact(ctx context.Context, progress func(CompactionProgress)) (err error) {
+	db.metrics.CompactCounter.Inc()
+	defer func(start time.Time) {
+		if err != nil {
+			db.metrics.CompactErrorCounter.Inc()
+		}
+		totalTimeMetric(db.metrics.TotalTimeCompact, start)
+	}(time.Now())
+
+	candidates := make([][]compactionCandidate, sharkyNoOfShards)
+	err = db.retrievalDataIndex.Iterate(func(item shed.Item) (stop bool, err error) {
+		loc, err := sharky.LocationFromBinary(item.Location)
+		if err != nil {
+			return true, err
+		}
+		candidates[loc.Shard] = append(candidates[loc.Shard], compactionCandidate{
+			address: append([]byte(nil), item.Address...),
+			slot:    loc.Slot,
+		})
+		return false, nil
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("iterate retrieval data index: %w", err)
+	}
+
+	p := CompactionProgress{Shards: sharkyNoOfShards}
+	for shard, items := range candidates {
+		// start from the end of the shard
+		sort.Slice(items, func(i, j int) bool { return items[i].slot > items[j].slot })
+
+		for _, item := range items {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			moved, done, err := db.compactChunk(ctx, item)
+			if err != nil {
+				return fmt.Errorf("compact shard %d: %w", shard, err)
+			}
+			if done {
+				break
+			}
+			if moved {
+				p.Moved++
+				db.metrics.CompactMovedCounter.Inc()
+			}
+		}
+
+		reclaimed, err := db.sharky.Truncate(ctx, uint8(shard))
+		if err != nil {
+			return fmt.Errorf("truncate shard %d: %w", shard, err)
+		}
+		db.metrics.CompactReclaimedBytes.Add(float64(reclaimed))
+
+		p.Shard = uint8(shard)
+		p.Reclaimed += reclaimed
+		if progress != nil {
+			progress(p)
+		}
+	}
+
+	return nil
+}
+
+// compactChunk moves the chunk to a lower sharky slot and updates its location
+// in the retrieval data index. Done is true if there is no free slot preceding
+// the current one, meaning that the rest of the shard is already compacted.
+func (db *DB) compactChunk(ctx context.Context, c compactionCandidate) (moved, done bool, err error) {
+	// protect the retrieval data index from concurrent changes
+	db.batchMu.Lock()
+	defer db.batchMu.Unlock()
+
+	item, err := db.retrievalDataIndex.Get(shed.Item{Address: c.address})
+	if err != nil {
+		if errors.Is(err, leveldb.ErrNotFound) {
+			// removed in the meantime
+			return false, false, nil
+		}
+		return false, false, err
+	}
+	from, err := sharky.LocationFromBinary(item.Location)
+	if err != nil {
+		return false, false, err
+	}
+	if from.Slot != c.slot {
+		// stored again in the meantime
+		return false, false, nil
+	}
+
+	to, err := db.sharky.Move(ctx, from)
+	if err != nil {
+		return false, false, err
+	}
+	if to == from {
+		return false, true, nil
+	}
+
+	item.Location, err = to.MarshalBinary()
+	if err != nil {
+		return false, false, err
+	}
+	if err := db.retrievalDataIndex.Put(item); err != nil {
+		if rerr := db.sharky.Release(ctx, to); rerr != nil {
+			db.logger.Warning("failed releasing sharky location", "location", to)
+		}
+		return false, false, err
+	}
+	if err := db.sharky.Release(ctx, from); err != nil {
+		db.logger.Warning("failed releasing sharky location", "location", from)
+	}
+	return true, false, nil
+}
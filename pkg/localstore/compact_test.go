@@ -0,0 +1,73 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package localstore
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/storage"
+)
+
+// TestCompact puts chunks, removes the ones written first and validates
+// that the remaining chunks are retrievable after the compaction.
+func TestCompact(t *testing.T) {
+	db := newTestDB(t, nil)
+	ctx := context.Background()
+
+	chunks := generateTestRandomChunks(2000)
+	for _, ch := range chunks {
+		if _, err := db.Put(ctx, storage.ModePutUpload, ch); err != nil {
+			t.Fatal(err)
+		}
+	}
+	removed, kept := chunks[:1000], chunks[1000:]
+	if err := db.Set(ctx, storage.ModeSetRemove, chunkAddresses(removed)...); err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		calls int
+		last  CompactionProgress
+	)
+	err := db.Compact(ctx, func(p CompactionProgress) {
+		calls++
+		last = p
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != sharkyNoOfShards {
+		t.Fatalf("got %d progress calls, want %d", calls, sharkyNoOfShards)
+	}
+	if last.Moved == 0 {
+		t.Fatal("expected chunks to be moved")
+	}
+	if last.Reclaimed == 0 {
+		t.Fatal("expected bytes to be reclaimed")
+	}
+
+	for _, ch := range kept {
+		got, err := db.Get(ctx, storage.ModeGetRequest, ch.Address())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got.Data(), ch.Data()) {
+			t.Fatalf("data mismatch for chunk %s", ch.Address())
+		}
+	}
+
+	// a subsequent compaction has nothing to move
+	err = db.Compact(ctx, func(p CompactionProgress) {
+		last = p
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last.Moved != 0 {
+		t.Fatalf("got %d moved chunks, want none", last.Moved)
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package localstore
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/storage"
+)
+
+// DiskSpaceState describes the free space left on the filesystem of the database.
+type DiskSpaceState int32
+
+const (
+	// DiskSpaceOK is the state with enough free space.
+	DiskSpaceOK DiskSpaceState = iota
+	// DiskSpaceLow is the state with the free space below the soft limit.
+	// Chunks from syncing are rejected and the garbage collection and the
+	// reserve eviction are forced on every check.
+	DiskSpaceLow
+	// DiskSpaceFull is the state with the free space below the hard limit.
+	// Uploaded and requested chunks are rejected as well.
+	DiskSpaceFull
+)
+
+// String returns the name of the state.
+func (s DiskSpaceState) String() string {
+	switch s {
+	case DiskSpaceOK:
+		return "ok"
+	case DiskSpaceLow:
+		return "low"
+	case DiskSpaceFull:
+		return "full"
+	}
+	return "unknown"
+}
+
+var (
+	// ErrDiskSpaceLow is returned by Put for chunks from syncing
+	// when the free disk space is below the soft limit.
+	ErrDiskSpaceLow = errors.New("disk space low")
+	// ErrDiskFull is returned by Put for uploaded and requested chunks
+	// when the free disk space is below the hard limit.
+	ErrDiskFull = errors.New("disk full")
+)
+
+var (
+	// diskSpaceCheckInterval is the time between two checks of the free disk space.
+	diskSpaceCheckInterval = 30 * time.Second
+	// diskFreeFn returns the free space on the filesystem of the path.
+	diskFreeFn = diskFree
+)
+
+// DiskSpaceState returns the state of the free disk space
+// as of the last check of the disk space watchdog.
+func (db *DB) DiskSpaceState() DiskSpaceState {
+	return DiskSpaceState(atomic.LoadInt32(&db.diskSpaceState))
+}
+
+// diskSpaceWorker is a long running function that periodically
+// checks the free space on the filesystem of the database.
+func (db *DB) diskSpaceWorker(path string) {
+	defer close(db.diskSpaceWorkerDone)
+
+	ticker := time.NewTicker(diskSpaceCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		db.checkDiskSpace(path)
+
+		select {
+		case <-ticker.C:
+		case <-db.close:
+			return
+		}
+	}
+}
+
+// checkDiskSpace updates the disk space state from the free space on the
+// filesystem of the path. While the free space is below the soft limit, it
+// triggers the garbage collection and the reserve eviction, which then evict
+// chunks beyond their usual targets.
+func (db *DB) checkDiskSpace(path string) {
+	free, err := diskFreeFn(path)
+	if err != nil {
+		db.logger.Error(err, "disk space check failed", "path", path)
+		return
+	}
+	db.metrics.DiskFreeBytes.Set(float64(free))
+
+	state := DiskSpaceOK
+	switch {
+	case free < db.freeSpaceHardLimit:
+		state = DiskSpaceFull
+	case free < db.freeSpaceSoftLimit:
+		state = DiskSpaceLow
+	}
+	db.metrics.DiskSpaceState.Set(float64(state))
+
+	previous := DiskSpaceState(atomic.SwapInt32(&db.diskSpaceState, int32(state)))
+	if state != previous {
+		if state > previous {
+			db.logger.Warning("disk space running out", "state", state, "free_bytes", free)
+		} else {
+			db.logger.Info("disk space recovered", "state", state, "free_bytes", free)
+		}
+	}
+
+	if state != DiskSpaceOK {
+		db.triggerGarbageCollection()
+		db.triggerReserveEviction()
+	}
+}
+
+// checkDiskSpaceForPut returns an error if chunks
+// with the mode can not be stored in the current state.
+func (db *DB) checkDiskSpaceForPut(mode storage.ModePut) error {
+	switch state := db.DiskSpaceState(); {
+	case state == DiskSpaceOK:
+		return nil
+	case mode == storage.ModePutSync:
+		return ErrDiskSpaceLow
+	case state == DiskSpaceFull:
+		return ErrDiskFull
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package localstore
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/log"
+	"github.com/ethersphere/bee/pkg/shed"
+	"github.com/ethersphere/bee/pkg/storage"
+)
+
+func TestDiskSpace(t *testing.T) {
+	var free uint64 = 1000
+	defaultDiskFreeFn := diskFreeFn
+	t.Cleanup(func() { diskFreeFn = defaultDiskFreeFn })
+	diskFreeFn = func(string) (uint64, error) {
+		return atomic.LoadUint64(&free), nil
+	}
+	t.Cleanup(setWithinRadiusFunc(func(_ *DB, _ shed.Item) bool { return false }))
+
+	collected := make(chan uint64, 1)
+	t.Cleanup(setTestHookCollectGarbage(func(collectedCount uint64) {
+		if collectedCount == 0 {
+			return
+		}
+		select {
+		case collected <- collectedCount:
+		default:
+		}
+	}))
+
+	dir := t.TempDir()
+	db, err := New(dir, make([]byte, 32), nil, &Options{
+		Capacity:           100,
+		FreeSpaceSoftLimit: 100,
+		FreeSpaceHardLimit: 10,
+	}, log.Noop)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := db.Close(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	count := 50
+	for i := 0; i < count; i++ {
+		putSyncedChunk(t, db)
+	}
+
+	ctx := context.Background()
+	for _, tc := range []struct {
+		free       uint64
+		state      DiskSpaceState
+		syncErr    error
+		uploadErr  error
+		wantGCSize uint64
+	}{
+		{free: 1000, state: DiskSpaceOK, wantGCSize: uint64(count)},
+		{free: 50, state: DiskSpaceLow, syncErr: ErrDiskSpaceLow, wantGCSize: uint64(float64(count) * gcTargetRatio)},
+		{free: 5, state: DiskSpaceFull, syncErr: ErrDiskSpaceLow, uploadErr: ErrDiskFull},
+		{free: 1000, state: DiskSpaceOK},
+	} {
+		atomic.StoreUint64(&free, tc.free)
+		db.checkDiskSpace(dir)
+
+		if got := db.DiskSpaceState(); got != tc.state {
+			t.Fatalf("free %d: got state %s, want %s", tc.free, got, tc.state)
+		}
+
+		if tc.wantGCSize > 0 {
+			if tc.state != DiskSpaceOK {
+				select {
+				case <-collected:
+				case <-time.After(10 * time.Second):
+					t.Fatal("collect garbage timeout")
+				}
+			}
+			gcSize, err := db.gcSize.Get()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if gcSize != tc.wantGCSize {
+				t.Fatalf("free %d: got gc size %d, want %d", tc.free, gcSize, tc.wantGCSize)
+			}
+		}
+
+		ch := generateTestRandomChunk()
+		unreserveChunkBatch(t, db, 0, ch)
+		if _, err := db.Put(ctx, storage.ModePutSync, ch); !errors.Is(err, tc.syncErr) {
+			t.Fatalf("free %d: got sync error %v, want %v", tc.free, err, tc.syncErr)
+		}
+		if _, err := db.Put(ctx, storage.ModePutUpload, ch); !errors.Is(err, tc.uploadErr) {
+			t.Fatalf("free %d: got upload error %v, want %v", tc.free, err, tc.uploadErr)
+		}
+	}
+}
@@ -18,17 +18,21 @@ package localstore
 
 import (
 	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
 	"context"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
-	"sync"
 
 	"github.com/ethersphere/bee/pkg/postage"
 	"github.com/ethersphere/bee/pkg/sharky"
 	"github.com/ethersphere/bee/pkg/shed"
 	"github.com/ethersphere/bee/pkg/storage"
 	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/syndtr/goleveldb/leveldb"
 )
 
 const (
@@ -37,14 +41,94 @@ const (
 	exportVersionFilename = ".swarm-export-version"
 	// current export format version
 	currentExportVersion = "3"
+	// number of chunks stored in a single batch on import
+	importBatchSize = 100
 )
 
-// Export writes a tar structured data to the writer of
-// all chunks in the retrieval data index. It returns the
-// number of chunks exported.
-func (db *DB) Export(w io.Writer) (count int64, err error) {
+// gzipMagic are the first bytes of gzip compressed data.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// ExportOptions holds optional filters of the export.
+type ExportOptions struct {
+	// BatchID, if set, limits the export to chunks stamped by the batch.
+	BatchID []byte
+	// PinnedOnly limits the export to pinned chunks.
+	PinnedOnly bool
+	// FromPO and ToPO limit the export to the chunks from the proximity
+	// order bins in range [FromPO, ToPO). A zero ToPO means no upper limit.
+	FromPO, ToPO uint8
+	// Since maps proximity order bins to the bin ID from which the
+	// export of the bin starts, allowing incremental exports.
+	Since map[uint8]uint64
+	// Compress enables gzip compression of the exported data.
+	Compress bool
+	// Progress, if set, is called after every exported chunk.
+	Progress func(ExportProgress)
+}
+
+// ExportProgress reports the state of a running export.
+type ExportProgress struct {
+	// Count is the number of chunks exported so far.
+	Count int64
+	// Bin and BinID identify the last exported chunk in the pull index.
+	// The export of the bin can be continued from BinID+1.
+	Bin   uint8
+	BinID uint64
+}
+
+// binFiltered reports whether the chunks need to be selected
+// by their proximity order bin or bin ID.
+func (o *ExportOptions) binFiltered() bool {
+	return o.FromPO > 0 || (o.ToPO > 0 && o.ToPO <= swarm.MaxPO) || len(o.Since) > 0
+}
+
+// ImportOptions holds optional parameters of the import.
+type ImportOptions struct {
+	// Skip is the number of chunks at the beginning of the stream that are
+	// not imported. It allows resuming an interrupted import.
+	Skip int64
+	// Progress, if set, is called after every imported batch of chunks.
+	Progress func(ImportProgress)
+}
+
+// ImportProgress reports the state of a running import.
+type ImportProgress struct {
+	// Processed is the number of chunks read from the stream and stored,
+	// skipped or found to exist. An interrupted import can be resumed by
+	// skipping this many chunks.
+	Processed int64 `json:"processed"`
+	// Imported is the number of chunks newly stored.
+	Imported int64 `json:"imported"`
+	// Existing is the number of chunks that were already stored.
+	Existing int64 `json:"existing"`
+}
+
+// Export writes a tar structured data to the writer of all chunks in the
+// retrieval data index that match the options. It returns the number of
+// chunks exported. The proximity order and bin ID filters select chunks
+// through the pull index, so only chunks subject to syncing are exported
+// when these filters are set.
+func (db *DB) Export(w io.Writer, o *ExportOptions) (count int64, err error) {
+	if o == nil {
+		o = new(ExportOptions)
+	}
+
+	if o.Compress {
+		gw := gzip.NewWriter(w)
+		defer func() {
+			if cerr := gw.Close(); err == nil {
+				err = cerr
+			}
+		}()
+		w = gw
+	}
+
 	tw := tar.NewWriter(w)
-	defer tw.Close()
+	defer func() {
+		if cerr := tw.Close(); err == nil {
+			err = cerr
+		}
+	}()
 
 	if err := tw.WriteHeader(&tar.Header{
 		Name: exportVersionFilename,
@@ -57,17 +141,39 @@ func (db *DB) Export(w io.Writer) (count int64, err error) {
 		return 0, err
 	}
 
-	err = db.retrievalDataIndex.Iterate(func(item shed.Item) (stop bool, err error) {
+	var p ExportProgress
+	export := func(item shed.Item) error {
+		if len(o.BatchID) > 0 && !bytes.Equal(item.BatchID, o.BatchID) {
+			return nil
+		}
+		if o.PinnedOnly {
+			pinned, err := db.pinIndex.Has(item)
+			if err != nil {
+				return err
+			}
+			if !pinned {
+				return nil
+			}
+		}
+
+		item, err := db.retrievalDataIndex.Get(item)
+		if err != nil {
+			if errors.Is(err, leveldb.ErrNotFound) {
+				// removed in the meantime
+				return nil
+			}
+			return err
+		}
 
 		loc, err := sharky.LocationFromBinary(item.Location)
 		if err != nil {
-			return false, err
+			return err
 		}
 
 		data := make([]byte, loc.Length)
 		err = db.sharky.Read(context.TODO(), loc, data)
 		if err != nil {
-			return false, err
+			return err
 		}
 
 		hdr := &tar.Header{
@@ -77,7 +183,7 @@ func (db *DB) Export(w io.Writer) (count int64, err error) {
 		}
 
 		if err := tw.WriteHeader(hdr); err != nil {
-			return false, err
+			return err
 		}
 		write := func(buf []byte) {
 			if err != nil {
@@ -91,140 +197,182 @@ func (db *DB) Export(w io.Writer) (count int64, err error) {
 		write(item.Sig)
 		write(data)
 		if err != nil {
-			return false, err
+			return err
 		}
 
 		count++
-		return false, nil
-	}, nil)
+		return nil
+	}
+
+	if !o.binFiltered() {
+		err = db.retrievalDataIndex.Iterate(func(item shed.Item) (stop bool, err error) {
+			if err := export(item); err != nil {
+				return true, err
+			}
+			if o.Progress != nil && p.Count != count {
+				p.Count = count
+				o.Progress(p)
+			}
+			return false, nil
+		}, nil)
+		return count, err
+	}
 
-	return count, err
+	to := int(swarm.MaxPO) + 1
+	if o.ToPO > 0 && int(o.ToPO) < to {
+		to = int(o.ToPO)
+	}
+	for bin := int(o.FromPO); bin < to; bin++ {
+		since := o.Since[uint8(bin)]
+		err = db.pullIndex.Iterate(func(item shed.Item) (stop bool, err error) {
+			if item.BinID < since {
+				return false, nil
+			}
+			if err := export(item); err != nil {
+				return true, err
+			}
+			if o.Progress != nil && p.Count != count {
+				p.Count, p.Bin, p.BinID = count, uint8(bin), item.BinID
+				o.Progress(p)
+			}
+			return false, nil
+		}, &shed.IterateOptions{
+			Prefix: []byte{uint8(bin)},
+		})
+		if err != nil {
+			return count, err
+		}
+	}
+
+	return count, nil
 }
 
-// Import reads a tar structured data from the reader and
-// stores chunks in the database. It returns the number of
-// chunks imported.
-func (db *DB) Import(ctx context.Context, r io.Reader) (count int64, err error) {
+// Import reads a tar structured data, optionally gzip compressed, from the
+// reader and stores chunks in the database. Chunks that are already stored
+// are skipped. It returns the number of chunks imported.
+func (db *DB) Import(ctx context.Context, r io.Reader, o *ImportOptions) (count int64, err error) {
+	if o == nil {
+		o = new(ImportOptions)
+	}
+
+	br := bufio.NewReader(r)
+	if magic, err := br.Peek(len(gzipMagic)); err == nil && bytes.Equal(magic, gzipMagic) {
+		gr, err := gzip.NewReader(br)
+		if err != nil {
+			return 0, err
+		}
+		defer gr.Close()
+		r = gr
+	} else {
+		r = br
+	}
 	tr := tar.NewReader(r)
 
-	errC := make(chan error)
-	doneC := make(chan struct{})
-	tokenPool := make(chan struct{}, 100)
-	var wg sync.WaitGroup
-	go func() {
-		var (
-			firstFile = true
-
-			// if exportVersionFilename file is not present
-			// assume current version
-			version = currentExportVersion
-		)
-		for {
-			hdr, err := tr.Next()
-			if err != nil {
-				if err == io.EOF {
-					break
-				}
-				select {
-				case errC <- err:
-				case <-ctx.Done():
-				}
-			}
-			if firstFile {
-				firstFile = false
-				if hdr.Name == exportVersionFilename {
-					data, err := io.ReadAll(tr)
-					if err != nil {
-						select {
-						case errC <- err:
-						case <-ctx.Done():
-						}
-					}
-					version = string(data)
-					continue
-				}
-			}
+	var (
+		p     ImportProgress
+		chs   []swarm.Chunk
+		first = true
 
-			if len(hdr.Name) != 64 {
-				db.logger.Warning("export: ignoring non-chunk file", "name", hdr.Name)
-				continue
-			}
+		// if exportVersionFilename file is not present
+		// assume current version
+		version = currentExportVersion
+	)
 
-			keybytes, err := hex.DecodeString(hdr.Name)
-			if err != nil {
-				db.logger.Warning("export: ignoring invalid chunk file", "name", hdr.Name, "error", err)
-				continue
+	// put stores the collected chunks in a single batch
+	put := func() error {
+		if len(chs) == 0 {
+			return nil
+		}
+		exist, err := db.Put(ctx, storage.ModePutUpload, chs...)
+		if err != nil {
+			return err
+		}
+		for _, e := range exist {
+			if e {
+				p.Existing++
+			} else {
+				p.Imported++
 			}
+		}
+		p.Processed += int64(len(chs))
+		chs = chs[:0]
+		if o.Progress != nil {
+			o.Progress(p)
+		}
+		return nil
+	}
 
-			rawdata, err := io.ReadAll(tr)
-			if err != nil {
-				select {
-				case errC <- err:
-				case <-ctx.Done():
-				}
-			}
-			stamp := new(postage.Stamp)
-			err = stamp.UnmarshalBinary(rawdata[:postage.StampSize])
-			if err != nil {
-				select {
-				case errC <- err:
-				case <-ctx.Done():
-				}
+	for {
+		if err := ctx.Err(); err != nil {
+			return p.Imported, err
+		}
+
+		hdr, err := tr.Next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
 			}
-			data := rawdata[postage.StampSize:]
-			key := swarm.NewAddress(keybytes)
-
-			var ch swarm.Chunk
-			switch version {
-			case currentExportVersion:
-				ch = swarm.NewChunk(key, data).WithStamp(stamp)
-			default:
-				select {
-				case errC <- fmt.Errorf("unsupported export data version %q", version):
-				case <-ctx.Done():
+			return p.Imported, err
+		}
+		if first {
+			first = false
+			if hdr.Name == exportVersionFilename {
+				data, err := io.ReadAll(tr)
+				if err != nil {
+					return p.Imported, err
 				}
+				version = string(data)
+				continue
 			}
-			tokenPool <- struct{}{}
-			wg.Add(1)
-
-			go func() {
-				_, err := db.Put(ctx, storage.ModePutUpload, ch)
-				select {
-				case errC <- err:
-				case <-ctx.Done():
-					wg.Done()
-					<-tokenPool
-				default:
-					_, err := db.Put(ctx, storage.ModePutUpload, ch)
-					if err != nil {
-						errC <- err
-					}
-					wg.Done()
-					<-tokenPool
-				}
-			}()
+		}
 
-			count++
+		if len(hdr.Name) != 64 {
+			db.logger.Warning("export: ignoring non-chunk file", "name", hdr.Name)
+			continue
 		}
-		wg.Wait()
-		close(doneC)
-	}()
 
-	// wait for all chunks to be stored
-	for {
-		select {
-		case err := <-errC:
-			if err != nil {
-				return count, err
-			}
-		case <-ctx.Done():
-			return count, ctx.Err()
+		keybytes, err := hex.DecodeString(hdr.Name)
+		if err != nil {
+			db.logger.Warning("export: ignoring invalid chunk file", "name", hdr.Name, "error", err)
+			continue
+		}
+
+		if p.Processed < o.Skip {
+			p.Processed++
+			continue
+		}
+
+		rawdata, err := io.ReadAll(tr)
+		if err != nil {
+			return p.Imported, err
+		}
+		if len(rawdata) < postage.StampSize {
+			return p.Imported, fmt.Errorf("chunk %s: invalid data size %d", hdr.Name, len(rawdata))
+		}
+		stamp := new(postage.Stamp)
+		err = stamp.UnmarshalBinary(rawdata[:postage.StampSize])
+		if err != nil {
+			return p.Imported, err
+		}
+		data := rawdata[postage.StampSize:]
+		key := swarm.NewAddress(keybytes)
+
+		switch version {
+		case currentExportVersion:
+			chs = append(chs, swarm.NewChunk(key, data).WithStamp(stamp))
 		default:
-			select {
-			case <-doneC:
-				return count, nil
-			default:
+			return p.Imported, fmt.Errorf("unsupported export data version %q", version)
+		}
+
+		if len(chs) == importBatchSize {
+			if err := put(); err != nil {
+				return p.Imported, err
 			}
 		}
 	}
+
+	if err := put(); err != nil {
+		return p.Imported, err
+	}
+	return p.Imported, nil
 }
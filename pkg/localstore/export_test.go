@@ -50,7 +50,7 @@ func TestExportImport(t *testing.T) {
 
 	var buf bytes.Buffer
 
-	c, err := db1.Export(&buf)
+	c, err := db1.Export(&buf, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -61,7 +61,7 @@ func TestExportImport(t *testing.T) {
 
 	db2 := newTestDB(t, nil)
 
-	c, err = db2.Import(context.Background(), &buf)
+	c, err = db2.Import(context.Background(), &buf, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -85,3 +85,156 @@ func TestExportImport(t *testing.T) {
 		}
 	}
 }
+
+// TestExportFiltered validates that the export options
+// select the expected subsets of chunks.
+func TestExportFiltered(t *testing.T) {
+	db := newTestDB(t, nil)
+	ctx := context.Background()
+
+	var chunkCount = 100
+
+	chunks := make([]swarm.Chunk, chunkCount)
+	bins := make(map[uint8]int)
+	for i := range chunks {
+		chunks[i] = generateTestRandomChunk()
+		if _, err := db.Put(ctx, storage.ModePutUpload, chunks[i]); err != nil {
+			t.Fatal(err)
+		}
+		bins[db.po(chunks[i].Address())]++
+	}
+	for _, ch := range chunks[:3] {
+		if err := db.Set(ctx, storage.ModeSetPin, ch.Address()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var (
+		inFirstBin = bins[0]
+		nonEmpty   = len(bins)
+	)
+
+	for _, tc := range []struct {
+		name string
+		o    *ExportOptions
+		want int64
+	}{
+		{
+			name: "batch",
+			o:    &ExportOptions{BatchID: chunks[0].Stamp().BatchID()},
+			want: 1,
+		},
+		{
+			name: "pinned",
+			o:    &ExportOptions{PinnedOnly: true},
+			want: 3,
+		},
+		{
+			name: "first bin",
+			o:    &ExportOptions{ToPO: 1},
+			want: int64(inFirstBin),
+		},
+		{
+			name: "other bins",
+			o:    &ExportOptions{FromPO: 1},
+			want: int64(chunkCount - inFirstBin),
+		},
+		{
+			name: "since",
+			o: &ExportOptions{Since: func() map[uint8]uint64 {
+				since := make(map[uint8]uint64)
+				for bin := range bins {
+					since[bin] = 2
+				}
+				return since
+			}()},
+			want: int64(chunkCount - nonEmpty),
+		},
+		{
+			name: "compressed",
+			o:    &ExportOptions{Compress: true},
+			want: int64(chunkCount),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				buf      bytes.Buffer
+				progress int64
+			)
+			tc.o.Progress = func(p ExportProgress) {
+				progress = p.Count
+			}
+			c, err := db.Export(&buf, tc.o)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if c != tc.want {
+				t.Fatalf("got export count %v, want %v", c, tc.want)
+			}
+			if progress != tc.want {
+				t.Fatalf("got progress count %v, want %v", progress, tc.want)
+			}
+
+			c, err = newTestDB(t, nil).Import(ctx, &buf, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if c != tc.want {
+				t.Fatalf("got import count %v, want %v", c, tc.want)
+			}
+		})
+	}
+}
+
+// TestImportResume validates that an import can be resumed by
+// skipping the processed chunks and that existing chunks are skipped.
+func TestImportResume(t *testing.T) {
+	db1 := newTestDB(t, nil)
+	ctx := context.Background()
+
+	var chunkCount = 250
+
+	for i := 0; i < chunkCount; i++ {
+		if _, err := db1.Put(ctx, storage.ModePutUpload, generateTestRandomChunk()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if _, err := db1.Export(&buf, &ExportOptions{Compress: true}); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	db2 := newTestDB(t, nil)
+
+	var progress ImportProgress
+	o := &ImportOptions{
+		Skip: 120,
+		Progress: func(p ImportProgress) {
+			progress = p
+		},
+	}
+	c, err := db2.Import(ctx, bytes.NewReader(data), o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := int64(chunkCount - 120); c != want {
+		t.Fatalf("got import count %v, want %v", c, want)
+	}
+	if want := (ImportProgress{Processed: int64(chunkCount), Imported: int64(chunkCount - 120)}); progress != want {
+		t.Fatalf("got progress %+v, want %+v", progress, want)
+	}
+
+	o.Skip = 0
+	c, err = db2.Import(ctx, bytes.NewReader(data), o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c != 120 {
+		t.Fatalf("got import count %v, want %v", c, 120)
+	}
+	if want := (ImportProgress{Processed: int64(chunkCount), Imported: 120, Existing: int64(chunkCount - 120)}); progress != want {
+		t.Fatalf("got progress %+v, want %+v", progress, want)
+	}
+}
@@ -119,6 +119,12 @@ func (db *DB) collectGarbage() (evicted uint64, done bool, err error) {
 	if err != nil {
 		return 0, true, err
 	}
+	if db.DiskSpaceState() != DiskSpaceOK {
+		// free a part of the cache on every disk space check
+		if t := uint64(float64(gcSize) * gcTargetRatio); t < target {
+			target = t
+		}
+	}
 	if gcSize == target {
 		return 0, true, nil
 	}
@@ -129,7 +135,7 @@ func (db *DB) collectGarbage() (evicted uint64, done bool, err error) {
 
 	candidates := make([]shed.Item, 0, gcBatchSize)
 
-	err = db.gcIndex.Iterate(func(item shed.Item) (stop bool, err error) {
+	err = db.gcPolicy.iterate(func(item shed.Item) (stop bool, err error) {
 		if first {
 			totalTimeMetric(db.metrics.TotalTimeGCFirstItem, start)
 			first = false
@@ -142,7 +148,7 @@ func (db *DB) collectGarbage() (evicted uint64, done bool, err error) {
 		candidates = append(candidates, item)
 
 		return false, nil
-	}, nil)
+	})
 	if err != nil {
 		return 0, false, err
 	}
@@ -196,6 +202,8 @@ func (db *DB) collectGarbage() (evicted uint64, done bool, err error) {
 		db.metrics.GCStoreTimeStamps.Set(float64(item.StoreTimestamp))
 		db.metrics.GCStoreAccessTimeStamps.Set(float64(item.AccessTimestamp))
 
+		db.chunkCache.remove(swarm.NewAddress(item.Address))
+
 		// delete from retrieve, pull, gc
 		err = db.retrievalDataIndex.DeleteInBatch(batch, item)
 		if err != nil {
@@ -217,6 +225,10 @@ func (db *DB) collectGarbage() (evicted uint64, done bool, err error) {
 		if err != nil {
 			return 0, false, err
 		}
+		err = db.gcPolicy.evict(batch, item)
+		if err != nil {
+			return 0, false, err
+		}
 		err = db.postageChunksIndex.DeleteInBatch(batch, item)
 		if err != nil {
 			return 0, false, err
@@ -394,6 +406,12 @@ func (db *DB) evictReserve() (totalEvicted uint64, done bool, err error) {
 	if err != nil {
 		return 0, false, err
 	}
+	if db.DiskSpaceState() != DiskSpaceOK && reserveSizeStart > reserveEvictionBatch {
+		// evict a batch from the reserve on every disk space check
+		if t := reserveSizeStart - reserveEvictionBatch; t < target {
+			target = t
+		}
+	}
 	if reserveSizeStart <= target {
 		return 0, true, nil
 	}
@@ -0,0 +1,354 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package localstore
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/ethersphere/bee/pkg/postage"
+	"github.com/ethersphere/bee/pkg/shed"
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+const (
+	// GCPolicyLRU evicts the least recently accessed chunks first.
+	GCPolicyLRU = "lru"
+	// GCPolicyLFU evicts the least frequently accessed chunks first.
+	// Frequencies are aged, so that chunks which were popular only in
+	// the past are eventually evicted as well.
+	GCPolicyLFU = "lfu"
+)
+
+// ErrUnknownGCPolicy is returned when the garbage collection policy is not supported.
+var ErrUnknownGCPolicy = errors.New("unknown gc policy")
+
+// gcPolicy defines the order in which the chunks from the gc index are
+// evicted. The gc index remains the set of the collectable chunks and the
+// policy keeps its own indexes up to date with it. The methods that take a
+// batch must be called under the batchMu lock.
+type gcPolicy interface {
+	// name returns the name under which the policy is persisted.
+	name() string
+	// put is called when the item is added to the gc index.
+	put(batch *leveldb.Batch, item shed.Item) error
+	// delete is called when the item is removed from the gc index.
+	delete(batch *leveldb.Batch, item shed.Item) error
+	// access is called with the new access timestamp of the
+	// item when the chunk from the gc index is requested.
+	access(batch *leveldb.Batch, item shed.Item) error
+	// evict is called for every item provided by iterate
+	// which is removed from the gc index by the garbage collection.
+	evict(batch *leveldb.Batch, item shed.Item) error
+	// iterate calls fn with the gc index items in the eviction order.
+	iterate(fn shed.IndexIterFunc) error
+	// reset removes all persisted state of the policy.
+	reset() error
+}
+
+// newGCPolicy constructs the garbage collection policy with the provided name.
+func (db *DB) newGCPolicy(name string) (gcPolicy, error) {
+	switch name {
+	case GCPolicyLRU:
+		return &lruGCPolicy{db: db}, nil
+	case GCPolicyLFU:
+		return newLFUGCPolicy(db)
+	}
+	return nil, fmt.Errorf("%q: %w", name, ErrUnknownGCPolicy)
+}
+
+// initGCPolicy sets up the garbage collection policy with the provided name
+// or, if the name is empty, the policy the database was used with before.
+// Databases created before the policies were introduced are considered to use
+// the LRU policy. If the policy changes, the indexes of the new policy are
+// rebuilt from the gc index.
+func (db *DB) initGCPolicy(name string) error {
+	field, err := db.shed.NewStringField("gc-policy")
+	if err != nil {
+		return err
+	}
+	current, err := field.Get()
+	if err != nil && !errors.Is(err, leveldb.ErrNotFound) {
+		return err
+	}
+	if current == "" {
+		current = GCPolicyLRU
+	}
+	if name == "" {
+		name = current
+	}
+	db.gcPolicy, err = db.newGCPolicy(name)
+	if err != nil {
+		return err
+	}
+	if name == current {
+		return field.Put(name)
+	}
+
+	db.logger.Info("migrating gc policy", "from", current, "to", name)
+
+	previous, err := db.newGCPolicy(current)
+	if err != nil {
+		return err
+	}
+	if err := previous.reset(); err != nil {
+		return fmt.Errorf("reset %s gc policy: %w", current, err)
+	}
+	if err := db.gcPolicy.reset(); err != nil {
+		return fmt.Errorf("reset %s gc policy: %w", db.gcPolicy.name(), err)
+	}
+
+	batch := new(leveldb.Batch)
+	var count uint64
+	err = db.gcIndex.Iterate(func(item shed.Item) (stop bool, err error) {
+		if err := db.gcPolicy.put(batch, item); err != nil {
+			return true, err
+		}
+		if count++; count%gcBatchSize == 0 {
+			if err := db.shed.WriteBatch(batch); err != nil {
+				return true, err
+			}
+			batch.Reset()
+		}
+		return false, nil
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("rebuild %s gc policy: %w", db.gcPolicy.name(), err)
+	}
+	field.PutInBatch(batch, db.gcPolicy.name())
+	if err := db.shed.WriteBatch(batch); err != nil {
+		return err
+	}
+	db.metrics.GCPolicyMigrationCounter.Inc()
+
+	db.logger.Info("gc policy migrated", "policy", db.gcPolicy.name(), "chunks", count)
+	return nil
+}
+
+// lruGCPolicy evicts the chunks in the order of the gc index,
+// which is sorted by the access timestamp.
+type lruGCPolicy struct {
+	db *DB
+}
+
+func (p *lruGCPolicy) name() string { return GCPolicyLRU }
+
+func (p *lruGCPolicy) put(*leveldb.Batch, shed.Item) error { return nil }
+
+func (p *lruGCPolicy) delete(*leveldb.Batch, shed.Item) error { return nil }
+
+func (p *lruGCPolicy) access(*leveldb.Batch, shed.Item) error { return nil }
+
+func (p *lruGCPolicy) evict(*leveldb.Batch, shed.Item) error { return nil }
+
+func (p *lruGCPolicy) iterate(fn shed.IndexIterFunc) error {
+	return p.db.gcIndex.Iterate(fn, nil)
+}
+
+func (p *lruGCPolicy) reset() error { return nil }
+
+// lfuGCPolicy evicts the chunks with the lowest access frequency first and
+// the least recently accessed ones among the chunks of the same frequency.
+// Frequencies are aged by dynamic aging: the chunks start with the frequency
+// of the last evicted chunk and every access raises the frequency to at least
+// that value, so that the chunks which are no longer requested fall behind
+// and get evicted.
+type lfuGCPolicy struct {
+	db *DB
+	// frequencyIndex holds the gc index items sorted by the frequency.
+	frequencyIndex shed.Index
+	// addressIndex maps the chunk address to the key of its frequency index entry.
+	addressIndex shed.Index
+	// age is the frequency of the last evicted chunk.
+	age shed.Uint64Field
+}
+
+func newLFUGCPolicy(db *DB) (p *lfuGCPolicy, err error) {
+	p = &lfuGCPolicy{db: db}
+
+	p.frequencyIndex, err = db.shed.NewIndex("Frequency|AccessTimestamp|BinID|Hash->BatchID|BatchIndex", shed.IndexFuncs{
+		EncodeKey: func(fields shed.Item) (key []byte, err error) {
+			b := make([]byte, 24, 24+len(fields.Address))
+			binary.BigEndian.PutUint64(b[:8], fields.Frequency)
+			binary.BigEndian.PutUint64(b[8:16], uint64(fields.AccessTimestamp))
+			binary.BigEndian.PutUint64(b[16:24], fields.BinID)
+			key = append(b, fields.Address...)
+			return key, nil
+		},
+		DecodeKey: func(key []byte) (e shed.Item, err error) {
+			e.Frequency = binary.BigEndian.Uint64(key[:8])
+			e.AccessTimestamp = int64(binary.BigEndian.Uint64(key[8:16]))
+			e.BinID = binary.BigEndian.Uint64(key[16:24])
+			e.Address = key[24:]
+			return e, nil
+		},
+		EncodeValue: func(fields shed.Item) (value []byte, err error) {
+			value = make([]byte, 40)
+			copy(value, fields.BatchID)
+			copy(value[32:], fields.Index)
+			return value, nil
+		},
+		DecodeValue: func(keyItem shed.Item, value []byte) (e shed.Item, err error) {
+			e.BatchID = make([]byte, 32)
+			copy(e.BatchID, value[:32])
+			e.Index = make([]byte, postage.IndexSize)
+			copy(e.Index, value[32:])
+			return e, nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	p.addressIndex, err = db.shed.NewIndex("Hash->Frequency|AccessTimestamp|BinID", shed.IndexFuncs{
+		EncodeKey: func(fields shed.Item) (key []byte, err error) {
+			return fields.Address, nil
+		},
+		DecodeKey: func(key []byte) (e shed.Item, err error) {
+			e.Address = key
+			return e, nil
+		},
+		EncodeValue: func(fields shed.Item) (value []byte, err error) {
+			value = make([]byte, 24)
+			binary.BigEndian.PutUint64(value[:8], fields.Frequency)
+			binary.BigEndian.PutUint64(value[8:16], uint64(fields.AccessTimestamp))
+			binary.BigEndian.PutUint64(value[16:24], fields.BinID)
+			return value, nil
+		},
+		DecodeValue: func(keyItem shed.Item, value []byte) (e shed.Item, err error) {
+			e.Frequency = binary.BigEndian.Uint64(value[:8])
+			e.AccessTimestamp = int64(binary.BigEndian.Uint64(value[8:16]))
+			e.BinID = binary.BigEndian.Uint64(value[16:24])
+			return e, nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	p.age, err = db.shed.NewUint64Field("gc-lfu-age")
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (p *lfuGCPolicy) name() string { return GCPolicyLFU }
+
+// lookup returns the current frequency index entry of the item.
+func (p *lfuGCPolicy) lookup(item shed.Item) (i shed.Item, found bool, err error) {
+	i, err = p.addressIndex.Get(item)
+	switch {
+	case err == nil:
+		return i, true, nil
+	case errors.Is(err, leveldb.ErrNotFound):
+		return i, false, nil
+	}
+	return i, false, err
+}
+
+// set replaces the current frequency index entry of the item, if any.
+func (p *lfuGCPolicy) set(batch *leveldb.Batch, item, current shed.Item, found bool) error {
+	if found {
+		if err := p.frequencyIndex.DeleteInBatch(batch, current); err != nil {
+			return err
+		}
+	}
+	if err := p.frequencyIndex.PutInBatch(batch, item); err != nil {
+		return err
+	}
+	return p.addressIndex.PutInBatch(batch, item)
+}
+
+func (p *lfuGCPolicy) put(batch *leveldb.Batch, item shed.Item) error {
+	current, found, err := p.lookup(item)
+	if err != nil {
+		return err
+	}
+	if found {
+		item.Frequency = current.Frequency
+	} else {
+		age, err := p.age.Get()
+		if err != nil {
+			return err
+		}
+		item.Frequency = age + 1
+	}
+	return p.set(batch, item, current, found)
+}
+
+func (p *lfuGCPolicy) delete(batch *leveldb.Batch, item shed.Item) error {
+	current, found, err := p.lookup(item)
+	if err != nil || !found {
+		return err
+	}
+	if err := p.frequencyIndex.DeleteInBatch(batch, current); err != nil {
+		return err
+	}
+	return p.addressIndex.DeleteInBatch(batch, current)
+}
+
+func (p *lfuGCPolicy) access(batch *leveldb.Batch, item shed.Item) error {
+	current, found, err := p.lookup(item)
+	if err != nil {
+		return err
+	}
+	age, err := p.age.Get()
+	if err != nil {
+		return err
+	}
+	item.Frequency = age
+	if found && current.Frequency > age {
+		item.Frequency = current.Frequency
+	}
+	item.Frequency++
+	p.db.metrics.GCLFUAccessCounter.Inc()
+	return p.set(batch, item, current, found)
+}
+
+func (p *lfuGCPolicy) evict(batch *leveldb.Batch, item shed.Item) error {
+	if err := p.frequencyIndex.DeleteInBatch(batch, item); err != nil {
+		return err
+	}
+	if err := p.addressIndex.DeleteInBatch(batch, item); err != nil {
+		return err
+	}
+	// items are evicted in the order of the frequency,
+	// so the last one in the batch sets the age
+	p.age.PutInBatch(batch, item.Frequency)
+	p.db.metrics.GCLFUAge.Set(float64(item.Frequency))
+	return nil
+}
+
+func (p *lfuGCPolicy) iterate(fn shed.IndexIterFunc) error {
+	return p.frequencyIndex.Iterate(fn, nil)
+}
+
+func (p *lfuGCPolicy) reset() error {
+	for _, index := range []shed.Index{p.frequencyIndex, p.addressIndex} {
+		for {
+			batch := new(leveldb.Batch)
+			var count uint64
+			err := index.Iterate(func(item shed.Item) (stop bool, err error) {
+				if err := index.DeleteInBatch(batch, item); err != nil {
+					return true, err
+				}
+				count++
+				return count == gcBatchSize, nil
+			}, nil)
+			if err != nil {
+				return err
+			}
+			if count == 0 {
+				break
+			}
+			if err := p.db.shed.WriteBatch(batch); err != nil {
+				return err
+			}
+		}
+	}
+	return p.age.Put(0)
+}
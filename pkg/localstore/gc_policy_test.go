@@ -0,0 +1,209 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package localstore
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/log"
+	"github.com/ethersphere/bee/pkg/shed"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// TestGCPolicy checks that the chunk accessed many times a long time ago
+// is evicted by the LRU policy and kept by the LFU policy.
+func TestGCPolicy(t *testing.T) {
+	for _, tc := range []struct {
+		policy   string
+		wantKept bool
+	}{
+		{policy: GCPolicyLRU, wantKept: false},
+		{policy: GCPolicyLFU, wantKept: true},
+	} {
+		tc := tc
+		t.Run(tc.policy, func(t *testing.T) {
+			var ts int64
+			t.Cleanup(setNow(func() int64 { return atomic.AddInt64(&ts, 1) }))
+			t.Cleanup(setWithinRadiusFunc(func(_ *DB, _ shed.Item) bool { return false }))
+
+			testHookCollectGarbageChan := make(chan uint64)
+			t.Cleanup(setTestHookCollectGarbage(func(collectedCount uint64) {
+				if collectedCount == 0 {
+					return
+				}
+				testHookCollectGarbageChan <- collectedCount
+			}))
+
+			db := newTestDB(t, &Options{
+				Capacity: 100,
+				GCPolicy: tc.policy,
+			})
+
+			addrs := make([]swarm.Address, 0)
+			for i := 0; i < int(db.cacheCapacity)-1; i++ {
+				addrs = append(addrs, putSyncedChunk(t, db))
+			}
+
+			// request the first chunk a few times and all the others once afterwards
+			for i := 0; i < 3; i++ {
+				accessChunk(t, db, addrs[0])
+			}
+			for _, addr := range addrs[1:] {
+				accessChunk(t, db, addr)
+			}
+
+			// trigger the garbage collection
+			addrs = append(addrs, putSyncedChunk(t, db))
+
+			gcTarget := db.gcTarget()
+			for {
+				select {
+				case <-testHookCollectGarbageChan:
+				case <-time.After(10 * time.Second):
+					t.Fatal("collect garbage timeout")
+				}
+				gcSize, err := db.gcSize.Get()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if gcSize == gcTarget {
+					break
+				}
+			}
+
+			t.Run("gc index count", newItemsCountTest(db.gcIndex, int(gcTarget)))
+
+			t.Run("gc size", newIndexGCSizeTest(db))
+
+			if p, ok := db.gcPolicy.(*lfuGCPolicy); ok {
+				t.Run("frequency index count", newItemsCountTest(p.frequencyIndex, int(gcTarget)))
+
+				t.Run("address index count", newItemsCountTest(p.addressIndex, int(gcTarget)))
+			}
+
+			kept, err := db.retrievalDataIndex.Has(addressToItem(addrs[0]))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if kept != tc.wantKept {
+				t.Fatalf("got chunk kept %v, want %v", kept, tc.wantKept)
+			}
+		})
+	}
+}
+
+// TestGCPolicyMigration checks that the indexes of the gc policy are
+// rebuilt when the database is opened with a different policy.
+func TestGCPolicyMigration(t *testing.T) {
+	t.Cleanup(setWithinRadiusFunc(func(_ *DB, _ shed.Item) bool { return false }))
+
+	dir := t.TempDir()
+	baseKey := make([]byte, 32)
+	if _, err := rand.Read(baseKey); err != nil {
+		t.Fatal(err)
+	}
+	logger := log.Noop
+
+	open := func(t *testing.T, policy string) *DB {
+		t.Helper()
+
+		db, err := New(dir, baseKey, nil, &Options{GCPolicy: policy}, logger)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() {
+			if err := db.Close(); err != nil {
+				t.Fatal(err)
+			}
+		})
+		return db
+	}
+
+	count := 10
+
+	t.Run("lru", func(t *testing.T) {
+		db := open(t, GCPolicyLRU)
+		for i := 0; i < count; i++ {
+			putSyncedChunk(t, db)
+		}
+	})
+
+	t.Run("lfu", func(t *testing.T) {
+		db := open(t, GCPolicyLFU)
+		p := db.gcPolicy.(*lfuGCPolicy)
+
+		t.Run("frequency index count", newItemsCountTest(p.frequencyIndex, count))
+
+		t.Run("address index count", newItemsCountTest(p.addressIndex, count))
+
+		accessChunk(t, db, putSyncedChunk(t, db))
+
+		t.Run("frequency index count after put", newItemsCountTest(p.frequencyIndex, count+1))
+	})
+
+	t.Run("keep", func(t *testing.T) {
+		db := open(t, "")
+		if got := db.gcPolicy.name(); got != GCPolicyLFU {
+			t.Fatalf("got policy %q, want %q", got, GCPolicyLFU)
+		}
+	})
+
+	t.Run("lru again", func(t *testing.T) {
+		db := open(t, GCPolicyLRU)
+		p, err := newLFUGCPolicy(db)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		t.Run("gc index count", newItemsCountTest(db.gcIndex, count+1))
+
+		t.Run("frequency index count", newItemsCountTest(p.frequencyIndex, 0))
+
+		t.Run("address index count", newItemsCountTest(p.addressIndex, 0))
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		_, err := New(dir, baseKey, nil, &Options{GCPolicy: "mru"}, logger)
+		if !errors.Is(err, ErrUnknownGCPolicy) {
+			t.Fatalf("got error %v, want %v", err, ErrUnknownGCPolicy)
+		}
+	})
+}
+
+// putSyncedChunk stores a new chunk as uploaded and synced,
+// which adds it to the gc index.
+func putSyncedChunk(t *testing.T, db *DB) swarm.Address {
+	t.Helper()
+
+	ch := generateTestRandomChunk()
+	unreserveChunkBatch(t, db, 0, ch)
+
+	if _, err := db.Put(context.Background(), storage.ModePutUpload, ch); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Set(context.Background(), storage.ModeSetSync, ch.Address()); err != nil {
+		t.Fatal(err)
+	}
+	return ch.Address()
+}
+
+// accessChunk updates the gc indexes as a chunk request does.
+func accessChunk(t *testing.T, db *DB, addr swarm.Address) {
+	t.Helper()
+
+	item, err := db.retrievalDataIndex.Get(addressToItem(addr))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.updateGC(item); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -110,6 +110,18 @@ type DB struct {
 	// garbage collection index
 	gcIndex shed.Index
 
+	// policy that defines the garbage collection order
+	gcPolicy gcPolicy
+
+	// in-memory cache of the requested chunks
+	chunkCache *chunkCache
+
+	// limits of the free disk space in bytes
+	freeSpaceSoftLimit uint64
+	freeSpaceHardLimit uint64
+	// current DiskSpaceState
+	diskSpaceState int32
+
 	// pin files Index
 	pinIndex shed.Index
 
@@ -179,6 +191,7 @@ type DB struct {
 	// are done
 	collectGarbageWorkerDone  chan struct{}
 	reserveEvictionWorkerDone chan struct{}
+	diskSpaceWorkerDone       chan struct{}
 
 	// wait for all subscriptions to finish before closing
 	// underlaying leveldb to prevent possible panics from
@@ -212,6 +225,21 @@ type Options struct {
 	// DisableSeeksCompaction toggles the seek driven compactions feature on leveldb
 	// and is passed on to shed.
 	DisableSeeksCompaction bool
+	// GCPolicy is the name of the policy that defines the order in which
+	// the cached chunks are garbage collected. If empty, the policy the
+	// database was used with before is kept, GCPolicyLRU for a new one.
+	GCPolicy string
+	// ChunkCacheSize is the size limit in bytes of the in-memory cache
+	// of the requested chunks. Zero disables the cache.
+	ChunkCacheSize uint64
+	// FreeSpaceSoftLimit is the free space in bytes on the filesystem of
+	// the database below which the chunks from syncing are rejected and
+	// the garbage collection is forced. Zero disables the limit.
+	FreeSpaceSoftLimit uint64
+	// FreeSpaceHardLimit is the free space in bytes on the filesystem of
+	// the database below which the uploaded chunks are rejected as well.
+	// Zero disables the limit.
+	FreeSpaceHardLimit uint64
 
 	// MetricsPrefix defines a prefix for metrics names.
 	MetricsPrefix string
@@ -307,12 +335,18 @@ func New(path string, baseKey []byte, ss storage.StateStorer, o *Options, logger
 		close:                     make(chan struct{}),
 		collectGarbageWorkerDone:  make(chan struct{}),
 		reserveEvictionWorkerDone: make(chan struct{}),
+		diskSpaceWorkerDone:       make(chan struct{}),
 		metrics:                   newMetrics(),
 		logger:                    logger.WithName(loggerName).Register(),
 	}
 	if db.cacheCapacity == 0 {
 		db.cacheCapacity = defaultCacheCapacity
 	}
+	if o.ChunkCacheSize > 0 {
+		db.chunkCache = newChunkCache(o.ChunkCacheSize, &db.metrics)
+	}
+	db.freeSpaceSoftLimit = o.FreeSpaceSoftLimit
+	db.freeSpaceHardLimit = o.FreeSpaceHardLimit
 
 	capacityMB := float64((db.cacheCapacity+uint64(batchstore.Capacity))*swarm.ChunkSize) * 9.5367431640625e-7
 
@@ -660,9 +694,18 @@ func New(path string, baseKey []byte, ss storage.StateStorer, o *Options, logger
 		return nil, err
 	}
 
+	if err := db.initGCPolicy(o.GCPolicy); err != nil {
+		return nil, multierror.Append(fmt.Errorf("gc policy: %w", err), db.sharky.Close(), db.shed.Close(), db.fdirtyCloser())
+	}
+
 	// start garbage collection worker
 	go db.collectGarbageWorker()
 	go db.reserveEvictionWorker()
+	if path != "" && (db.freeSpaceSoftLimit > 0 || db.freeSpaceHardLimit > 0) {
+		go db.diskSpaceWorker(path)
+	} else {
+		close(db.diskSpaceWorkerDone)
+	}
 	return db, nil
 }
 
@@ -679,6 +722,7 @@ func (db *DB) Close() error {
 		// return before closing the shed
 		<-db.collectGarbageWorkerDone
 		<-db.reserveEvictionWorkerDone
+		<-db.diskSpaceWorkerDone
 		close(done)
 	}()
 
@@ -61,10 +61,31 @@ type metrics struct {
 	GCStoreTimeStamps       prometheus.Gauge
 	GCStoreAccessTimeStamps prometheus.Gauge
 
+	GCPolicyMigrationCounter prometheus.Counter
+	GCLFUAccessCounter       prometheus.Counter
+	GCLFUAge                 prometheus.Gauge
+
 	ReserveSize              prometheus.Gauge
 	EvictReserveCounter      prometheus.Counter
 	EvictReserveErrorCounter prometheus.Counter
 	TotalTimeEvictReserve    prometheus.Counter
+
+	CompactCounter        prometheus.Counter
+	CompactErrorCounter   prometheus.Counter
+	CompactMovedCounter   prometheus.Counter
+	CompactReclaimedBytes prometheus.Counter
+	TotalTimeCompact      prometheus.Counter
+
+	ChunkCacheHits   prometheus.Counter
+	ChunkCacheMisses prometheus.Counter
+	ChunkCacheSize   prometheus.Gauge
+
+	DiskFreeBytes  prometheus.Gauge
+	DiskSpaceState prometheus.Gauge
+
+	BackupCounter      prometheus.Counter
+	BackupErrorCounter prometheus.Counter
+	TotalTimeBackup    prometheus.Counter
 }
 
 func newMetrics() metrics {
@@ -355,6 +376,24 @@ func newMetrics() metrics {
 			Name:      "gc_access_time_stamp",
 			Help:      "Access timestamp in Garbage collection iteration.",
 		}),
+		GCPolicyMigrationCounter: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "gc_policy_migration_count",
+			Help:      "Number of times the gc policy indexes were rebuilt.",
+		}),
+		GCLFUAccessCounter: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "gc_lfu_access_count",
+			Help:      "Number of chunk accesses recorded by the lfu gc policy.",
+		}),
+		GCLFUAge: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "gc_lfu_age",
+			Help:      "Frequency of the last chunk evicted by the lfu gc policy.",
+		}),
 		ReserveSize: prometheus.NewGauge(prometheus.GaugeOpts{
 			Namespace: m.Namespace,
 			Subsystem: subsystem,
@@ -379,6 +418,84 @@ func newMetrics() metrics {
 			Name:      "evict_reserve_total_time",
 			Help:      "total time spent evicting from reserve",
 		}),
+		CompactCounter: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "compact_count",
+			Help:      "number of times compaction ran",
+		}),
+		CompactErrorCounter: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "compact_err_count",
+			Help:      "number of times compaction got an error",
+		}),
+		CompactMovedCounter: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "compact_moved_count",
+			Help:      "number of chunks relocated by compaction",
+		}),
+		CompactReclaimedBytes: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "compact_reclaimed_bytes",
+			Help:      "number of bytes reclaimed by compaction",
+		}),
+		TotalTimeCompact: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "compact_total_time",
+			Help:      "total time spent compacting",
+		}),
+		ChunkCacheHits: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "chunk_cache_hits",
+			Help:      "Number of chunks served from the in-memory chunk cache.",
+		}),
+		ChunkCacheMisses: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "chunk_cache_misses",
+			Help:      "Number of chunks not found in the in-memory chunk cache.",
+		}),
+		ChunkCacheSize: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "chunk_cache_size",
+			Help:      "Size of the in-memory chunk cache in bytes.",
+		}),
+		DiskFreeBytes: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "disk_free_bytes",
+			Help:      "Free space on the filesystem of the database in bytes.",
+		}),
+		DiskSpaceState: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "disk_space_state",
+			Help:      "Free disk space state, 0 for ok, 1 below the soft limit and 2 below the hard limit.",
+		}),
+		BackupCounter: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "backup_count",
+			Help:      "number of times backup ran",
+		}),
+		BackupErrorCounter: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "backup_err_count",
+			Help:      "number of times backup got an error",
+		}),
+		TotalTimeBackup: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "backup_total_time",
+			Help:      "total time spent in backup",
+		}),
 	}
 }
 
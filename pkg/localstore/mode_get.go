@@ -58,8 +58,29 @@ func (db *DB) Get(ctx context.Context, mode storage.ModeGet, addr swarm.Address)
 // get returns Item from the retrieval index
 // and updates other indexes.
 func (db *DB) get(ctx context.Context, mode storage.ModeGet, addr swarm.Address) (out shed.Item, err error) {
-	item := addressToItem(addr)
+	out, ok := db.chunkCache.get(addr)
+	if !ok {
+		out, err = db.getItem(ctx, addressToItem(addr))
+		if err != nil {
+			return out, err
+		}
+	}
 
+	switch mode {
+	// update the access timestamp and gc index
+	case storage.ModeGetRequest:
+		db.updateGCItems(out)
+
+	// no updates to indexes
+	case storage.ModeGetSync, storage.ModeGetLookup:
+	default:
+		return out, ErrInvalidMode
+	}
+	return out, nil
+}
+
+// getItem returns Item from the retrieval index with the chunk data read from sharky.
+func (db *DB) getItem(ctx context.Context, item shed.Item) (out shed.Item, err error) {
 	out, err = db.retrievalDataIndex.Get(item)
 	if err != nil {
 		return out, err
@@ -75,17 +96,6 @@ func (db *DB) get(ctx context.Context, mode storage.ModeGet, addr swarm.Address)
 	if err != nil {
 		return out, err
 	}
-
-	switch mode {
-	// update the access timestamp and gc index
-	case storage.ModeGetRequest:
-		db.updateGCItems(out)
-
-	// no updates to indexes
-	case storage.ModeGetSync, storage.ModeGetLookup:
-	default:
-		return out, ErrInvalidMode
-	}
 	return out, nil
 }
 
@@ -135,6 +145,10 @@ func (db *DB) updateGC(item shed.Item) (err error) {
 		db.dirtyAddresses = append(db.dirtyAddresses, swarm.NewAddress(item.Address))
 	}
 
+	if err := db.cacheChunk(item); err != nil {
+		return err
+	}
+
 	batch := new(leveldb.Batch)
 
 	// update accessTimeStamp in retrieve, gc
@@ -168,6 +182,10 @@ func (db *DB) updateGC(item shed.Item) (err error) {
 		if err != nil {
 			return err
 		}
+		err = db.gcPolicy.access(batch, item)
+		if err != nil {
+			return err
+		}
 	} else if !errors.Is(err, leveldb.ErrNotFound) {
 		return err
 	}
@@ -63,26 +63,39 @@ func (db *DB) GetMulti(ctx context.Context, mode storage.ModeGet, addrs ...swarm
 // and updates other indexes.
 func (db *DB) getMulti(ctx context.Context, mode storage.ModeGet, addrs ...swarm.Address) (out []shed.Item, err error) {
 	out = make([]shed.Item, len(addrs))
+	missing := make([]shed.Item, 0, len(addrs))
 	for i, addr := range addrs {
-		out[i].Address = addr.Bytes()
+		item, ok := db.chunkCache.get(addr)
+		if !ok {
+			item = addressToItem(addr)
+			missing = append(missing, item)
+		}
+		out[i] = item
 	}
 
-	err = db.retrievalDataIndex.Fill(out)
+	err = db.retrievalDataIndex.Fill(missing)
 	if err != nil {
 		return nil, err
 	}
 
-	for i, item := range out {
+	for i, j := 0, 0; i < len(out) && j < len(missing); i++ {
+		if out[i].Data != nil {
+			continue
+		}
+		item := missing[j]
+		j++
+
 		l, err := sharky.LocationFromBinary(item.Location)
 		if err != nil {
 			return nil, err
 		}
 
-		out[i].Data = make([]byte, l.Length)
-		err = db.sharky.Read(ctx, l, out[i].Data)
+		item.Data = make([]byte, l.Length)
+		err = db.sharky.Read(ctx, l, item.Data)
 		if err != nil {
 			return nil, err
 		}
+		out[i] = item
 	}
 
 	switch mode {
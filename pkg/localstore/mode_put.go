@@ -43,6 +43,11 @@ func (db *DB) Put(ctx context.Context, mode storage.ModePut, chs ...swarm.Chunk)
 	db.metrics.ModePut.Inc()
 	defer totalTimeMetric(db.metrics.TotalTimePut, time.Now())
 
+	if err := db.checkDiskSpaceForPut(mode); err != nil {
+		db.metrics.ModePutFailure.Inc()
+		return nil, err
+	}
+
 	exist, err = db.put(ctx, mode, chs...)
 	if err != nil {
 		db.metrics.ModePutFailure.Inc()
@@ -542,6 +547,10 @@ func (db *DB) preserveOrCache(batch *leveldb.Batch, item shed.Item, forcePin, fo
 	if err != nil {
 		return 0, 0, err
 	}
+	err = db.gcPolicy.put(batch, item)
+	if err != nil {
+		return 0, 0, err
+	}
 	gcSizeChange++
 
 	return gcSizeChange, 0, nil
@@ -251,6 +251,8 @@ func (db *DB) setRemove(batch *leveldb.Batch, item shed.Item, check bool) (gcSiz
 	db.metrics.GCStoreTimeStamps.Set(float64(item.StoreTimestamp))
 	db.metrics.GCStoreAccessTimeStamps.Set(float64(item.AccessTimestamp))
 
+	db.chunkCache.remove(swarm.NewAddress(item.Address))
+
 	err = db.retrievalDataIndex.DeleteInBatch(batch, item)
 	if err != nil {
 		return 0, err
@@ -288,6 +290,10 @@ func (db *DB) setRemove(batch *leveldb.Batch, item shed.Item, check bool) (gcSiz
 	if err != nil {
 		return 0, err
 	}
+	err = db.gcPolicy.delete(batch, item)
+	if err != nil {
+		return 0, err
+	}
 	return -1, nil
 }
 
@@ -326,6 +332,10 @@ func (db *DB) setPin(batch *leveldb.Batch, item shed.Item) (gcSizeChange int64,
 			if err != nil {
 				return 0, err
 			}
+			err = db.gcPolicy.delete(batch, item)
+			if err != nil {
+				return 0, err
+			}
 			gcSizeChange = -1
 		}
 	}
@@ -399,6 +409,10 @@ func (db *DB) setUnpin(batch *leveldb.Batch, addr swarm.Address) (gcSizeChange i
 	if err != nil {
 		return 0, err
 	}
+	err = db.gcPolicy.put(batch, item)
+	if err != nil {
+		return 0, err
+	}
 
 	gcSizeChange++
 	return gcSizeChange, nil
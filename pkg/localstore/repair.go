@@ -0,0 +1,138 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package localstore
+
+import (
+	"bytes"
+	"context"
+	"errors"
+
+	"github.com/ethersphere/bee/pkg/cac"
+	"github.com/ethersphere/bee/pkg/sharky"
+	"github.com/ethersphere/bee/pkg/shed"
+	"github.com/ethersphere/bee/pkg/soc"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+// ErrNotCorrupted is returned by ReplaceCorrupted
+// when the data of the chunk is readable and valid.
+var ErrNotCorrupted = errors.New("chunk not corrupted")
+
+// ReplaceCorrupted replaces the unreadable or invalid data of the stored
+// chunk with the data of the provided chunk, which must be valid. Only the
+// location of the data is changed, the pin counter, the stamp and the gc
+// and reserve state of the chunk are kept as they are.
+func (db *DB) ReplaceCorrupted(ctx context.Context, ch swarm.Chunk) error {
+	if !cac.Valid(ch) && !soc.Valid(ch) {
+		return ErrChunkInvalid
+	}
+
+	item, err := db.retrievalDataIndex.Get(addressToItem(ch.Address()))
+	if err != nil {
+		if errors.Is(err, leveldb.ErrNotFound) {
+			return storage.ErrNotFound
+		}
+		return err
+	}
+
+	err = db.validateItem(ctx, item, nil)
+	switch {
+	case err == nil:
+		return ErrNotCorrupted
+	case !errors.Is(err, ErrChunkUnreadable) && !errors.Is(err, ErrChunkInvalid):
+		return err
+	}
+
+	loc, err := db.sharky.Write(ctx, ch.Data())
+	if err != nil {
+		return err
+	}
+	replaced, err := db.replaceLocation(item, loc)
+	if err != nil || !replaced {
+		if err := db.sharky.Release(ctx, loc); err != nil {
+			db.logger.Warning("failed releasing sharky location", "location", loc)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if !replaced {
+		// stored again in the meantime
+		return ErrNotCorrupted
+	}
+
+	if len(item.Location) == sharky.LocationSize {
+		if old, err := sharky.LocationFromBinary(item.Location); err == nil {
+			if err := db.sharky.Release(ctx, old); err != nil {
+				db.logger.Warning("failed releasing sharky location", "location", old)
+			}
+		}
+	}
+	return nil
+}
+
+// replaceLocation sets the location of the chunk data of the item,
+// provided it was not changed since it was validated, and drops the
+// corrupted data from the chunk cache.
+func (db *DB) replaceLocation(item shed.Item, loc sharky.Location) (replaced bool, err error) {
+	db.batchMu.Lock()
+	defer db.batchMu.Unlock()
+
+	i, err := db.retrievalDataIndex.Get(item)
+	if err != nil {
+		if errors.Is(err, leveldb.ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	if i.BinID != item.BinID || i.StoreTimestamp != item.StoreTimestamp || !bytes.Equal(i.Location, item.Location) {
+		return false, nil
+	}
+
+	i.Location, err = loc.MarshalBinary()
+	if err != nil {
+		return false, err
+	}
+	if err := db.retrievalDataIndex.Put(i); err != nil {
+		return false, err
+	}
+
+	db.chunkCache.remove(swarm.NewAddress(i.Address))
+
+	return true, nil
+}
+
+// ValidatePinned reads the data of the pinned chunks, which include the
+// chunks of the reserve, and calls fn with the address of every chunk with
+// unreadable or invalid data. Iteration stops if fn returns an error.
+func (db *DB) ValidatePinned(ctx context.Context, fn func(swarm.Address) error) error {
+	return db.pinIndex.Iterate(func(item shed.Item) (stop bool, err error) {
+		if err := ctx.Err(); err != nil {
+			return true, err
+		}
+		i, err := db.retrievalDataIndex.Get(item)
+		if err != nil {
+			if errors.Is(err, leveldb.ErrNotFound) {
+				// orphaned entry, reported by Validate
+				return false, nil
+			}
+			return true, err
+		}
+
+		err = db.validateItem(ctx, i, nil)
+		switch {
+		case err == nil:
+			return false, nil
+		case errors.Is(err, ErrChunkUnreadable), errors.Is(err, ErrChunkInvalid):
+			if err := fn(swarm.NewAddress(i.Address)); err != nil {
+				return true, err
+			}
+			return false, nil
+		}
+		return true, err
+	}, nil)
+}
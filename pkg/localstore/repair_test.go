@@ -0,0 +1,186 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package localstore
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+func TestRepair(t *testing.T) {
+	db := newTestDB(t, nil)
+	ctx := context.Background()
+
+	chunks := []swarm.Chunk{generateTestRandomChunk(), generateTestRandomChunk(), generateTestRandomChunk()}
+	if _, err := db.Put(ctx, storage.ModePutUpload, chunks...); err != nil {
+		t.Fatal(err)
+	}
+	// pin the first chunk twice and the second one once
+	for _, addr := range []swarm.Address{chunks[0].Address(), chunks[0].Address(), chunks[1].Address()} {
+		if err := db.Set(ctx, storage.ModeSetPin, addr); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// point the first and the last chunk to foreign data
+	for _, ch := range []swarm.Chunk{chunks[0], chunks[2]} {
+		item, err := db.retrievalDataIndex.Get(addressToItem(ch.Address()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		loc, err := db.sharky.Write(ctx, generateTestRandomChunk().Data())
+		if err != nil {
+			t.Fatal(err)
+		}
+		item.Location, err = loc.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := db.retrievalDataIndex.Put(item); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Run("validate pinned", func(t *testing.T) {
+		var corrupted []swarm.Address
+		err := db.ValidatePinned(ctx, func(addr swarm.Address) error {
+			corrupted = append(corrupted, addr)
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(corrupted) != 1 || !corrupted[0].Equal(chunks[0].Address()) {
+			t.Fatalf("got corrupted chunks %v, want %v", corrupted, chunks[0].Address())
+		}
+	})
+
+	t.Run("replace valid", func(t *testing.T) {
+		if err := db.ReplaceCorrupted(ctx, chunks[1]); !errors.Is(err, ErrNotCorrupted) {
+			t.Fatalf("got error %v, want %v", err, ErrNotCorrupted)
+		}
+	})
+
+	t.Run("replace with invalid", func(t *testing.T) {
+		invalid := swarm.NewChunk(chunks[0].Address(), generateTestRandomChunk().Data())
+		if err := db.ReplaceCorrupted(ctx, invalid); !errors.Is(err, ErrChunkInvalid) {
+			t.Fatalf("got error %v, want %v", err, ErrChunkInvalid)
+		}
+	})
+
+	t.Run("replace not found", func(t *testing.T) {
+		if err := db.ReplaceCorrupted(ctx, generateTestRandomChunk()); !errors.Is(err, storage.ErrNotFound) {
+			t.Fatalf("got error %v, want %v", err, storage.ErrNotFound)
+		}
+	})
+
+	t.Run("replace corrupted", func(t *testing.T) {
+		gcSize, err := db.gcSize.Get()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := db.ReplaceCorrupted(ctx, chunks[0]); err != nil {
+			t.Fatal(err)
+		}
+		ch, err := db.Get(ctx, storage.ModeGetLookup, chunks[0].Address())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(ch.Data(), chunks[0].Data()) {
+			t.Fatal("repaired chunk data mismatch")
+		}
+		item, err := db.pinIndex.Get(addressToItem(chunks[0].Address()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if item.PinCounter != 2 {
+			t.Fatalf("got pin counter %d, want 2", item.PinCounter)
+		}
+		if got, err := db.gcSize.Get(); err != nil || got != gcSize {
+			t.Fatalf("got gc size %d, error %v, want %d", got, err, gcSize)
+		}
+		if err := db.ReplaceCorrupted(ctx, chunks[0]); !errors.Is(err, ErrNotCorrupted) {
+			t.Fatalf("got error %v, want %v", err, ErrNotCorrupted)
+		}
+	})
+}
+
+// TestRepairChunkCache checks that the corrupted data
+// of the repaired chunk is not served from the chunk cache.
+func TestRepairChunkCache(t *testing.T) {
+	db := newTestDB(t, &Options{
+		ChunkCacheSize: 1024 * 1024,
+	})
+
+	updated := make(chan struct{}, 10)
+	t.Cleanup(setTestHookUpdateGC(func() {
+		updated <- struct{}{}
+	}))
+
+	ctx := context.Background()
+	ch := generateTestRandomChunk()
+	unreserveChunkBatch(t, db, 0, ch)
+	if _, err := db.Put(ctx, storage.ModePutUpload, ch); err != nil {
+		t.Fatal(err)
+	}
+
+	// point the chunk to foreign data
+	item, err := db.retrievalDataIndex.Get(addressToItem(ch.Address()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	loc, err := db.sharky.Write(ctx, generateTestRandomChunk().Data())
+	if err != nil {
+		t.Fatal(err)
+	}
+	item.Location, err = loc.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.retrievalDataIndex.Put(item); err != nil {
+		t.Fatal(err)
+	}
+
+	get := func(t *testing.T) []byte {
+		t.Helper()
+		got, err := db.Get(ctx, storage.ModeGetRequest, ch.Address())
+		if err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case <-updated:
+		case <-time.After(10 * time.Second):
+			t.Fatal("updateGC was not called after getting chunk with ModeGetRequest")
+		}
+		return got.Data()
+	}
+
+	if bytes.Equal(get(t), ch.Data()) {
+		t.Fatal("corrupted chunk data returned valid")
+	}
+	if _, ok := db.chunkCache.get(ch.Address()); !ok {
+		t.Fatal("corrupted chunk not cached")
+	}
+
+	if err := db.ReplaceCorrupted(ctx, ch); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := db.chunkCache.get(ch.Address()); ok {
+		t.Fatal("repaired chunk still cached")
+	}
+	if !bytes.Equal(get(t), ch.Data()) {
+		t.Fatal("repaired chunk data mismatch")
+	}
+	if !bytes.Equal(get(t), ch.Data()) {
+		t.Fatal("cached repaired chunk data mismatch")
+	}
+}
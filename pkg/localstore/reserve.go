@@ -42,6 +42,7 @@ func (db *DB) UnreserveBatch(id []byte, radius uint8) (evicted uint64, err error
 	)
 	unpin := func(item shed.Item) (stop bool, err error) {
 		addr := swarm.NewAddress(item.Address)
+		db.chunkCache.remove(addr)
 		c, err := db.setUnpin(batch, addr)
 		if err != nil {
 			if !errors.Is(err, leveldb.ErrNotFound) {
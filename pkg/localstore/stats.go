@@ -0,0 +1,138 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package localstore
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/ethersphere/bee/pkg/sharky"
+	"github.com/ethersphere/bee/pkg/shed"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// BinStats holds the chunk counts of a proximity order bin.
+type BinStats struct {
+	PO     uint8  `json:"po"`
+	Chunks uint64 `json:"chunks"`
+	Pinned uint64 `json:"pinned"`
+}
+
+// ShardStats holds the slot usage of a sharky shard.
+type ShardStats struct {
+	Shard uint8 `json:"shard"`
+	// Slots is the number of slots spanned by the shard file.
+	Slots     uint64 `json:"slots"`
+	UsedSlots uint64 `json:"usedSlots"`
+	FreeSlots uint64 `json:"freeSlots"`
+	// StoredBytes is the total length of the stored chunks.
+	StoredBytes uint64 `json:"storedBytes"`
+	// Utilisation is the ratio of the used slots.
+	Utilisation float64 `json:"utilisation"`
+	// Fragmentation is the ratio of the shard file
+	// which is not holding chunk data.
+	Fragmentation float64 `json:"fragmentation"`
+}
+
+// Stats describes the distribution of the stored chunks
+// and the usage of the storage.
+type Stats struct {
+	Chunks          uint64 `json:"chunks"`
+	Pinned          uint64 `json:"pinned"`
+	GCSize          uint64 `json:"gcSize"`
+	CacheCapacity   uint64 `json:"cacheCapacity"`
+	ReserveSize     uint64 `json:"reserveSize"`
+	ReserveCapacity uint64 `json:"reserveCapacity"`
+	StorageRadius   uint8  `json:"storageRadius"`
+	// OutsideRadius is the number of chunks with proximity
+	// order lower than the storage radius.
+	OutsideRadius uint64            `json:"outsideRadius"`
+	Bins          []BinStats        `json:"bins"`
+	Batches       map[string]uint64 `json:"batches"`
+	Shards        []ShardStats      `json:"shards"`
+}
+
+// Stats iterates over the indexes and reports the chunk counts per
+// proximity order bin, postage batch and pin state, the gc and reserve
+// sizes and the slot usage of the sharky shards. The chunks with proximity
+// order lower than the storage radius are counted as outside of it.
+func (db *DB) Stats(ctx context.Context, storageRadius uint8) (*Stats, error) {
+	s := &Stats{
+		CacheCapacity:   db.cacheCapacity,
+		ReserveCapacity: db.reserveCapacity,
+		StorageRadius:   storageRadius,
+		Bins:            make([]BinStats, swarm.MaxBins),
+		Batches:         make(map[string]uint64),
+		Shards:          make([]ShardStats, sharkyNoOfShards),
+	}
+	for i := range s.Bins {
+		s.Bins[i].PO = uint8(i)
+	}
+
+	err := db.retrievalDataIndex.Iterate(func(item shed.Item) (stop bool, err error) {
+		if err := ctx.Err(); err != nil {
+			return true, err
+		}
+		s.Chunks++
+		po := db.po(swarm.NewAddress(item.Address))
+		s.Bins[po].Chunks++
+		if po < storageRadius {
+			s.OutsideRadius++
+		}
+		s.Batches[hex.EncodeToString(item.BatchID)]++
+
+		loc, err := sharky.LocationFromBinary(item.Location)
+		if err != nil {
+			return true, err
+		}
+		s.Shards[loc.Shard].UsedSlots++
+		s.Shards[loc.Shard].StoredBytes += uint64(loc.Length)
+		return false, nil
+	}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("iterate retrieval data index: %w", err)
+	}
+
+	err = db.pinIndex.Iterate(func(item shed.Item) (stop bool, err error) {
+		if err := ctx.Err(); err != nil {
+			return true, err
+		}
+		s.Pinned++
+		s.Bins[db.po(swarm.NewAddress(item.Address))].Pinned++
+		return false, nil
+	}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("iterate pin index: %w", err)
+	}
+
+	if s.GCSize, err = db.gcSize.Get(); err != nil {
+		return nil, fmt.Errorf("gc size: %w", err)
+	}
+	if s.ReserveSize, err = db.reserveSize.Get(); err != nil {
+		return nil, fmt.Errorf("reserve size: %w", err)
+	}
+
+	for i := range s.Shards {
+		shard := &s.Shards[i]
+		shard.Shard = uint8(i)
+
+		size, err := db.sharky.ShardSize(uint8(i))
+		if err != nil {
+			return nil, fmt.Errorf("shard %d size: %w", i, err)
+		}
+		shard.Slots = uint64((size + swarm.SocMaxChunkSize - 1) / swarm.SocMaxChunkSize)
+		if shard.Slots < shard.UsedSlots {
+			shard.Slots = shard.UsedSlots
+		}
+		shard.FreeSlots = shard.Slots - shard.UsedSlots
+		if shard.Slots > 0 {
+			shard.Utilisation = float64(shard.UsedSlots) / float64(shard.Slots)
+			shard.Fragmentation = 1 - float64(shard.StoredBytes)/float64(shard.Slots*swarm.SocMaxChunkSize)
+		}
+	}
+
+	return s, nil
+}
@@ -0,0 +1,91 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package localstore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+func TestStats(t *testing.T) {
+	db := newTestDB(t, nil)
+	ctx := context.Background()
+
+	chunks := make([]swarm.Chunk, 20)
+	for i := range chunks {
+		chunks[i] = generateTestRandomChunk()
+	}
+	if _, err := db.Put(ctx, storage.ModePutUpload, chunks...); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Set(ctx, storage.ModeSetPin, chunkAddresses(chunks[:3])...); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Set(ctx, storage.ModeSetRemove, chunkAddresses(chunks[18:])...); err != nil {
+		t.Fatal(err)
+	}
+	stored := chunks[:18]
+
+	var (
+		bins          = make(map[uint8]uint64)
+		pinnedBins    = make(map[uint8]uint64)
+		storageRadius = uint8(1)
+		outside       uint64
+	)
+	for i, ch := range stored {
+		po := db.po(ch.Address())
+		bins[po]++
+		if i < 3 {
+			pinnedBins[po]++
+		}
+		if po < storageRadius {
+			outside++
+		}
+	}
+
+	s, err := db.Stats(ctx, storageRadius)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s.Chunks != uint64(len(stored)) {
+		t.Fatalf("got %d chunks, want %d", s.Chunks, len(stored))
+	}
+	if s.Pinned != 3 {
+		t.Fatalf("got %d pinned chunks, want %d", s.Pinned, 3)
+	}
+	if s.OutsideRadius != outside {
+		t.Fatalf("got %d chunks outside radius, want %d", s.OutsideRadius, outside)
+	}
+	if len(s.Batches) != len(stored) {
+		t.Fatalf("got %d batches, want %d", len(s.Batches), len(stored))
+	}
+	for _, b := range s.Bins {
+		if b.Chunks != bins[b.PO] || b.Pinned != pinnedBins[b.PO] {
+			t.Fatalf("bin %d: got %d chunks and %d pinned, want %d and %d", b.PO, b.Chunks, b.Pinned, bins[b.PO], pinnedBins[b.PO])
+		}
+	}
+
+	var used, free uint64
+	for _, shard := range s.Shards {
+		used += shard.UsedSlots
+		free += shard.FreeSlots
+		if shard.UsedSlots+shard.FreeSlots != shard.Slots {
+			t.Fatalf("shard %d: used and free slots do not add up to %d", shard.Shard, shard.Slots)
+		}
+		if shard.UsedSlots > 0 && (shard.Utilisation <= 0 || shard.Fragmentation < 0 || shard.Fragmentation >= 1) {
+			t.Fatalf("shard %d: unexpected utilisation %f and fragmentation %f", shard.Shard, shard.Utilisation, shard.Fragmentation)
+		}
+	}
+	if used != uint64(len(stored)) {
+		t.Fatalf("got %d used slots, want %d", used, len(stored))
+	}
+	if free < 2 {
+		t.Fatalf("got %d free slots, want at least %d", free, 2)
+	}
+}
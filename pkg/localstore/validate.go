@@ -0,0 +1,311 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package localstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethersphere/bee/pkg/cac"
+	"github.com/ethersphere/bee/pkg/postage"
+	"github.com/ethersphere/bee/pkg/sharky"
+	"github.com/ethersphere/bee/pkg/shed"
+	"github.com/ethersphere/bee/pkg/soc"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+var (
+	// ErrChunkUnreadable is reported when the chunk data can not be read from sharky.
+	ErrChunkUnreadable = errors.New("chunk data unreadable")
+	// ErrChunkInvalid is reported when the chunk data does not match the chunk address.
+	ErrChunkInvalid = errors.New("chunk data invalid")
+	// ErrStampInvalid is reported when the postage stamp of the chunk does not verify.
+	ErrStampInvalid = errors.New("postage stamp invalid")
+	// ErrOrphanedEntry is reported for index entries of chunks missing from the retrieval data index.
+	ErrOrphanedEntry = errors.New("orphaned index entry")
+)
+
+// ValidateOptions holds optional parameters of the validation.
+type ValidateOptions struct {
+	// ValidStamp, if set, is used to verify the postage stamps of the chunks.
+	ValidStamp postage.ValidStampFn
+	// Repair enables the removal of the corrupted chunks and the orphaned index entries.
+	Repair bool
+	// Report, if set, is called for every problem found.
+	Report func(ValidationIssue)
+}
+
+// ValidationIssue describes a single problem found by the validation.
+type ValidationIssue struct {
+	Address swarm.Address
+	Index   string
+	Err     error
+}
+
+// ValidationResult summarizes the validation.
+type ValidationResult struct {
+	Total        uint64         `json:"total"`
+	Unreadable   uint64         `json:"unreadable"`
+	Invalid      uint64         `json:"invalid"`
+	InvalidStamp uint64         `json:"invalidStamp"`
+	UnknownBatch uint64         `json:"unknownBatch"`
+	Orphaned     map[string]int `json:"orphaned"`
+	Repaired     uint64         `json:"repaired"`
+}
+
+// Validate reads every chunk referred to by the retrieval data index and
+// verifies that its data matches its address and, optionally, that its postage
+// stamp is valid. It also looks for entries of the other indexes that refer to
+// chunks missing from the retrieval data index. With the Repair option set, the
+// corrupted chunks are removed from all indexes, their sharky slots released and
+// the orphaned entries are deleted. Chunks of unknown batches are only reported.
+func (db *DB) Validate(ctx context.Context, o *ValidateOptions) (*ValidationResult, error) {
+	if o == nil {
+		o = new(ValidateOptions)
+	}
+	report := func(addr []byte, index string, err error) {
+		if o.Report != nil {
+			o.Report(ValidationIssue{Address: swarm.NewAddress(addr), Index: index, Err: err})
+		}
+	}
+
+	res := &ValidationResult{Orphaned: make(map[string]int)}
+
+	var corrupted []shed.Item
+	err := db.retrievalDataIndex.Iterate(func(item shed.Item) (stop bool, err error) {
+		if err := ctx.Err(); err != nil {
+			return true, err
+		}
+		res.Total++
+
+		err = db.validateItem(ctx, item, o.ValidStamp)
+		switch {
+		case err == nil:
+			return false, nil
+		case errors.Is(err, postage.ErrNotFound):
+			res.UnknownBatch++
+			report(item.Address, "retrievalDataIndex", err)
+			return false, nil
+		case errors.Is(err, ErrChunkUnreadable):
+			res.Unreadable++
+		case errors.Is(err, ErrChunkInvalid):
+			res.Invalid++
+		case errors.Is(err, ErrStampInvalid):
+			res.InvalidStamp++
+		default:
+			return true, err
+		}
+		report(item.Address, "retrievalDataIndex", err)
+		corrupted = append(corrupted, item)
+		return false, nil
+	}, nil)
+	if err != nil {
+		return res, fmt.Errorf("iterate retrieval data index: %w", err)
+	}
+
+	if o.Repair {
+		for _, item := range corrupted {
+			removed, _, err := db.removeCorrupted(ctx, item)
+			if err != nil {
+				return res, fmt.Errorf("remove chunk %x: %w", item.Address, err)
+			}
+			if removed {
+				res.Repaired++
+			}
+		}
+	}
+
+	for name, index := range map[string]shed.Index{
+		"retrievalAccessIndex": db.retrievalAccessIndex,
+		"pushIndex":            db.pushIndex,
+		"pullIndex":            db.pullIndex,
+		"gcIndex":              db.gcIndex,
+		"pinIndex":             db.pinIndex,
+		"postageChunksIndex":   db.postageChunksIndex,
+		"postageIndexIndex":    db.postageIndexIndex,
+	} {
+		var orphaned []shed.Item
+		err := index.Iterate(func(item shed.Item) (stop bool, err error) {
+			if err := ctx.Err(); err != nil {
+				return true, err
+			}
+			has, err := db.retrievalDataIndex.Has(item)
+			if err != nil {
+				return true, err
+			}
+			if !has {
+				report(item.Address, name, ErrOrphanedEntry)
+				orphaned = append(orphaned, item)
+			}
+			return false, nil
+		}, nil)
+		if err != nil {
+			return res, fmt.Errorf("iterate %s: %w", name, err)
+		}
+		res.Orphaned[name] = len(orphaned)
+
+		if o.Repair && len(orphaned) > 0 {
+			n, err := db.removeOrphaned(index, name == "gcIndex", orphaned)
+			if err != nil {
+				return res, fmt.Errorf("remove orphaned %s entries: %w", name, err)
+			}
+			res.Repaired += n
+		}
+	}
+
+	return res, nil
+}
+
+// validateItem reads the chunk data of the item and verifies it.
+func (db *DB) validateItem(ctx context.Context, item shed.Item, validStamp postage.ValidStampFn) error {
+	if len(item.Location) != sharky.LocationSize {
+		return fmt.Errorf("invalid location: %w", ErrChunkUnreadable)
+	}
+	loc, err := sharky.LocationFromBinary(item.Location)
+	if err != nil {
+		return fmt.Errorf("%v: %w", err, ErrChunkUnreadable)
+	}
+	data := make([]byte, loc.Length)
+	if err := db.sharky.Read(ctx, loc, data); err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, sharky.ErrQuitting) {
+			return err
+		}
+		return fmt.Errorf("%v: %w", err, ErrChunkUnreadable)
+	}
+
+	ch := swarm.NewChunk(swarm.NewAddress(item.Address), data)
+	if !cac.Valid(ch) && !soc.Valid(ch) {
+		return ErrChunkInvalid
+	}
+
+	if validStamp != nil {
+		stamp, err := postage.NewStamp(item.BatchID, item.Index, item.Timestamp, item.Sig).MarshalBinary()
+		if err != nil {
+			return fmt.Errorf("%v: %w", err, ErrStampInvalid)
+		}
+		if _, err := validStamp(ch, stamp); err != nil {
+			if errors.Is(err, postage.ErrNotFound) {
+				return err
+			}
+			return fmt.Errorf("%v: %w", err, ErrStampInvalid)
+		}
+	}
+	return nil
+}
+
+// removeCorrupted removes the chunk from all indexes and releases its sharky
+// slot, provided it was not changed since it was validated. The pin counter
+// the chunk had before the removal is returned.
+func (db *DB) removeCorrupted(ctx context.Context, item shed.Item) (removed bool, pinCounter uint64, err error) {
+	db.batchMu.Lock()
+	defer db.batchMu.Unlock()
+
+	i, err := db.retrievalDataIndex.Get(item)
+	if err != nil {
+		if errors.Is(err, leveldb.ErrNotFound) {
+			return false, 0, nil
+		}
+		return false, 0, err
+	}
+	if i.BinID != item.BinID || i.StoreTimestamp != item.StoreTimestamp {
+		// stored again in the meantime
+		return false, 0, nil
+	}
+	if db.gcRunning {
+		db.dirtyAddresses = append(db.dirtyAddresses, swarm.NewAddress(item.Address))
+	}
+
+	pin, err := db.pinIndex.Get(i)
+	switch {
+	case err == nil:
+		pinCounter = pin.PinCounter
+	case !errors.Is(err, leveldb.ErrNotFound):
+		return false, 0, err
+	}
+
+	var reserveSizeChange int64
+	radius, err := db.postageRadiusIndex.Get(i)
+	switch {
+	case err == nil:
+		if db.po(swarm.NewAddress(i.Address)) >= radius.Radius {
+			reserveSizeChange--
+		}
+	case !errors.Is(err, leveldb.ErrNotFound):
+		return false, 0, err
+	}
+
+	batch := new(leveldb.Batch)
+	gcSizeChange, err := db.setRemove(batch, i, true)
+	if err != nil {
+		return false, 0, err
+	}
+	if err := db.pinIndex.DeleteInBatch(batch, i); err != nil {
+		return false, 0, err
+	}
+	if err := db.postageIndexIndex.DeleteInBatch(batch, i); err != nil {
+		return false, 0, err
+	}
+	if err := db.incGCSizeInBatch(batch, gcSizeChange); err != nil {
+		return false, 0, err
+	}
+	if err := db.incReserveSizeInBatch(batch, reserveSizeChange); err != nil {
+		return false, 0, err
+	}
+	if err := db.shed.WriteBatch(batch); err != nil {
+		return false, 0, err
+	}
+
+	if len(i.Location) == sharky.LocationSize {
+		loc, err := sharky.LocationFromBinary(i.Location)
+		if err == nil {
+			if err := db.sharky.Release(ctx, loc); err != nil {
+				db.logger.Warning("failed releasing sharky location", "location", loc)
+			}
+		}
+	}
+	return true, pinCounter, nil
+}
+
+// removeOrphaned deletes the index entries of chunks which are
+// still missing from the retrieval data index.
+func (db *DB) removeOrphaned(index shed.Index, gc bool, items []shed.Item) (uint64, error) {
+	db.batchMu.Lock()
+	defer db.batchMu.Unlock()
+
+	batch := new(leveldb.Batch)
+	var removed uint64
+	for _, item := range items {
+		has, err := db.retrievalDataIndex.Has(item)
+		if err != nil {
+			return 0, err
+		}
+		if has {
+			continue
+		}
+		if db.gcRunning {
+			db.dirtyAddresses = append(db.dirtyAddresses, swarm.NewAddress(item.Address))
+		}
+		if err := index.DeleteInBatch(batch, item); err != nil {
+			return 0, err
+		}
+		if gc {
+			if err := db.gcPolicy.delete(batch, item); err != nil {
+				return 0, err
+			}
+		}
+		removed++
+	}
+	if gc {
+		if err := db.incGCSizeInBatch(batch, -int64(removed)); err != nil {
+			return 0, err
+		}
+	}
+	if err := db.shed.WriteBatch(batch); err != nil {
+		return 0, err
+	}
+	return removed, nil
+}
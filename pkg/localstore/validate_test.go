@@ -0,0 +1,115 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package localstore
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/sharky"
+	"github.com/ethersphere/bee/pkg/shed"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+func TestValidate(t *testing.T) {
+	db := newTestDB(t, nil)
+	ctx := context.Background()
+
+	chunks := make([]swarm.Chunk, 10)
+	for i := range chunks {
+		chunks[i] = generateTestRandomChunk()
+	}
+	for _, ch := range chunks {
+		if _, err := db.Put(ctx, storage.ModePutUpload, ch); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// point the first chunk to foreign data
+	item, err := db.retrievalDataIndex.Get(addressToItem(chunks[0].Address()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	loc, err := db.sharky.Write(ctx, generateTestRandomChunk().Data())
+	if err != nil {
+		t.Fatal(err)
+	}
+	item.Location, err = loc.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.retrievalDataIndex.Put(item); err != nil {
+		t.Fatal(err)
+	}
+
+	// corrupt the location of the second chunk
+	item, err = db.retrievalDataIndex.Get(addressToItem(chunks[1].Address()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	item.Location = item.Location[:sharky.LocationSize-1]
+	if err := db.retrievalDataIndex.Put(item); err != nil {
+		t.Fatal(err)
+	}
+
+	// pin a chunk that is not stored
+	orphan := swarm.MustParseHexAddress("0102030405060708091011121314151617181920212223242526272829303132")
+	if err := db.pinIndex.Put(shed.Item{Address: orphan.Bytes(), PinCounter: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	var issues []ValidationIssue
+	res, err := db.Validate(ctx, &ValidateOptions{
+		Report: func(i ValidationIssue) { issues = append(issues, i) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Total != 10 || res.Invalid != 1 || res.Unreadable != 1 || res.Orphaned["pinIndex"] != 1 || res.Repaired != 0 {
+		t.Fatalf("unexpected result %+v", res)
+	}
+	if len(issues) != 3 {
+		t.Fatalf("got %d issues, want 3", len(issues))
+	}
+	for _, i := range issues {
+		var want error
+		switch {
+		case i.Address.Equal(chunks[0].Address()):
+			want = ErrChunkInvalid
+		case i.Address.Equal(chunks[1].Address()):
+			want = ErrChunkUnreadable
+		case i.Address.Equal(orphan):
+			want = ErrOrphanedEntry
+		default:
+			t.Fatalf("unexpected issue for address %s", i.Address)
+		}
+		if !errors.Is(i.Err, want) {
+			t.Fatalf("got error %v for address %s, want %v", i.Err, i.Address, want)
+		}
+	}
+
+	res, err = db.Validate(ctx, &ValidateOptions{Repair: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Repaired != 3 {
+		t.Fatalf("got %d repaired, want 3", res.Repaired)
+	}
+
+	res, err = db.Validate(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Total != 8 || res.Invalid != 0 || res.Unreadable != 0 || res.Orphaned["pinIndex"] != 0 {
+		t.Fatalf("unexpected result after repair %+v", res)
+	}
+	for _, ch := range chunks[:2] {
+		if _, err := db.Get(ctx, storage.ModeGetRequest, ch.Address()); !errors.Is(err, storage.ErrNotFound) {
+			t.Fatalf("got error %v, want %v", err, storage.ErrNotFound)
+		}
+	}
+}
@@ -41,6 +41,10 @@ var (
 // the Store function.
 type StoreSizeFunc func(int64) error
 
+// EntryIterFunc is the type of the function called for each entry
+// visited by IterateEntries.
+type EntryIterFunc func(path string, entry Entry) error
+
 // Interface for operations with manifest.
 type Interface interface {
 	// Type returns manifest implementation type information
@@ -58,6 +62,9 @@ type Interface interface {
 	// IterateAddresses is used to iterate over chunks addresses for
 	// the manifest.
 	IterateAddresses(context.Context, swarm.AddressIterFunc) error
+	// IterateEntries is used to iterate over the entries with the
+	// paths beginning with the prefix, in the order of the paths.
+	IterateEntries(context.Context, string, EntryIterFunc) error
 }
 
 // Entry represents a single manifest entry.
@@ -179,6 +179,34 @@ func (m *mantarayManifest) IterateAddresses(ctx context.Context, fn swarm.Addres
 	return nil
 }
 
+func (m *mantarayManifest) IterateEntries(ctx context.Context, prefix string, fn EntryIterFunc) error {
+	emptyAddr := swarm.NewAddress([]byte{31: 0})
+	walker := func(path []byte, node *mantaray.Node, err error) error {
+		if err != nil {
+			return err
+		}
+
+		// the nodes without the reference, like the root
+		// path with the website metadata, are not entries
+		if !node.IsValueType() || len(node.Entry()) == 0 {
+			return nil
+		}
+		entry := swarm.NewAddress(node.Entry())
+		if entry.Equal(emptyAddr) {
+			return nil
+		}
+
+		return fn(string(path), NewEntry(entry, node.Metadata()))
+	}
+
+	err := m.trie.WalkNodePrefix(ctx, []byte(prefix), m.ls, walker)
+	if err != nil {
+		return fmt.Errorf("manifest iterate entries: %w", err)
+	}
+
+	return nil
+}
+
 type mantarayLoadSaver struct {
 	ls          file.LoadSaver
 	storeSizeFn []StoreSizeFunc
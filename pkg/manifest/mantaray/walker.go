@@ -4,7 +4,11 @@
 
 package mantaray
 
-import "context"
+import (
+	"bytes"
+	"context"
+	"sort"
+)
 
 // WalkNodeFunc is the type of the function called for each node visited
 // by WalkNode.
@@ -53,6 +57,64 @@ func (n *Node) WalkNode(ctx context.Context, root []byte, l Loader, walkFn WalkN
 	return err
 }
 
+// walkNodePrefix recursively descends path in the order of the fork
+// prefixes, calling walkFn for the nodes whose path begins with prefix.
+func walkNodePrefix(ctx context.Context, path, prefix []byte, l Loader, n *Node, walkFn WalkNodeFunc) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+	if n.forks == nil {
+		if err := n.load(ctx, l); err != nil {
+			return err
+		}
+	}
+
+	if bytes.HasPrefix(path, prefix) {
+		err := walkNodeFnCopyBytes(ctx, path, n, nil, walkFn)
+		if err != nil {
+			return err
+		}
+	}
+
+	keys := make([]int, 0, len(n.forks))
+	for k := range n.forks {
+		keys = append(keys, int(k))
+	}
+	sort.Ints(keys)
+
+	for _, k := range keys {
+		v := n.forks[byte(k)]
+		nextPath := append(path[:0:0], path...)
+		nextPath = append(nextPath, v.prefix...)
+
+		// skip the forks which neither lead to the prefix nor begin with it
+		c := len(nextPath)
+		if len(prefix) < c {
+			c = len(prefix)
+		}
+		if !bytes.Equal(nextPath[:c], prefix[:c]) {
+			continue
+		}
+
+		err := walkNodePrefix(ctx, nextPath, prefix, l, v.Node, walkFn)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WalkNodePrefix walks the node tree structure rooted at n in the
+// lexicographical order of the paths, calling walkFn for each node whose
+// path begins with prefix. The nodes which cannot lead to such paths
+// are not loaded.
+func (n *Node) WalkNodePrefix(ctx context.Context, prefix []byte, l Loader, walkFn WalkNodeFunc) error {
+	return walkNodePrefix(ctx, []byte{}, prefix, l, n, walkFn)
+}
+
 // WalkFunc is the type of the function called for each file or directory
 // visited by Walk.
 type WalkFunc func(path []byte, isDir bool, err error) error
@@ -249,3 +249,77 @@ func TestWalk(t *testing.T) {
 		})
 	}
 }
+
+func TestWalkNodePrefix(t *testing.T) {
+	ctx := context.Background()
+	toAdd := [][]byte{
+		[]byte("robots.txt"),
+		[]byte("img/2.png"),
+		[]byte("index.html"),
+		[]byte("img/1.png"),
+		[]byte("img/sub/3.png"),
+		[]byte("imgs.txt"),
+	}
+
+	n := mantaray.New()
+	for _, c := range toAdd {
+		e := append(make([]byte, 32-len(c)), c...)
+		if err := n.Add(ctx, c, e, nil, nil); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+	ls := newMockLoadSaver()
+	if err := n.Save(ctx, ls); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		prefix   string
+		expected []string
+	}{
+		{
+			prefix:   "",
+			expected: []string{"img/1.png", "img/2.png", "img/sub/3.png", "imgs.txt", "index.html", "robots.txt"},
+		},
+		{
+			prefix:   "img/",
+			expected: []string{"img/1.png", "img/2.png", "img/sub/3.png"},
+		},
+		{
+			prefix:   "img",
+			expected: []string{"img/1.png", "img/2.png", "img/sub/3.png", "imgs.txt"},
+		},
+		{
+			prefix:   "img/sub/3.png",
+			expected: []string{"img/sub/3.png"},
+		},
+		{
+			prefix: "video/",
+		},
+	} {
+		t.Run(tc.prefix, func(t *testing.T) {
+			var walked []string
+			walker := func(path []byte, node *mantaray.Node, err error) error {
+				if err != nil {
+					return err
+				}
+				if !bytes.HasPrefix(path, []byte(tc.prefix)) {
+					return fmt.Errorf("walkFn returned path %q without prefix %q", path, tc.prefix)
+				}
+				if node.IsValueType() {
+					walked = append(walked, string(path))
+				}
+				return nil
+			}
+
+			n2 := mantaray.NewNodeRef(n.Reference())
+			if err := n2.WalkNodePrefix(ctx, []byte(tc.prefix), ls, walker); err != nil {
+				t.Fatalf("no error expected, found: %s", err)
+			}
+
+			if fmt.Sprint(walked) != fmt.Sprint(tc.expected) {
+				t.Fatalf("got paths %v, want %v", walked, tc.expected)
+			}
+		})
+	}
+}
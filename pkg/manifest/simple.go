@@ -8,6 +8,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/ethersphere/bee/pkg/file"
 	"github.com/ethersphere/bee/pkg/manifest/simple"
@@ -144,6 +146,45 @@ func (m *simpleManifest) IterateAddresses(ctx context.Context, fn swarm.AddressI
 	return nil
 }
 
+func (m *simpleManifest) IterateEntries(_ context.Context, prefix string, fn EntryIterFunc) error {
+	entries := make(map[string]Entry)
+	walker := func(path string, entry simple.Entry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !strings.HasPrefix(path, prefix) {
+			return nil
+		}
+
+		ref, err := swarm.ParseHexAddress(entry.Reference())
+		if err != nil {
+			return err
+		}
+		entries[path] = NewEntry(ref, entry.Metadata())
+		return nil
+	}
+
+	err := m.manifest.WalkEntry("", walker)
+	if err != nil {
+		return fmt.Errorf("manifest iterate entries: %w", err)
+	}
+
+	// the entries of the simple manifest are walked in no particular order
+	paths := make([]string, 0, len(entries))
+	for path := range entries {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		if err := fn(path, entries[path]); err != nil {
+			return fmt.Errorf("manifest iterate entries: %w", err)
+		}
+	}
+
+	return nil
+}
+
 func (m *simpleManifest) load(ctx context.Context, reference swarm.Address) error {
 	buf, err := m.ls.Load(ctx, reference.Bytes())
 	if err != nil {
@@ -13,6 +13,12 @@ type metrics struct {
 	LocalChunksCounter        prometheus.Counter
 	InvalidLocalChunksCounter prometheus.Counter
 	RetrievedChunksCounter    prometheus.Counter
+
+	RepairedChunksCounter       prometheus.Counter
+	RepairFailedCounter         prometheus.Counter
+	ScrubRunsCounter            prometheus.Counter
+	ScrubCorruptedChunksCounter prometheus.Counter
+	ScrubDuration               prometheus.Histogram
 }
 
 func newMetrics() metrics {
@@ -37,6 +43,37 @@ func newMetrics() metrics {
 			Name:      "chunks_retrieved_from_network",
 			Help:      "Total no. of chunks retrieved from network.",
 		}),
+		RepairedChunksCounter: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "chunks_repaired",
+			Help:      "Total no. of corrupted chunks replaced by the ones retrieved from network.",
+		}),
+		RepairFailedCounter: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "chunk_repairs_failed",
+			Help:      "Total no. of failed repairs of corrupted chunks.",
+		}),
+		ScrubRunsCounter: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "scrub_runs",
+			Help:      "Total no. of validations of the pinned chunks.",
+		}),
+		ScrubCorruptedChunksCounter: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "scrub_corrupted_chunks",
+			Help:      "Total no. of corrupted pinned chunks found by the validations.",
+		}),
+		ScrubDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "scrub_duration_seconds",
+			Help:      "Duration of the validations of the pinned chunks.",
+			Buckets:   []float64{1, 10, 60, 300, 900, 3600, 3 * 3600, 12 * 3600},
+		}),
 	}
 }
 
@@ -31,6 +31,20 @@ const (
 	maxBgPutters int = 16
 )
 
+// Options holds optional parameters of the netstore.
+type Options struct {
+	// ScrubInterval is the time between two validations of the pinned
+	// chunks in the local storage. Zero disables the scrubbing.
+	ScrubInterval time.Duration
+}
+
+// repairer is implemented by the local storage that is able
+// to replace the chunks with corrupted data.
+type repairer interface {
+	ReplaceCorrupted(ctx context.Context, ch swarm.Chunk) error
+	ValidatePinned(ctx context.Context, fn func(swarm.Address) error) error
+}
+
 type store struct {
 	storage.Storer
 	retrieval  retrieval.Interface
@@ -43,12 +57,8 @@ type store struct {
 	metrics    metrics
 }
 
-var (
-	errInvalidLocalChunk = errors.New("invalid chunk found locally")
-)
-
 // New returns a new NetStore that wraps a given Storer.
-func New(s storage.Storer, validStamp postage.ValidStampFn, r retrieval.Interface, logger log.Logger) storage.Storer {
+func New(s storage.Storer, validStamp postage.ValidStampFn, r retrieval.Interface, logger log.Logger, o Options) storage.Storer {
 	ns := &store{
 		Storer:     s,
 		validStamp: validStamp,
@@ -58,6 +68,11 @@ func New(s storage.Storer, validStamp postage.ValidStampFn, r retrieval.Interfac
 		metrics:    newMetrics(),
 	}
 	ns.sCtx, ns.sCancel = context.WithCancel(context.Background())
+
+	if r, ok := s.(repairer); ok && o.ScrubInterval > 0 {
+		ns.wg.Add(1)
+		go ns.scrubWorker(r, o.ScrubInterval)
+	}
 	return ns
 }
 
@@ -74,29 +89,59 @@ func (s *store) Get(ctx context.Context, mode storage.ModeGet, addr swarm.Addres
 		// this would ensure it is retrieved again from network and added back with
 		// the correct data
 		if !cac.Valid(ch) && !soc.Valid(ch) {
-			err = errInvalidLocalChunk
-			ch = nil
 			s.logger.Warning("netstore: got invalid chunk from localstore, falling back to retrieval")
 			s.metrics.InvalidLocalChunksCounter.Inc()
+			return s.repair(ctx, mode, addr)
 		}
 	}
 	if err != nil {
-		if errors.Is(err, storage.ErrNotFound) || errors.Is(err, errInvalidLocalChunk) {
-			// request from network
-			ch, err = s.retrieval.RetrieveChunk(ctx, addr, swarm.ZeroAddress)
-			if err != nil {
-				return nil, err
-			}
-			s.wg.Add(1)
-			s.put(ch, mode)
-			s.metrics.RetrievedChunksCounter.Inc()
-			return ch, nil
+		if errors.Is(err, storage.ErrNotFound) {
+			return s.retrieve(ctx, mode, addr)
 		}
 		return nil, fmt.Errorf("netstore get: %w", err)
 	}
 	return ch, nil
 }
 
+// repair retrieves the chunk with corrupted data in the local storage from
+// the network and replaces the corrupted data with the retrieved one. The
+// corrupted chunk is kept with its pin counter if the retrieval fails. If
+// the local storage is not able to replace the data, the chunk is stored
+// the same way as any other retrieved chunk.
+func (s *store) repair(ctx context.Context, mode storage.ModeGet, addr swarm.Address) (swarm.Chunk, error) {
+	r, ok := s.Storer.(repairer)
+	if !ok {
+		return s.retrieve(ctx, mode, addr)
+	}
+
+	ch, err := s.retrieval.RetrieveChunk(ctx, addr, swarm.ZeroAddress)
+	if err != nil {
+		s.metrics.RepairFailedCounter.Inc()
+		return nil, err
+	}
+	s.metrics.RetrievedChunksCounter.Inc()
+
+	if err := r.ReplaceCorrupted(ctx, ch); err != nil {
+		s.metrics.RepairFailedCounter.Inc()
+		s.logger.Error(err, "netstore: failed to replace corrupted chunk", "chunk_address", addr)
+		return ch, nil
+	}
+	s.metrics.RepairedChunksCounter.Inc()
+	return ch, nil
+}
+
+// retrieve requests the chunk from the network and stores it.
+func (s *store) retrieve(ctx context.Context, mode storage.ModeGet, addr swarm.Address) (swarm.Chunk, error) {
+	ch, err := s.retrieval.RetrieveChunk(ctx, addr, swarm.ZeroAddress)
+	if err != nil {
+		return nil, err
+	}
+	s.wg.Add(1)
+	s.put(ch, mode)
+	s.metrics.RetrievedChunksCounter.Inc()
+	return ch, nil
+}
+
 // put will store the chunk into storage asynchronously
 func (s *store) put(ch swarm.Chunk, mode storage.ModeGet) {
 	go func() {
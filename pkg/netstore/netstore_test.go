@@ -8,6 +8,7 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -184,6 +185,88 @@ func TestInvalidPostageStamp(t *testing.T) {
 	}
 }
 
+// TestCorruptedChunkRepair verifies that the data of a corrupted chunk is
+// replaced in the local storage by the data retrieved from the network.
+func TestCorruptedChunkRepair(t *testing.T) {
+	retrieve := &retrievalMock{}
+	store := newRepairingStorer()
+	ns := netstore.New(store, noopValidStamp, retrieve, log.Noop, netstore.Options{})
+	t.Cleanup(func() {
+		if err := ns.Close(); err != nil {
+			t.Fatal("failed closing netstore", err)
+		}
+	})
+
+	addr := testChunk.Address()
+	invalidChunk := swarm.NewChunk(addr, []byte("deadbeef"))
+	if _, err := store.Put(context.Background(), storage.ModePutUpload, invalidChunk); err != nil {
+		t.Fatal(err)
+	}
+
+	ch, err := ns.Get(context.Background(), storage.ModeGetRequest, addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(ch.Data(), testChunk.Data()) {
+		t.Fatal("chunk data not equal to expected data")
+	}
+	if retrieve.callCount != 1 {
+		t.Fatalf("call count %d", retrieve.callCount)
+	}
+
+	store.waitRepaired(t, addr)
+}
+
+// TestCorruptedChunkRepairFailed verifies that the corrupted chunk
+// is kept in the local storage if it cannot be retrieved.
+func TestCorruptedChunkRepairFailed(t *testing.T) {
+	retrieve := &retrievalMock{failure: true}
+	store := newRepairingStorer()
+	ns := netstore.New(store, noopValidStamp, retrieve, log.Noop, netstore.Options{})
+	t.Cleanup(func() {
+		if err := ns.Close(); err != nil {
+			t.Fatal("failed closing netstore", err)
+		}
+	})
+
+	addr := testChunk.Address()
+	invalidChunk := swarm.NewChunk(addr, []byte("deadbeef"))
+	if _, err := store.Put(context.Background(), storage.ModePutUpload, invalidChunk); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ns.Get(context.Background(), storage.ModeGetRequest, addr); err == nil {
+		t.Fatal("expected error")
+	}
+	if store.isRepaired(addr) {
+		t.Fatal("chunk repaired")
+	}
+	has, err := store.Has(context.Background(), addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !has {
+		t.Fatal("corrupted chunk removed")
+	}
+}
+
+// TestScrub verifies that the corrupted pinned chunks are repaired periodically.
+func TestScrub(t *testing.T) {
+	retrieve := &retrievalMock{}
+	store := newRepairingStorer()
+	store.corrupted = []swarm.Address{testChunk.Address()}
+	ns := netstore.New(store, noopValidStamp, retrieve, log.Noop, netstore.Options{
+		ScrubInterval: 10 * time.Millisecond,
+	})
+	t.Cleanup(func() {
+		if err := ns.Close(); err != nil {
+			t.Fatal("failed closing netstore", err)
+		}
+	})
+
+	store.waitRepaired(t, testChunk.Address())
+}
+
 func waitAndGetChunk(t *testing.T, store storage.Storer, addr swarm.Address, mode storage.ModeGet) swarm.Chunk {
 	t.Helper()
 
@@ -207,7 +290,7 @@ func newRetrievingNetstore(t *testing.T, validStamp postage.ValidStampFn) (ret *
 	retrieve := &retrievalMock{}
 	store := mock.NewStorer()
 	logger := log.Noop
-	ns = netstore.New(store, validStamp, retrieve, logger)
+	ns = netstore.New(store, validStamp, retrieve, logger, netstore.Options{})
 	t.Cleanup(func() {
 		err := ns.Close()
 		if err != nil {
@@ -237,3 +320,63 @@ func (r *retrievalMock) RetrieveChunk(ctx context.Context, addr, sourceAddr swar
 var noopValidStamp = func(c swarm.Chunk, _ []byte) (swarm.Chunk, error) {
 	return c, nil
 }
+
+// repairingStorer is a mock storer which is able to repair corrupted chunks.
+type repairingStorer struct {
+	*mock.MockStorer
+	corrupted []swarm.Address
+	mtx       sync.Mutex
+	repaired  map[string]struct{}
+}
+
+func newRepairingStorer() *repairingStorer {
+	return &repairingStorer{
+		MockStorer: mock.NewStorer(),
+		repaired:   make(map[string]struct{}),
+	}
+}
+
+func (s *repairingStorer) ReplaceCorrupted(ctx context.Context, ch swarm.Chunk) error {
+	if err := s.Set(ctx, storage.ModeSetRemove, ch.Address()); err != nil {
+		return err
+	}
+	if _, err := s.Put(ctx, storage.ModePutRequest, ch); err != nil {
+		return err
+	}
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.repaired[ch.Address().ByteString()] = struct{}{}
+	return nil
+}
+
+func (s *repairingStorer) ValidatePinned(_ context.Context, fn func(swarm.Address) error) error {
+	s.mtx.Lock()
+	corrupted := s.corrupted
+	s.corrupted = nil
+	s.mtx.Unlock()
+
+	for _, addr := range corrupted {
+		if err := fn(addr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *repairingStorer) isRepaired(addr swarm.Address) bool {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	_, ok := s.repaired[addr.ByteString()]
+	return ok
+}
+
+func (s *repairingStorer) waitRepaired(t *testing.T, addr swarm.Address) {
+	t.Helper()
+
+	for start := time.Now(); time.Since(start) < 3*time.Second; time.Sleep(10 * time.Millisecond) {
+		if s.isRepaired(addr) {
+			return
+		}
+	}
+	t.Fatal("chunk not repaired")
+}
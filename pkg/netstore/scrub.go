@@ -0,0 +1,59 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netstore
+
+import (
+	"time"
+
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// scrubWorker is a long running function that periodically
+// validates the pinned chunks and repairs the corrupted ones.
+func (s *store) scrubWorker(r repairer, interval time.Duration) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-s.sCtx.Done():
+			return
+		}
+		s.scrub(r)
+	}
+}
+
+// scrub validates the pinned chunks of the local storage
+// and repairs the ones with corrupted data.
+func (s *store) scrub(r repairer) {
+	start := time.Now()
+	s.metrics.ScrubRunsCounter.Inc()
+
+	var corrupted []swarm.Address
+	err := r.ValidatePinned(s.sCtx, func(addr swarm.Address) error {
+		corrupted = append(corrupted, addr)
+		return nil
+	})
+	if err != nil {
+		s.logger.Error(err, "netstore: scrubbing pinned chunks failed")
+		return
+	}
+	s.metrics.ScrubCorruptedChunksCounter.Add(float64(len(corrupted)))
+
+	for _, addr := range corrupted {
+		if _, err := s.repair(s.sCtx, storage.ModeGetRequest, addr); err != nil {
+			s.logger.Error(err, "netstore: repairing corrupted chunk failed", "chunk_address", addr)
+		}
+	}
+	s.metrics.ScrubDuration.Observe(time.Since(start).Seconds())
+
+	if len(corrupted) > 0 {
+		s.logger.Info("netstore: scrubbed pinned chunks", "corrupted", len(corrupted), "elapsed", time.Since(start))
+	}
+}
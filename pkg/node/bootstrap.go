@@ -8,7 +8,6 @@ import (
 	"context"
 	"crypto/ecdsa"
 	"encoding/hex"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -188,7 +187,7 @@ func bootstrapNode(
 		return nil, fmt.Errorf("retrieval service: %w", err)
 	}
 
-	ns := netstore.New(storer, noopValidStamp, retrieve, logger)
+	ns := netstore.New(storer, noopValidStamp, retrieve, logger, netstore.Options{})
 
 	if err := kad.Start(p2pCtx); err != nil {
 		return nil, err
@@ -261,13 +260,8 @@ func bootstrapNode(
 		return nil, err
 	}
 
-	events := postage.ChainSnapshot{}
-	err = json.Unmarshal(eventsJSON, &events)
-	if err != nil {
-		return nil, err
-	}
-
-	return &events, nil
+	// the snapshot may be compressed by the postage snapshot command
+	return postage.DecodeChainSnapshot(eventsJSON)
 }
 
 // wait till some peers are connected. returns true if all is ok
@@ -10,6 +10,7 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"net/url"
 	"strings"
 	"time"
 
@@ -32,6 +33,7 @@ import (
 	"github.com/ethersphere/bee/pkg/settlement/swap/swapprotocol"
 	"github.com/ethersphere/bee/pkg/storage"
 	"github.com/ethersphere/bee/pkg/transaction"
+	"github.com/ethersphere/bee/pkg/transaction/multibackend"
 	"github.com/ethersphere/bee/pkg/transaction/wrapped"
 	"github.com/ethersphere/go-sw3-abi/sw3abi"
 	"github.com/prometheus/client_golang/prometheus"
@@ -41,18 +43,29 @@ const (
 	maxDelay                = 1 * time.Minute
 	cancellationDepth       = 12
 	additionalConfirmations = 2
+
+	endpointHealthCheckInterval = 15 * time.Second
+	endpointMaxBlockLag         = 10
 )
 
 // InitChain will initialize the Ethereum backend at the given endpoint and
 // set up the Transaction Service to interact with it using the provided signer.
+// With fallback endpoints or an endpoint quorum of at least two, the calls are
+// spread over all the endpoints, failing over to the fallback endpoints when
+// the preceding ones are not reachable. The max fee and the priority fee, if
+// not empty, are the fees per gas in wei of the dynamic fee transactions.
 func InitChain(
 	ctx context.Context,
 	logger log.Logger,
 	stateStore storage.StateStorer,
 	endpoint string,
+	fallbackEndpoints []string,
+	endpointQuorum int,
 	oChainID int64,
 	signer crypto.Signer,
 	pollingInterval time.Duration,
+	maxFee string,
+	priorityFee string,
 	chainEnabled bool,
 ) (transaction.Backend, common.Address, int64, transaction.Monitor, transaction.Service, error) {
 	var backend transaction.Backend = &noOpChainBackend{
@@ -61,21 +74,20 @@ func InitChain(
 
 	if chainEnabled {
 		// connect to the real one
-		rpcClient, err := rpc.DialContext(ctx, endpoint)
-		if err != nil {
-			return nil, common.Address{}, 0, nil, nil, fmt.Errorf("dial eth client: %w", err)
-		}
-
-		var versionString string
-		err = rpcClient.CallContext(ctx, &versionString, "web3_clientVersion")
-		if err != nil {
-			logger.Info("could not connect to backend; in a swap-enabled network a working blockchain node (for xdai network in production, goerli in testnet) is required; check your node or specify another node using --swap-endpoint.", "backend_endpoint", endpoint)
-			return nil, common.Address{}, 0, nil, nil, fmt.Errorf("eth client get version: %w", err)
+		if len(fallbackEndpoints) == 0 && endpointQuorum < 2 {
+			b, err := dialChainBackend(ctx, logger, endpoint)
+			if err != nil {
+				logger.Info("could not connect to backend; in a swap-enabled network a working blockchain node (for xdai network in production, goerli in testnet) is required; check your node or specify another node using --swap-endpoint.", "backend_endpoint", endpoint)
+				return nil, common.Address{}, 0, nil, nil, err
+			}
+			backend = wrapped.NewBackend(b)
+		} else {
+			b, err := dialMultiChainBackend(ctx, logger, append([]string{endpoint}, fallbackEndpoints...), endpointQuorum)
+			if err != nil {
+				return nil, common.Address{}, 0, nil, nil, err
+			}
+			backend = wrapped.NewBackend(b)
 		}
-
-		logger.Info("connected to ethereum backend", "version", versionString)
-
-		backend = wrapped.NewBackend(ethclient.NewClient(rpcClient))
 	}
 
 	chainID, err := backend.ChainID(ctx)
@@ -90,7 +102,23 @@ func InitChain(
 
 	transactionMonitor := transaction.NewMonitor(logger, backend, overlayEthAddress, pollingInterval, cancellationDepth)
 
-	transactionService, err := transaction.NewService(logger, backend, signer, stateStore, chainID, transactionMonitor)
+	var fees transaction.FeeOptions
+	if maxFee != "" {
+		fee, ok := new(big.Int).SetString(maxFee, 10)
+		if !ok {
+			return nil, common.Address{}, 0, nil, nil, fmt.Errorf("transaction max fee \"%s\" cannot be parsed", maxFee)
+		}
+		fees.MaxFee = fee
+	}
+	if priorityFee != "" {
+		fee, ok := new(big.Int).SetString(priorityFee, 10)
+		if !ok {
+			return nil, common.Address{}, 0, nil, nil, fmt.Errorf("transaction priority fee \"%s\" cannot be parsed", priorityFee)
+		}
+		fees.PriorityFee = fee
+	}
+
+	transactionService, err := transaction.NewService(logger, backend, signer, stateStore, chainID, transactionMonitor, fees)
 	if err != nil {
 		return nil, common.Address{}, 0, nil, nil, fmt.Errorf("new transaction service: %w", err)
 	}
@@ -98,6 +126,89 @@ func InitChain(
 	return backend, overlayEthAddress, chainID.Int64(), transactionMonitor, transactionService, nil
 }
 
+// dialChainBackend connects to the Ethereum backend at the given endpoint.
+func dialChainBackend(ctx context.Context, logger log.Logger, endpoint string) (transaction.Backend, error) {
+	rpcClient, err := rpc.DialContext(ctx, endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("dial eth client: %w", err)
+	}
+
+	var versionString string
+	err = rpcClient.CallContext(ctx, &versionString, "web3_clientVersion")
+	if err != nil {
+		rpcClient.Close()
+		return nil, fmt.Errorf("eth client get version: %w", err)
+	}
+
+	logger.Info("connected to ethereum backend", "version", versionString)
+
+	return ethclient.NewClient(rpcClient), nil
+}
+
+// dialMultiChainBackend connects to the Ethereum backends at the given
+// endpoints and spreads the calls over them. The unreachable endpoints are
+// unhealthy until the health checks connect them, at least one endpoint
+// must be reachable. The endpoints keep the order they are given in for
+// the failover.
+func dialMultiChainBackend(ctx context.Context, logger log.Logger, endpoints []string, quorum int) (transaction.Backend, error) {
+	var (
+		backends  []multibackend.Endpoint
+		names     = make(map[string]bool)
+		reachable int
+	)
+	for i, endpoint := range endpoints {
+		name := endpointName(endpoint, i)
+		if names[name] {
+			name = fmt.Sprintf("%s#%d", name, i)
+		}
+		names[name] = true
+
+		dial := func(endpoint string) func(context.Context) (transaction.Backend, error) {
+			return func(ctx context.Context) (transaction.Backend, error) {
+				return dialChainBackend(ctx, logger, endpoint)
+			}
+		}(endpoint)
+
+		b, err := dialChainBackend(ctx, logger, endpoint)
+		if err != nil {
+			logger.Warning("could not connect to backend; retrying it with the health checks", "backend_endpoint", name, "error", err)
+		} else {
+			reachable++
+		}
+		backends = append(backends, multibackend.Endpoint{Name: name, Backend: b, Dial: dial})
+	}
+	if reachable == 0 {
+		logger.Info("could not connect to any backend; in a swap-enabled network a working blockchain node (for xdai network in production, goerli in testnet) is required; check your nodes or specify other nodes using --swap-endpoint and --swap-fallback-endpoints.")
+		return nil, errors.New("no reachable eth backend endpoints")
+	}
+
+	b, err := multibackend.New(logger, backends, multibackend.Options{
+		Quorum:              quorum,
+		HealthCheckInterval: endpointHealthCheckInterval,
+		MaxBlockLag:         endpointMaxBlockLag,
+	})
+	if err != nil {
+		for _, e := range backends {
+			if e.Backend != nil {
+				e.Backend.Close()
+			}
+		}
+		return nil, fmt.Errorf("multi backend: %w", err)
+	}
+	return b, nil
+}
+
+// endpointName returns the name of the endpoint used in the logs and the
+// metrics, which is the host of the endpoint URL as the rest of the URL
+// may hold credentials.
+func endpointName(endpoint string, i int) string {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" {
+		return fmt.Sprintf("endpoint-%d", i)
+	}
+	return u.Host
+}
+
 // InitChequebookFactory will initialize the chequebook factory with the given
 // chain backend.
 func InitChequebookFactory(
@@ -399,6 +510,9 @@ func (m noOpChainBackend) PendingNonceAt(context.Context, common.Address) (uint6
 func (m noOpChainBackend) SuggestGasPrice(context.Context) (*big.Int, error) {
 	panic("chain no op: SuggestGasPrice")
 }
+func (m noOpChainBackend) SuggestGasTipCap(context.Context) (*big.Int, error) {
+	panic("chain no op: SuggestGasTipCap")
+}
 func (m noOpChainBackend) EstimateGas(context.Context, ethereum.CallMsg) (uint64, error) {
 	panic("chain no op: EstimateGas")
 }
@@ -17,6 +17,7 @@ import (
 	"math/big"
 	"net"
 	"net/http"
+	"os"
 	"path/filepath"
 	"runtime"
 	"sync"
@@ -47,6 +48,8 @@ import (
 	"github.com/ethersphere/bee/pkg/postage/batchservice"
 	"github.com/ethersphere/bee/pkg/postage/batchstore"
 	"github.com/ethersphere/bee/pkg/postage/listener"
+	"github.com/ethersphere/bee/pkg/postage/notifier"
+	"github.com/ethersphere/bee/pkg/postage/policy"
 	"github.com/ethersphere/bee/pkg/postage/postagecontract"
 	"github.com/ethersphere/bee/pkg/pricer"
 	"github.com/ethersphere/bee/pkg/pricing"
@@ -73,7 +76,9 @@ import (
 	"github.com/ethersphere/bee/pkg/topology/lightnode"
 	"github.com/ethersphere/bee/pkg/tracing"
 	"github.com/ethersphere/bee/pkg/transaction"
+	"github.com/ethersphere/bee/pkg/transaction/stuck"
 	"github.com/ethersphere/bee/pkg/traversal"
+	"github.com/ethersphere/bee/pkg/upload"
 	"github.com/ethersphere/bee/pkg/util"
 	"github.com/ethersphere/bee/pkg/util/ioutil"
 	"github.com/hashicorp/go-multierror"
@@ -96,6 +101,7 @@ type Bee struct {
 	errorLogWriter           io.Writer
 	tracerCloser             io.Closer
 	tagsCloser               io.Closer
+	uploadsCloser            io.Closer
 	stateStoreCloser         io.Closer
 	localstoreCloser         io.Closer
 	nsCloser                 io.Closer
@@ -109,8 +115,11 @@ type Bee struct {
 	ethClientCloser          func()
 	transactionMonitorCloser io.Closer
 	transactionCloser        io.Closer
+	stuckTransactionsCloser  io.Closer
 	listenerCloser           io.Closer
 	postageServiceCloser     io.Closer
+	postagePolicyCloser      io.Closer
+	batchNotifierCloser      io.Closer
 	priceOracleCloser        io.Closer
 	hiveCloser               io.Closer
 	chainSyncerCloser        io.Closer
@@ -122,10 +131,15 @@ type Bee struct {
 type Options struct {
 	DataDir                    string
 	CacheCapacity              uint64
+	CacheGCPolicy              string
+	CacheMemorySize            uint64
 	DBOpenFilesLimit           uint64
 	DBWriteBufferSize          uint64
 	DBBlockCacheCapacity       uint64
 	DBDisableSeeksCompaction   bool
+	DBFreeSpaceSoftLimit       uint64
+	DBFreeSpaceHardLimit       uint64
+	DBScrubInterval            time.Duration
 	APIAddr                    string
 	DebugAPIAddr               string
 	Addr                       string
@@ -143,9 +157,19 @@ type Options struct {
 	PaymentEarly               int64
 	ResolverConnectionCfgs     []multiresolver.ConnectionConfig
 	RetrievalCaching           bool
+	UploadSessionExpiry        time.Duration
+	UploadZipMaxArchiveSize    int64
+	UploadZipMaxSize           int64
 	GatewayMode                bool
 	BootnodeMode               bool
 	SwapEndpoint               string
+	SwapFallbackEndpoints      []string
+	SwapEndpointQuorum         int
+	TransactionMaxFee          string
+	TransactionPriorityFee     string
+	TransactionStuckTimeout    time.Duration
+	TransactionFeeBump         uint64
+	TransactionBumpMaxFee      string
 	SwapFactoryAddress         string
 	SwapLegacyFactoryAddresses []string
 	SwapInitialDeposit         string
@@ -155,6 +179,10 @@ type Options struct {
 	Transaction                string
 	BlockHash                  string
 	PostageContractAddress     string
+	PostagePolicyBudget        string
+	PostagePolicyInterval      time.Duration
+	PostageEventsWebhook       string
+	PostageExpiryWarnings      []string
 	PriceOracleAddress         string
 	BlockTime                  uint64
 	DeployGasPrice             string
@@ -180,6 +208,8 @@ const (
 	minPaymentThreshold           = 2 * refreshRate
 	maxPaymentThreshold           = 24 * refreshRate
 	mainnetNetworkID              = uint64(1)
+	stuckTransactionsInterval     = time.Minute
+	postageExpiryCheckInterval    = time.Minute
 )
 
 func NewBee(interrupt chan struct{}, addr string, publicKey *ecdsa.PublicKey, signer crypto.Signer, networkID uint64, logger log.Logger, libp2pPrivateKey, pssPrivateKey *ecdsa.PrivateKey, o *Options) (b *Bee, err error) {
@@ -262,10 +292,14 @@ func NewBee(interrupt chan struct{}, addr string, publicKey *ecdsa.PublicKey, si
 
 	var batchStore postage.Storer = new(postage.NoOpBatchStore)
 	var unreserveFn func([]byte, uint8) (uint64, error)
+	var batchNotifier *notifier.Notifier
 
 	if chainEnabled {
 		var evictFn = func(b []byte) error {
 			_, err := unreserveFn(b, swarm.MaxPO+1)
+			if err == nil && batchNotifier != nil {
+				batchNotifier.HandleEviction(b)
+			}
 			return err
 		}
 		batchStore, err = batchstore.New(stateStore, evictFn, logger)
@@ -279,9 +313,13 @@ func NewBee(interrupt chan struct{}, addr string, publicKey *ecdsa.PublicKey, si
 		logger,
 		stateStore,
 		o.SwapEndpoint,
+		o.SwapFallbackEndpoints,
+		o.SwapEndpointQuorum,
 		o.ChainID,
 		signer,
 		pollingInterval,
+		o.TransactionMaxFee,
+		o.TransactionPriorityFee,
 		chainEnabled)
 	if err != nil {
 		return nil, fmt.Errorf("init chain: %w", err)
@@ -295,6 +333,25 @@ func NewBee(interrupt chan struct{}, addr string, publicKey *ecdsa.PublicKey, si
 	b.transactionCloser = tracerCloser
 	b.transactionMonitorCloser = transactionMonitor
 
+	var stuckTransactions *stuck.Manager
+	if chainEnabled && o.TransactionStuckTimeout > 0 {
+		var maxFee *big.Int
+		if o.TransactionBumpMaxFee != "" {
+			fee, ok := new(big.Int).SetString(o.TransactionBumpMaxFee, 10)
+			if !ok {
+				return nil, fmt.Errorf("invalid transaction bump max fee: %s", o.TransactionBumpMaxFee)
+			}
+			maxFee = fee
+		}
+		stuckTransactions = stuck.New(transactionService, logger, stuck.Options{
+			Timeout:  o.TransactionStuckTimeout,
+			Interval: stuckTransactionsInterval,
+			FeeBump:  o.TransactionFeeBump,
+			MaxFee:   maxFee,
+		})
+		b.stuckTransactionsCloser = stuckTransactions
+	}
+
 	var authenticator *auth.Authenticator
 
 	if o.Restricted {
@@ -591,6 +648,10 @@ func NewBee(interrupt chan struct{}, addr string, publicKey *ecdsa.PublicKey, si
 		BlockCacheCapacity:     o.DBBlockCacheCapacity,
 		WriteBufferSize:        o.DBWriteBufferSize,
 		DisableSeeksCompaction: o.DBDisableSeeksCompaction,
+		GCPolicy:               o.CacheGCPolicy,
+		ChunkCacheSize:         o.CacheMemorySize,
+		FreeSpaceSoftLimit:     o.DBFreeSpaceSoftLimit,
+		FreeSpaceHardLimit:     o.DBFreeSpaceHardLimit,
 	}
 
 	storer, err := localstore.New(path, swarmAddress.Bytes(), stateStore, lo, logger)
@@ -607,6 +668,20 @@ func NewBee(interrupt chan struct{}, addr string, publicKey *ecdsa.PublicKey, si
 	}
 	b.postageServiceCloser = post
 
+	var (
+		syncErr    atomic.Value
+		syncStatus atomic.Value
+
+		syncStatusFn = func() (isDone bool, err error) {
+			iErr := syncErr.Load()
+			if iErr != nil {
+				err = iErr.(error)
+			}
+			isDone = syncStatus.Load() != nil
+			return isDone, err
+		}
+	)
+
 	var (
 		postageContractService postagecontract.Interface
 		batchSvc               postage.EventUpdater
@@ -632,7 +707,27 @@ func NewBee(interrupt chan struct{}, addr string, publicKey *ecdsa.PublicKey, si
 	eventListener = listener.New(b.syncingStopped, logger, chainBackend, postageContractAddress, o.BlockTime, postageSyncingStallingTimeout, postageSyncingBackoffTimeout)
 	b.listenerCloser = eventListener
 
-	batchSvc, err = batchservice.New(stateStore, batchStore, logger, eventListener, overlayEthAddress.Bytes(), post, sha3.New256, o.Resync)
+	var batchListener postage.BatchEventListener = post
+	if chainEnabled {
+		expiryWarnings := make([]time.Duration, 0, len(o.PostageExpiryWarnings))
+		for _, w := range o.PostageExpiryWarnings {
+			d, err := time.ParseDuration(w)
+			if err != nil {
+				return nil, fmt.Errorf("invalid postage expiry warning %q: %w", w, err)
+			}
+			expiryWarnings = append(expiryWarnings, d)
+		}
+		batchNotifier = notifier.New(post, batchStore, post, big.NewInt(int64(o.BlockTime)), logger, notifier.Options{
+			WebhookURL:       o.PostageEventsWebhook,
+			ExpiryThresholds: expiryWarnings,
+			Interval:         postageExpiryCheckInterval,
+			SyncStatus:       syncStatusFn,
+		})
+		b.batchNotifierCloser = batchNotifier
+		batchListener = batchNotifier
+	}
+
+	batchSvc, err = batchservice.New(stateStore, batchStore, logger, eventListener, overlayEthAddress.Bytes(), batchListener, sha3.New256, o.Resync)
 	if err != nil {
 		return nil, err
 	}
@@ -701,20 +796,6 @@ func NewBee(interrupt chan struct{}, addr string, publicKey *ecdsa.PublicKey, si
 	p2ps.SetPickyNotifier(kad)
 	batchStore.SetRadiusSetter(kad)
 
-	var (
-		syncErr    atomic.Value
-		syncStatus atomic.Value
-
-		syncStatusFn = func() (isDone bool, err error) {
-			iErr := syncErr.Load()
-			if iErr != nil {
-				err = iErr.(error)
-			}
-			isDone = syncStatus.Load() != nil
-			return isDone, err
-		}
-	)
-
 	if batchSvc != nil && chainEnabled {
 		logger.Info("waiting to sync postage contract data, this may take a while... more info available in Debug loglevel")
 		if o.FullNodeMode {
@@ -738,6 +819,20 @@ func NewBee(interrupt chan struct{}, addr string, publicKey *ecdsa.PublicKey, si
 		}
 	}
 
+	var postagePolicy *policy.Service
+	if chainEnabled {
+		budget, ok := new(big.Int).SetString(o.PostagePolicyBudget, 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid postage policy budget: %s", o.PostagePolicyBudget)
+		}
+		postagePolicy = policy.New(stateStore, batchStore, post, postageContractService, big.NewInt(int64(o.BlockTime)), logger, policy.Options{
+			Budget:     budget,
+			Interval:   o.PostagePolicyInterval,
+			SyncStatus: syncStatusFn,
+		})
+		b.postagePolicyCloser = postagePolicy
+	}
+
 	pricer := pricer.NewFixedPricer(swarmAddress, basePrice)
 
 	pricing := pricing.New(p2ps, logger, paymentThreshold, big.NewInt(minPaymentThreshold))
@@ -817,10 +912,17 @@ func NewBee(interrupt chan struct{}, addr string, publicKey *ecdsa.PublicKey, si
 	tagService := tags.NewTags(stateStore, logger)
 	b.tagsCloser = tagService
 
+	uploadService := upload.New(stateStore, logger, upload.Options{
+		Expiry: o.UploadSessionExpiry,
+	})
+	b.uploadsCloser = uploadService
+
 	pssService := pss.New(pssPrivateKey, logger)
 	b.pssCloser = pssService
 
-	var ns storage.Storer = netstore.New(storer, validStamp, retrieve, logger)
+	var ns storage.Storer = netstore.New(storer, validStamp, retrieve, logger, netstore.Options{
+		ScrubInterval: o.DBScrubInterval,
+	})
 	b.nsCloser = ns
 
 	traversalService := traversal.New(ns)
@@ -842,7 +944,9 @@ func NewBee(interrupt chan struct{}, addr string, publicKey *ecdsa.PublicKey, si
 
 	var pullerService *puller.Puller
 	if o.FullNodeMode && !o.BootnodeMode {
-		pullerService = puller.New(stateStore, kad, pullSyncProtocol, logger, puller.Options{}, warmupTime)
+		pullerService = puller.New(stateStore, kad, pullSyncProtocol, logger, puller.Options{
+			Paused: func() bool { return storer.DiskSpaceState() != localstore.DiskSpaceOK },
+		}, warmupTime)
 		b.pullerCloser = pullerService
 	}
 
@@ -907,6 +1011,7 @@ func NewBee(interrupt chan struct{}, addr string, publicKey *ecdsa.PublicKey, si
 		BlockTime:        big.NewInt(int64(o.BlockTime)),
 		Tags:             tagService,
 		Storer:           ns,
+		LocalStore:       storer,
 		Resolver:         multiResolver,
 		Pss:              pssService,
 		TraversalService: traversalService,
@@ -914,11 +1019,27 @@ func NewBee(interrupt chan struct{}, addr string, publicKey *ecdsa.PublicKey, si
 		FeedFactory:      feedFactory,
 		Post:             post,
 		PostageContract:  postageContractService,
+		PostagePolicy:    postagePolicy,
+		BatchNotifier:    batchNotifier,
 		Steward:          steward,
+		Uploads:          uploadService,
 		SyncStatus:       syncStatusFn,
 	}
 
 	if o.APIAddr != "" {
+		// the temporary files of the uploads are kept in the data
+		// dir, the ones left behind by the previous run are removed
+		var tempDir string
+		if o.DataDir != "" {
+			tempDir = filepath.Join(o.DataDir, "upload-tmp")
+			if err := os.RemoveAll(tempDir); err != nil {
+				return nil, fmt.Errorf("temp dir: %w", err)
+			}
+			if err := os.MkdirAll(tempDir, 0700); err != nil {
+				return nil, fmt.Errorf("temp dir: %w", err)
+			}
+		}
+
 		if apiService == nil {
 			apiService = api.New(*publicKey, pssPrivateKey.PublicKey, overlayEthAddress, logger, transactionService, batchStore, o.GatewayMode, beeNodeMode, o.ChequebookEnable, o.SwapEnable, o.CORSAllowedOrigins)
 		}
@@ -928,6 +1049,9 @@ func NewBee(interrupt chan struct{}, addr string, publicKey *ecdsa.PublicKey, si
 			GatewayMode:        o.GatewayMode,
 			WsPingPeriod:       60 * time.Second,
 			Restricted:         o.Restricted,
+			TempDir:            tempDir,
+			ZipMaxArchiveSize:  o.UploadZipMaxArchiveSize,
+			ZipMaxSize:         o.UploadZipMaxSize,
 		}, extraOpts, chainID, chainBackend, erc20Service)
 
 		pusherService.AddFeed(chunkC)
@@ -982,6 +1106,17 @@ func NewBee(interrupt chan struct{}, addr string, publicKey *ecdsa.PublicKey, si
 		debugService.MustRegisterMetrics(retrieve.Metrics()...)
 		debugService.MustRegisterMetrics(lightNodes.Metrics()...)
 		debugService.MustRegisterMetrics(hive.Metrics()...)
+		debugService.MustRegisterMetrics(uploadService.Metrics()...)
+
+		if postagePolicy != nil {
+			debugService.MustRegisterMetrics(postagePolicy.Metrics()...)
+		}
+		if stuckTransactions != nil {
+			debugService.MustRegisterMetrics(stuckTransactions.Metrics()...)
+		}
+		if batchNotifier != nil {
+			debugService.MustRegisterMetrics(batchNotifier.Metrics()...)
+		}
 
 		if bs, ok := batchStore.(metrics.Collector); ok {
 			debugService.MustRegisterMetrics(bs.Metrics()...)
@@ -1139,10 +1274,13 @@ func (b *Bee) Shutdown() error {
 
 	tryClose(b.p2pService, "p2p server")
 	tryClose(b.priceOracleCloser, "price oracle service")
+	tryClose(b.postagePolicyCloser, "postage policy")
+	tryClose(b.batchNotifierCloser, "postage notifier")
 
 	wg.Add(3)
 	go func() {
 		defer wg.Done()
+		tryClose(b.stuckTransactionsCloser, "stuck transactions manager")
 		tryClose(b.transactionMonitorCloser, "transaction monitor")
 		tryClose(b.transactionCloser, "transaction")
 	}()
@@ -1162,6 +1300,7 @@ func (b *Bee) Shutdown() error {
 	}
 
 	tryClose(b.tracerCloser, "tracer")
+	tryClose(b.uploadsCloser, "upload sessions")
 	tryClose(b.tagsCloser, "tag persistence")
 	tryClose(b.topologyCloser, "topology driver")
 	tryClose(b.nsCloser, "netstore")
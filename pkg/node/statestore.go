@@ -46,3 +46,12 @@ func CheckOverlayWithStore(overlay swarm.Address, storer storage.StateStorer) er
 
 	return nil
 }
+
+// StoredOverlay returns the overlay address stored in the statestore.
+func StoredOverlay(storer storage.StateStorer) (swarm.Address, error) {
+	var overlay swarm.Address
+	if err := storer.Get(secureOverlayKey, &overlay); err != nil {
+		return swarm.ZeroAddress, err
+	}
+	return overlay, nil
+}
@@ -0,0 +1,205 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package archive reads and writes the chunk archives in the localstore
+// export format, so that the chunks can be stamped offline and imported
+// or uploaded afterwards.
+//
+// An archive is a tar file, optionally gzip compressed. The first file
+// holds the version of the format, every following file is a chunk named
+// by its hex encoded address and holding its serialized postage stamp
+// followed by its data.
+package archive
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/ethersphere/bee/pkg/postage"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+const (
+	// versionFilename and version must match the
+	// ones of the localstore export.
+	versionFilename = ".swarm-export-version"
+	version         = "3"
+)
+
+var (
+	// ErrUnsupportedVersion is returned for archives of an unknown format.
+	ErrUnsupportedVersion = errors.New("unsupported archive version")
+	// ErrMissingStamp is returned when writing a chunk without a stamp.
+	ErrMissingStamp = errors.New("missing postage stamp")
+)
+
+// gzipMagic are the first bytes of gzip compressed data.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// ReadTar calls fn for every chunk of the archive read from r.
+// The stamps of the chunks are attached to them.
+func ReadTar(r io.Reader, fn func(swarm.Chunk) error) error {
+	br := bufio.NewReader(r)
+	if magic, err := br.Peek(len(gzipMagic)); err == nil && bytes.Equal(magic, gzipMagic) {
+		gr, err := gzip.NewReader(br)
+		if err != nil {
+			return err
+		}
+		defer gr.Close()
+		r = gr
+	} else {
+		r = br
+	}
+	tr := tar.NewReader(r)
+
+	for first := true; ; first = false {
+		hdr, err := tr.Next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		if first && hdr.Name == versionFilename {
+			v, err := io.ReadAll(tr)
+			if err != nil {
+				return err
+			}
+			if string(v) != version {
+				return fmt.Errorf("%w: %q", ErrUnsupportedVersion, v)
+			}
+			continue
+		}
+
+		addr, err := chunkAddress(hdr.Name)
+		if err != nil {
+			return err
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return err
+		}
+		if len(data) < postage.StampSize+swarm.SpanSize {
+			return fmt.Errorf("chunk %s: invalid data size %d", hdr.Name, len(data))
+		}
+		stamp := new(postage.Stamp)
+		if err := stamp.UnmarshalBinary(data[:postage.StampSize]); err != nil {
+			return fmt.Errorf("chunk %s: %w", hdr.Name, err)
+		}
+
+		if err := fn(swarm.NewChunk(addr, data[postage.StampSize:]).WithStamp(stamp)); err != nil {
+			return err
+		}
+	}
+}
+
+// ReadDir calls fn for every chunk file in the directory. The files are
+// named by the hex encoded addresses of the chunks and hold their data,
+// without stamps.
+func ReadDir(dir string, fn func(swarm.Chunk) error) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if !e.Type().IsRegular() {
+			continue
+		}
+		addr, err := chunkAddress(e.Name())
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return err
+		}
+		if len(data) < swarm.SpanSize || len(data) > swarm.ChunkWithSpanSize {
+			return fmt.Errorf("chunk %s: invalid data size %d", e.Name(), len(data))
+		}
+		if err := fn(swarm.NewChunk(addr, data)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func chunkAddress(name string) (swarm.Address, error) {
+	b, err := hex.DecodeString(name)
+	if err != nil || len(b) != swarm.HashSize {
+		return swarm.ZeroAddress, fmt.Errorf("invalid chunk file name %q", name)
+	}
+	return swarm.NewAddress(b), nil
+}
+
+// Writer writes the stamped chunks to an archive.
+type Writer struct {
+	gw *gzip.Writer
+	tw *tar.Writer
+}
+
+// NewWriter returns a new Writer of the archive written to w,
+// which is gzip compressed if compress is true.
+func NewWriter(w io.Writer, compress bool) (*Writer, error) {
+	aw := new(Writer)
+	if compress {
+		aw.gw = gzip.NewWriter(w)
+		w = aw.gw
+	}
+	aw.tw = tar.NewWriter(w)
+
+	if err := aw.tw.WriteHeader(&tar.Header{
+		Name: versionFilename,
+		Mode: 0644,
+		Size: int64(len(version)),
+	}); err != nil {
+		return nil, err
+	}
+	if _, err := aw.tw.Write([]byte(version)); err != nil {
+		return nil, err
+	}
+	return aw, nil
+}
+
+// Write writes the chunk with its stamp to the archive.
+func (w *Writer) Write(ch swarm.Chunk) error {
+	if ch.Stamp() == nil {
+		return ErrMissingStamp
+	}
+	stamp, err := ch.Stamp().MarshalBinary()
+	if err != nil {
+		return err
+	}
+
+	if err := w.tw.WriteHeader(&tar.Header{
+		Name: ch.Address().String(),
+		Mode: 0644,
+		Size: int64(len(stamp) + len(ch.Data())),
+	}); err != nil {
+		return err
+	}
+	if _, err := w.tw.Write(stamp); err != nil {
+		return err
+	}
+	_, err = w.tw.Write(ch.Data())
+	return err
+}
+
+// Close flushes the archive. It does not close the underlying writer.
+func (w *Writer) Close() error {
+	if err := w.tw.Close(); err != nil {
+		return err
+	}
+	if w.gw != nil {
+		return w.gw.Close()
+	}
+	return nil
+}
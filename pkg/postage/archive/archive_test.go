@@ -0,0 +1,133 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package archive_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/localstore"
+	"github.com/ethersphere/bee/pkg/log"
+	"github.com/ethersphere/bee/pkg/postage"
+	"github.com/ethersphere/bee/pkg/postage/archive"
+	mockbatchstore "github.com/ethersphere/bee/pkg/postage/batchstore/mock"
+	postagetesting "github.com/ethersphere/bee/pkg/postage/testing"
+	"github.com/ethersphere/bee/pkg/storage"
+	testingc "github.com/ethersphere/bee/pkg/storage/testing"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+func TestStampArchive(t *testing.T) {
+	key, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := crypto.NewDefaultSigner(key)
+	owner, err := signer.EthereumAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	batch := postagetesting.MustNewBatch(postagetesting.WithOwner(owner.Bytes()))
+	issuer := postage.NewStampIssuer("", "", batch.ID, big.NewInt(0), batch.Depth, batch.BucketDepth, 0, batch.Immutable)
+	stamper := postage.NewStamper(issuer, signer)
+	batchStore := mockbatchstore.New(mockbatchstore.WithBatch(batch))
+
+	// the chunks to stamp are read from a directory
+	dir := t.TempDir()
+	chunks := testingc.GenerateTestRandomChunks(10)
+	for _, ch := range chunks {
+		if err := os.WriteFile(filepath.Join(dir, ch.Address().String()), ch.Data(), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, compress := range []bool{false, true} {
+		var buf bytes.Buffer
+		w, err := archive.NewWriter(&buf, compress)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = archive.ReadDir(dir, func(ch swarm.Chunk) error {
+			stamp, err := stamper.Stamp(ch.Address())
+			if err != nil {
+				return err
+			}
+			return w.Write(ch.WithStamp(stamp))
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		var count int
+		err = archive.ReadTar(bytes.NewReader(buf.Bytes()), func(ch swarm.Chunk) error {
+			count++
+			stampBytes, err := ch.Stamp().MarshalBinary()
+			if err != nil {
+				return err
+			}
+			_, err = postage.ValidStamp(batchStore)(ch, stampBytes)
+			return err
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if count != len(chunks) {
+			t.Fatalf("got %d chunks, want %d", count, len(chunks))
+		}
+
+		// the archive can be imported to the localstore
+		db, err := localstore.New("", make([]byte, 32), nil, nil, log.Noop)
+		if err != nil {
+			t.Fatal(err)
+		}
+		imported, err := db.Import(context.Background(), bytes.NewReader(buf.Bytes()), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if imported != int64(len(chunks)) {
+			t.Fatalf("got %d imported chunks, want %d", imported, len(chunks))
+		}
+		for _, ch := range chunks {
+			got, err := db.Get(context.Background(), storage.ModeGetRequest, ch.Address())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got.Data(), ch.Data()) {
+				t.Fatalf("chunk %s: data mismatch", ch.Address())
+			}
+		}
+		if err := db.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the bucket usage of the issuer reflects all the stamps
+	var total uint32
+	for _, c := range issuer.Buckets() {
+		total += c
+	}
+	if total != uint32(2*len(chunks)) {
+		t.Fatalf("got %d issued stamps, want %d", total, 2*len(chunks))
+	}
+}
+
+func TestWriteUnstamped(t *testing.T) {
+	w, err := archive.NewWriter(new(bytes.Buffer), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write(testingc.GenerateTestRandomChunk().WithStamp(nil)); !errors.Is(err, archive.ErrMissingStamp) {
+		t.Fatalf("got error %v, want %v", err, archive.ErrMissingStamp)
+	}
+}
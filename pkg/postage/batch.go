@@ -53,3 +53,23 @@ func (b *Batch) UnmarshalBinary(buf []byte) error {
 	b.StorageRadius = buf[95]
 	return nil
 }
+
+// TTL estimates the time in seconds remaining until the batch expires, given
+// the chain state and the block time in seconds. The -1 signals that the
+// batch never expires.
+func (b *Batch) TTL(state *ChainState, blockTime *big.Int) int64 {
+	if len(state.CurrentPrice.Bits()) == 0 {
+		return -1
+	}
+
+	var (
+		normalizedBalance = b.Value
+		cumulativePayout  = state.TotalAmount
+		pricePerBlock     = state.CurrentPrice
+	)
+	ttl := new(big.Int).Sub(normalizedBalance, cumulativePayout)
+	ttl = ttl.Mul(ttl, blockTime)
+	ttl = ttl.Div(ttl, pricePerBlock)
+
+	return ttl.Int64()
+}
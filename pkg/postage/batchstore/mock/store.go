@@ -177,6 +177,7 @@ func (bs *BatchStore) GetReserveState() *postage.ReserveState {
 	rs := new(postage.ReserveState)
 	if bs.rs != nil {
 		rs.Radius = bs.rs.Radius
+		rs.StorageRadius = bs.rs.StorageRadius
 	}
 	return rs
 }
@@ -0,0 +1,62 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postage_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethersphere/bee/pkg/postage"
+)
+
+func TestDecodeChainSnapshot(t *testing.T) {
+	want := &postage.ChainSnapshot{
+		Events:           []types.Log{{BlockNumber: 10, Data: []byte{1}, Topics: []common.Hash{{2}}}},
+		FirstBlockNumber: 1,
+		LastBlockNumber:  20,
+		Timestamp:        1234,
+	}
+	data, err := json.Marshal(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var compressed bytes.Buffer
+	gw := gzip.NewWriter(&compressed)
+	if _, err := gw.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name string
+		data []byte
+	}{
+		{name: "json", data: data},
+		{name: "gzip", data: compressed.Bytes()},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := postage.DecodeChainSnapshot(tc.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("got snapshot %+v, want %+v", got, want)
+			}
+		})
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		if _, err := postage.DecodeChainSnapshot([]byte{0x1f, 0x8b, 0x0}); err == nil {
+			t.Fatal("expected error")
+		}
+	})
+}
@@ -0,0 +1,81 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postage
+
+import (
+	"math/bits"
+	"sync"
+
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// FitChecker simulates the stamping of chunks by a StampIssuer on a copy
+// of its collision buckets, in order to tell whether content fits into
+// the batch before it is uploaded. No stamps are issued.
+type FitChecker struct {
+	mu             sync.Mutex
+	depth          uint8
+	bucketDepth    uint8
+	buckets        []uint32
+	maxBucketCount uint32
+	chunks         int64
+}
+
+// NewFitChecker returns a FitChecker starting from the current
+// bucket counters of the issuer.
+func NewFitChecker(si *StampIssuer) *FitChecker {
+	return &FitChecker{
+		depth:          si.Depth(),
+		bucketDepth:    si.BucketDepth(),
+		buckets:        si.Buckets(),
+		maxBucketCount: si.Utilization(),
+	}
+}
+
+// Inc increments the count of the collision bucket of the chunk address
+// the same way stamping the chunk would. The count is not limited by the
+// bucket upper bound, so that the fill of overflowing buckets is known.
+func (fc *FitChecker) Inc(addr swarm.Address) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	b := toBucket(fc.bucketDepth, addr)
+	fc.buckets[b]++
+	if fc.buckets[b] > fc.maxBucketCount {
+		fc.maxBucketCount = fc.buckets[b]
+	}
+	fc.chunks++
+}
+
+// Chunks returns the number of simulated chunks.
+func (fc *FitChecker) Chunks() int64 {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	return fc.chunks
+}
+
+// MaxBucketCount returns the count of the fullest bucket.
+func (fc *FitChecker) MaxBucketCount() uint32 {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	return fc.maxBucketCount
+}
+
+// Fits reports whether none of the buckets exceeds the bucket upper bound
+// of the batch depth.
+func (fc *FitChecker) Fits() bool {
+	return fc.MaxBucketCount() <= 1<<(fc.depth-fc.bucketDepth)
+}
+
+// MinDepth returns the smallest batch depth with the bucket upper bound
+// not exceeded by the fullest bucket.
+func (fc *FitChecker) MinDepth() uint8 {
+	depth := fc.bucketDepth + 1
+	if max := fc.MaxBucketCount(); max > 1 {
+		if d := fc.bucketDepth + uint8(bits.Len32(max-1)); d > depth {
+			depth = d
+		}
+	}
+	return depth
+}
@@ -0,0 +1,75 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postage_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/postage"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// TestFitChecker tests that the fit checker simulates the bucket
+// increments without changing the stamp issuer.
+func TestFitChecker(t *testing.T) {
+	// bucket upper bound is 1<<(12-8) = 16
+	st := postage.NewStampIssuer("label", "keyID", make([]byte, 32), big.NewInt(3), 12, 8, 0, true)
+
+	privKey, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stamper := postage.NewStamper(st, crypto.NewDefaultSigner(privKey))
+
+	// all the addresses fall into the first bucket
+	addr := func(i int) swarm.Address {
+		b := make([]byte, 32)
+		b[31] = byte(i)
+		return swarm.NewAddress(b)
+	}
+
+	for i := 0; i < 10; i++ {
+		if _, err := stamper.Stamp(addr(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fc := postage.NewFitChecker(st)
+	for i := 10; i < 16; i++ {
+		fc.Inc(addr(i))
+	}
+	if !fc.Fits() {
+		t.Fatal("expected content to fit")
+	}
+	if got := fc.MaxBucketCount(); got != 16 {
+		t.Fatalf("got max bucket count %d, want 16", got)
+	}
+	if got := fc.MinDepth(); got != 12 {
+		t.Fatalf("got min depth %d, want 12", got)
+	}
+
+	fc.Inc(addr(16))
+	if fc.Fits() {
+		t.Fatal("expected content not to fit")
+	}
+	if got := fc.Chunks(); got != 7 {
+		t.Fatalf("got %d chunks, want 7", got)
+	}
+	if got := fc.MaxBucketCount(); got != 17 {
+		t.Fatalf("got max bucket count %d, want 17", got)
+	}
+	if got := fc.MinDepth(); got != 13 {
+		t.Fatalf("got min depth %d, want 13", got)
+	}
+
+	if got := st.Utilization(); got != 10 {
+		t.Fatalf("got issuer utilization %d, want 10", got)
+	}
+	if got := st.Buckets()[0]; got != 10 {
+		t.Fatalf("got issuer bucket count %d, want 10", got)
+	}
+}
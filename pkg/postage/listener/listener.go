@@ -97,11 +97,17 @@ func New(
 }
 
 func (l *listener) filterQuery(from, to *big.Int) ethereum.FilterQuery {
+	return filterQuery(l.postageStampAddress, from, to)
+}
+
+// filterQuery returns the query of the postage
+// contract events between the from and the to blocks.
+func filterQuery(postageStampAddress common.Address, from, to *big.Int) ethereum.FilterQuery {
 	return ethereum.FilterQuery{
 		FromBlock: from,
 		ToBlock:   to,
 		Addresses: []common.Address{
-			l.postageStampAddress,
+			postageStampAddress,
 		},
 		Topics: [][]common.Hash{
 			{
@@ -177,44 +183,50 @@ func (l *listener) processEvent(e types.Log, updater postage.EventUpdater) error
 	}
 }
 
-func (l *listener) Listen(from uint64, updater postage.EventUpdater, initState *postage.ChainSnapshot) <-chan error {
-	ctx, cancel := context.WithCancel(context.Background())
-	go func() {
-		<-l.quit
-		cancel()
-	}()
+// processEvents applies the events to the updater in a single
+// transaction and moves the block number of the updater to the to block.
+func (l *listener) processEvents(events []types.Log, to uint64, updater postage.EventUpdater) error {
+	if err := updater.TransactionStart(); err != nil {
+		return err
+	}
 
-	processEvents := func(events []types.Log, to uint64) error {
-		if err := updater.TransactionStart(); err != nil {
+	for _, e := range events {
+		startEv := time.Now()
+		err := updater.UpdateBlockNumber(e.BlockNumber)
+		if err != nil {
 			return err
 		}
-
-		for _, e := range events {
-			startEv := time.Now()
-			err := updater.UpdateBlockNumber(e.BlockNumber)
-			if err != nil {
+		if err = l.processEvent(e, updater); err != nil {
+			// if we have a zero value batch - silence & log then move on
+			if !errors.Is(err, batchservice.ErrZeroValueBatch) {
 				return err
 			}
-			if err = l.processEvent(e, updater); err != nil {
-				// if we have a zero value batch - silence & log then move on
-				if !errors.Is(err, batchservice.ErrZeroValueBatch) {
-					return err
-				}
-				l.logger.Debug("failed processing event", "error", err)
-			}
-			totalTimeMetric(l.metrics.EventProcessDuration, startEv)
+			l.logger.Debug("failed processing event", "error", err)
 		}
+		totalTimeMetric(l.metrics.EventProcessDuration, startEv)
+	}
 
-		err := updater.UpdateBlockNumber(to)
-		if err != nil {
-			return err
-		}
+	err := updater.UpdateBlockNumber(to)
+	if err != nil {
+		return err
+	}
 
-		if err := updater.TransactionEnd(); err != nil {
-			return err
-		}
+	if err := updater.TransactionEnd(); err != nil {
+		return err
+	}
+
+	return nil
+}
 
-		return nil
+func (l *listener) Listen(from uint64, updater postage.EventUpdater, initState *postage.ChainSnapshot) <-chan error {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-l.quit
+		cancel()
+	}()
+
+	processEvents := func(events []types.Log, to uint64) error {
+		return l.processEvents(events, to, updater)
 	}
 
 	if initState != nil {
@@ -0,0 +1,70 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package listener
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethersphere/bee/pkg/log"
+	"github.com/ethersphere/bee/pkg/postage"
+)
+
+// Snapshot collects the postage contract events between the from and the
+// to blocks, both inclusive, into a chain snapshot. The events are queried
+// in pages of blocks with the same filter the listener uses. If to is zero,
+// the events are collected up to the block the listener considers final.
+func Snapshot(ctx context.Context, ev BlockHeightContractFilterer, postageStampAddress common.Address, from, to uint64) (*postage.ChainSnapshot, error) {
+	if to == 0 {
+		head, err := ev.BlockNumber(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("block number: %w", err)
+		}
+		if head < tailSize {
+			return nil, fmt.Errorf("chain head %d below the tail size", head)
+		}
+		to = head - tailSize
+	}
+	if to < from {
+		return nil, fmt.Errorf("invalid block range from %d to %d", from, to)
+	}
+
+	snapshot := &postage.ChainSnapshot{
+		Events:           []types.Log{},
+		FirstBlockNumber: from,
+		LastBlockNumber:  to,
+	}
+	for start := from; start <= to; start += blockPage {
+		end := start + blockPage - 1
+		if end > to {
+			end = to
+		}
+		events, err := ev.FilterLogs(ctx, filterQuery(postageStampAddress, new(big.Int).SetUint64(start), new(big.Int).SetUint64(end)))
+		if err != nil {
+			return nil, fmt.Errorf("filter logs from %d to %d: %w", start, end, err)
+		}
+		snapshot.Events = append(snapshot.Events, events...)
+	}
+	snapshot.Timestamp = time.Now().Unix()
+
+	return snapshot, nil
+}
+
+// Replay applies the events of the snapshot to the updater in a single
+// transaction and moves the block number of the updater to the last block
+// of the snapshot. Unlike the initial state applied by the listener, which
+// is followed by the listening from the next block, the replayed state is
+// reported up to the last block the snapshot covers.
+func Replay(logger log.Logger, snapshot *postage.ChainSnapshot, updater postage.EventUpdater) error {
+	l := &listener{
+		logger:  logger.WithName(loggerName).Register(),
+		metrics: newMetrics(),
+	}
+	return l.processEvents(snapshot.Events, snapshot.LastBlockNumber, updater)
+}
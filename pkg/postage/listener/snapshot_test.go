@@ -0,0 +1,118 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package listener_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethersphere/bee/pkg/log"
+	"github.com/ethersphere/bee/pkg/postage"
+	"github.com/ethersphere/bee/pkg/postage/batchservice"
+	"github.com/ethersphere/bee/pkg/postage/batchstore"
+	"github.com/ethersphere/bee/pkg/postage/listener"
+	"github.com/ethersphere/bee/pkg/statestore/mock"
+)
+
+func TestSnapshot(t *testing.T) {
+	create := createArgs{
+		id:               hash[:],
+		owner:            addr[:],
+		amount:           big.NewInt(42),
+		normalisedAmount: big.NewInt(43),
+		depth:            100,
+	}
+	priceUpdate := priceArgs{
+		price: big.NewInt(500),
+	}
+	mf := newMockFilterer(
+		WithFilterLogEvents(create.toLog(20), priceUpdate.toLog(30)),
+		WithBlockNumber(100),
+	)
+
+	t.Run("to head", func(t *testing.T) {
+		snapshot, err := listener.Snapshot(context.Background(), mf, postageStampAddress, 10, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if snapshot.FirstBlockNumber != 10 {
+			t.Fatalf("got first block %d, want 10", snapshot.FirstBlockNumber)
+		}
+		if want := uint64(100 - listener.TailSize); snapshot.LastBlockNumber != want {
+			t.Fatalf("got last block %d, want %d", snapshot.LastBlockNumber, want)
+		}
+		if len(snapshot.Events) != 2 {
+			t.Fatalf("got %d events, want 2", len(snapshot.Events))
+		}
+		if snapshot.Timestamp == 0 {
+			t.Fatal("missing timestamp")
+		}
+	})
+
+	t.Run("invalid range", func(t *testing.T) {
+		_, err := listener.Snapshot(context.Background(), mf, postageStampAddress, 50, 40)
+		if err == nil {
+			t.Fatal("expected error")
+		}
+	})
+}
+
+func TestReplay(t *testing.T) {
+	create := createArgs{
+		id:               hash[:],
+		owner:            addr[:],
+		amount:           big.NewInt(42),
+		normalisedAmount: big.NewInt(1000000),
+		depth:            100,
+	}
+	depthIncrease := depthArgs{
+		id:                hash[:],
+		depth:             200,
+		normalisedBalance: big.NewInt(1000000),
+	}
+	priceUpdate := priceArgs{
+		price: big.NewInt(500),
+	}
+	snapshot := &postage.ChainSnapshot{
+		Events: []types.Log{
+			create.toLog(496),
+			depthIncrease.toLog(497),
+			priceUpdate.toLog(498),
+		},
+		FirstBlockNumber: 496,
+		LastBlockNumber:  499,
+	}
+
+	stateStore := mock.NewStateStore()
+	store, err := batchstore.New(stateStore, func([]byte) error { return nil }, log.Noop)
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc, err := batchservice.New(stateStore, store, log.Noop, nil, nil, nil, nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := listener.Replay(log.Noop, snapshot, svc); err != nil {
+		t.Fatal(err)
+	}
+
+	cs := store.GetChainState()
+	if cs.Block != 499 {
+		t.Fatalf("got block %d, want 499", cs.Block)
+	}
+	if cs.CurrentPrice.Cmp(priceUpdate.price) != 0 {
+		t.Fatalf("got price %d, want %d", cs.CurrentPrice, priceUpdate.price)
+	}
+	b, err := store.Get(hash[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Depth != depthIncrease.depth {
+		t.Fatalf("got batch depth %d, want %d", b.Depth, depthIncrease.depth)
+	}
+}
@@ -0,0 +1,48 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package notifier
+
+import (
+	m "github.com/ethersphere/bee/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type metrics struct {
+	Events        *prometheus.CounterVec
+	DroppedEvents prometheus.Counter
+	WebhookErrors prometheus.Counter
+}
+
+func newMetrics() metrics {
+	subsystem := "postage_notifier"
+
+	return metrics{
+		Events: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: m.Namespace,
+				Subsystem: subsystem,
+				Name:      "events",
+				Help:      "Total number of batch events published per type.",
+			},
+			[]string{"type"},
+		),
+		DroppedEvents: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "dropped_events",
+			Help:      "Total number of batch events dropped for a subscriber or the webhook not keeping up.",
+		}),
+		WebhookErrors: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "webhook_errors",
+			Help:      "Total number of batch events the webhook failed to receive.",
+		}),
+	}
+}
+
+func (n *Notifier) Metrics() []prometheus.Collector {
+	return m.PrometheusCollectorsFromFields(n.metrics)
+}
@@ -0,0 +1,418 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package notifier publishes the lifecycle events of the postage batches of
+// the node: their creation, top-ups and dilutions reported by the postage
+// listener, the warnings about their approaching expiry and their eviction
+// from the batch store. The events are delivered to the subscribers and,
+// optionally, posted to a webhook.
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/bigint"
+	"github.com/ethersphere/bee/pkg/log"
+	"github.com/ethersphere/bee/pkg/postage"
+)
+
+// loggerName is the tree path name of the logger for this package.
+const loggerName = "postage-notifier"
+
+const (
+	// eventBufferSize is the number of events buffered for a
+	// subscriber or the webhook before the following ones are dropped.
+	eventBufferSize = 64
+	// webhookTimeout is the timeout of a webhook request.
+	webhookTimeout = 10 * time.Second
+)
+
+const (
+	// EventCreate is the type of the events of the created batches.
+	EventCreate = "create"
+	// EventTopUp is the type of the events of the topped up batches.
+	EventTopUp = "topup"
+	// EventDilute is the type of the events of the batches
+	// with the increased depth.
+	EventDilute = "dilute"
+	// EventExpiry is the type of the warnings about the batches
+	// with the time to live below one of the expiry thresholds.
+	EventExpiry = "expiry"
+	// EventEvict is the type of the events of the expired
+	// batches evicted from the batch store.
+	EventEvict = "evict"
+)
+
+// Event is a lifecycle event of a batch of the node.
+type Event struct {
+	Type      string         `json:"type"`
+	Timestamp int64          `json:"timestamp"`
+	BatchID   string         `json:"batchID"`
+	Amount    *bigint.BigInt `json:"amount,omitempty"`    // amount per chunk of a creation or a top-up
+	Depth     uint8          `json:"depth,omitempty"`     // depth of the batch after a creation or a dilution
+	BatchTTL  int64          `json:"batchTTL,omitempty"`  // time to live of the batch in seconds of an expiry warning
+	Threshold int64          `json:"threshold,omitempty"` // crossed expiry threshold in seconds of an expiry warning
+}
+
+// Options are the options of the Notifier.
+type Options struct {
+	// WebhookURL is the URL the events are posted to.
+	// The empty value disables the webhook.
+	WebhookURL string
+	// ExpiryThresholds are the times to live of the batches
+	// below which the expiry warnings are published.
+	ExpiryThresholds []time.Duration
+	// Interval is the time between two checks of the times to live
+	// of the batches. The zero value disables the expiry warnings.
+	Interval time.Duration
+	// SyncStatus reports whether the postage
+	// events are synced with the blockchain.
+	SyncStatus func() (isDone bool, err error)
+}
+
+// Notifier publishes the lifecycle events of the batches of the node.
+// It implements the postage.BatchEventListener interface, passing the
+// events on to the wrapped listener.
+type Notifier struct {
+	logger     log.Logger
+	listener   postage.BatchEventListener
+	batchStore postage.Storer
+	post       postage.Service
+	blockTime  *big.Int
+	thresholds []time.Duration // from the highest to the lowest
+	syncStatus func() (isDone bool, err error)
+	metrics    metrics
+
+	checkMu  sync.Mutex               // serializes the checks of the batches
+	warnedMu sync.Mutex               // guards warned, not held while calling the batch store
+	warned   map[string]time.Duration // lowest threshold warned about per batch
+
+	subscribersMu sync.Mutex
+	subscribers   map[chan Event]struct{}
+
+	webhookURL    string
+	webhookClient *http.Client
+	webhookC      chan Event
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+var _ postage.BatchEventListener = (*Notifier)(nil)
+
+// New constructs a new Notifier of the batches of the postage service.
+// The events received by the notifier as a postage.BatchEventListener are
+// passed on to the listener. The block time is in seconds.
+func New(listener postage.BatchEventListener, batchStore postage.Storer, post postage.Service, blockTime *big.Int, logger log.Logger, o Options) *Notifier {
+	thresholds := make([]time.Duration, 0, len(o.ExpiryThresholds))
+	for _, t := range o.ExpiryThresholds {
+		if t > 0 {
+			thresholds = append(thresholds, t)
+		}
+	}
+	sort.Slice(thresholds, func(i, j int) bool { return thresholds[i] > thresholds[j] })
+
+	n := &Notifier{
+		logger:      logger.WithName(loggerName).Register(),
+		listener:    listener,
+		batchStore:  batchStore,
+		post:        post,
+		blockTime:   blockTime,
+		thresholds:  thresholds,
+		syncStatus:  o.SyncStatus,
+		metrics:     newMetrics(),
+		warned:      make(map[string]time.Duration),
+		subscribers: make(map[chan Event]struct{}),
+		webhookURL:  o.WebhookURL,
+		quit:        make(chan struct{}),
+	}
+
+	if n.webhookURL != "" {
+		n.webhookClient = &http.Client{Timeout: webhookTimeout}
+		n.webhookC = make(chan Event, eventBufferSize)
+		n.wg.Add(1)
+		go n.webhookWorker()
+	}
+
+	if o.Interval > 0 && len(thresholds) > 0 {
+		n.wg.Add(1)
+		go n.worker(o.Interval)
+	}
+	return n
+}
+
+// HandleCreate implements the postage.BatchEventListener interface.
+func (n *Notifier) HandleCreate(b *postage.Batch, amount *big.Int) error {
+	if err := n.listener.HandleCreate(b, amount); err != nil {
+		return err
+	}
+	n.emit(Event{
+		Type:    EventCreate,
+		BatchID: hex.EncodeToString(b.ID),
+		Amount:  bigint.Wrap(amount),
+		Depth:   b.Depth,
+	})
+	return nil
+}
+
+// HandleTopUp implements the postage.BatchEventListener interface.
+func (n *Notifier) HandleTopUp(id []byte, amount *big.Int) {
+	n.listener.HandleTopUp(id, amount)
+	n.emit(Event{
+		Type:    EventTopUp,
+		BatchID: hex.EncodeToString(id),
+		Amount:  bigint.Wrap(amount),
+	})
+}
+
+// HandleDepthIncrease implements the postage.BatchEventListener interface.
+func (n *Notifier) HandleDepthIncrease(id []byte, newDepth uint8) {
+	n.listener.HandleDepthIncrease(id, newDepth)
+	n.emit(Event{
+		Type:    EventDilute,
+		BatchID: hex.EncodeToString(id),
+		Depth:   newDepth,
+	})
+}
+
+// HandleEviction publishes the eviction of the batch from the batch
+// store, if it is one of the batches of the node. It is meant to be
+// called from the eviction function of the batch store.
+func (n *Notifier) HandleEviction(id []byte) {
+	if !n.owned(id) {
+		return
+	}
+
+	n.warnedMu.Lock()
+	delete(n.warned, string(id))
+	n.warnedMu.Unlock()
+
+	n.emit(Event{
+		Type:    EventEvict,
+		BatchID: hex.EncodeToString(id),
+	})
+}
+
+// SubscribeEvents returns the channel receiving the events of the
+// batches. The events are dropped while the buffer of the channel is full.
+func (n *Notifier) SubscribeEvents() (c <-chan Event, unsubscribe func()) {
+	channel := make(chan Event, eventBufferSize)
+	var once sync.Once
+
+	n.subscribersMu.Lock()
+	n.subscribers[channel] = struct{}{}
+	n.subscribersMu.Unlock()
+
+	unsubscribe = func() {
+		n.subscribersMu.Lock()
+		defer n.subscribersMu.Unlock()
+
+		once.Do(func() {
+			delete(n.subscribers, channel)
+			close(channel)
+		})
+	}
+
+	return channel, unsubscribe
+}
+
+// Close stops the checks of the batches and the webhook deliveries.
+func (n *Notifier) Close() error {
+	close(n.quit)
+	n.wg.Wait()
+	return nil
+}
+
+func (n *Notifier) owned(id []byte) bool {
+	for _, si := range n.post.StampIssuers() {
+		if bytes.Equal(si.ID(), id) {
+			return true
+		}
+	}
+	return false
+}
+
+func (n *Notifier) emit(e Event) {
+	e.Timestamp = time.Now().Unix()
+
+	n.metrics.Events.WithLabelValues(e.Type).Inc()
+	n.logger.Debug("batch event", "type", e.Type, "batch_id", e.BatchID)
+
+	n.subscribersMu.Lock()
+	for c := range n.subscribers {
+		select {
+		case c <- e:
+		default:
+			n.metrics.DroppedEvents.Inc()
+		}
+	}
+	n.subscribersMu.Unlock()
+
+	if n.webhookC != nil {
+		select {
+		case n.webhookC <- e:
+		default:
+			n.metrics.DroppedEvents.Inc()
+			n.logger.Warning("batch event dropped, webhook not keeping up", "type", e.Type, "batch_id", e.BatchID)
+		}
+	}
+}
+
+// worker periodically checks the times to live
+// of the batches until the notifier is closed.
+func (n *Notifier) worker(interval time.Duration) {
+	defer n.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-n.quit:
+			return
+		}
+
+		if err := n.check(); err != nil {
+			n.logger.Error(err, "batch expiry check failed")
+		}
+	}
+}
+
+// check publishes an expiry warning for every batch whose time to live
+// fell below an expiry threshold it was not warned about yet. A batch is
+// warned again about the thresholds it crosses after being topped up.
+func (n *Notifier) check() error {
+	n.checkMu.Lock()
+	defer n.checkMu.Unlock()
+
+	if n.syncStatus != nil {
+		done, err := n.syncStatus()
+		if err != nil {
+			return fmt.Errorf("sync status: %w", err)
+		}
+		if !done {
+			return nil
+		}
+	}
+
+	cs := n.batchStore.GetChainState()
+	for _, si := range n.post.StampIssuers() {
+		id := si.ID()
+		b, err := n.batchStore.Get(id)
+		if err != nil {
+			// the batch is not in the batch store yet or was evicted
+			continue
+		}
+
+		ttl := b.TTL(cs, n.blockTime)
+		if ttl < 0 {
+			continue
+		}
+
+		threshold, crossed := n.crossedThreshold(time.Duration(ttl) * time.Second)
+		if !n.warn(id, threshold, crossed) {
+			continue
+		}
+
+		n.emit(Event{
+			Type:      EventExpiry,
+			BatchID:   hex.EncodeToString(id),
+			BatchTTL:  ttl,
+			Threshold: int64(threshold / time.Second),
+		})
+	}
+	return nil
+}
+
+// warn records the threshold crossed by the batch and reports whether
+// the batch should be warned about it. The record is cleared when the
+// batch is not below any threshold, after it was topped up.
+func (n *Notifier) warn(id []byte, threshold time.Duration, crossed bool) bool {
+	n.warnedMu.Lock()
+	defer n.warnedMu.Unlock()
+
+	if !crossed {
+		delete(n.warned, string(id))
+		return false
+	}
+	if warned, ok := n.warned[string(id)]; ok && warned <= threshold {
+		return false
+	}
+	n.warned[string(id)] = threshold
+	return true
+}
+
+// crossedThreshold returns the lowest threshold above the time to live.
+func (n *Notifier) crossedThreshold(ttl time.Duration) (threshold time.Duration, crossed bool) {
+	for _, t := range n.thresholds {
+		if ttl >= t {
+			break
+		}
+		threshold, crossed = t, true
+	}
+	return threshold, crossed
+}
+
+// webhookWorker posts the events to the webhook
+// one by one until the notifier is closed.
+func (n *Notifier) webhookWorker() {
+	defer n.wg.Done()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-n.quit:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	for {
+		select {
+		case e := <-n.webhookC:
+			if err := n.deliver(ctx, e); err != nil {
+				n.metrics.WebhookErrors.Inc()
+				n.logger.Error(err, "batch event webhook failed", "type", e.Type, "batch_id", e.BatchID)
+			}
+		case <-n.quit:
+			return
+		}
+	}
+}
+
+// deliver posts the event to the webhook.
+func (n *Notifier) deliver(ctx context.Context, e Event) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.webhookClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected response status %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,202 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package notifier_test
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/log"
+	"github.com/ethersphere/bee/pkg/postage"
+	mockbatchstore "github.com/ethersphere/bee/pkg/postage/batchstore/mock"
+	mockpost "github.com/ethersphere/bee/pkg/postage/mock"
+	"github.com/ethersphere/bee/pkg/postage/notifier"
+	postagetesting "github.com/ethersphere/bee/pkg/postage/testing"
+)
+
+type listener struct {
+	created   []byte
+	toppedUp  []byte
+	increased []byte
+}
+
+func (l *listener) HandleCreate(b *postage.Batch, _ *big.Int) error {
+	l.created = b.ID
+	return nil
+}
+
+func (l *listener) HandleTopUp(id []byte, _ *big.Int) {
+	l.toppedUp = id
+}
+
+func (l *listener) HandleDepthIncrease(id []byte, _ uint8) {
+	l.increased = id
+}
+
+func receive(t *testing.T, c <-chan notifier.Event) notifier.Event {
+	t.Helper()
+
+	select {
+	case e := <-c:
+		return e
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for event")
+	}
+	return notifier.Event{}
+}
+
+func expectNone(t *testing.T, c <-chan notifier.Event) {
+	t.Helper()
+
+	select {
+	case e := <-c:
+		t.Fatalf("unexpected event %+v", e)
+	default:
+	}
+}
+
+func TestListenerEvents(t *testing.T) {
+	batch := postagetesting.MustNewBatch(postagetesting.WithDepth(20))
+	l := new(listener)
+	n := notifier.New(l, mockbatchstore.New(), mockpost.New(), big.NewInt(5), log.Noop, notifier.Options{})
+	t.Cleanup(func() { _ = n.Close() })
+
+	c, unsubscribe := n.SubscribeEvents()
+	defer unsubscribe()
+
+	if err := n.HandleCreate(batch, big.NewInt(10)); err != nil {
+		t.Fatal(err)
+	}
+	e := receive(t, c)
+	if e.Type != notifier.EventCreate || e.BatchID != hex.EncodeToString(batch.ID) || e.Amount.Int64() != 10 || e.Depth != 20 {
+		t.Fatalf("unexpected event %+v", e)
+	}
+
+	n.HandleTopUp(batch.ID, big.NewInt(30))
+	e = receive(t, c)
+	if e.Type != notifier.EventTopUp || e.Amount.Int64() != 30 {
+		t.Fatalf("unexpected event %+v", e)
+	}
+
+	n.HandleDepthIncrease(batch.ID, 21)
+	e = receive(t, c)
+	if e.Type != notifier.EventDilute || e.Depth != 21 {
+		t.Fatalf("unexpected event %+v", e)
+	}
+
+	if string(l.created) != string(batch.ID) || string(l.toppedUp) != string(batch.ID) || string(l.increased) != string(batch.ID) {
+		t.Fatal("events not passed on to the listener")
+	}
+}
+
+func TestEviction(t *testing.T) {
+	batch := postagetesting.MustNewBatch()
+	issuer := postage.NewStampIssuer("label", "keyID", batch.ID, big.NewInt(3), 16, 8, 1, true)
+	n := notifier.New(new(listener), mockbatchstore.New(), mockpost.New(mockpost.WithIssuer(issuer)), big.NewInt(5), log.Noop, notifier.Options{})
+	t.Cleanup(func() { _ = n.Close() })
+
+	c, unsubscribe := n.SubscribeEvents()
+	defer unsubscribe()
+
+	n.HandleEviction(postagetesting.MustNewID())
+	expectNone(t, c)
+
+	n.HandleEviction(batch.ID)
+	e := receive(t, c)
+	if e.Type != notifier.EventEvict || e.BatchID != hex.EncodeToString(batch.ID) {
+		t.Fatalf("unexpected event %+v", e)
+	}
+}
+
+func TestExpiry(t *testing.T) {
+	batch := postagetesting.MustNewBatch()
+	issuer := postage.NewStampIssuer("label", "keyID", batch.ID, big.NewInt(3), 16, 8, 1, true)
+	batchStore := mockbatchstore.New(
+		mockbatchstore.WithBatch(batch),
+		mockbatchstore.WithChainState(&postage.ChainState{
+			TotalAmount:  big.NewInt(0),
+			CurrentPrice: big.NewInt(1),
+		}),
+	)
+	n := notifier.New(new(listener), batchStore, mockpost.New(mockpost.WithIssuer(issuer)), big.NewInt(1), log.Noop, notifier.Options{
+		ExpiryThresholds: []time.Duration{time.Hour, 24 * time.Hour},
+	})
+	t.Cleanup(func() { _ = n.Close() })
+
+	c, unsubscribe := n.SubscribeEvents()
+	defer unsubscribe()
+
+	// with the price of one per block and the block time of one second,
+	// the time to live of the batch in seconds is its value
+	check := func(ttl time.Duration) {
+		t.Helper()
+
+		batch.Value = big.NewInt(int64(ttl / time.Second))
+		if err := notifier.Check(n); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expectExpiry := func(threshold time.Duration) {
+		t.Helper()
+
+		e := receive(t, c)
+		if e.Type != notifier.EventExpiry || e.Threshold != int64(threshold/time.Second) || e.BatchTTL != batch.Value.Int64() {
+			t.Fatalf("unexpected event %+v", e)
+		}
+	}
+
+	check(48 * time.Hour)
+	expectNone(t, c)
+
+	check(12 * time.Hour)
+	expectExpiry(24 * time.Hour)
+
+	check(11 * time.Hour)
+	expectNone(t, c)
+
+	check(30 * time.Minute)
+	expectExpiry(time.Hour)
+
+	check(10 * time.Minute)
+	expectNone(t, c)
+
+	// topped up above the thresholds
+	check(48 * time.Hour)
+	expectNone(t, c)
+
+	check(12 * time.Hour)
+	expectExpiry(24 * time.Hour)
+}
+
+func TestWebhook(t *testing.T) {
+	received := make(chan notifier.Event, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var e notifier.Event
+		if err := json.NewDecoder(r.Body).Decode(&e); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		received <- e
+	}))
+	defer server.Close()
+
+	batch := postagetesting.MustNewBatch()
+	n := notifier.New(new(listener), mockbatchstore.New(), mockpost.New(), big.NewInt(5), log.Noop, notifier.Options{
+		WebhookURL: server.URL,
+	})
+	t.Cleanup(func() { _ = n.Close() })
+
+	n.HandleTopUp(batch.ID, big.NewInt(30))
+
+	e := receive(t, received)
+	if e.Type != notifier.EventTopUp || e.BatchID != hex.EncodeToString(batch.ID) || e.Amount.Int64() != 30 || e.Timestamp == 0 {
+		t.Fatalf("unexpected event %+v", e)
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package policy
+
+import (
+	m "github.com/ethersphere/bee/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type metrics struct {
+	TopUpCounter          prometheus.Counter
+	DiluteCounter         prometheus.Counter
+	FailedActionCounter   prometheus.Counter
+	BudgetExceededCounter prometheus.Counter
+}
+
+func newMetrics() metrics {
+	subsystem := "postage_policy"
+
+	return metrics{
+		TopUpCounter: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "topups",
+			Help:      "Total number of batches topped up.",
+		}),
+		DiluteCounter: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "dilutions",
+			Help:      "Total number of batches diluted.",
+		}),
+		FailedActionCounter: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "failed_actions",
+			Help:      "Total number of failed top-ups and dilutions.",
+		}),
+		BudgetExceededCounter: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "budget_exceeded",
+			Help:      "Total number of top-ups skipped because of the budget.",
+		}),
+	}
+}
+
+func (s *Service) Metrics() []prometheus.Collector {
+	return m.PrometheusCollectorsFromFields(s.metrics)
+}
@@ -0,0 +1,471 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package policy provides the automatic top-up and dilution of the postage
+// batches of the node. The policy of a batch defines when the batch is topped
+// up because its time to live is running out and when it is diluted because
+// it is filling up. The top-ups are limited by a global spending budget and
+// every action taken is recorded in the statestore.
+package policy
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/log"
+	"github.com/ethersphere/bee/pkg/postage"
+	"github.com/ethersphere/bee/pkg/postage/postagecontract"
+	"github.com/ethersphere/bee/pkg/storage"
+)
+
+// loggerName is the tree path name of the logger for this package.
+const loggerName = "postage-policy"
+
+const (
+	policyKeyPrefix = "postage_policy_batch_"
+	actionKeyPrefix = "postage_policy_action_"
+	spentKey        = "postage_policy_spent"
+)
+
+const (
+	// ActionTopUp is the type of the actions topping up a batch.
+	ActionTopUp = "topup"
+	// ActionDilute is the type of the actions diluting a batch.
+	ActionDilute = "dilute"
+)
+
+var (
+	// ErrNotFound is returned when the batch has no policy.
+	ErrNotFound = errors.New("policy not found")
+	// ErrInvalidPolicy is returned when the policy is not valid.
+	ErrInvalidPolicy = errors.New("invalid policy")
+	// ErrUnknownBatch is returned when the policy is set for
+	// a batch without a stamp issuer of the node.
+	ErrUnknownBatch = errors.New("unknown batch")
+)
+
+// Policy defines the automatic actions taken for a batch.
+type Policy struct {
+	// TopUpTTL is the time to live of the batch below which it is topped up.
+	// The zero value disables the top-ups.
+	TopUpTTL time.Duration `json:"topUpTTL"`
+	// TopUpAmount is the amount per chunk added to the batch by a top-up.
+	TopUpAmount *big.Int `json:"topUpAmount"`
+	// DiluteUtilization is the utilization of the batch in percent at which
+	// its depth is increased by one. The zero value disables the dilution.
+	DiluteUtilization uint8 `json:"diluteUtilization"`
+	// MaxDepth is the depth of the batch up to which it is diluted.
+	MaxDepth uint8 `json:"maxDepth"`
+}
+
+// Validate returns an ErrInvalidPolicy error if the policy is not valid.
+func (p Policy) Validate() error {
+	switch {
+	case p.TopUpTTL < 0:
+		return fmt.Errorf("%w: negative top-up ttl", ErrInvalidPolicy)
+	case p.TopUpTTL > 0 && (p.TopUpAmount == nil || p.TopUpAmount.Sign() <= 0):
+		return fmt.Errorf("%w: top-up amount not positive", ErrInvalidPolicy)
+	case p.DiluteUtilization > 100:
+		return fmt.Errorf("%w: dilute utilization above 100 percent", ErrInvalidPolicy)
+	case p.DiluteUtilization > 0 && p.MaxDepth == 0:
+		return fmt.Errorf("%w: missing max depth", ErrInvalidPolicy)
+	case p.TopUpTTL == 0 && p.DiluteUtilization == 0:
+		return fmt.Errorf("%w: neither top-up nor dilution enabled", ErrInvalidPolicy)
+	}
+	return nil
+}
+
+// BatchPolicy is the policy of the batch.
+type BatchPolicy struct {
+	BatchID []byte
+	Policy
+}
+
+// Action is a record of a top-up or a dilution of a batch.
+type Action struct {
+	Timestamp int64    `json:"timestamp"`
+	BatchID   []byte   `json:"batchID"`
+	Type      string   `json:"type"`
+	Amount    *big.Int `json:"amount,omitempty"` // amount per chunk of a top-up
+	Depth     uint8    `json:"depth"`            // depth of the batch after the action
+	Cost      *big.Int `json:"cost,omitempty"`   // total amount spent by a top-up
+	Error     string   `json:"error,omitempty"`  // error of a failed action
+}
+
+// Options are the options of the Service.
+type Options struct {
+	// Budget is the total amount that the top-ups may spend.
+	// Nil or zero budget disables the top-ups.
+	Budget *big.Int
+	// Interval is the time between two checks of the
+	// batches. The zero value disables the checks.
+	Interval time.Duration
+	// SyncStatus reports whether the postage
+	// events are synced with the blockchain.
+	SyncStatus func() (isDone bool, err error)
+}
+
+// pendingAction is the state of the batch at the time of
+// an action that is not yet reflected in the batch store.
+type pendingAction struct {
+	value *big.Int
+	depth uint8
+}
+
+// Service applies the policies of the batches.
+type Service struct {
+	logger     log.Logger
+	store      storage.StateStorer
+	batchStore postage.Storer
+	post       postage.Service
+	contract   postagecontract.Interface
+	blockTime  *big.Int
+	budget     *big.Int
+	syncStatus func() (isDone bool, err error)
+	metrics    metrics
+
+	checkMu sync.Mutex // serializes the checks of the batches
+	pending map[string]pendingAction
+	warned  map[string]bool // batches with a budget warning
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// New constructs a new Service. The block time is in seconds.
+func New(store storage.StateStorer, batchStore postage.Storer, post postage.Service, contract postagecontract.Interface, blockTime *big.Int, logger log.Logger, o Options) *Service {
+	s := &Service{
+		logger:     logger.WithName(loggerName).Register(),
+		store:      store,
+		batchStore: batchStore,
+		post:       post,
+		contract:   contract,
+		blockTime:  blockTime,
+		budget:     new(big.Int),
+		syncStatus: o.SyncStatus,
+		metrics:    newMetrics(),
+		pending:    make(map[string]pendingAction),
+		warned:     make(map[string]bool),
+		quit:       make(chan struct{}),
+	}
+	if o.Budget != nil {
+		s.budget.Set(o.Budget)
+	}
+
+	if o.Interval > 0 {
+		s.wg.Add(1)
+		go s.worker(o.Interval)
+	}
+	return s
+}
+
+// Policy returns the policy of the batch.
+func (s *Service) Policy(batchID []byte) (Policy, error) {
+	var p Policy
+	if err := s.store.Get(policyKey(batchID), &p); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return p, ErrNotFound
+		}
+		return p, err
+	}
+	return p, nil
+}
+
+// Policies returns the policies of all batches.
+func (s *Service) Policies() (policies []BatchPolicy, err error) {
+	err = s.store.Iterate(policyKeyPrefix, func(key, value []byte) (bool, error) {
+		id, err := hex.DecodeString(strings.TrimPrefix(string(key), policyKeyPrefix))
+		if err != nil {
+			return true, fmt.Errorf("invalid policy key %q: %w", key, err)
+		}
+		var p Policy
+		if err := json.Unmarshal(value, &p); err != nil {
+			return true, fmt.Errorf("invalid policy %q: %w", key, err)
+		}
+		policies = append(policies, BatchPolicy{BatchID: id, Policy: p})
+		return false, nil
+	})
+	return policies, err
+}
+
+// SetPolicy sets the policy of the batch, which must
+// have a stamp issuer of the node.
+func (s *Service) SetPolicy(batchID []byte, p Policy) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	if s.issuer(batchID) == nil {
+		return ErrUnknownBatch
+	}
+	return s.store.Put(policyKey(batchID), p)
+}
+
+// DeletePolicy removes the policy of the batch.
+func (s *Service) DeletePolicy(batchID []byte) error {
+	if _, err := s.Policy(batchID); err != nil {
+		return err
+	}
+	return s.store.Delete(policyKey(batchID))
+}
+
+// Actions returns the recorded actions from the oldest to the newest.
+func (s *Service) Actions() ([]Action, error) {
+	var (
+		keys    []string
+		actions = make(map[string]Action)
+	)
+	err := s.store.Iterate(actionKeyPrefix, func(key, value []byte) (bool, error) {
+		var a Action
+		if err := json.Unmarshal(value, &a); err != nil {
+			return true, fmt.Errorf("invalid action %q: %w", key, err)
+		}
+		keys = append(keys, string(key))
+		actions[string(key)] = a
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(keys)
+	sorted := make([]Action, 0, len(keys))
+	for _, k := range keys {
+		sorted = append(sorted, actions[k])
+	}
+	return sorted, nil
+}
+
+// Budget returns the total amount that the top-ups may spend.
+func (s *Service) Budget() *big.Int {
+	return new(big.Int).Set(s.budget)
+}
+
+// Spent returns the total amount spent by the top-ups.
+func (s *Service) Spent() (*big.Int, error) {
+	spent := new(big.Int)
+	if err := s.store.Get(spentKey, spent); err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return nil, err
+	}
+	return spent, nil
+}
+
+// Close stops the checks of the batches.
+func (s *Service) Close() error {
+	close(s.quit)
+	s.wg.Wait()
+	return nil
+}
+
+// worker periodically checks the batches
+// until the service is closed.
+func (s *Service) worker(interval time.Duration) {
+	defer s.wg.Done()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-s.quit:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-s.quit:
+			return
+		}
+
+		if err := s.check(ctx); err != nil {
+			s.logger.Error(err, "postage policy check failed")
+		}
+	}
+}
+
+// check applies the policies of all batches. The batches are not checked
+// while the postage events are being synced, as their state is not current.
+func (s *Service) check(ctx context.Context) error {
+	s.checkMu.Lock()
+	defer s.checkMu.Unlock()
+
+	if s.syncStatus != nil {
+		done, err := s.syncStatus()
+		if err != nil {
+			return fmt.Errorf("sync status: %w", err)
+		}
+		if !done {
+			s.logger.Debug("postage policy check skipped, syncing in progress")
+			return nil
+		}
+	}
+
+	policies, err := s.Policies()
+	if err != nil {
+		return fmt.Errorf("policies: %w", err)
+	}
+	for _, p := range policies {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := s.apply(ctx, p); err != nil {
+			s.logger.Error(err, "postage policy action failed", "batch_id", hex.EncodeToString(p.BatchID))
+		}
+	}
+	return nil
+}
+
+// apply takes at most one action for the batch. No action is taken until
+// the previous action for the batch is reflected in the batch store.
+func (s *Service) apply(ctx context.Context, p BatchPolicy) error {
+	batch, err := s.batchStore.Get(p.BatchID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			// expired or not yet synced
+			return nil
+		}
+		return err
+	}
+
+	key := string(p.BatchID)
+	if pa, ok := s.pending[key]; ok {
+		if batch.Value.Cmp(pa.value) == 0 && batch.Depth == pa.depth {
+			return nil
+		}
+		delete(s.pending, key)
+	}
+
+	if p.TopUpTTL > 0 {
+		ttl := batch.TTL(s.batchStore.GetChainState(), s.blockTime)
+		if ttl >= 0 && ttl < int64(p.TopUpTTL/time.Second) {
+			return s.topUp(ctx, batch, p.TopUpAmount)
+		}
+	}
+
+	if p.DiluteUtilization > 0 && batch.Depth < p.MaxDepth {
+		issuer := s.issuer(p.BatchID)
+		if issuer == nil {
+			return nil
+		}
+		utilization := uint64(issuer.Utilization()) * 100 / uint64(issuer.BucketUpperBound())
+		if utilization >= uint64(p.DiluteUtilization) {
+			return s.dilute(ctx, batch, batch.Depth+1)
+		}
+	}
+	return nil
+}
+
+// topUp tops up the batch with the amount per chunk if the cost fits into
+// the budget. The cost is charged to the budget only if the top-up succeeds.
+func (s *Service) topUp(ctx context.Context, batch *postage.Batch, amount *big.Int) error {
+	key := string(batch.ID)
+	cost := new(big.Int).Lsh(amount, uint(batch.Depth))
+
+	spent, err := s.Spent()
+	if err != nil {
+		return err
+	}
+	spent.Add(spent, cost)
+	if spent.Cmp(s.budget) > 0 {
+		s.metrics.BudgetExceededCounter.Inc()
+		if !s.warned[key] {
+			s.warned[key] = true
+			s.logger.Warning("postage policy top-up exceeds the budget", "batch_id", hex.EncodeToString(batch.ID), "cost", cost, "budget", s.budget)
+		}
+		return nil
+	}
+	delete(s.warned, key)
+
+	a := Action{
+		BatchID: batch.ID,
+		Type:    ActionTopUp,
+		Amount:  amount,
+		Depth:   batch.Depth,
+		Cost:    cost,
+	}
+	err = s.contract.TopUpBatch(ctx, batch.ID, amount)
+	if err != nil {
+		return s.record(a, nil, fmt.Errorf("top up: %w", err))
+	}
+	s.metrics.TopUpCounter.Inc()
+	s.pending[key] = pendingAction{value: batch.Value, depth: batch.Depth}
+	s.logger.Info("postage policy topped up batch", "batch_id", hex.EncodeToString(batch.ID), "amount", amount, "cost", cost)
+	return s.record(a, spent, nil)
+}
+
+// dilute increases the depth of the batch.
+func (s *Service) dilute(ctx context.Context, batch *postage.Batch, depth uint8) error {
+	a := Action{
+		BatchID: batch.ID,
+		Type:    ActionDilute,
+		Depth:   depth,
+	}
+	err := s.contract.DiluteBatch(ctx, batch.ID, depth)
+	if err != nil {
+		return s.record(a, nil, fmt.Errorf("dilute: %w", err))
+	}
+	s.metrics.DiluteCounter.Inc()
+	s.pending[string(batch.ID)] = pendingAction{value: batch.Value, depth: batch.Depth}
+	s.logger.Info("postage policy diluted batch", "batch_id", hex.EncodeToString(batch.ID), "depth", depth)
+	return s.record(a, nil, nil)
+}
+
+// record stores the action together with the new total amount spent, if
+// any. The error of the action is recorded and returned.
+func (s *Service) record(a Action, spent *big.Int, actionErr error) error {
+	now := time.Now()
+	a.Timestamp = now.Unix()
+	if actionErr != nil {
+		s.metrics.FailedActionCounter.Inc()
+		a.Error = actionErr.Error()
+	}
+
+	b := s.store.Batch()
+	defer b.Discard()
+
+	if spent != nil {
+		if err := b.Put(spentKey, spent); err != nil {
+			return err
+		}
+	}
+	if err := b.Put(actionKey(now, a.BatchID), a); err != nil {
+		return err
+	}
+	if err := b.Commit(); err != nil {
+		return fmt.Errorf("record action: %w", err)
+	}
+	return actionErr
+}
+
+// issuer returns the stamp issuer of the batch or nil if there is none.
+func (s *Service) issuer(batchID []byte) *postage.StampIssuer {
+	for _, si := range s.post.StampIssuers() {
+		if bytes.Equal(si.ID(), batchID) {
+			return si
+		}
+	}
+	return nil
+}
+
+func policyKey(batchID []byte) string {
+	return policyKeyPrefix + hex.EncodeToString(batchID)
+}
+
+// actionKey returns the key of the action, which sorts by the time.
+func actionKey(t time.Time, batchID []byte) string {
+	return fmt.Sprintf("%s%020d_%x", actionKeyPrefix, t.UnixNano(), batchID)
+}
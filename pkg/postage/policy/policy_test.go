@@ -0,0 +1,258 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package policy_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/log"
+	"github.com/ethersphere/bee/pkg/postage"
+	batchstoremock "github.com/ethersphere/bee/pkg/postage/batchstore/mock"
+	postagemock "github.com/ethersphere/bee/pkg/postage/mock"
+	"github.com/ethersphere/bee/pkg/postage/policy"
+	contractmock "github.com/ethersphere/bee/pkg/postage/postagecontract/mock"
+	postagetesting "github.com/ethersphere/bee/pkg/postage/testing"
+	statestore "github.com/ethersphere/bee/pkg/statestore/mock"
+)
+
+// newTestIssuer returns a stamp issuer of the batch with the
+// fullest bucket holding maxBucketCount chunks.
+func newTestIssuer(t *testing.T, batch *postage.Batch, bucketDepth uint8, maxBucketCount uint32) *postage.StampIssuer {
+	t.Helper()
+
+	data, err := json.Marshal(map[string]interface{}{
+		"batchID":        batch.ID,
+		"batchAmount":    batch.Value,
+		"batchDepth":     batch.Depth,
+		"bucketDepth":    bucketDepth,
+		"buckets":        make([]uint32, 1<<bucketDepth),
+		"maxBucketCount": maxBucketCount,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	si := new(postage.StampIssuer)
+	if err := json.Unmarshal(data, si); err != nil {
+		t.Fatal(err)
+	}
+	return si
+}
+
+func TestPolicies(t *testing.T) {
+	batch := postagetesting.MustNewBatch()
+	svc := policy.New(
+		statestore.NewStateStore(),
+		batchstoremock.New(batchstoremock.WithBatch(batch)),
+		postagemock.New(postagemock.WithIssuer(newTestIssuer(t, batch, 2, 0))),
+		contractmock.New(),
+		big.NewInt(5),
+		log.Noop,
+		policy.Options{},
+	)
+	defer svc.Close()
+
+	for _, p := range []policy.Policy{
+		{},
+		{TopUpTTL: -time.Hour, TopUpAmount: big.NewInt(1)},
+		{TopUpTTL: time.Hour},
+		{TopUpTTL: time.Hour, TopUpAmount: big.NewInt(0)},
+		{DiluteUtilization: 101, MaxDepth: 30},
+		{DiluteUtilization: 50},
+	} {
+		if err := svc.SetPolicy(batch.ID, p); !errors.Is(err, policy.ErrInvalidPolicy) {
+			t.Fatalf("policy %+v: got error %v, want %v", p, err, policy.ErrInvalidPolicy)
+		}
+	}
+
+	want := policy.Policy{
+		TopUpTTL:          time.Hour,
+		TopUpAmount:       big.NewInt(10),
+		DiluteUtilization: 80,
+		MaxDepth:          30,
+	}
+	if err := svc.SetPolicy(postagetesting.MustNewID(), want); !errors.Is(err, policy.ErrUnknownBatch) {
+		t.Fatalf("got error %v, want %v", err, policy.ErrUnknownBatch)
+	}
+	if _, err := svc.Policy(batch.ID); !errors.Is(err, policy.ErrNotFound) {
+		t.Fatalf("got error %v, want %v", err, policy.ErrNotFound)
+	}
+	if err := svc.SetPolicy(batch.ID, want); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := svc.Policy(batch.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.TopUpTTL != want.TopUpTTL || got.TopUpAmount.Cmp(want.TopUpAmount) != 0 || got.DiluteUtilization != want.DiluteUtilization || got.MaxDepth != want.MaxDepth {
+		t.Fatalf("got policy %+v, want %+v", got, want)
+	}
+	policies, err := svc.Policies()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(policies) != 1 || string(policies[0].BatchID) != string(batch.ID) {
+		t.Fatalf("got policies %+v, want the policy of batch %x", policies, batch.ID)
+	}
+
+	if err := svc.DeletePolicy(batch.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.DeletePolicy(batch.ID); !errors.Is(err, policy.ErrNotFound) {
+		t.Fatalf("got error %v, want %v", err, policy.ErrNotFound)
+	}
+	if policies, err := svc.Policies(); err != nil || len(policies) != 0 {
+		t.Fatalf("got policies %+v, error %v, want none", policies, err)
+	}
+}
+
+func TestTopUp(t *testing.T) {
+	batch := postagetesting.MustNewBatch()
+	batch.Value = big.NewInt(100)
+	batch.Depth = 4
+	chainState := &postage.ChainState{
+		TotalAmount:  big.NewInt(0),
+		CurrentPrice: big.NewInt(1),
+	}
+
+	var topUps int
+	contract := contractmock.New(
+		contractmock.WithTopUpBatchFunc(func(_ context.Context, batchID []byte, amount *big.Int) error {
+			if string(batchID) != string(batch.ID) {
+				t.Fatalf("got batch id %x, want %x", batchID, batch.ID)
+			}
+			if amount.Cmp(big.NewInt(10)) != 0 {
+				t.Fatalf("got amount %v, want 10", amount)
+			}
+			topUps++
+			return nil
+		}),
+	)
+
+	svc := policy.New(
+		statestore.NewStateStore(),
+		batchstoremock.New(batchstoremock.WithBatch(batch), batchstoremock.WithChainState(chainState)),
+		postagemock.New(postagemock.WithIssuer(newTestIssuer(t, batch, 2, 0))),
+		contract,
+		big.NewInt(5),
+		log.Noop,
+		policy.Options{Budget: big.NewInt(300)},
+	)
+	defer svc.Close()
+
+	// the batch expires in 500 seconds
+	err := svc.SetPolicy(batch.ID, policy.Policy{TopUpTTL: 10 * time.Minute, TopUpAmount: big.NewInt(10)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	check := func(wantTopUps int, wantSpent int64) {
+		t.Helper()
+
+		if err := policy.Check(svc, context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		if topUps != wantTopUps {
+			t.Fatalf("got %d top-ups, want %d", topUps, wantTopUps)
+		}
+		spent, err := svc.Spent()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if spent.Cmp(big.NewInt(wantSpent)) != 0 {
+			t.Fatalf("got spent %v, want %d", spent, wantSpent)
+		}
+	}
+
+	check(1, 160)
+	// the top-up is not yet reflected in the batch store
+	check(1, 160)
+	// the top-up is reflected, but the batch expires in 550 seconds
+	batch.Value = big.NewInt(110)
+	check(1, 160) // the next top-up exceeds the budget
+
+	actions, err := svc.Actions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(actions) != 1 {
+		t.Fatalf("got %d actions, want 1", len(actions))
+	}
+	a := actions[0]
+	if a.Type != policy.ActionTopUp || a.Amount.Cmp(big.NewInt(10)) != 0 || a.Cost.Cmp(big.NewInt(160)) != 0 || a.Error != "" {
+		t.Fatalf("got action %+v", a)
+	}
+}
+
+func TestDilute(t *testing.T) {
+	batch := postagetesting.MustNewBatch()
+	batch.Depth = 4
+
+	var (
+		dilutions []uint8
+		diluteErr = errors.New("dilute error")
+	)
+	contract := contractmock.New(
+		contractmock.WithDiluteBatchFunc(func(_ context.Context, _ []byte, depth uint8) error {
+			dilutions = append(dilutions, depth)
+			if len(dilutions) == 1 {
+				return diluteErr
+			}
+			return nil
+		}),
+	)
+
+	svc := policy.New(
+		statestore.NewStateStore(),
+		batchstoremock.New(batchstoremock.WithBatch(batch)),
+		// the fullest bucket is filled at 75 percent
+		postagemock.New(postagemock.WithIssuer(newTestIssuer(t, batch, 2, 3))),
+		contract,
+		big.NewInt(5),
+		log.Noop,
+		policy.Options{},
+	)
+	defer svc.Close()
+
+	err := svc.SetPolicy(batch.ID, policy.Policy{DiluteUtilization: 75, MaxDepth: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if err := policy.Check(svc, context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the dilution is reflected in the batch store
+	batch.Depth = 5
+	if err := policy.Check(svc, context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// the failed dilution is retried, the successful one is not repeated
+	if len(dilutions) != 2 || dilutions[0] != 5 || dilutions[1] != 5 {
+		t.Fatalf("got dilutions %v, want [5 5]", dilutions)
+	}
+
+	actions, err := svc.Actions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(actions) != 2 {
+		t.Fatalf("got %d actions, want 2", len(actions))
+	}
+	if a := actions[0]; a.Type != policy.ActionDilute || a.Depth != 5 || a.Error == "" {
+		t.Fatalf("got action %+v, want a failed dilution", a)
+	}
+	if a := actions[1]; a.Type != policy.ActionDilute || a.Depth != 5 || a.Error != "" {
+		t.Fatalf("got action %+v, want a dilution", a)
+	}
+}
@@ -76,13 +76,10 @@ func (ps *service) Add(st *StampIssuer) error {
 	ps.lock.Lock()
 	defer ps.lock.Unlock()
 
-	if ps.add(st) {
-		if err := ps.store.Put(ps.keyForIndex(len(ps.issuers)), st); err != nil {
-			return err
-		}
+	if !ps.add(st) {
+		return nil
 	}
-
-	return nil
+	return ps.save()
 }
 
 // add adds a stamp issuer to the active issuers and returns false if it is already present.
@@ -178,12 +175,24 @@ func (ps *service) GetStampIssuer(batchID []byte) (*StampIssuer, error) {
 
 // Close saves all the active stamp issuers to statestore.
 func (ps *service) Close() error {
+	ps.lock.Lock()
+	defer ps.lock.Unlock()
+
+	return ps.save()
+}
+
+// save stores all the active stamp issuers, keyed by their index, in one
+// batch, so that the stored issuers are never a mix of two states.
+func (ps *service) save() error {
+	batch := ps.store.Batch()
+	defer batch.Discard()
+
 	for i, st := range ps.issuers {
-		if err := ps.store.Put(ps.keyForIndex(i), st); err != nil {
+		if err := batch.Put(ps.keyForIndex(i), st); err != nil {
 			return err
 		}
 	}
-	return nil
+	return batch.Commit()
 }
 
 // keyForIndex returns the statestore key for an issuer
@@ -5,6 +5,7 @@
 package postage_test
 
 import (
+	"bytes"
 	crand "crypto/rand"
 	"io"
 	"math/big"
@@ -63,6 +64,36 @@ func TestSaveLoad(t *testing.T) {
 	test(1)
 }
 
+// TestAddSaved tests that the added stamp issuers are
+// saved without closing the postage.Service.
+func TestAddSaved(t *testing.T) {
+	store := storemock.NewStateStore()
+	pstore := pstoremock.New()
+
+	ps, err := postage.NewService(store, pstore, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 4; i++ {
+		if err := ps.Add(newTestStampIssuer(t, 1000)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	loaded, err := postage.NewService(store, pstore, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(loaded.StampIssuers()); got != 4 {
+		t.Fatalf("got %d stamp issuers, want 4", got)
+	}
+	for i, st := range loaded.StampIssuers() {
+		if want := ps.StampIssuers()[i]; !bytes.Equal(st.ID(), want.ID()) {
+			t.Fatalf("stamp issuer %d: got %x, want %x", i, st.ID(), want.ID())
+		}
+	}
+}
+
 func TestGetStampIssuer(t *testing.T) {
 	store := storemock.NewStateStore()
 	testChainState := postagetesting.NewChainState()
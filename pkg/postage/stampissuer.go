@@ -6,6 +6,7 @@ package postage
 
 import (
 	"encoding/binary"
+	"encoding/json"
 	"math/big"
 	"sync"
 
@@ -18,16 +19,16 @@ import (
 // serialization/deserialization easier and at the same
 // time not to export the fields outside of the package.
 type stampIssuerData struct {
-	Label          string   `msgpack:"label"`          // Label to identify the batch period/importance.
-	KeyID          string   `msgpack:"keyID"`          // Owner identity.
-	BatchID        []byte   `msgpack:"batchID"`        // The batch stamps are issued from.
-	BatchAmount    *big.Int `msgpack:"batchAmount"`    // Amount paid for the batch.
-	BatchDepth     uint8    `msgpack:"batchDepth"`     // Batch depth: batch size = 2^{depth}.
-	BucketDepth    uint8    `msgpack:"bucketDepth"`    // Bucket depth: the depth of collision Buckets uniformity.
-	Buckets        []uint32 `msgpack:"buckets"`        // Collision Buckets: counts per neighbourhoods (limited to 2^{batchdepth-bucketdepth}).
-	MaxBucketCount uint32   `msgpack:"maxBucketCount"` // the count of the fullest bucket
-	BlockNumber    uint64   `msgpack:"blockNumber"`    // BlockNumber when this batch was created
-	ImmutableFlag  bool     `msgpack:"immutableFlag"`  // Specifies immutability of the created batch.
+	Label          string   `msgpack:"label" json:"label"`                   // Label to identify the batch period/importance.
+	KeyID          string   `msgpack:"keyID" json:"keyID"`                   // Owner identity.
+	BatchID        []byte   `msgpack:"batchID" json:"batchID"`               // The batch stamps are issued from.
+	BatchAmount    *big.Int `msgpack:"batchAmount" json:"batchAmount"`       // Amount paid for the batch.
+	BatchDepth     uint8    `msgpack:"batchDepth" json:"batchDepth"`         // Batch depth: batch size = 2^{depth}.
+	BucketDepth    uint8    `msgpack:"bucketDepth" json:"bucketDepth"`       // Bucket depth: the depth of collision Buckets uniformity.
+	Buckets        []uint32 `msgpack:"buckets" json:"buckets"`               // Collision Buckets: counts per neighbourhoods (limited to 2^{batchdepth-bucketdepth}).
+	MaxBucketCount uint32   `msgpack:"maxBucketCount" json:"maxBucketCount"` // the count of the fullest bucket
+	BlockNumber    uint64   `msgpack:"blockNumber" json:"blockNumber"`       // BlockNumber when this batch was created
+	ImmutableFlag  bool     `msgpack:"immutableFlag" json:"immutableFlag"`   // Specifies immutability of the created batch.
 }
 
 // StampIssuer is a local extension of a batch issuing stamps for uploads.
@@ -114,6 +115,16 @@ func (si *StampIssuer) UnmarshalBinary(data []byte) error {
 	return msgpack.Unmarshal(data, &si.data)
 }
 
+// MarshalJSON implements the json.Marshaler interface.
+func (si *StampIssuer) MarshalJSON() ([]byte, error) {
+	return json.Marshal(si.data)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (si *StampIssuer) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &si.data)
+}
+
 // Utilization returns the batch utilization in the form of
 // an integer between 0 and 4294967295. Batch fullness can be
 // calculated with: max_bucket_value / 2 ^ (batch_depth - bucket_depth)
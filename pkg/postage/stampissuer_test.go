@@ -6,6 +6,7 @@ package postage_test
 
 import (
 	crand "crypto/rand"
+	"encoding/json"
 	"io"
 	"math/big"
 	"reflect"
@@ -31,6 +32,23 @@ func TestStampIssuerMarshalling(t *testing.T) {
 	}
 }
 
+// TestStampIssuerJSONMarshalling tests the idempotence of json marshal/unmarshal.
+func TestStampIssuerJSONMarshalling(t *testing.T) {
+	st := newTestStampIssuer(t, 1000)
+	buf, err := json.Marshal(st)
+	if err != nil {
+		t.Fatal(err)
+	}
+	st0 := &postage.StampIssuer{}
+	err = json.Unmarshal(buf, st0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(st, st0) {
+		t.Fatalf("unmarshal(marshal(StampIssuer)) != StampIssuer \n%v\n%v", st, st0)
+	}
+}
+
 func newTestStampIssuer(t *testing.T, block uint64) *postage.StampIssuer {
 	t.Helper()
 	id := make([]byte, 32)
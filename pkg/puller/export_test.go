@@ -1,6 +1,15 @@
 package puller
 
+import "time"
+
 var (
 	PeerIntervalKey = peerIntervalKey
 	IsSyncing       = isSyncing
 )
+
+func SetPausedCheckInterval(d time.Duration) (reset func()) {
+	current := pausedCheckInterval
+	reset = func() { pausedCheckInterval = current }
+	pausedCheckInterval = d
+	return reset
+}
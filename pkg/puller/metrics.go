@@ -16,6 +16,7 @@ type metrics struct {
 	LiveWorkerIterCounter prometheus.Counter // counts the number of live syncing iterations
 	LiveWorkerErrCounter  prometheus.Counter // count number of errors
 	MaxUintErrCounter     prometheus.Counter // how many times we got maxuint as topmost
+	PausedCounter         prometheus.Counter // how many times a worker waited while syncing was paused
 }
 
 func newMetrics() metrics {
@@ -58,6 +59,12 @@ func newMetrics() metrics {
 			Name:      "max_uint_errors",
 			Help:      "Total max uint errors.",
 		}),
+		PausedCounter: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "paused",
+			Help:      "Total times a sync worker waited while syncing was paused.",
+		}),
 	}
 }
 
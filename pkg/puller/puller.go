@@ -29,8 +29,15 @@ const (
 	cursorPruneTimeout = 24 * time.Hour
 )
 
+// pausedCheckInterval is the time between two checks
+// whether the syncing is still paused.
+var pausedCheckInterval = 5 * time.Second
+
 type Options struct {
 	Bins uint8
+	// Paused reports whether the syncing should be paused,
+	// for example while the local storage is running out of space.
+	Paused func() bool
 }
 
 type Puller struct {
@@ -51,6 +58,8 @@ type Puller struct {
 	wg   sync.WaitGroup
 
 	bins uint8 // how many bins do we support
+
+	paused func() bool
 }
 
 func New(stateStore storage.StateStorer, topology topology.Driver, pullSync pullsync.Interface, logger log.Logger, o Options, warmupTime time.Duration) *Puller {
@@ -74,6 +83,8 @@ func New(stateStore storage.StateStorer, topology topology.Driver, pullSync pull
 		wg:        sync.WaitGroup{},
 
 		bins: bins,
+
+		paused: o.Paused,
 	}
 
 	for i := uint8(0); i < bins; i++ {
@@ -349,6 +360,11 @@ func (p *Puller) histSyncWorker(ctx context.Context, peer swarm.Address, bin uin
 		default:
 		}
 
+		if !p.waitResumed(ctx) {
+			loggerV2.Debug("histSyncWorker quitting while paused", "peer_address", peer, "bin", bin, "cursor", cur)
+			return
+		}
+
 		s, _, _, err := p.nextPeerInterval(peer, bin)
 		if err != nil {
 			p.metrics.HistWorkerErrCounter.Inc()
@@ -404,6 +420,12 @@ func (p *Puller) liveSyncWorker(ctx context.Context, peer swarm.Address, bin uin
 			return
 		default:
 		}
+
+		if !p.waitResumed(ctx) {
+			loggerV2.Debug("liveSyncWorker quitting while paused", "peer_address", peer, "bin", bin, "cursor", cur)
+			return
+		}
+
 		top, ruid, err := p.syncer.SyncInterval(ctx, peer, bin, from, math.MaxUint64)
 		if err != nil {
 			loggerV2.Debug("liveSyncWorker exit on sync error", "peer_address", peer, "bin", bin, "from", from, "error", err)
@@ -438,6 +460,25 @@ func (p *Puller) liveSyncWorker(ctx context.Context, peer swarm.Address, bin uin
 	}
 }
 
+// waitResumed blocks while the syncing is paused. It returns false
+// if the puller is closed or the context is cancelled in the meantime.
+func (p *Puller) waitResumed(ctx context.Context) bool {
+	if p.paused == nil {
+		return true
+	}
+	for p.paused() {
+		p.metrics.PausedCounter.Inc()
+		select {
+		case <-time.After(pausedCheckInterval):
+		case <-p.quit:
+			return false
+		case <-ctx.Done():
+			return false
+		}
+	}
+	return true
+}
+
 func (p *Puller) Close() error {
 	p.logger.Info("puller shutting down")
 	close(p.quit)
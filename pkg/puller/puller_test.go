@@ -7,6 +7,7 @@ package puller_test
 import (
 	"errors"
 	"math"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -59,6 +60,46 @@ func TestOneSync(t *testing.T) {
 	waitSyncCalled(t, pullsync, addr, false)
 }
 
+// test that syncing waits while it is paused
+func TestSyncPaused(t *testing.T) {
+	t.Cleanup(puller.SetPausedCheckInterval(10 * time.Millisecond))
+
+	var (
+		addr        = test.RandomAddress()
+		cursors     = []uint64{1000, 1000, 1000}
+		liveReplies = []uint64{1001}
+		paused      atomic.Value
+	)
+	paused.Store(true)
+
+	puller, _, kad, pullsync := newPuller(opts{
+		kad: []mockk.Option{
+			mockk.WithEachPeerRevCalls(
+				mockk.AddrTuple{Addr: addr, PO: 1},
+			), mockk.WithDepth(1),
+		},
+		pullSync: []mockps.Option{mockps.WithCursors(cursors), mockps.WithLiveSyncReplies(liveReplies...)},
+		bins:     3,
+		paused:   func() bool { return paused.Load().(bool) },
+	})
+	defer puller.Close()
+	defer pullsync.Close()
+	time.Sleep(100 * time.Millisecond)
+
+	kad.Trigger()
+
+	waitCursorsCalled(t, pullsync, addr, false)
+
+	waitSyncCalled(t, pullsync, addr, true)
+	if calls := pullsync.LiveSyncCalls(addr); len(calls) > 0 {
+		t.Fatalf("got %d live sync calls while paused", len(calls))
+	}
+
+	paused.Store(false)
+
+	waitSyncCalled(t, pullsync, addr, false)
+}
+
 func TestNoSyncOutsideDepth(t *testing.T) {
 	var (
 		addr        = test.RandomAddress()
@@ -585,6 +626,7 @@ type opts struct {
 	pullSync []mockps.Option
 	kad      []mockk.Option
 	bins     uint8
+	paused   func() bool
 }
 
 func newPuller(ops opts) (*puller.Puller, storage.StateStorer, *mockk.Mock, *mockps.PullSyncMock) {
@@ -594,7 +636,8 @@ func newPuller(ops opts) (*puller.Puller, storage.StateStorer, *mockk.Mock, *moc
 	logger := log.Noop
 
 	o := puller.Options{
-		Bins: ops.bins,
+		Bins:   ops.bins,
+		Paused: ops.paused,
 	}
 	return puller.New(s, kad, ps, logger, o, 0), s, kad, ps
 }
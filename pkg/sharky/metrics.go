@@ -11,15 +11,19 @@ import (
 
 // metrics groups sharky related prometheus counters.
 type metrics struct {
-	TotalWriteCalls      prometheus.Counter
-	TotalWriteCallsErr   prometheus.Counter
-	TotalReadCalls       prometheus.Counter
-	TotalReadCallsErr    prometheus.Counter
-	TotalReleaseCalls    prometheus.Counter
-	TotalReleaseCallsErr prometheus.Counter
-	ShardCount           prometheus.Gauge
-	CurrentShardSize     *prometheus.GaugeVec
-	ShardFragmentation   *prometheus.GaugeVec
+	TotalWriteCalls       prometheus.Counter
+	TotalWriteCallsErr    prometheus.Counter
+	TotalReadCalls        prometheus.Counter
+	TotalReadCallsErr     prometheus.Counter
+	TotalReleaseCalls     prometheus.Counter
+	TotalReleaseCallsErr  prometheus.Counter
+	TotalMoveCalls        prometheus.Counter
+	TotalMoveCallsErr     prometheus.Counter
+	TotalTruncateCalls    prometheus.Counter
+	TotalTruncateCallsErr prometheus.Counter
+	ShardCount            prometheus.Gauge
+	CurrentShardSize      *prometheus.GaugeVec
+	ShardFragmentation    *prometheus.GaugeVec
 }
 
 // newMetrics is a convenient constructor for creating new metrics.
@@ -63,6 +67,30 @@ func newMetrics() metrics {
 			Name:      "total_release_calls_err",
 			Help:      "The total release calls ended up with error.",
 		}),
+		TotalMoveCalls: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "total_move_calls",
+			Help:      "The total move calls made.",
+		}),
+		TotalMoveCallsErr: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "total_move_calls_err",
+			Help:      "The total move calls ended up with error.",
+		}),
+		TotalTruncateCalls: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "total_truncate_calls",
+			Help:      "The total truncate calls made.",
+		}),
+		TotalTruncateCallsErr: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "total_truncate_calls_err",
+			Help:      "The total truncate calls ended up with error.",
+		}),
 		ShardCount: prometheus.NewGauge(prometheus.GaugeOpts{
 			Namespace: m.Namespace,
 			Subsystem: subsystem,
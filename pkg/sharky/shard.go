@@ -71,6 +71,23 @@ type entry struct {
 	err error    // signal for end of operation
 }
 
+// move models the input to a move operation
+type move struct {
+	loc Location   // location of the blob to be moved
+	res chan entry // to put the result through
+}
+
+// truncation models the input to a truncate operation
+type truncation struct {
+	res chan truncated // to put the result through
+}
+
+// truncated models the output result of a truncate operation
+type truncated struct {
+	size int64 // number of bytes reclaimed
+	err  error // signal for end of operation
+}
+
 // read models the input to read operation (the output is an error)
 type read struct {
 	ctx  context.Context
@@ -80,14 +97,16 @@ type read struct {
 
 // shard models a shard writing to a file with periodic offsets due to fixed maxDataSize
 type shard struct {
-	reads       chan read     // channel for reads
-	errc        chan error    // result for reads
-	writes      chan write    // channel for writes
-	index       uint8         // index of the shard
-	maxDataSize int           // max size of blobs
-	file        sharkyFile    // the file handle the shard is writing data to
-	slots       *slots        // component keeping track of freed slots
-	quit        chan struct{} // channel to signal quitting
+	reads       chan read       // channel for reads
+	errc        chan error      // result for reads
+	writes      chan write      // channel for writes
+	moves       chan move       // channel for moves
+	truncations chan truncation // channel for truncations
+	index       uint8           // index of the shard
+	maxDataSize int             // max size of blobs
+	file        sharkyFile      // the file handle the shard is writing data to
+	slots       *slots          // component keeping track of freed slots
+	quit        chan struct{}   // channel to signal quitting
 }
 
 // forever loop processing
@@ -133,6 +152,24 @@ func (sh *shard) process() {
 			free = sh.slots.out // reenable popping a free slot next time we can write
 			writes = nil        // disable popping a write operation until there is a free slot
 
+		case op := <-sh.moves:
+			// give back the slot popped earlier since it may not be the lowest one anymore
+			if writes != nil {
+				sh.slots.in <- slot
+				free = sh.slots.out
+				writes = nil
+			}
+			op.res <- sh.move(op.loc)
+
+		case op := <-sh.truncations:
+			// give back the slot popped earlier so that it can be truncated away
+			if writes != nil {
+				sh.slots.in <- slot
+				free = sh.slots.out
+				writes = nil
+			}
+			op.res <- sh.truncate()
+
 			// pop a free slot
 		case slot = <-free:
 			// only if there is one can we pop a chunk to write otherwise keep back pressure on writes
@@ -184,6 +221,48 @@ func (sh *shard) write(buf []byte, slot uint32) entry {
 	}
 }
 
+// move copies the blob at loc to the lowest free slot if it precedes loc.Slot
+// otherwise the free slot is given back and loc is returned unchanged
+func (sh *shard) move(loc Location) entry {
+	res := make(chan uint32)
+	sh.slots.lowest <- res
+	slot := <-res
+	if slot >= loc.Slot {
+		sh.slots.in <- slot
+		return entry{loc: loc}
+	}
+	buf := make([]byte, loc.Length)
+	if _, err := sh.file.ReadAt(buf, sh.offset(loc.Slot)); err != nil {
+		sh.slots.in <- slot
+		return entry{loc: loc, err: err}
+	}
+	e := sh.write(buf, slot)
+	if e.err != nil {
+		sh.slots.in <- slot
+		return entry{loc: loc, err: e.err}
+	}
+	return e
+}
+
+// truncate discards the free slots at the end of the shard and shrinks the file accordingly
+func (sh *shard) truncate() truncated {
+	res := make(chan uint32)
+	sh.slots.truncations <- res
+	size := sh.offset(<-res)
+
+	end, err := sh.file.Seek(0, io.SeekEnd)
+	if err != nil {
+		return truncated{err: err}
+	}
+	if end <= size {
+		return truncated{}
+	}
+	if err := sh.file.Truncate(size); err != nil {
+		return truncated{err: err}
+	}
+	return truncated{size: end - size}
+}
+
 // release frees the slot allowing new entry to overwrite
 func (sh *shard) release(ctx context.Context, slot uint32) error {
 	select {
@@ -279,3 +279,213 @@ func TestConcurrency(t *testing.T) {
 		})
 	}
 }
+
+// TestMoveAndTruncate tests that blobs are moved to the lowest free slots
+// and that the trailing free slots are truncated away afterwards
+func TestMoveAndTruncate(t *testing.T) {
+	datasize := 4
+	items := 32
+	dir := t.TempDir()
+	s, err := sharky.New(&dirFS{basedir: dir}, 1, datasize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	ctx := context.Background()
+
+	buf := make([]byte, datasize)
+	locs := make([]sharky.Location, items)
+	for i := range locs {
+		binary.BigEndian.PutUint32(buf, uint32(i))
+		locs[i], err = s.Write(ctx, buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	// release the first half of the slots
+	for _, loc := range locs[:items/2] {
+		if err := s.Release(ctx, loc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// move the second half starting from the tail
+	for i := items - 1; i >= items/2; i-- {
+		loc, err := s.Move(ctx, locs[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if loc.Slot >= locs[i].Slot {
+			t.Fatalf("expected a lower slot than %d, got %d", locs[i].Slot, loc.Slot)
+		}
+		if err := s.Release(ctx, locs[i]); err != nil {
+			t.Fatal(err)
+		}
+		locs[i] = loc
+	}
+	// no free slot precedes the moved blobs
+	for _, loc := range locs[items/2:] {
+		got, err := s.Move(ctx, loc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != loc {
+			t.Fatalf("expected location %v to stay, got %v", loc, got)
+		}
+	}
+
+	reclaimed, err := s.Truncate(ctx, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := int64(items / 2 * datasize); reclaimed != want {
+		t.Fatalf("reclaimed bytes mismatch. want %d, got %d", want, reclaimed)
+	}
+	fi, err := os.Stat(filepath.Join(dir, "shard_000"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := int64(items / 2 * datasize); fi.Size() != want {
+		t.Fatalf("shard size mismatch. want %d, got %d", want, fi.Size())
+	}
+
+	for want, loc := range locs[items/2:] {
+		if err := s.Read(ctx, loc, buf); err != nil {
+			t.Fatal(err)
+		}
+		if got := binary.BigEndian.Uint32(buf); int(got) != want+items/2 {
+			t.Fatalf("data mismatch. want %d, got %d", want+items/2, got)
+		}
+	}
+
+	// the truncated shard is extended again on writes
+	loc, err := s.Write(ctx, []byte{1, 2, 3, 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := uint32(items / 2); loc.Slot != want {
+		t.Fatalf("expected write to slot %d, got %d", want, loc.Slot)
+	}
+}
+
+func TestHoldAndCopyShard(t *testing.T) {
+	datasize := 4
+	items := 8
+	s, err := sharky.New(&dirFS{basedir: t.TempDir()}, 1, datasize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	ctx := context.Background()
+
+	buf := make([]byte, datasize)
+	locs := make([]sharky.Location, items)
+	for i := range locs {
+		binary.BigEndian.PutUint32(buf, uint32(i))
+		locs[i], err = s.Write(ctx, buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	unhold := s.Hold()
+	// the released slots are not reused while the hold is active
+	for _, loc := range locs[:items/2] {
+		if err := s.Release(ctx, loc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 0; i < items; i++ {
+		loc, err := s.Write(ctx, []byte{0xff, 0xff, 0xff, 0xff})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if loc.Slot < uint32(items) {
+			t.Fatalf("expected write beyond slot %d during hold, got %d", items, loc.Slot)
+		}
+	}
+
+	size, err := s.ShardSize(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := int64(2 * items * datasize); size != want {
+		t.Fatalf("shard size mismatch. want %d, got %d", want, size)
+	}
+
+	var copied bytes.Buffer
+	n, err := s.CopyShard(&copied, 0, uint32(items+1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := int64((items + 1) * datasize); n != want || int64(copied.Len()) != want {
+		t.Fatalf("copied size mismatch. want %d, got %d", want, n)
+	}
+	for i := 0; i < items; i++ {
+		if got := binary.BigEndian.Uint32(copied.Bytes()[i*datasize:]); int(got) != i {
+			t.Fatalf("copied data mismatch at slot %d. want %d, got %d", i, i, got)
+		}
+	}
+
+	if err := unhold(ctx); err != nil {
+		t.Fatal(err)
+	}
+	// the held slots become available for writes
+	i, runs := 0, items
+	for ; i < runs; i++ {
+		loc, err := s.Write(ctx, []byte{0xff, 0xff, 0xff, 0xff})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if loc.Slot < uint32(items/2) {
+			break
+		}
+	}
+	if i == runs {
+		t.Fatalf("expected write to a released slot within %d runs", runs)
+	}
+}
+
+func TestHoldClose(t *testing.T) {
+	datasize := 4
+	items := 8
+	dir := t.TempDir()
+	s, err := sharky.New(&dirFS{basedir: dir}, 1, datasize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	locs := make([]sharky.Location, items)
+	for i := range locs {
+		locs[i], err = s.Write(ctx, []byte{0x1, 0x1, 0x1, 0x1})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_ = s.Hold()
+	for _, loc := range locs[:items/2] {
+		if err := s.Release(ctx, loc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the store is closed while the hold is active
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err = sharky.New(&dirFS{basedir: dir}, 1, datasize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	// the held slots are saved as free
+	loc, err := s.Write(ctx, []byte{0xff, 0xff, 0xff, 0xff})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loc.Slot >= uint32(items/2) {
+		t.Fatalf("expected write to a released slot below %d, got %d", items/2, loc.Slot)
+	}
+}
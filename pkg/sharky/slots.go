@@ -10,22 +10,26 @@ import (
 )
 
 type slots struct {
-	data    []byte          // byteslice serving as bitvector: i-t bit set <>
-	size    uint32          // number of slots
-	head    uint32          // the first free slot
-	file    sharkyFile      // file to persist free slots across sessions
-	in      chan uint32     // incoming channel for free slots,
-	out     chan uint32     // outgoing channel for free slots
-	wg      *sync.WaitGroup // count started write operations
-	limboWG sync.WaitGroup  // wait for the limbo writes to in chan after the quit is closed
+	data        []byte           // byteslice serving as bitvector: i-t bit set <>
+	size        uint32           // number of slots
+	head        uint32           // the first free slot
+	file        sharkyFile       // file to persist free slots across sessions
+	in          chan uint32      // incoming channel for free slots,
+	out         chan uint32      // outgoing channel for free slots
+	lowest      chan chan uint32 // incoming channel for requests of the lowest free slot
+	truncations chan chan uint32 // incoming channel for truncation requests
+	wg          *sync.WaitGroup  // count started write operations
+	limboWG     sync.WaitGroup   // wait for the limbo writes to in chan after the quit is closed
 }
 
 func newSlots(file sharkyFile, wg *sync.WaitGroup) *slots {
 	return &slots{
-		file: file,
-		in:   make(chan uint32),
-		out:  make(chan uint32),
-		wg:   wg,
+		file:        file,
+		in:          make(chan uint32),
+		out:         make(chan uint32),
+		lowest:      make(chan chan uint32),
+		truncations: make(chan chan uint32),
+		wg:          wg,
 	}
 }
 
@@ -92,6 +96,21 @@ func (sl *slots) pop() uint32 {
 	return head
 }
 
+// truncate drops the trailing free slots (bytewise) and returns the new size.
+func (sl *slots) truncate() uint32 {
+	size := sl.size
+	for size > 0 && sl.data[(size-1)/8]&(1<<((size-1)%8)) > 0 {
+		size--
+	}
+	size = (size + 7) / 8 * 8
+	sl.data = sl.data[:size/8]
+	sl.size = size
+	if sl.head > size {
+		sl.head = size
+	}
+	return size
+}
+
 // forever loop processing.
 func (sl *slots) process(quit chan struct{}) {
 	var head uint32     // the currently pending next free slots
@@ -116,6 +135,22 @@ func (sl *slots) process(quit chan struct{}) {
 		case out <- head:
 			out = nil
 
+			// the pending free slot may be stale, pop the actual lowest one
+		case res := <-sl.lowest:
+			if out != nil {
+				sl.push(head)
+				out = nil
+			}
+			res <- sl.pop()
+
+			// truncation is only safe with the pending free slot given back
+		case res := <-sl.truncations:
+			if out != nil {
+				sl.push(head)
+				out = nil
+			}
+			res <- sl.truncate()
+
 			// quit is effective only after all initiated releases are received
 		case <-quit:
 			if out != nil {
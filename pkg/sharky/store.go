@@ -8,6 +8,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"io/fs"
 	"strconv"
 	"sync"
@@ -29,11 +30,16 @@ var (
 // - free slots allow write
 type Store struct {
 	maxDataSize int             // max length of blobs
+	basedir     fs.FS           // base directory of the shard files
 	writes      chan write      // shared write operations channel
 	shards      []*shard        // shards
 	wg          *sync.WaitGroup // count started operations
 	quit        chan struct{}   // quit channel
 	metrics     metrics
+
+	holdMu sync.Mutex // protects holds and held
+	holds  int        // number of active holds
+	held   []Location // locations released while holds are active
 }
 
 // New constructs a sharded blobstore
@@ -45,6 +51,7 @@ type Store struct {
 func New(basedir fs.FS, shardCnt int, maxDataSize int) (*Store, error) {
 	store := &Store{
 		maxDataSize: maxDataSize,
+		basedir:     basedir,
 		writes:      make(chan write),
 		shards:      make([]*shard, shardCnt),
 		wg:          &sync.WaitGroup{},
@@ -64,9 +71,20 @@ func New(basedir fs.FS, shardCnt int, maxDataSize int) (*Store, error) {
 }
 
 // Close closes each shard and return incidental errors from each shard
+// The slots released while a hold is active are given back to the shards
+// before closing, so that they are saved as free.
 func (s *Store) Close() error {
-	close(s.quit)
+	s.holdMu.Lock()
+	held := s.held
+	s.held = nil
+	s.holdMu.Unlock()
+
 	err := new(multierror.Error)
+	for _, loc := range held {
+		err = multierror.Append(err, s.release(context.Background(), loc))
+	}
+
+	close(s.quit)
 	for _, sh := range s.shards {
 		err = multierror.Append(err, sh.close())
 	}
@@ -93,6 +111,8 @@ func (s *Store) create(index uint8, maxDataSize int, basedir fs.FS) (*shard, err
 		reads:       make(chan read),
 		errc:        make(chan error),
 		writes:      s.writes,
+		moves:       make(chan move),
+		truncations: make(chan truncation),
 		index:       index,
 		maxDataSize: maxDataSize,
 		file:        file.(sharkyFile),
@@ -187,7 +207,20 @@ func (s *Store) Write(ctx context.Context, data []byte) (loc Location, err error
 // Note that releasing is not safe for obfuscating earlier content, since
 // even after reuse, the slot may be used by a very short blob and leaves the
 // rest of the old blob bytes untouched
+// While a hold is active, the release is deferred until the hold ends.
 func (s *Store) Release(ctx context.Context, loc Location) error {
+	s.holdMu.Lock()
+	if s.holds > 0 {
+		s.held = append(s.held, loc)
+		s.holdMu.Unlock()
+		return nil
+	}
+	s.holdMu.Unlock()
+	return s.release(ctx, loc)
+}
+
+// release gives back the slot to the shard and updates the metrics.
+func (s *Store) release(ctx context.Context, loc Location) error {
 	sh := s.shards[loc.Shard]
 	err := sh.release(ctx, loc.Slot)
 	s.metrics.TotalReleaseCalls.Inc()
@@ -200,3 +233,146 @@ func (s *Store) Release(ctx context.Context, loc Location) error {
 	}
 	return err
 }
+
+// Move relocates the blob found at location to the lowest free slot of the same
+// shard, provided that slot precedes the current one, and returns the new location.
+// If there is no such free slot, the original location is returned unchanged.
+// The original slot is not released, it is meant to be released by the caller
+// once the upstream db refers to the new location.
+func (s *Store) Move(ctx context.Context, loc Location) (Location, error) {
+	s.wg.Add(1)
+	defer s.wg.Done()
+
+	sh := s.shards[loc.Shard]
+	c := make(chan entry, 1) // buffer the channel to avoid blocking in shard.process on quit or context done
+
+	select {
+	case sh.moves <- move{loc, c}:
+		s.metrics.TotalMoveCalls.Inc()
+	case <-s.quit:
+		return loc, ErrQuitting
+	case <-ctx.Done():
+		return loc, ctx.Err()
+	}
+
+	select {
+	case e := <-c:
+		if e.err != nil {
+			s.metrics.TotalMoveCallsErr.Inc()
+			return loc, e.err
+		}
+		if e.loc != loc {
+			shard := strconv.Itoa(int(sh.index))
+			s.metrics.CurrentShardSize.WithLabelValues(shard).Inc()
+			s.metrics.ShardFragmentation.WithLabelValues(shard).Add(float64(s.maxDataSize - int(e.loc.Length)))
+		}
+		return e.loc, nil
+	case <-s.quit:
+		return loc, ErrQuitting
+	}
+}
+
+// Truncate discards the free slots at the end of the shard and shrinks the
+// shard file accordingly. It returns the number of bytes reclaimed.
+// Truncate is meant to be called after the blobs at the end of the shard
+// have been moved towards its beginning by Move calls.
+func (s *Store) Truncate(ctx context.Context, shard uint8) (int64, error) {
+	s.wg.Add(1)
+	defer s.wg.Done()
+
+	sh := s.shards[shard]
+	c := make(chan truncated, 1)
+
+	select {
+	case sh.truncations <- truncation{c}:
+		s.metrics.TotalTruncateCalls.Inc()
+	case <-s.quit:
+		return 0, ErrQuitting
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+
+	select {
+	case t := <-c:
+		if t.err != nil {
+			s.metrics.TotalTruncateCallsErr.Inc()
+		}
+		return t.size, t.err
+	case <-s.quit:
+		return 0, ErrQuitting
+	}
+}
+
+// ShardSize returns the size of the shard file in bytes.
+func (s *Store) ShardSize(shard uint8) (int64, error) {
+	f, err := s.basedir.Open(fmt.Sprintf("shard_%03d", shard))
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	return fi.Size(), nil
+}
+
+// Hold suspends the reuse of released slots, so that the blobs stored at the
+// time of the call remain intact until the returned function is called. The
+// slots released in the meantime are given back to the shards by that call.
+func (s *Store) Hold() (unhold func(context.Context) error) {
+	s.holdMu.Lock()
+	s.holds++
+	s.holdMu.Unlock()
+
+	var once sync.Once
+	return func(ctx context.Context) (err error) {
+		once.Do(func() {
+			s.holdMu.Lock()
+			s.holds--
+			var held []Location
+			if s.holds == 0 {
+				held, s.held = s.held, nil
+			}
+			s.holdMu.Unlock()
+
+			for _, loc := range held {
+				if e := s.release(ctx, loc); e != nil && err == nil {
+					err = e
+				}
+			}
+		})
+		return err
+	}
+}
+
+// CopyShard writes the content of the first slots of the shard to the writer.
+// The blobs are copied as they are found in the shard file, so only the slots
+// which are not overwritten during the copy, e.g. while a hold is active, are
+// consistent. Slots beyond the end of the shard file are written as zeros.
+func (s *Store) CopyShard(w io.Writer, shard uint8, slots uint32) (int64, error) {
+	size := int64(slots) * int64(s.maxDataSize)
+	f, err := s.basedir.Open(fmt.Sprintf("shard_%03d", shard))
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	n, err := io.CopyN(w, f, size)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return n, err
+	}
+	m, err := io.CopyN(w, zeros{}, size-n)
+	return n + m, err
+}
+
+// zeros is an infinite reader of zero bytes.
+type zeros struct{}
+
+func (zeros) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 0
+	}
+	return len(p), nil
+}
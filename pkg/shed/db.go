@@ -173,6 +173,12 @@ func (db *DB) NewIterator() iterator.Iterator {
 	return db.ldb.NewIterator(nil, nil)
 }
 
+// GetSnapshot returns a point-in-time snapshot of the LevelDB database.
+// The snapshot must be released after use.
+func (db *DB) GetSnapshot() (*leveldb.Snapshot, error) {
+	return db.ldb.GetSnapshot()
+}
+
 // WriteBatch wraps LevelDB Write method to increment metrics counter.
 func (db *DB) WriteBatch(batch *leveldb.Batch) (err error) {
 	err = db.ldb.Write(batch, nil)
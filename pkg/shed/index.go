@@ -45,6 +45,7 @@ type Item struct {
 	StoreTimestamp  int64
 	BinID           uint64
 	PinCounter      uint64 // maintains the no of time a chunk is pinned
+	Frequency       uint64 // access frequency score used by the frequency aware gc policy
 	Tag             uint32
 	BatchID         []byte // postage batch ID
 	Index           []byte // postage stamp within-batch: index
@@ -81,6 +82,9 @@ func (i Item) Merge(i2 Item) Item {
 	if i.PinCounter == 0 {
 		i.PinCounter = i2.PinCounter
 	}
+	if i.Frequency == 0 {
+		i.Frequency = i2.Frequency
+	}
 	if i.Tag == 0 {
 		i.Tag = i2.Tag
 	}
@@ -327,6 +331,8 @@ type IterateOptions struct {
 	Prefix []byte
 	// Iterate over items in reverse order.
 	Reverse bool
+	// Snapshot, if set, is iterated instead of the current database state.
+	Snapshot *leveldb.Snapshot
 }
 
 // Iterate function iterates over keys of the Index.
@@ -347,7 +353,12 @@ func (f Index) Iterate(fn IndexIterFunc, options *IterateOptions) (err error) {
 		}
 	}
 
-	it := f.db.NewIterator()
+	var it iterator.Iterator
+	if options.Snapshot != nil {
+		it = options.Snapshot.NewIterator(nil, nil)
+	} else {
+		it = f.db.NewIterator()
+	}
 	defer it.Release()
 
 	var ok bool
@@ -500,6 +500,46 @@ func TestIndex_Iterate(t *testing.T) {
 		}
 	})
 
+	t.Run("snapshot", func(t *testing.T) {
+		snapshot, err := db.GetSnapshot()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer snapshot.Release()
+
+		laterItem := Item{
+			Address: []byte("iterate-hash-07"),
+			Data:    []byte("data-later"),
+		}
+		if err := index.Put(laterItem); err != nil {
+			t.Fatal(err)
+		}
+		defer func() {
+			if err := index.Delete(laterItem); err != nil {
+				t.Fatal(err)
+			}
+		}()
+
+		var i int
+		err = index.Iterate(func(item Item) (stop bool, err error) {
+			if i > len(items)-1 {
+				return true, fmt.Errorf("got unexpected index item: %#v", item)
+			}
+			want := items[i]
+			checkItem(t, item, want)
+			i++
+			return false, nil
+		}, &IterateOptions{
+			Snapshot: snapshot,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i != len(items) {
+			t.Errorf("got %v items, expected %v", i, len(items))
+		}
+	})
+
 	t.Run("no overflow", func(t *testing.T) {
 		secondIndex, err := db.NewIndex("second-index", retrievalIndexFuncs)
 		if err != nil {
@@ -0,0 +1,365 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package dump exports the content of the leveldb statestore as JSON lines
+// and imports it back.
+//
+// The first line is a header with the version of the format and the schema
+// of the exported statestore. Every following line is an entry. The entries
+// are grouped by the key prefixes of the known value types, followed by the
+// entries with the keys not matching any of them. Values of the known types
+// are decoded into JSON, all the other values are written as base64 encoded
+// raw bytes.
+package dump
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"unicode/utf8"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/bzz"
+	"github.com/ethersphere/bee/pkg/postage"
+	"github.com/ethersphere/bee/pkg/postage/policy"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	"github.com/ethersphere/bee/pkg/statestore/leveldb"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/tags"
+	"github.com/ethersphere/bee/pkg/transaction"
+	"github.com/ethersphere/bee/pkg/upload"
+)
+
+// Version is the version of the dump format.
+const Version = 1
+
+var (
+	// ErrUnsupportedVersion is returned by Restore
+	// if the dump was written in an unknown format.
+	ErrUnsupportedVersion = errors.New("unsupported dump version")
+	// ErrUnknownSchema is returned by Restore if the dumped
+	// statestore can not be migrated to the current schema.
+	ErrUnknownSchema = errors.New("unknown statestore schema")
+	// ErrNotEmpty is returned by Restore if the statestore
+	// already holds entries and the restore is not forced.
+	ErrNotEmpty = errors.New("statestore not empty")
+)
+
+// header is the first line of the dump.
+type header struct {
+	Version int    `json:"version"`
+	Schema  string `json:"schema"`
+}
+
+// entry is a single key value pair of the statestore. Keys which are not
+// valid UTF-8 strings are hex encoded. Value holds the decoded value of the
+// Type, Raw holds the stored bytes of the values which were not decoded.
+type entry struct {
+	Prefix string          `json:"prefix,omitempty"`
+	Key    string          `json:"key,omitempty"`
+	KeyHex string          `json:"keyHex,omitempty"`
+	Type   string          `json:"type,omitempty"`
+	Value  json.RawMessage `json:"value,omitempty"`
+	Raw    []byte          `json:"raw,omitempty"`
+}
+
+// Stats are the counts of the dumped or restored entries.
+type Stats struct {
+	Entries int // number of all entries
+	Decoded int // number of entries with the value decoded into JSON
+}
+
+// valueType is the type of the values stored under the keys with the prefix.
+type valueType struct {
+	prefix string
+	name   string
+	new    func() interface{}
+}
+
+func newBigInt() interface{}            { return new(big.Int) }
+func newEthAddress() interface{}        { return new(common.Address) }
+func newHash() interface{}              { return new(common.Hash) }
+func newSwarmAddress() interface{}      { return new(swarm.Address) }
+func newSignedCheque() interface{}      { return new(chequebook.SignedCheque) }
+func newFlag() interface{}              { return new(struct{}) }
+func newStampIssuer() interface{}       { return new(postage.StampIssuer) }
+func newBatch() interface{}             { return new(postage.Batch) }
+func newChainState() interface{}        { return new(postage.ChainState) }
+func newReserveState() interface{}      { return new(postage.ReserveState) }
+func newBzzAddress() interface{}        { return new(bzz.Address) }
+func newTag() interface{}               { return new(tags.Tag) }
+func newNonce() interface{}             { return new(uint64) }
+func newStoredTransaction() interface{} { return new(transaction.StoredTransaction) }
+func newPostagePolicy() interface{}     { return new(policy.Policy) }
+func newPostageAction() interface{}     { return new(policy.Action) }
+func newUploadSession() interface{}     { return new(upload.Session) }
+
+// valueTypes are the known value types, in the order of the dumped groups.
+// The prefixes mirror the keys used by the packages storing the values.
+var valueTypes = []valueType{
+	{prefix: "accounting_balance_", name: "big.Int", new: newBigInt},
+	{prefix: "accounting_surplusbalance_", name: "big.Int", new: newBigInt},
+	{prefix: "accounting_originatedbalance_", name: "big.Int", new: newBigInt},
+	{prefix: "pseudosettle_total_received_", name: "big.Int", new: newBigInt},
+	{prefix: "pseudosettle_total_sent_", name: "big.Int", new: newBigInt},
+	{prefix: "swap_chequebook", name: "common.Address", new: newEthAddress},
+	{prefix: "swap_chequebook_transaction_deployment", name: "common.Hash", new: newHash},
+	{prefix: "swap_chequebook_total_issued_", name: "big.Int", new: newBigInt},
+	{prefix: "swap_chequebook_last_issued_cheque_", name: "chequebook.SignedCheque", new: newSignedCheque},
+	{prefix: "swap_chequebook_last_received_cheque_", name: "chequebook.SignedCheque", new: newSignedCheque},
+	{prefix: "swap_chequebook_peer_", name: "common.Address", new: newEthAddress},
+	{prefix: "swap_peer_chequebook_", name: "swarm.Address", new: newSwarmAddress},
+	{prefix: "swap_beneficiary_peer_", name: "swarm.Address", new: newSwarmAddress},
+	{prefix: "swap_peer_beneficiary_", name: "common.Address", new: newEthAddress},
+	{prefix: "swap_deducted_for_peer_", name: "flag", new: newFlag},
+	{prefix: "swap_deducted_by_peer_", name: "flag", new: newFlag},
+	{prefix: "postage", name: "postage.StampIssuer", new: newStampIssuer},
+	{prefix: "postage_policy_batch_", name: "policy.Policy", new: newPostagePolicy},
+	{prefix: "postage_policy_action_", name: "policy.Action", new: newPostageAction},
+	{prefix: "postage_policy_spent", name: "big.Int", new: newBigInt},
+	{prefix: "batchstore_batch_", name: "postage.Batch", new: newBatch},
+	{prefix: "batchstore_chainstate", name: "postage.ChainState", new: newChainState},
+	{prefix: "batchstore_reservestate", name: "postage.ReserveState", new: newReserveState},
+	{prefix: "addressbook_entry_", name: "bzz.Address", new: newBzzAddress},
+	{prefix: "tags_", name: "tags.Tag", new: newTag},
+	{prefix: "upload_session_", name: "upload.Session", new: newUploadSession},
+	{prefix: "root-pin", name: "swarm.Address", new: newSwarmAddress},
+	{prefix: "non-mineable-overlay", name: "swarm.Address", new: newSwarmAddress},
+	{prefix: "transaction_nonce_", name: "uint64", new: newNonce},
+	{prefix: "transaction_stored_", name: "transaction.StoredTransaction", new: newStoredTransaction},
+	{prefix: "transaction_pending_", name: "flag", new: newFlag},
+}
+
+// lookupValueType returns the known value type
+// with the longest prefix matching the key.
+func lookupValueType(key []byte) (t valueType, ok bool) {
+	for _, v := range valueTypes {
+		if bytes.HasPrefix(key, []byte(v.prefix)) && len(v.prefix) > len(t.prefix) {
+			t, ok = v, true
+		}
+	}
+	return t, ok
+}
+
+// encode serializes the value the same way as the leveldb statestore.
+func encode(v interface{}) ([]byte, error) {
+	if marshaler, ok := v.(encoding.BinaryMarshaler); ok {
+		return marshaler.MarshalBinary()
+	}
+	return json.Marshal(v)
+}
+
+// decode deserializes the value the same way as the leveldb statestore.
+func decode(data []byte, v interface{}) error {
+	if unmarshaler, ok := v.(encoding.BinaryUnmarshaler); ok {
+		return unmarshaler.UnmarshalBinary(data)
+	}
+	return json.Unmarshal(data, v)
+}
+
+// toJSON decodes the stored value into JSON. The value is decoded only if
+// it can be restored from the JSON to exactly the same bytes, so that no
+// information is lost by the restore.
+func (t valueType) toJSON(data []byte) (value json.RawMessage, ok bool) {
+	defer func() {
+		// some binary unmarshalers panic on
+		// values of an unexpected length
+		if recover() != nil {
+			value, ok = nil, false
+		}
+	}()
+
+	v := t.new()
+	if err := decode(data, v); err != nil {
+		return nil, false
+	}
+	value, err := json.Marshal(v)
+	if err != nil {
+		return nil, false
+	}
+
+	v = t.new()
+	if err := json.Unmarshal(value, v); err != nil {
+		return nil, false
+	}
+	restored, err := encode(v)
+	if err != nil || !bytes.Equal(restored, data) {
+		return nil, false
+	}
+	return value, true
+}
+
+// rawValue is a value stored as is.
+type rawValue []byte
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+func (v rawValue) MarshalBinary() ([]byte, error) {
+	return v, nil
+}
+
+// Dump writes all the entries of the statestore to w.
+func Dump(w io.Writer, s *leveldb.Store) (stats Stats, err error) {
+	schema, err := s.SchemaName()
+	if err != nil {
+		return stats, fmt.Errorf("get schema name: %w", err)
+	}
+
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(header{Version: Version, Schema: schema}); err != nil {
+		return stats, err
+	}
+
+	// write encodes the entry, the value is decoded
+	// only if the value type t is a known one
+	write := func(key, value []byte, t valueType) error {
+		e := entry{Prefix: t.prefix, Raw: value}
+		if utf8.Valid(key) {
+			e.Key = string(key)
+		} else {
+			e.KeyHex = hex.EncodeToString(key)
+		}
+		if t.new != nil {
+			if v, ok := t.toJSON(value); ok {
+				e.Type, e.Value, e.Raw = t.name, v, nil
+				stats.Decoded++
+			}
+		}
+		stats.Entries++
+		return enc.Encode(e)
+	}
+
+	for _, t := range valueTypes {
+		t := t
+		err := s.Iterate(t.prefix, func(key, value []byte) (bool, error) {
+			// skip the keys of the types with longer prefixes
+			if v, _ := lookupValueType(key); v.prefix != t.prefix {
+				return false, nil
+			}
+			return false, write(key, value, t)
+		})
+		if err != nil {
+			return stats, fmt.Errorf("dump %s: %w", t.prefix, err)
+		}
+	}
+
+	err = s.Iterate("", func(key, value []byte) (bool, error) {
+		if _, ok := lookupValueType(key); ok || leveldb.IsSchemaKey(key) {
+			return false, nil
+		}
+		return false, write(key, value, valueType{})
+	})
+	if err != nil {
+		return stats, fmt.Errorf("dump: %w", err)
+	}
+	return stats, nil
+}
+
+// Restore reads the dump from r and writes its entries to the statestore.
+// The typed values are validated against their types before being stored.
+// The schema of the statestore is set to the one of the dump, so that the
+// data is migrated to the current schema when the store is opened again.
+// The statestore must be empty unless force is set, in which case the
+// entries of the dump overwrite the existing ones with the same keys and
+// the other existing entries are kept.
+func Restore(r io.Reader, s *leveldb.Store, force bool) (stats Stats, err error) {
+	if !force {
+		empty, err := isEmpty(s)
+		if err != nil {
+			return stats, err
+		}
+		if !empty {
+			return stats, ErrNotEmpty
+		}
+	}
+
+	dec := json.NewDecoder(r)
+
+	var h header
+	if err := dec.Decode(&h); err != nil {
+		return stats, fmt.Errorf("read header: %w", err)
+	}
+	if h.Version != Version {
+		return stats, fmt.Errorf("%w: %d", ErrUnsupportedVersion, h.Version)
+	}
+	if !leveldb.IsKnownSchema(h.Schema) {
+		return stats, fmt.Errorf("%w: %q", ErrUnknownSchema, h.Schema)
+	}
+
+	for {
+		var e entry
+		if err := dec.Decode(&e); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return stats, fmt.Errorf("read entry %d: %w", stats.Entries+1, err)
+		}
+		if err := restoreEntry(s, e); err != nil {
+			return stats, fmt.Errorf("entry %d: %w", stats.Entries+1, err)
+		}
+		stats.Entries++
+		if e.Value != nil {
+			stats.Decoded++
+		}
+	}
+
+	if err := s.SetSchemaName(h.Schema); err != nil {
+		return stats, fmt.Errorf("set schema name: %w", err)
+	}
+	return stats, nil
+}
+
+// isEmpty reports whether the statestore holds
+// no entries other than the schema name.
+func isEmpty(s *leveldb.Store) (empty bool, err error) {
+	empty = true
+	err = s.Iterate("", func(key, _ []byte) (bool, error) {
+		if leveldb.IsSchemaKey(key) {
+			return false, nil
+		}
+		empty = false
+		return true, nil
+	})
+	return empty, err
+}
+
+// restoreEntry validates the entry and writes it to the statestore.
+func restoreEntry(s *leveldb.Store, e entry) error {
+	key := []byte(e.Key)
+	if e.KeyHex != "" {
+		var err error
+		if key, err = hex.DecodeString(e.KeyHex); err != nil {
+			return fmt.Errorf("invalid key: %w", err)
+		}
+	}
+	if len(key) == 0 {
+		return errors.New("missing key")
+	}
+	if leveldb.IsSchemaKey(key) {
+		return fmt.Errorf("key %q: reserved for the schema name", key)
+	}
+
+	t, ok := lookupValueType(key)
+	if e.Prefix != t.prefix {
+		return fmt.Errorf("key %q: got prefix %q, want %q", key, e.Prefix, t.prefix)
+	}
+	if e.Value == nil {
+		return s.Put(string(key), rawValue(e.Raw))
+	}
+
+	if !ok || e.Type != t.name {
+		return fmt.Errorf("key %q: got type %q, want %q", key, e.Type, t.name)
+	}
+	v := t.new()
+	dec := json.NewDecoder(bytes.NewReader(e.Value))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("key %q: invalid %s value: %w", key, t.name, err)
+	}
+	return s.Put(string(key), v)
+}
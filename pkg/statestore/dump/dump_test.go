@@ -0,0 +1,257 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dump_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/log"
+	"github.com/ethersphere/bee/pkg/postage"
+	"github.com/ethersphere/bee/pkg/postage/policy"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	"github.com/ethersphere/bee/pkg/statestore/dump"
+	"github.com/ethersphere/bee/pkg/statestore/leveldb"
+	"github.com/ethersphere/bee/pkg/upload"
+)
+
+func newTestStore(t *testing.T) *leveldb.Store {
+	t.Helper()
+
+	s, err := leveldb.NewInMemoryStateStore(log.Noop)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := s.Close(); err != nil {
+			t.Fatal(err)
+		}
+	})
+	return s
+}
+
+// entries returns all the stored key value pairs.
+func entries(t *testing.T, s *leveldb.Store) map[string][]byte {
+	t.Helper()
+
+	m := make(map[string][]byte)
+	err := s.Iterate("", func(key, value []byte) (bool, error) {
+		m[string(key)] = value
+		return false, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestDumpRestore(t *testing.T) {
+	s := newTestStore(t)
+
+	values := map[string]interface{}{
+		"accounting_balance_a":        big.NewInt(-42),
+		"accounting_surplusbalance_a": big.NewInt(7),
+		"swap_chequebook":             common.HexToAddress("0xabcd"),
+		"swap_chequebook_peer_a":      common.HexToAddress("0x1234"),
+		"postage\x01\x00":             postage.NewStampIssuer("label", "key", make([]byte, 32), big.NewInt(3), 17, 16, 1, true),
+		"transaction_nonce_0xabcd":    uint64(5),
+		"postage_policy_batch_00":     policy.Policy{TopUpTTL: time.Hour, TopUpAmount: big.NewInt(10)},
+		"upload_session_a":            upload.Session{ID: "a", TagID: 1, BatchID: []byte{1}, Offset: 4096, State: []byte{2, 3}},
+		"swap_chequebook_last_issued_cheque_a": &chequebook.SignedCheque{
+			Cheque: chequebook.Cheque{
+				Chequebook:       common.HexToAddress("0xabcd"),
+				Beneficiary:      common.HexToAddress("0x1234"),
+				CumulativePayout: big.NewInt(500),
+			},
+			Signature: []byte{1, 2, 3},
+		},
+		// not decodable as a batch
+		"batchstore_batch_a": []byte{1, 2, 3},
+		// unknown types
+		"unknown_key":         "value",
+		"\xff\xfe binary key": 1,
+	}
+	for k, v := range values {
+		if err := s.Put(k, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var buf bytes.Buffer
+	stats, err := dump.Dump(&buf, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (dump.Stats{Entries: len(values), Decoded: 9}); stats != want {
+		t.Fatalf("got dump stats %+v, want %+v", stats, want)
+	}
+
+	schema, err := s.SchemaName()
+	if err != nil {
+		t.Fatal(err)
+	}
+	types := make(map[string]string)
+	prefixes := make([]string, 0, len(values))
+	scanner := bufio.NewScanner(bytes.NewReader(buf.Bytes()))
+	scanner.Buffer(nil, 1024*1024)
+	for i := 0; scanner.Scan(); i++ {
+		var line struct {
+			Schema string
+			Prefix string
+			Key    string
+			KeyHex string
+			Type   string
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatal(err)
+		}
+		if i == 0 {
+			if line.Schema != schema {
+				t.Fatalf("got schema %q, want %q", line.Schema, schema)
+			}
+			continue
+		}
+		types[line.Key+line.KeyHex] = line.Type
+		prefixes = append(prefixes, line.Prefix)
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]string{
+		"accounting_balance_a":                 "big.Int",
+		"swap_chequebook":                      "common.Address",
+		"postage\x01\x00":                      "postage.StampIssuer",
+		"postage_policy_batch_00":              "policy.Policy",
+		"upload_session_a":                     "upload.Session",
+		"swap_chequebook_last_issued_cheque_a": "chequebook.SignedCheque",
+		"batchstore_batch_a":                   "",
+		"fffe2062696e617279206b6579":           "",
+	} {
+		if got := types[key]; got != want {
+			t.Errorf("key %q: got type %q, want %q", key, got, want)
+		}
+	}
+	// entries with the same prefix are dumped together
+	seen := make(map[string]bool)
+	for i, p := range prefixes {
+		if seen[p] && prefixes[i-1] != p {
+			t.Fatalf("entries with prefix %q not grouped: %v", p, prefixes)
+		}
+		seen[p] = true
+	}
+
+	restored := newTestStore(t)
+	stats, err = dump.Restore(bytes.NewReader(buf.Bytes()), restored, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (dump.Stats{Entries: len(values), Decoded: 9}); stats != want {
+		t.Fatalf("got restore stats %+v, want %+v", stats, want)
+	}
+	if got, want := entries(t, restored), entries(t, s); !reflect.DeepEqual(got, want) {
+		t.Fatalf("restored entries mismatch\ngot  %q\nwant %q", got, want)
+	}
+}
+
+func TestRestoreChecks(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		dump string
+		err  error
+		msg  string
+	}{
+		{
+			name: "version",
+			dump: `{"version":2,"schema":"batchstore"}`,
+			err:  dump.ErrUnsupportedVersion,
+		},
+		{
+			name: "schema",
+			dump: `{"version":1,"schema":"unknown"}`,
+			err:  dump.ErrUnknownSchema,
+		},
+		{
+			name: "prefix",
+			dump: `{"version":1,"schema":"batchstore"}
+{"prefix":"tags_","key":"accounting_balance_a","type":"big.Int","value":1}`,
+			msg: "got prefix",
+		},
+		{
+			name: "type",
+			dump: `{"version":1,"schema":"batchstore"}
+{"prefix":"accounting_balance_","key":"accounting_balance_a","type":"uint64","value":1}`,
+			msg: "got type",
+		},
+		{
+			name: "value",
+			dump: `{"version":1,"schema":"batchstore"}
+{"prefix":"swap_chequebook_last_issued_cheque_","key":"swap_chequebook_last_issued_cheque_a","type":"chequebook.SignedCheque","value":{"Unknown":1}}`,
+			msg: "invalid chequebook.SignedCheque value",
+		},
+		{
+			name: "schema key",
+			dump: `{"version":1,"schema":"batchstore"}
+{"key":"statestore_schema","raw":"YmF0Y2hzdG9yZQ=="}`,
+			msg: "reserved",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := dump.Restore(strings.NewReader(tc.dump), newTestStore(t), false)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if tc.err != nil && !errors.Is(err, tc.err) {
+				t.Fatalf("got error %v, want %v", err, tc.err)
+			}
+			if tc.msg != "" && !strings.Contains(err.Error(), tc.msg) {
+				t.Fatalf("got error %v, want %q", err, tc.msg)
+			}
+		})
+	}
+
+	t.Run("older schema", func(t *testing.T) {
+		s := newTestStore(t)
+		_, err := dump.Restore(strings.NewReader(`{"version":1,"schema":"kademlia-metrics"}`), s, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		schema, err := s.SchemaName()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if schema != "kademlia-metrics" {
+			t.Fatalf("got schema %q, want %q", schema, "kademlia-metrics")
+		}
+	})
+
+	t.Run("not empty", func(t *testing.T) {
+		const d = `{"version":1,"schema":"batchstore"}
+{"prefix":"accounting_balance_","key":"accounting_balance_a","type":"big.Int","value":1}`
+
+		s := newTestStore(t)
+		if err := s.Put("accounting_balance_b", big.NewInt(2)); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := dump.Restore(strings.NewReader(d), s, false); !errors.Is(err, dump.ErrNotEmpty) {
+			t.Fatalf("got error %v, want %v", err, dump.ErrNotEmpty)
+		}
+		if _, err := dump.Restore(strings.NewReader(d), s, true); err != nil {
+			t.Fatal(err)
+		}
+		for _, key := range []string{"accounting_balance_a", "accounting_balance_b"} {
+			if err := s.Get(key, new(big.Int)); err != nil {
+				t.Fatalf("get %s: %v", key, err)
+			}
+		}
+	})
+}
@@ -1,7 +1,3 @@
 package leveldb
 
 var DbSchemaCurrent = dbSchemaCurrent
-
-func (s *Store) GetSchemaName() (string, error) {
-	return s.getSchemaName()
-}
@@ -125,12 +125,8 @@ func (s *Store) Get(key string, i interface{}) error {
 // interface method will be called on the provided value
 // with fallback to JSON serialization.
 func (s *Store) Put(key string, i interface{}) (err error) {
-	var bytes []byte
-	if marshaler, ok := i.(encoding.BinaryMarshaler); ok {
-		if bytes, err = marshaler.MarshalBinary(); err != nil {
-			return err
-		}
-	} else if bytes, err = json.Marshal(i); err != nil {
+	bytes, err := marshal(i)
+	if err != nil {
 		return err
 	}
 
@@ -142,6 +138,55 @@ func (s *Store) Delete(key string) (err error) {
 	return s.db.Delete([]byte(key), nil)
 }
 
+// marshal serializes the value with the BinaryMarshaler
+// interface method with fallback to JSON serialization.
+func marshal(i interface{}) ([]byte, error) {
+	if marshaler, ok := i.(encoding.BinaryMarshaler); ok {
+		return marshaler.MarshalBinary()
+	}
+	return json.Marshal(i)
+}
+
+// batch is the leveldb implementation of the storage.StateBatch.
+type batch struct {
+	db    *leveldb.DB
+	batch *leveldb.Batch
+}
+
+// Batch implements StateStorer.Batch method.
+func (s *Store) Batch() storage.StateBatch {
+	return &batch{
+		db:    s.db,
+		batch: new(leveldb.Batch),
+	}
+}
+
+// Put adds storing of the value under the key to the batch.
+func (b *batch) Put(key string, i interface{}) (err error) {
+	bytes, err := marshal(i)
+	if err != nil {
+		return err
+	}
+	b.batch.Put([]byte(key), bytes)
+	return nil
+}
+
+// Delete adds removal of the key to the batch.
+func (b *batch) Delete(key string) (err error) {
+	b.batch.Delete([]byte(key))
+	return nil
+}
+
+// Commit atomically writes the batch to the store.
+func (b *batch) Commit() (err error) {
+	return b.db.Write(b.batch, nil)
+}
+
+// Discard drops the writes of the batch.
+func (b *batch) Discard() {
+	b.batch.Reset()
+}
+
 // Iterate entries that match the supplied prefix.
 func (s *Store) Iterate(prefix string, iterFunc storage.StateIterFunc) (err error) {
 	iter := s.db.NewIterator(util.BytesPrefix([]byte(prefix)), nil)
@@ -159,7 +204,13 @@ func (s *Store) Iterate(prefix string, iterFunc storage.StateIterFunc) (err erro
 }
 
 func (s *Store) getSchemaName() (string, error) {
-	name, err := s.db.Get([]byte(dbSchemaKey), nil)
+	return DBSchemaName(s.db)
+}
+
+// DBSchemaName returns the name of the schema stored in the LevelDB database
+// of a state store which is not opened as a Store, e.g. a restored one.
+func DBSchemaName(db *leveldb.DB) (string, error) {
+	name, err := db.Get([]byte(dbSchemaKey), nil)
 	if err != nil {
 		if errors.Is(err, leveldb.ErrNotFound) {
 			return "", storage.ErrNotFound
@@ -173,6 +224,20 @@ func (s *Store) putSchemaName(val string) error {
 	return s.db.Put([]byte(dbSchemaKey), []byte(val), nil)
 }
 
+// SchemaName returns the name of the schema of the stored data.
+func (s *Store) SchemaName() (string, error) {
+	return s.getSchemaName()
+}
+
+// SetSchemaName sets the name of the schema of the stored data. The
+// migrations from that schema are run when the store is opened again.
+func (s *Store) SetSchemaName(name string) error {
+	if !IsKnownSchema(name) {
+		return fmt.Errorf("unknown schema %q", name)
+	}
+	return s.putSchemaName(name)
+}
+
 // DB implements StateStorer.DB method.
 func (s *Store) DB() *leveldb.DB {
 	return s.db
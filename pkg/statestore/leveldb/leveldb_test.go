@@ -52,7 +52,7 @@ func TestGetSchemaName(t *testing.T) {
 			t.Fatal(err)
 		}
 	})
-	n, err := store.GetSchemaName() // expect current
+	n, err := store.SchemaName() // expect current
 	if err != nil {
 		t.Fatal(err)
 	}
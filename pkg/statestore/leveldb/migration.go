@@ -202,6 +202,22 @@ func migrateKademliaMetrics(s *Store) error {
 	return nil
 }
 
+// IsKnownSchema reports whether the schema is one of the schemas
+// that the stored data can be migrated from to the current one.
+func IsKnownSchema(name string) bool {
+	for _, m := range schemaMigrations {
+		if m.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// IsSchemaKey reports whether the key is the one holding the schema name.
+func IsSchemaKey(key []byte) bool {
+	return string(key) == dbSchemaKey
+}
+
 func (s *Store) migrate(schemaName string) error {
 	migrations, err := getMigrations(schemaName, dbSchemaCurrent, schemaMigrations, s)
 	if err != nil {
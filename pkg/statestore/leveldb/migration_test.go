@@ -71,7 +71,7 @@ func TestOneMigration(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	schemaName, err := db.GetSchemaName()
+	schemaName, err := db.SchemaName()
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -151,7 +151,7 @@ func TestManyMigrations(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	schemaName, err := db.GetSchemaName()
+	schemaName, err := db.SchemaName()
 	if err != nil {
 		t.Fatal(err)
 	}
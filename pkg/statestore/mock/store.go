@@ -56,12 +56,8 @@ func (s *store) Put(key string, i interface{}) (err error) {
 	s.mtx.Lock()
 	defer s.mtx.Unlock()
 
-	var bytes []byte
-	if marshaler, ok := i.(encoding.BinaryMarshaler); ok {
-		if bytes, err = marshaler.MarshalBinary(); err != nil {
-			return err
-		}
-	} else if bytes, err = json.Marshal(i); err != nil {
+	bytes, err := marshal(i)
+	if err != nil {
 		return err
 	}
 
@@ -69,6 +65,13 @@ func (s *store) Put(key string, i interface{}) (err error) {
 	return nil
 }
 
+func marshal(i interface{}) ([]byte, error) {
+	if marshaler, ok := i.(encoding.BinaryMarshaler); ok {
+		return marshaler.MarshalBinary()
+	}
+	return json.Marshal(i)
+}
+
 func (s *store) Delete(key string) (err error) {
 	s.mtx.Lock()
 	defer s.mtx.Unlock()
@@ -100,6 +103,59 @@ func (s *store) Iterate(prefix string, iterFunc storage.StateIterFunc) (err erro
 	return nil
 }
 
+// batchOp is a single write of the batch,
+// a nil value removes the key.
+type batchOp struct {
+	key   string
+	value []byte
+}
+
+type batch struct {
+	store *store
+	ops   []batchOp
+}
+
+// Batch implements StateStorer.Batch method.
+func (s *store) Batch() storage.StateBatch {
+	return &batch{store: s}
+}
+
+func (b *batch) Put(key string, i interface{}) (err error) {
+	bytes, err := marshal(i)
+	if err != nil {
+		return err
+	}
+	if bytes == nil {
+		bytes = []byte{}
+	}
+	b.ops = append(b.ops, batchOp{key: key, value: bytes})
+	return nil
+}
+
+func (b *batch) Delete(key string) (err error) {
+	b.ops = append(b.ops, batchOp{key: key})
+	return nil
+}
+
+func (b *batch) Commit() (err error) {
+	b.store.mtx.Lock()
+	defer b.store.mtx.Unlock()
+
+	for _, op := range b.ops {
+		if op.value == nil {
+			delete(b.store.store, op.key)
+			continue
+		}
+		b.store.store[op.key] = op.value
+	}
+	b.ops = nil
+	return nil
+}
+
+func (b *batch) Discard() {
+	b.ops = nil
+}
+
 // DB implements StateStorer.DB method.
 func (s *store) DB() *leveldb.DB {
 	return nil
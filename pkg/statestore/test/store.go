@@ -5,6 +5,7 @@
 package test
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"strings"
@@ -85,6 +86,65 @@ func Run(t *testing.T, f func(t *testing.T) storage.StateStorer) {
 	t.Run("test_put_get", func(t *testing.T) { testPutGet(t, f) })
 	t.Run("test_delete", func(t *testing.T) { testDelete(t, f) })
 	t.Run("test_iterator", func(t *testing.T) { testIterator(t, f) })
+	t.Run("test_batch", func(t *testing.T) { testBatch(t, f) })
+}
+
+func testBatch(t *testing.T, f func(t *testing.T) storage.StateStorer) {
+	t.Helper()
+
+	// create a store
+	store := f(t)
+
+	// insert some values
+	insertValues(t, store, key1, key2, value1, value2)
+
+	// discarded writes are not applied
+	b := store.Batch()
+	if err := b.Delete(key1); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Put("key3", "value3"); err != nil {
+		t.Fatal(err)
+	}
+	b.Discard()
+	testPersistedValues(t, store, key1, key2, value1, value2)
+	if err := store.Get("key3", new(string)); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("got error %v, want %v", err, storage.ErrNotFound)
+	}
+
+	// the writes are applied on commit only
+	b = store.Batch()
+	if err := b.Delete(key1); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Delete(key2); err != nil {
+		t.Fatal(err)
+	}
+	value3 := &Serializing{value: "value3"}
+	if err := b.Put("key3", value3); err != nil {
+		t.Fatal(err)
+	}
+	if !value3.marshalCalled {
+		t.Fatal("binaryMarshaller not called on serialized type")
+	}
+	testPersistedValues(t, store, key1, key2, value1, value2)
+	if err := b.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	v := &Serializing{}
+	if err := store.Get("key3", v); err != nil {
+		t.Fatal(err)
+	}
+	if v.value != value3.value {
+		t.Fatalf("expected persisted to be %s but got %s", value3.value, v.value)
+	}
+	if err := store.Get(key1, &Serializing{}); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("got error %v, want %v", err, storage.ErrNotFound)
+	}
+	if err := store.Get(key2, &[]string{}); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("got error %v, want %v", err, storage.ErrNotFound)
+	}
 }
 
 func testDelete(t *testing.T, f func(t *testing.T) storage.StateStorer) {
@@ -174,10 +174,24 @@ type StateStorer interface {
 	Put(key string, i interface{}) (err error)
 	Delete(key string) (err error)
 	Iterate(prefix string, iterFunc StateIterFunc) (err error)
+	// Batch returns a new batch of writes to the store.
+	Batch() StateBatch
 	// DB returns the underlying DB storage.
 	DB() *leveldb.DB
 	io.Closer
 }
 
+// StateBatch groups writes to a StateStorer, so that either all or none of
+// them are applied. The writes are applied by Commit and dropped by Discard.
+// The writes are not visible to the reads from the store before Commit.
+// A batch must not be used after it is committed or discarded, apart from
+// Discard, which has no effect on a committed batch.
+type StateBatch interface {
+	Put(key string, i interface{}) (err error)
+	Delete(key string) (err error)
+	Commit() (err error)
+	Discard()
+}
+
 // StateIterFunc is used when iterating through StateStorer key/value pairs
 type StateIterFunc func(key, value []byte) (stop bool, err error)
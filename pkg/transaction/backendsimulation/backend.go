@@ -25,6 +25,7 @@ type simulatedBackend struct {
 
 	receipts map[common.Hash]*types.Receipt
 	noncesAt map[AccountAtKey]uint64
+	logs     []types.Log
 
 	blocks []Block
 	step   uint64
@@ -34,6 +35,7 @@ type Block struct {
 	Number   uint64
 	Receipts map[common.Hash]*types.Receipt
 	NoncesAt map[AccountAtKey]uint64
+	Logs     []types.Log
 }
 
 type Option interface {
@@ -84,6 +86,8 @@ func (m *simulatedBackend) advanceBlock() {
 			m.noncesAt[addr] = nonce
 		}
 	}
+
+	m.logs = append(m.logs, block.Logs...)
 }
 
 func (m *simulatedBackend) BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error) {
@@ -118,8 +122,32 @@ func (m *simulatedBackend) SendTransaction(ctx context.Context, tx *types.Transa
 	return errors.New("not implemented")
 }
 
-func (*simulatedBackend) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
-	return nil, errors.New("not implemented")
+// FilterLogs returns the logs of the blocks reached so far which match
+// the block range and the addresses of the query. Topics are not matched.
+func (m *simulatedBackend) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	logs := []types.Log{}
+	for _, l := range m.logs {
+		if query.FromBlock != nil && l.BlockNumber < query.FromBlock.Uint64() {
+			continue
+		}
+		if query.ToBlock != nil && l.BlockNumber > query.ToBlock.Uint64() {
+			continue
+		}
+		if len(query.Addresses) > 0 && !containsAddress(query.Addresses, l.Address) {
+			continue
+		}
+		logs = append(logs, l)
+	}
+	return logs, nil
+}
+
+func containsAddress(addresses []common.Address, address common.Address) bool {
+	for _, a := range addresses {
+		if a == address {
+			return true
+		}
+	}
+	return false
 }
 
 func (*simulatedBackend) SubscribeFilterLogs(ctx context.Context, query ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
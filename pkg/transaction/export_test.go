@@ -7,7 +7,8 @@ package transaction
 import "time"
 
 var (
-	StoredTransactionKey = storedTransactionKey
+	StoredTransactionKey  = storedTransactionKey
+	PendingTransactionKey = pendingTransactionKey
 )
 
 func (s *Matcher) SetTimeNow(f func() time.Time) {
@@ -26,6 +26,8 @@ type transactionServiceMock struct {
 	resendTransaction    func(ctx context.Context, txHash common.Hash) error
 	storedTransaction    func(txHash common.Hash) (*transaction.StoredTransaction, error)
 	cancelTransaction    func(ctx context.Context, originalTxHash common.Hash) (common.Hash, error)
+	replaceTransaction   func(ctx context.Context, txHash common.Hash, feeBump uint64, maxFee *big.Int) (common.Hash, error)
+	fillNonceGap         func(ctx context.Context) (common.Hash, bool, error)
 }
 
 func (m *transactionServiceMock) Send(ctx context.Context, request *transaction.TxRequest) (txHash common.Hash, err error) {
@@ -84,6 +86,20 @@ func (m *transactionServiceMock) CancelTransaction(ctx context.Context, original
 	return common.Hash{}, errors.New("not implemented")
 }
 
+func (m *transactionServiceMock) ReplaceTransaction(ctx context.Context, txHash common.Hash, feeBump uint64, maxFee *big.Int) (common.Hash, error) {
+	if m.replaceTransaction != nil {
+		return m.replaceTransaction(ctx, txHash, feeBump, maxFee)
+	}
+	return common.Hash{}, errors.New("not implemented")
+}
+
+func (m *transactionServiceMock) FillNonceGap(ctx context.Context) (common.Hash, bool, error) {
+	if m.fillNonceGap != nil {
+		return m.fillNonceGap(ctx)
+	}
+	return common.Hash{}, false, errors.New("not implemented")
+}
+
 func (m *transactionServiceMock) Close() error {
 	return nil
 }
@@ -139,6 +155,18 @@ func WithCancelTransactionFunc(f func(ctx context.Context, originalTxHash common
 	})
 }
 
+func WithReplaceTransactionFunc(f func(ctx context.Context, txHash common.Hash, feeBump uint64, maxFee *big.Int) (common.Hash, error)) Option {
+	return optionFunc(func(s *transactionServiceMock) {
+		s.replaceTransaction = f
+	})
+}
+
+func WithFillNonceGapFunc(f func(ctx context.Context) (common.Hash, bool, error)) Option {
+	return optionFunc(func(s *transactionServiceMock) {
+		s.fillNonceGap = f
+	})
+}
+
 func New(opts ...Option) transaction.Service {
 	mock := new(transactionServiceMock)
 	for _, o := range opts {
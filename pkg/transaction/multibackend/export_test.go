@@ -0,0 +1,19 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package multibackend
+
+func (b *Backend) HealthCheck() { b.healthCheck() }
+
+func (b *Backend) Healthy(name string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, ep := range b.endpoints {
+		if ep.Name == name {
+			return ep.healthy
+		}
+	}
+	return false
+}
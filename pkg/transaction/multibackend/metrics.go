@@ -0,0 +1,79 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package multibackend
+
+import (
+	m "github.com/ethersphere/bee/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type metrics struct {
+	EndpointCalls   *prometheus.CounterVec
+	EndpointErrors  *prometheus.CounterVec
+	EndpointLatency *prometheus.HistogramVec
+	EndpointHealthy *prometheus.GaugeVec
+	Failovers       prometheus.Counter
+	QuorumFailures  prometheus.Counter
+}
+
+func newMetrics() metrics {
+	subsystem := "eth_multi_backend"
+
+	return metrics{
+		EndpointCalls: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: m.Namespace,
+				Subsystem: subsystem,
+				Name:      "endpoint_calls",
+				Help:      "Count of rpc calls per endpoint.",
+			},
+			[]string{"endpoint"},
+		),
+		EndpointErrors: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: m.Namespace,
+				Subsystem: subsystem,
+				Name:      "endpoint_errors",
+				Help:      "Count of rpc calls failed because of the endpoint per endpoint.",
+			},
+			[]string{"endpoint"},
+		),
+		EndpointLatency: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: m.Namespace,
+				Subsystem: subsystem,
+				Name:      "endpoint_latency",
+				Help:      "Histogram of the rpc call latency in seconds per endpoint.",
+				Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+			},
+			[]string{"endpoint"},
+		),
+		EndpointHealthy: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: m.Namespace,
+				Subsystem: subsystem,
+				Name:      "endpoint_healthy",
+				Help:      "Whether the endpoint is healthy.",
+			},
+			[]string{"endpoint"},
+		),
+		Failovers: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "failovers",
+			Help:      "Count of rpc calls retried on another endpoint.",
+		}),
+		QuorumFailures: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "quorum_failures",
+			Help:      "Count of quorum reads without the quorum of endpoints agreeing.",
+		}),
+	}
+}
+
+func (b *Backend) Metrics() []prometheus.Collector {
+	return m.PrometheusCollectorsFromFields(b.metrics)
+}
@@ -0,0 +1,539 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package multibackend provides a chain backend which spreads the calls
+// over several endpoints. The endpoints are health checked and the calls
+// fail over to the next endpoint when one is not reachable. Optionally,
+// the block number and the log queries need the agreement of a quorum of
+// endpoints.
+package multibackend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethersphere/bee/pkg/log"
+	"github.com/ethersphere/bee/pkg/transaction"
+)
+
+// loggerName is the tree path name of the logger for this package.
+const loggerName = "multibackend"
+
+var (
+	_ transaction.Backend = (*Backend)(nil)
+
+	// ErrNoEndpoints is returned when the backend is created without endpoints.
+	ErrNoEndpoints = errors.New("no endpoints")
+	// ErrNoQuorum is returned by the quorum reads when not enough endpoints agree.
+	ErrNoQuorum = errors.New("no quorum of endpoints")
+
+	errNotConnected = errors.New("endpoint not connected")
+)
+
+// Endpoint is a chain backend of a single endpoint.
+type Endpoint struct {
+	// Name identifies the endpoint in the logs and the metrics,
+	// so it must not hold the credentials of the endpoint URL.
+	Name    string
+	Backend transaction.Backend
+	// Dial connects the backend of the endpoint which was not reachable
+	// when the Backend was created, in which case Backend is nil. The
+	// endpoint is unhealthy until it is connected by a health check or
+	// by a call failing over to it.
+	Dial func(ctx context.Context) (transaction.Backend, error)
+}
+
+// Options are the options of the Backend.
+type Options struct {
+	// Quorum is the number of endpoints which must agree on the block
+	// number and the logs. Values lower than two disable quorum reads.
+	Quorum int
+	// HealthCheckInterval is the interval of the health checks of the
+	// endpoints. Zero disables the health checks.
+	HealthCheckInterval time.Duration
+	// MaxBlockLag is the number of blocks an endpoint may be behind the
+	// most advanced one before it is considered unhealthy. Zero disables
+	// the check.
+	MaxBlockLag uint64
+}
+
+type endpoint struct {
+	Endpoint
+	healthy bool
+	connMu  sync.Mutex // guards the connection of the backend
+}
+
+// Backend is a transaction.Backend which spreads the calls over several
+// endpoints. The calls go to the first healthy endpoint, in the order the
+// endpoints are given, and fail over to the next ones, healthy first, when
+// the endpoint fails to respond.
+type Backend struct {
+	logger    log.Logger
+	endpoints []*endpoint
+	mu        sync.Mutex // guards the health of the endpoints
+	options   Options
+	metrics   metrics
+	quit      chan struct{}
+	wg        sync.WaitGroup
+}
+
+// New returns a new Backend of the endpoints and starts their health
+// checks. The Backend closes the backends of the endpoints on Close.
+func New(logger log.Logger, endpoints []Endpoint, o Options) (*Backend, error) {
+	if len(endpoints) == 0 {
+		return nil, ErrNoEndpoints
+	}
+	if o.Quorum > len(endpoints) {
+		return nil, fmt.Errorf("quorum %d exceeds the number of endpoints %d", o.Quorum, len(endpoints))
+	}
+
+	b := &Backend{
+		logger:  logger.WithName(loggerName).Register(),
+		options: o,
+		metrics: newMetrics(),
+		quit:    make(chan struct{}),
+	}
+	for _, e := range endpoints {
+		healthy := e.Backend != nil
+		b.endpoints = append(b.endpoints, &endpoint{Endpoint: e, healthy: healthy})
+		if healthy {
+			b.metrics.EndpointHealthy.WithLabelValues(e.Name).Set(1)
+		} else {
+			b.metrics.EndpointHealthy.WithLabelValues(e.Name).Set(0)
+		}
+	}
+
+	if o.HealthCheckInterval > 0 {
+		b.wg.Add(1)
+		go b.healthCheckLoop()
+	}
+
+	return b, nil
+}
+
+func (b *Backend) healthCheckLoop() {
+	defer b.wg.Done()
+
+	ticker := time.NewTicker(b.options.HealthCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-b.quit:
+			return
+		case <-ticker.C:
+		}
+		b.healthCheck()
+	}
+}
+
+// healthCheck queries the block number of all the endpoints and marks the
+// ones which fail to respond or are too far behind the others as unhealthy.
+func (b *Backend) healthCheck() {
+	ctx, cancel := context.WithTimeout(context.Background(), b.options.HealthCheckInterval)
+	defer cancel()
+
+	var (
+		wg    sync.WaitGroup
+		heads = make([]uint64, len(b.endpoints))
+		errs  = make([]error, len(b.endpoints))
+	)
+	for i, ep := range b.endpoints {
+		wg.Add(1)
+		go func(i int, ep *endpoint) {
+			defer wg.Done()
+			backend, err := b.connect(ctx, ep)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			b.metrics.EndpointCalls.WithLabelValues(ep.Name).Inc()
+			start := time.Now()
+			heads[i], errs[i] = backend.BlockNumber(ctx)
+			b.metrics.EndpointLatency.WithLabelValues(ep.Name).Observe(time.Since(start).Seconds())
+		}(i, ep)
+	}
+	wg.Wait()
+
+	var max uint64
+	for i := range heads {
+		if errs[i] == nil && heads[i] > max {
+			max = heads[i]
+		}
+	}
+
+	for i, ep := range b.endpoints {
+		switch {
+		case errs[i] != nil:
+			b.metrics.EndpointErrors.WithLabelValues(ep.Name).Inc()
+			b.setHealthy(ep, false, errs[i])
+		case b.options.MaxBlockLag > 0 && heads[i]+b.options.MaxBlockLag < max:
+			b.setHealthy(ep, false, fmt.Errorf("block %d is %d blocks behind", heads[i], max-heads[i]))
+		default:
+			b.setHealthy(ep, true, nil)
+		}
+	}
+}
+
+func (b *Backend) setHealthy(ep *endpoint, healthy bool, reason error) {
+	b.mu.Lock()
+	changed := ep.healthy != healthy
+	ep.healthy = healthy
+	b.mu.Unlock()
+
+	if !changed {
+		return
+	}
+	if healthy {
+		b.metrics.EndpointHealthy.WithLabelValues(ep.Name).Set(1)
+		b.logger.Info("chain backend endpoint recovered", "endpoint", ep.Name)
+	} else {
+		b.metrics.EndpointHealthy.WithLabelValues(ep.Name).Set(0)
+		b.logger.Warning("chain backend endpoint unhealthy", "endpoint", ep.Name, "error", reason)
+	}
+}
+
+// candidates returns the endpoints in the order they are called,
+// the healthy ones first, both in the order they were given.
+func (b *Backend) candidates() []*endpoint {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var healthy, unhealthy []*endpoint
+	for _, ep := range b.endpoints {
+		if ep.healthy {
+			healthy = append(healthy, ep)
+		} else {
+			unhealthy = append(unhealthy, ep)
+		}
+	}
+	return append(healthy, unhealthy...)
+}
+
+// endpointError reports whether the error is caused by the endpoint, like
+// a failed connection, rather than being its response to the call, like a
+// reverted call or a missing receipt, which the other endpoints would
+// respond with as well.
+func endpointError(err error) bool {
+	if errors.Is(err, ethereum.NotFound) || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var rpcErr rpc.Error
+	return !errors.As(err, &rpcErr)
+}
+
+// connect returns the backend of the endpoint, dialling
+// it first if the endpoint has not been connected yet.
+func (b *Backend) connect(ctx context.Context, ep *endpoint) (transaction.Backend, error) {
+	ep.connMu.Lock()
+	defer ep.connMu.Unlock()
+
+	if ep.Backend != nil {
+		return ep.Backend, nil
+	}
+	if ep.Dial == nil {
+		return nil, errNotConnected
+	}
+	backend, err := ep.Dial(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errNotConnected, err)
+	}
+	ep.Backend = backend
+	b.logger.Info("chain backend endpoint connected", "endpoint", ep.Name)
+	return backend, nil
+}
+
+// callEndpoint calls f with the backend of the endpoint
+// and marks the endpoint as unhealthy if it fails.
+func (b *Backend) callEndpoint(ctx context.Context, ep *endpoint, f func(transaction.Backend) error) error {
+	backend, err := b.connect(ctx, ep)
+	if err != nil {
+		b.metrics.EndpointErrors.WithLabelValues(ep.Name).Inc()
+		b.setHealthy(ep, false, err)
+		return err
+	}
+
+	b.metrics.EndpointCalls.WithLabelValues(ep.Name).Inc()
+	start := time.Now()
+	err = f(backend)
+	b.metrics.EndpointLatency.WithLabelValues(ep.Name).Observe(time.Since(start).Seconds())
+
+	if err != nil && endpointError(err) {
+		b.metrics.EndpointErrors.WithLabelValues(ep.Name).Inc()
+		b.setHealthy(ep, false, err)
+		return err
+	}
+	// without the health checks, the endpoints
+	// recover when they respond to a call
+	if b.options.HealthCheckInterval == 0 {
+		b.setHealthy(ep, true, nil)
+	}
+	return err
+}
+
+// call calls f with the backends of the endpoints until one of them
+// responds. The error of the last endpoint is returned if none does.
+func (b *Backend) call(ctx context.Context, f func(transaction.Backend) error) (err error) {
+	for i, ep := range b.candidates() {
+		if i > 0 {
+			b.metrics.Failovers.Inc()
+			b.logger.Debug("failing over to endpoint", "endpoint", ep.Name, "error", err)
+		}
+		err = b.callEndpoint(ctx, ep, f)
+		if err == nil || !endpointError(err) || ctx.Err() != nil {
+			return err
+		}
+	}
+	return err
+}
+
+// callAll calls f concurrently with the backends of the healthy endpoints,
+// or of all the endpoints if there are not enough healthy ones for the
+// quorum, and returns the results of the endpoints which responded.
+func (b *Backend) callAll(ctx context.Context, f func(transaction.Backend) (interface{}, error)) ([]interface{}, error) {
+	endpoints := b.candidates()
+	b.mu.Lock()
+	healthy := 0
+	for _, ep := range endpoints {
+		if ep.healthy {
+			healthy++
+		}
+	}
+	b.mu.Unlock()
+	if healthy >= b.options.Quorum {
+		endpoints = endpoints[:healthy]
+	}
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		results []interface{}
+		lastErr error
+	)
+	for _, ep := range endpoints {
+		wg.Add(1)
+		go func(ep *endpoint) {
+			defer wg.Done()
+			var v interface{}
+			err := b.callEndpoint(ctx, ep, func(backend transaction.Backend) (err error) {
+				v, err = f(backend)
+				return err
+			})
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				lastErr = err
+				return
+			}
+			results = append(results, v)
+		}(ep)
+	}
+	wg.Wait()
+
+	return results, lastErr
+}
+
+func (b *Backend) noQuorum(responded int, err error) error {
+	b.metrics.QuorumFailures.Inc()
+	if err != nil {
+		return fmt.Errorf("%w: %d endpoints agree, %d needed: %v", ErrNoQuorum, responded, b.options.Quorum, err)
+	}
+	return fmt.Errorf("%w: %d endpoints agree, %d needed", ErrNoQuorum, responded, b.options.Quorum)
+}
+
+// BlockNumber returns the block number of the endpoint. With the quorum, it
+// returns the highest block number reached by the quorum of the endpoints.
+func (b *Backend) BlockNumber(ctx context.Context) (blockNumber uint64, err error) {
+	if b.options.Quorum < 2 {
+		err = b.call(ctx, func(backend transaction.Backend) (err error) {
+			blockNumber, err = backend.BlockNumber(ctx)
+			return err
+		})
+		return blockNumber, err
+	}
+
+	results, err := b.callAll(ctx, func(backend transaction.Backend) (interface{}, error) {
+		return backend.BlockNumber(ctx)
+	})
+	if len(results) < b.options.Quorum {
+		return 0, b.noQuorum(len(results), err)
+	}
+	numbers := make([]uint64, 0, len(results))
+	for _, r := range results {
+		numbers = append(numbers, r.(uint64))
+	}
+	sort.Slice(numbers, func(i, j int) bool { return numbers[i] > numbers[j] })
+	return numbers[b.options.Quorum-1], nil
+}
+
+// FilterLogs returns the logs of the query. With the quorum,
+// the quorum of the endpoints must respond with the same logs.
+func (b *Backend) FilterLogs(ctx context.Context, query ethereum.FilterQuery) (logs []types.Log, err error) {
+	if b.options.Quorum < 2 {
+		err = b.call(ctx, func(backend transaction.Backend) (err error) {
+			logs, err = backend.FilterLogs(ctx, query)
+			return err
+		})
+		return logs, err
+	}
+
+	results, err := b.callAll(ctx, func(backend transaction.Backend) (interface{}, error) {
+		return backend.FilterLogs(ctx, query)
+	})
+	var (
+		counts = make(map[string]int)
+		max    int
+	)
+	for _, r := range results {
+		logs := r.([]types.Log)
+		key := logsKey(logs)
+		counts[key]++
+		if counts[key] >= b.options.Quorum {
+			return logs, nil
+		}
+		if counts[key] > max {
+			max = counts[key]
+		}
+	}
+	return nil, b.noQuorum(max, err)
+}
+
+// logsKey identifies the logs by the blocks, the transactions and the
+// positions they were emitted at, in order to compare the responses.
+func logsKey(logs []types.Log) string {
+	var sb strings.Builder
+	for _, l := range logs {
+		fmt.Fprintf(&sb, "%x:%x:%d:%t;", l.BlockHash, l.TxHash, l.Index, l.Removed)
+	}
+	return sb.String()
+}
+
+func (b *Backend) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) (code []byte, err error) {
+	err = b.call(ctx, func(backend transaction.Backend) (err error) {
+		code, err = backend.CodeAt(ctx, contract, blockNumber)
+		return err
+	})
+	return code, err
+}
+
+func (b *Backend) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) (result []byte, err error) {
+	err = b.call(ctx, func(backend transaction.Backend) (err error) {
+		result, err = backend.CallContract(ctx, call, blockNumber)
+		return err
+	})
+	return result, err
+}
+
+func (b *Backend) HeaderByNumber(ctx context.Context, number *big.Int) (header *types.Header, err error) {
+	err = b.call(ctx, func(backend transaction.Backend) (err error) {
+		header, err = backend.HeaderByNumber(ctx, number)
+		return err
+	})
+	return header, err
+}
+
+func (b *Backend) PendingNonceAt(ctx context.Context, account common.Address) (nonce uint64, err error) {
+	err = b.call(ctx, func(backend transaction.Backend) (err error) {
+		nonce, err = backend.PendingNonceAt(ctx, account)
+		return err
+	})
+	return nonce, err
+}
+
+func (b *Backend) SuggestGasPrice(ctx context.Context) (gasPrice *big.Int, err error) {
+	err = b.call(ctx, func(backend transaction.Backend) (err error) {
+		gasPrice, err = backend.SuggestGasPrice(ctx)
+		return err
+	})
+	return gasPrice, err
+}
+
+func (b *Backend) SuggestGasTipCap(ctx context.Context) (gasTipCap *big.Int, err error) {
+	err = b.call(ctx, func(backend transaction.Backend) (err error) {
+		gasTipCap, err = backend.SuggestGasTipCap(ctx)
+		return err
+	})
+	return gasTipCap, err
+}
+
+func (b *Backend) EstimateGas(ctx context.Context, call ethereum.CallMsg) (gas uint64, err error) {
+	err = b.call(ctx, func(backend transaction.Backend) (err error) {
+		gas, err = backend.EstimateGas(ctx, call)
+		return err
+	})
+	return gas, err
+}
+
+// SendTransaction sends the signed transaction. Sending it again to
+// another endpoint on failover is safe, as the transaction hash and
+// nonce stay the same.
+func (b *Backend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	return b.call(ctx, func(backend transaction.Backend) error {
+		return backend.SendTransaction(ctx, tx)
+	})
+}
+
+func (b *Backend) TransactionReceipt(ctx context.Context, txHash common.Hash) (receipt *types.Receipt, err error) {
+	err = b.call(ctx, func(backend transaction.Backend) (err error) {
+		receipt, err = backend.TransactionReceipt(ctx, txHash)
+		return err
+	})
+	return receipt, err
+}
+
+func (b *Backend) TransactionByHash(ctx context.Context, hash common.Hash) (tx *types.Transaction, isPending bool, err error) {
+	err = b.call(ctx, func(backend transaction.Backend) (err error) {
+		tx, isPending, err = backend.TransactionByHash(ctx, hash)
+		return err
+	})
+	return tx, isPending, err
+}
+
+func (b *Backend) BalanceAt(ctx context.Context, address common.Address, block *big.Int) (balance *big.Int, err error) {
+	err = b.call(ctx, func(backend transaction.Backend) (err error) {
+		balance, err = backend.BalanceAt(ctx, address, block)
+		return err
+	})
+	return balance, err
+}
+
+func (b *Backend) NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (nonce uint64, err error) {
+	err = b.call(ctx, func(backend transaction.Backend) (err error) {
+		nonce, err = backend.NonceAt(ctx, account, blockNumber)
+		return err
+	})
+	return nonce, err
+}
+
+func (b *Backend) ChainID(ctx context.Context) (chainID *big.Int, err error) {
+	err = b.call(ctx, func(backend transaction.Backend) (err error) {
+		chainID, err = backend.ChainID(ctx)
+		return err
+	})
+	return chainID, err
+}
+
+// Close stops the health checks and closes the backends of the endpoints.
+func (b *Backend) Close() {
+	close(b.quit)
+	b.wg.Wait()
+
+	for _, ep := range b.endpoints {
+		ep.connMu.Lock()
+		if ep.Backend != nil {
+			ep.Backend.Close()
+		}
+		ep.connMu.Unlock()
+	}
+}
@@ -0,0 +1,296 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package multibackend_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethersphere/bee/pkg/log"
+	"github.com/ethersphere/bee/pkg/transaction"
+	"github.com/ethersphere/bee/pkg/transaction/backendsimulation"
+	"github.com/ethersphere/bee/pkg/transaction/multibackend"
+)
+
+var errUnreachable = errors.New("connection refused")
+
+// failingBackend fails the calls with err, if set,
+// and counts the calls reaching the wrapped backend.
+type failingBackend struct {
+	transaction.Backend
+	err   error
+	calls int
+}
+
+func (b *failingBackend) BlockNumber(ctx context.Context) (uint64, error) {
+	b.calls++
+	if b.err != nil {
+		return 0, b.err
+	}
+	return b.Backend.BlockNumber(ctx)
+}
+
+func (b *failingBackend) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	b.calls++
+	if b.err != nil {
+		return nil, b.err
+	}
+	return b.Backend.TransactionReceipt(ctx, txHash)
+}
+
+func simulated(blockNumber uint64, logs ...types.Log) transaction.Backend {
+	return backendsimulation.New(backendsimulation.WithBlocks(backendsimulation.Block{
+		Number: blockNumber,
+		Logs:   logs,
+	}))
+}
+
+func newBackend(t *testing.T, o multibackend.Options, backends ...transaction.Backend) *multibackend.Backend {
+	t.Helper()
+
+	var endpoints []multibackend.Endpoint
+	for i, b := range backends {
+		endpoints = append(endpoints, multibackend.Endpoint{
+			Name:    string(rune('a' + i)),
+			Backend: b,
+		})
+	}
+	b, err := multibackend.New(log.Noop, endpoints, o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(b.Close)
+	return b
+}
+
+func TestNew(t *testing.T) {
+	if _, err := multibackend.New(log.Noop, nil, multibackend.Options{}); !errors.Is(err, multibackend.ErrNoEndpoints) {
+		t.Fatalf("got error %v, want %v", err, multibackend.ErrNoEndpoints)
+	}
+
+	endpoints := []multibackend.Endpoint{{Name: "a", Backend: simulated(1)}}
+	if _, err := multibackend.New(log.Noop, endpoints, multibackend.Options{Quorum: 2}); err == nil {
+		t.Fatal("expected error for the quorum exceeding the endpoints")
+	}
+}
+
+func TestUnreachableEndpoint(t *testing.T) {
+	var dials int
+	dialErr := errUnreachable
+	endpoints := []multibackend.Endpoint{
+		{Name: "a", Backend: simulated(10)},
+		{Name: "b", Dial: func(ctx context.Context) (transaction.Backend, error) {
+			dials++
+			if dialErr != nil {
+				return nil, dialErr
+			}
+			return simulated(10), nil
+		}},
+	}
+	// the quorum is checked against all the endpoints, reachable or not
+	b, err := multibackend.New(log.Noop, endpoints, multibackend.Options{Quorum: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(b.Close)
+
+	if b.Healthy("b") {
+		t.Fatal("unreachable endpoint is healthy")
+	}
+	if _, err := b.BlockNumber(context.Background()); !errors.Is(err, multibackend.ErrNoQuorum) {
+		t.Fatalf("got error %v, want %v", err, multibackend.ErrNoQuorum)
+	}
+	b.HealthCheck()
+	if b.Healthy("b") {
+		t.Fatal("unreachable endpoint is healthy")
+	}
+
+	// the endpoint is connected by the health check once it is reachable
+	dialErr = nil
+	b.HealthCheck()
+	if !b.Healthy("b") {
+		t.Fatal("reachable endpoint is not healthy")
+	}
+	n, err := b.BlockNumber(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 10 {
+		t.Fatalf("got block number %d, want 10", n)
+	}
+	if dials != 3 {
+		t.Fatalf("got %d dials, want 3", dials)
+	}
+}
+
+func TestFailover(t *testing.T) {
+	first := &failingBackend{Backend: simulated(10), err: errUnreachable}
+	second := &failingBackend{Backend: simulated(20)}
+	b := newBackend(t, multibackend.Options{}, first, second)
+
+	n, err := b.BlockNumber(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 20 {
+		t.Fatalf("got block number %d, want 20", n)
+	}
+	if b.Healthy("a") {
+		t.Fatal("failed endpoint is healthy")
+	}
+
+	// the unhealthy endpoint is not called while there are healthy ones
+	if _, err := b.BlockNumber(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if first.calls != 1 {
+		t.Fatalf("got %d calls to the failed endpoint, want 1", first.calls)
+	}
+
+	// the endpoint recovers when it responds
+	first.err = nil
+	second.err = errUnreachable
+	n, err = b.BlockNumber(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 10 {
+		t.Fatalf("got block number %d, want 10", n)
+	}
+	if !b.Healthy("a") {
+		t.Fatal("recovered endpoint is not healthy")
+	}
+
+	// all the endpoints failing returns the error
+	first.err = errUnreachable
+	if _, err := b.BlockNumber(context.Background()); !errors.Is(err, errUnreachable) {
+		t.Fatalf("got error %v, want %v", err, errUnreachable)
+	}
+}
+
+func TestNoFailoverOnResponseError(t *testing.T) {
+	first := &failingBackend{Backend: simulated(10)}
+	second := &failingBackend{Backend: simulated(10)}
+	b := newBackend(t, multibackend.Options{}, first, second)
+
+	if _, err := b.TransactionReceipt(context.Background(), common.Hash{}); !errors.Is(err, ethereum.NotFound) {
+		t.Fatalf("got error %v, want %v", err, ethereum.NotFound)
+	}
+	if second.calls != 0 {
+		t.Fatalf("got %d calls to the second endpoint, want 0", second.calls)
+	}
+	if !b.Healthy("a") {
+		t.Fatal("endpoint responding with not found is unhealthy")
+	}
+}
+
+func TestQuorumBlockNumber(t *testing.T) {
+	t.Run("highest block of the quorum", func(t *testing.T) {
+		b := newBackend(t, multibackend.Options{Quorum: 2}, simulated(15), simulated(10), simulated(12))
+
+		n, err := b.BlockNumber(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != 12 {
+			t.Fatalf("got block number %d, want 12", n)
+		}
+	})
+
+	t.Run("no quorum", func(t *testing.T) {
+		b := newBackend(t, multibackend.Options{Quorum: 2},
+			simulated(15),
+			&failingBackend{Backend: simulated(10), err: errUnreachable},
+		)
+
+		if _, err := b.BlockNumber(context.Background()); !errors.Is(err, multibackend.ErrNoQuorum) {
+			t.Fatalf("got error %v, want %v", err, multibackend.ErrNoQuorum)
+		}
+	})
+}
+
+func TestQuorumFilterLogs(t *testing.T) {
+	logs := []types.Log{
+		{BlockNumber: 5, BlockHash: common.HexToHash("0x1"), TxHash: common.HexToHash("0x2"), Index: 0},
+		{BlockNumber: 6, BlockHash: common.HexToHash("0x3"), TxHash: common.HexToHash("0x4"), Index: 1},
+	}
+	// the disagreeing endpoint has the second log in a block of another fork
+	forked := []types.Log{
+		logs[0],
+		{BlockNumber: 6, BlockHash: common.HexToHash("0x5"), TxHash: common.HexToHash("0x4"), Index: 1},
+	}
+
+	newBackends := func() []transaction.Backend {
+		return []transaction.Backend{
+			simulated(10, forked...),
+			simulated(10, logs...),
+			simulated(10, logs...),
+		}
+	}
+
+	t.Run("quorum agrees", func(t *testing.T) {
+		b := newBackend(t, multibackend.Options{Quorum: 2}, newBackends()...)
+		// reach the blocks holding the logs
+		if _, err := b.BlockNumber(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := b.FilterLogs(context.Background(), ethereum.FilterQuery{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != len(logs) {
+			t.Fatalf("got %d logs, want %d", len(got), len(logs))
+		}
+		for i := range got {
+			if got[i].BlockHash != logs[i].BlockHash {
+				t.Fatalf("got log %d in block %s, want %s", i, got[i].BlockHash, logs[i].BlockHash)
+			}
+		}
+	})
+
+	t.Run("no quorum", func(t *testing.T) {
+		b := newBackend(t, multibackend.Options{Quorum: 3}, newBackends()...)
+		if _, err := b.BlockNumber(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := b.FilterLogs(context.Background(), ethereum.FilterQuery{}); !errors.Is(err, multibackend.ErrNoQuorum) {
+			t.Fatalf("got error %v, want %v", err, multibackend.ErrNoQuorum)
+		}
+	})
+}
+
+func TestHealthCheck(t *testing.T) {
+	failing := &failingBackend{Backend: simulated(100), err: errUnreachable}
+	b := newBackend(t, multibackend.Options{
+		HealthCheckInterval: time.Hour,
+		MaxBlockLag:         5,
+	}, simulated(100), simulated(90), failing)
+
+	b.HealthCheck()
+
+	if !b.Healthy("a") {
+		t.Fatal("endpoint at the head is unhealthy")
+	}
+	if b.Healthy("b") {
+		t.Fatal("lagging endpoint is healthy")
+	}
+	if b.Healthy("c") {
+		t.Fatal("failing endpoint is healthy")
+	}
+
+	failing.err = nil
+	b.HealthCheck()
+
+	if !b.Healthy("c") {
+		t.Fatal("recovered endpoint is unhealthy")
+	}
+}
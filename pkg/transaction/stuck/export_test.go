@@ -0,0 +1,17 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stuck
+
+import "context"
+
+func (s *Manager) Check() { s.check(context.Background()) }
+
+// SubscribeEvents returns the channel receiving the events
+// of the manager, which must be subscribed before the checks.
+func (s *Manager) SubscribeEvents() (<-chan Event, func()) {
+	c := make(chan Event, 16)
+	s.eventHook = func(e Event) { c <- e }
+	return c, func() { s.eventHook = nil }
+}
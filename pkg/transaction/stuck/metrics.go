@@ -0,0 +1,59 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stuck
+
+import (
+	m "github.com/ethersphere/bee/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type metrics struct {
+	StuckTransactions prometheus.Gauge
+	Replacements      prometheus.Counter
+	Rebroadcasts      prometheus.Counter
+	NonceGapsFilled   prometheus.Counter
+	Errors            prometheus.Counter
+}
+
+func newMetrics() metrics {
+	subsystem := "stuck_transactions"
+
+	return metrics{
+		StuckTransactions: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "stuck",
+			Help:      "Number of pending transactions unconfirmed past the timeout at the last check.",
+		}),
+		Replacements: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "replacements",
+			Help:      "Count of stuck transactions replaced with bumped fees.",
+		}),
+		Rebroadcasts: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "rebroadcasts",
+			Help:      "Count of stuck transactions rebroadcast unchanged.",
+		}),
+		NonceGapsFilled: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "nonce_gaps_filled",
+			Help:      "Count of nonce gaps filled.",
+		}),
+		Errors: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "errors",
+			Help:      "Count of failed replacements, rebroadcasts and nonce gap fillings.",
+		}),
+	}
+}
+
+func (s *Manager) Metrics() []prometheus.Collector {
+	return m.PrometheusCollectorsFromFields(s.metrics)
+}
@@ -0,0 +1,252 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package stuck provides the manager of the stuck transactions. The pending
+// transactions which stay unconfirmed past a timeout are replaced with the
+// same transactions paying bumped fees, up to a maximum fee, and are
+// rebroadcast unchanged once the maximum is reached. The gaps in the nonces
+// of the transactions, which block all the following transactions, are
+// filled as well.
+package stuck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/log"
+	"github.com/ethersphere/bee/pkg/transaction"
+)
+
+// loggerName is the tree path name of the logger for this package.
+const loggerName = "stuck-transactions"
+
+const (
+	// EventReplacement is the type of the events of the stuck
+	// transactions replaced with the ones paying bumped fees.
+	EventReplacement = "replacement"
+	// EventRebroadcast is the type of the events of the
+	// stuck transactions rebroadcast unchanged.
+	EventRebroadcast = "rebroadcast"
+	// EventNonceGap is the type of the events of the nonce gaps
+	// filled by rebroadcasting the transaction with the missing
+	// nonce or by cancelling the nonce if there is none.
+	EventNonceGap = "nonce-gap"
+)
+
+// Event is a record of an action taken on a stuck transaction,
+// logged and counted by the metrics.
+type Event struct {
+	Type      string
+	Timestamp int64
+	TxHash    common.Hash // the stuck transaction
+	NewTxHash common.Hash // the transaction sent by the action
+	Nonce     uint64
+	Error     error // error of a failed action
+}
+
+// Options are the options of the Manager.
+type Options struct {
+	// Timeout is the time after which a pending
+	// transaction is considered stuck.
+	Timeout time.Duration
+	// Interval is the time between two checks of the pending
+	// transactions. The zero value disables the checks.
+	Interval time.Duration
+	// FeeBump is the percentage by which the fees of the stuck
+	// transactions are bumped, at least the one required by the
+	// backends for the replacements.
+	FeeBump uint64
+	// MaxFee is the fee per gas the bumped fees do not exceed.
+	// Nil disables the bumps, the stuck transactions are only
+	// rebroadcast.
+	MaxFee *big.Int
+}
+
+// Manager detects the stuck transactions and unsticks them.
+type Manager struct {
+	logger  log.Logger
+	service transaction.Service
+	options Options
+	metrics metrics
+
+	checkMu     sync.Mutex                // serializes the checks of the transactions
+	rebroadcast map[common.Hash]time.Time // time of the last rebroadcast of the transactions
+
+	eventHook func(Event) // called with every event, set in tests
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// New constructs a new Manager of the transactions of the service.
+func New(service transaction.Service, logger log.Logger, o Options) *Manager {
+	s := &Manager{
+		logger:      logger.WithName(loggerName).Register(),
+		service:     service,
+		options:     o,
+		metrics:     newMetrics(),
+		rebroadcast: make(map[common.Hash]time.Time),
+		quit:        make(chan struct{}),
+	}
+
+	if o.Interval > 0 {
+		s.wg.Add(1)
+		go s.worker()
+	}
+	return s
+}
+
+func (s *Manager) worker() {
+	defer s.wg.Done()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-s.quit
+		cancel()
+	}()
+
+	ticker := time.NewTicker(s.options.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.quit:
+			return
+		case <-ticker.C:
+		}
+		s.check(ctx)
+	}
+}
+
+func (s *Manager) emit(e Event) {
+	e.Timestamp = time.Now().Unix()
+
+	if e.Error != nil {
+		s.metrics.Errors.Inc()
+		s.logger.Warning("unsticking transaction failed", "type", e.Type, "tx", fmt.Sprintf("%x", e.TxHash), "nonce", e.Nonce, "error", e.Error)
+	} else {
+		s.logger.Info("unsticking transaction", "type", e.Type, "tx", fmt.Sprintf("%x", e.TxHash), "new_tx", fmt.Sprintf("%x", e.NewTxHash), "nonce", e.Nonce)
+	}
+
+	if s.eventHook != nil {
+		s.eventHook(e)
+	}
+}
+
+// check fills the nonce gap, if there is one,
+// and unsticks the stuck pending transactions.
+func (s *Manager) check(ctx context.Context) {
+	s.checkMu.Lock()
+	defer s.checkMu.Unlock()
+
+	txHash, filled, err := s.service.FillNonceGap(ctx)
+	switch {
+	case err != nil:
+		s.emit(Event{Type: EventNonceGap, Error: err})
+	case filled:
+		s.metrics.NonceGapsFilled.Inc()
+		e := Event{Type: EventNonceGap, NewTxHash: txHash}
+		if storedTransaction, err := s.service.StoredTransaction(txHash); err == nil {
+			e.Nonce = storedTransaction.Nonce
+		}
+		s.emit(e)
+	}
+
+	pendingTxs, err := s.service.PendingTransactions()
+	if err != nil {
+		s.logger.Error(err, "unable to get pending transactions")
+		return
+	}
+
+	var (
+		stuck   float64
+		pending = make(map[common.Hash]bool)
+		stored  = make(map[common.Hash]*transaction.StoredTransaction)
+		latest  = make(map[uint64]common.Hash) // latest pending transaction of a nonce
+	)
+	for _, txHash := range pendingTxs {
+		pending[txHash] = true
+
+		storedTransaction, err := s.service.StoredTransaction(txHash)
+		if err != nil {
+			s.logger.Error(err, "unable to get pending transaction", "tx", fmt.Sprintf("%x", txHash))
+			continue
+		}
+		// the replacement is tracked on its own
+		if storedTransaction.ReplacedBy != nil {
+			continue
+		}
+		stored[txHash] = storedTransaction
+
+		if h, ok := latest[storedTransaction.Nonce]; !ok || transaction.Supersedes(storedTransaction, stored[h]) {
+			latest[storedTransaction.Nonce] = txHash
+		}
+	}
+
+	for _, txHash := range pendingTxs {
+		storedTransaction, ok := stored[txHash]
+		// the nonce is unstuck only through its latest transaction
+		if !ok || latest[storedTransaction.Nonce] != txHash {
+			continue
+		}
+
+		since := time.Unix(storedTransaction.Created, 0)
+		if t, ok := s.rebroadcast[txHash]; ok && t.After(since) {
+			since = t
+		}
+		if time.Since(since) < s.options.Timeout {
+			continue
+		}
+
+		stuck++
+		s.unstick(ctx, txHash, storedTransaction)
+	}
+	s.metrics.StuckTransactions.Set(stuck)
+
+	for txHash := range s.rebroadcast {
+		if !pending[txHash] {
+			delete(s.rebroadcast, txHash)
+		}
+	}
+}
+
+// unstick replaces the stuck transaction with the one paying bumped fees,
+// or rebroadcasts it unchanged if the fees can not be bumped anymore.
+func (s *Manager) unstick(ctx context.Context, txHash common.Hash, storedTransaction *transaction.StoredTransaction) {
+	if s.options.MaxFee != nil {
+		newTxHash, err := s.service.ReplaceTransaction(ctx, txHash, s.options.FeeBump, s.options.MaxFee)
+		switch {
+		case err == nil:
+			s.metrics.Replacements.Inc()
+			s.emit(Event{Type: EventReplacement, TxHash: txHash, NewTxHash: newTxHash, Nonce: storedTransaction.Nonce})
+			return
+		case !errors.Is(err, transaction.ErrMaxFeeReached):
+			s.rebroadcast[txHash] = time.Now()
+			s.emit(Event{Type: EventReplacement, TxHash: txHash, Nonce: storedTransaction.Nonce, Error: err})
+			return
+		}
+	}
+
+	s.rebroadcast[txHash] = time.Now()
+	err := s.service.ResendTransaction(ctx, txHash)
+	if err != nil && !errors.Is(err, transaction.ErrAlreadyImported) {
+		s.emit(Event{Type: EventRebroadcast, TxHash: txHash, Nonce: storedTransaction.Nonce, Error: err})
+		return
+	}
+	s.metrics.Rebroadcasts.Inc()
+	s.emit(Event{Type: EventRebroadcast, TxHash: txHash, NewTxHash: txHash, Nonce: storedTransaction.Nonce})
+}
+
+// Close stops the checks of the transactions.
+func (s *Manager) Close() error {
+	close(s.quit)
+	s.wg.Wait()
+	return nil
+}
@@ -0,0 +1,346 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stuck_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	signermock "github.com/ethersphere/bee/pkg/crypto/mock"
+	"github.com/ethersphere/bee/pkg/log"
+	storemock "github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/transaction"
+	"github.com/ethersphere/bee/pkg/transaction/backendmock"
+	"github.com/ethersphere/bee/pkg/transaction/mock"
+	"github.com/ethersphere/bee/pkg/transaction/monitormock"
+	"github.com/ethersphere/bee/pkg/transaction/stuck"
+)
+
+var (
+	stuckTxHash       = common.HexToHash("0x1")
+	recentTxHash      = common.HexToHash("0x2")
+	replacedTxHash    = common.HexToHash("0x3")
+	replacementTxHash = common.HexToHash("0x4")
+	gapTxHash         = common.HexToHash("0x5")
+)
+
+type calls struct {
+	replaced    []common.Hash
+	resent      []common.Hash
+	feeBump     uint64
+	maxFee      *big.Int
+	fillNonce   int
+	replaceErr  error
+	gapFilled   bool
+	replacement common.Hash
+}
+
+func newService(c *calls) transaction.Service {
+	old := time.Now().Add(-time.Hour).Unix()
+	stored := map[common.Hash]*transaction.StoredTransaction{
+		stuckTxHash:    {Nonce: 1, Created: old},
+		recentTxHash:   {Nonce: 2, Created: time.Now().Unix()},
+		replacedTxHash: {Nonce: 3, Created: old, ReplacedBy: &replacementTxHash},
+		gapTxHash:      {Nonce: 0, Created: time.Now().Unix()},
+	}
+	return mock.New(
+		mock.WithPendingTransactionsFunc(func() ([]common.Hash, error) {
+			return []common.Hash{stuckTxHash, recentTxHash, replacedTxHash}, nil
+		}),
+		mock.WithStoredTransactionFunc(func(txHash common.Hash) (*transaction.StoredTransaction, error) {
+			if tx, ok := stored[txHash]; ok {
+				return tx, nil
+			}
+			return nil, transaction.ErrUnknownTransaction
+		}),
+		mock.WithReplaceTransactionFunc(func(ctx context.Context, txHash common.Hash, feeBump uint64, maxFee *big.Int) (common.Hash, error) {
+			c.replaced = append(c.replaced, txHash)
+			c.feeBump, c.maxFee = feeBump, maxFee
+			return c.replacement, c.replaceErr
+		}),
+		mock.WithResendTransactionFunc(func(ctx context.Context, txHash common.Hash) error {
+			c.resent = append(c.resent, txHash)
+			return nil
+		}),
+		mock.WithFillNonceGapFunc(func(ctx context.Context) (common.Hash, bool, error) {
+			c.fillNonce++
+			if c.gapFilled {
+				return gapTxHash, true, nil
+			}
+			return common.Hash{}, false, nil
+		}),
+	)
+}
+
+func TestReplacement(t *testing.T) {
+	c := &calls{replacement: common.HexToHash("0xff")}
+	maxFee := big.NewInt(1000)
+	m := stuck.New(newService(c), log.Noop, stuck.Options{
+		Timeout: time.Minute,
+		FeeBump: 20,
+		MaxFee:  maxFee,
+	})
+	defer m.Close()
+
+	events, unsubscribe := m.SubscribeEvents()
+	defer unsubscribe()
+
+	m.Check()
+
+	if len(c.replaced) != 1 || c.replaced[0] != stuckTxHash {
+		t.Fatalf("got replaced transactions %v, want %v", c.replaced, stuckTxHash)
+	}
+	if c.feeBump != 20 {
+		t.Fatalf("got fee bump %d, want 20", c.feeBump)
+	}
+	if c.maxFee.Cmp(maxFee) != 0 {
+		t.Fatalf("got max fee %d, want %d", c.maxFee, maxFee)
+	}
+	if len(c.resent) != 0 {
+		t.Fatalf("got resent transactions %v, want none", c.resent)
+	}
+
+	select {
+	case e := <-events:
+		if e.Type != stuck.EventReplacement || e.TxHash != stuckTxHash || e.NewTxHash != c.replacement || e.Nonce != 1 {
+			t.Fatalf("got event %+v", e)
+		}
+	default:
+		t.Fatal("no replacement event")
+	}
+}
+
+func TestRebroadcast(t *testing.T) {
+	t.Run("max fee reached", func(t *testing.T) {
+		c := &calls{replaceErr: transaction.ErrMaxFeeReached}
+		m := stuck.New(newService(c), log.Noop, stuck.Options{
+			Timeout: time.Minute,
+			MaxFee:  big.NewInt(1000),
+		})
+		defer m.Close()
+
+		events, unsubscribe := m.SubscribeEvents()
+		defer unsubscribe()
+
+		m.Check()
+
+		if len(c.resent) != 1 || c.resent[0] != stuckTxHash {
+			t.Fatalf("got resent transactions %v, want %v", c.resent, stuckTxHash)
+		}
+		e := <-events
+		if e.Type != stuck.EventRebroadcast || e.TxHash != stuckTxHash || e.Error != nil {
+			t.Fatalf("got event %+v", e)
+		}
+
+		// the rebroadcast transaction is stuck again only after the timeout
+		m.Check()
+		if len(c.resent) != 1 {
+			t.Fatalf("got %d rebroadcasts, want 1", len(c.resent))
+		}
+	})
+
+	t.Run("no max fee", func(t *testing.T) {
+		c := &calls{}
+		m := stuck.New(newService(c), log.Noop, stuck.Options{
+			Timeout: time.Minute,
+		})
+		defer m.Close()
+
+		m.Check()
+
+		if len(c.replaced) != 0 {
+			t.Fatalf("got replaced transactions %v, want none", c.replaced)
+		}
+		if len(c.resent) != 1 || c.resent[0] != stuckTxHash {
+			t.Fatalf("got resent transactions %v, want %v", c.resent, stuckTxHash)
+		}
+	})
+}
+
+func TestReplacementError(t *testing.T) {
+	c := &calls{replaceErr: errors.New("replacement underpriced")}
+	m := stuck.New(newService(c), log.Noop, stuck.Options{
+		Timeout: time.Minute,
+		MaxFee:  big.NewInt(1000),
+	})
+	defer m.Close()
+
+	events, unsubscribe := m.SubscribeEvents()
+	defer unsubscribe()
+
+	m.Check()
+
+	e := <-events
+	if e.Type != stuck.EventReplacement || !errors.Is(e.Error, c.replaceErr) {
+		t.Fatalf("got event %+v", e)
+	}
+
+	// the failed replacement is retried only after the timeout
+	m.Check()
+	if len(c.replaced) != 1 {
+		t.Fatalf("got %d replacements, want 1", len(c.replaced))
+	}
+}
+
+func TestNonceGap(t *testing.T) {
+	c := &calls{gapFilled: true}
+	m := stuck.New(newService(c), log.Noop, stuck.Options{
+		Timeout: 2 * time.Hour,
+	})
+	defer m.Close()
+
+	events, unsubscribe := m.SubscribeEvents()
+	defer unsubscribe()
+
+	m.Check()
+
+	if c.fillNonce != 1 {
+		t.Fatalf("got %d nonce gap fillings, want 1", c.fillNonce)
+	}
+	e := <-events
+	if e.Type != stuck.EventNonceGap || e.NewTxHash != gapTxHash {
+		t.Fatalf("got event %+v", e)
+	}
+	if len(c.resent) != 0 || len(c.replaced) != 0 {
+		t.Fatal("unsticking transactions before the timeout")
+	}
+}
+
+func TestSharedNonce(t *testing.T) {
+	old := time.Now().Add(-time.Hour).Unix()
+	originalTxHash := common.HexToHash("0x10")
+	newerTxHash := common.HexToHash("0x11")
+	stored := map[common.Hash]*transaction.StoredTransaction{
+		originalTxHash: {Nonce: 1, Created: old, GasPrice: big.NewInt(10)},
+		newerTxHash:    {Nonce: 1, Created: old + 1, GasPrice: big.NewInt(11)},
+	}
+
+	var resent []common.Hash
+	service := mock.New(
+		mock.WithPendingTransactionsFunc(func() ([]common.Hash, error) {
+			return []common.Hash{newerTxHash, originalTxHash}, nil
+		}),
+		mock.WithStoredTransactionFunc(func(txHash common.Hash) (*transaction.StoredTransaction, error) {
+			if tx, ok := stored[txHash]; ok {
+				return tx, nil
+			}
+			return nil, transaction.ErrUnknownTransaction
+		}),
+		mock.WithResendTransactionFunc(func(ctx context.Context, txHash common.Hash) error {
+			resent = append(resent, txHash)
+			return nil
+		}),
+		mock.WithFillNonceGapFunc(func(ctx context.Context) (common.Hash, bool, error) {
+			return common.Hash{}, false, nil
+		}),
+	)
+
+	m := stuck.New(service, log.Noop, stuck.Options{
+		Timeout: time.Minute,
+	})
+	defer m.Close()
+
+	m.Check()
+
+	if len(resent) != 1 || resent[0] != newerTxHash {
+		t.Fatalf("got resent transactions %v, want %v", resent, newerTxHash)
+	}
+}
+
+func TestCancelled(t *testing.T) {
+	sender := common.HexToAddress("0xddff")
+	recipient := common.HexToAddress("0xbbbddd")
+	chainID := big.NewInt(5)
+	gasPrice := big.NewInt(1000)
+
+	store := storemock.NewStateStore()
+	defer store.Close()
+
+	originalTx := types.NewTx(&types.LegacyTx{
+		Nonce:    3,
+		To:       &recipient,
+		Value:    big.NewInt(0),
+		Gas:      100000,
+		GasPrice: gasPrice,
+		Data:     []byte{1, 2, 3},
+	})
+	err := store.Put(fmt.Sprintf("transaction_stored_%x", originalTx.Hash()), transaction.StoredTransaction{
+		To:       &recipient,
+		Data:     []byte{1, 2, 3},
+		GasPrice: gasPrice,
+		GasLimit: 100000,
+		Value:    big.NewInt(0),
+		Nonce:    3,
+		Created:  time.Now().Add(-time.Hour).Unix(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Put(fmt.Sprintf("transaction_pending_%x", originalTx.Hash()), struct{}{}); err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		mu   sync.Mutex
+		sent []common.Hash
+	)
+	service, err := transaction.NewService(log.Noop,
+		backendmock.New(
+			backendmock.WithSendTransactionFunc(func(ctx context.Context, tx *types.Transaction) error {
+				mu.Lock()
+				defer mu.Unlock()
+				sent = append(sent, tx.Hash())
+				return nil
+			}),
+		),
+		signermock.New(
+			signermock.WithSignTxFunc(func(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+				return tx, nil
+			}),
+			signermock.WithEthereumAddressFunc(func() (common.Address, error) {
+				return sender, nil
+			}),
+		),
+		store,
+		chainID,
+		monitormock.New(),
+		transaction.FeeOptions{},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer service.Close()
+
+	cancelTxHash, err := service.CancelTransaction(context.Background(), originalTx.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// both the original and the cancellation are past the timeout
+	m := stuck.New(service, log.Noop, stuck.Options{
+		Timeout: time.Nanosecond,
+	})
+	defer m.Close()
+
+	m.Check()
+
+	mu.Lock()
+	defer mu.Unlock()
+	for _, txHash := range sent {
+		if txHash == originalTx.Hash() {
+			t.Fatal("cancelled transaction resent")
+		}
+	}
+	if len(sent) != 2 || sent[0] != cancelTxHash || sent[1] != cancelTxHash {
+		t.Fatalf("got sent transactions %v, want the cancellation %v sent and resent", sent, cancelTxHash)
+	}
+}
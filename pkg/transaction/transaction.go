@@ -5,6 +5,7 @@
 package transaction
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"io"
@@ -39,17 +40,32 @@ var (
 	ErrUnknownTransaction  = errors.New("unknown transaction")
 	ErrAlreadyImported     = errors.New("already imported")
 	ErrGasPriceTooLow      = errors.New("gas price too low")
+	// ErrMaxFeeReached denotes that the fees of a replacement
+	// transaction would exceed the given maximum.
+	ErrMaxFeeReached = errors.New("max fee reached")
+	// ErrAlreadyReplaced denotes that the transaction
+	// has already been replaced by another one.
+	ErrAlreadyReplaced = errors.New("already replaced")
 )
 
 // minGasPrice determines the minimum gas price
 // threshold (in wei) for the creation of a transaction.
 var minGasPrice = big.NewInt(1000)
 
+// baseFeeMultiplier is the multiple of the base fee of the latest block the
+// max fee of a dynamic fee transaction covers, so that the transaction stays
+// includable over several blocks with increasing base fees.
+const baseFeeMultiplier = 2
+
+// replacementFeeBump is the percentage by which the fees of a replacement
+// transaction must exceed the ones of the transaction it replaces.
+const replacementFeeBump = 10
+
 // TxRequest describes a request for a transaction that can be executed.
 type TxRequest struct {
 	To          *common.Address // recipient of the transaction
 	Data        []byte          // transaction data
-	GasPrice    *big.Int        // gas price or nil if suggested gas price should be used
+	GasPrice    *big.Int        // gas price, or max fee per gas for dynamic fee transactions, or nil if suggested gas price should be used
 	GasLimit    uint64          // gas limit or 0 if it should be estimated
 	Value       *big.Int        // amount of wei to send
 	Description string          // optional description
@@ -58,12 +74,21 @@ type TxRequest struct {
 type StoredTransaction struct {
 	To          *common.Address // recipient of the transaction
 	Data        []byte          // transaction data
-	GasPrice    *big.Int        // used gas price
+	GasPrice    *big.Int        // used gas price, the max fee per gas for dynamic fee transactions
+	GasFeeCap   *big.Int        // used max fee per gas or nil for legacy transactions
+	GasTipCap   *big.Int        // used priority fee per gas or nil for legacy transactions
 	GasLimit    uint64          // used gas limit
 	Value       *big.Int        // amount of wei to send
 	Nonce       uint64          // used nonce
 	Created     int64           // creation timestamp
 	Description string          // description
+	ReplacedBy  *common.Hash    // hash of the transaction replacing this one or nil
+}
+
+// FeeOptions configures the fees of the dynamic fee transactions.
+type FeeOptions struct {
+	MaxFee      *big.Int // max fee per gas or nil for no limit
+	PriorityFee *big.Int // priority fee per gas or nil if the suggested one should be used
 }
 
 // Service is the service to send transactions. It takes care of gas price, gas
@@ -90,6 +115,12 @@ type Service interface {
 	ResendTransaction(ctx context.Context, txHash common.Hash) error
 	// CancelTransaction cancels a previously sent transaction by double-spending its nonce with zero-transfer one
 	CancelTransaction(ctx context.Context, originalTxHash common.Hash) (common.Hash, error)
+	// ReplaceTransaction replaces a previously sent transaction with the same one paying fees bumped by the given percentage
+	// The receipt of the replacement is returned when waiting for the receipt of the replaced transaction
+	ReplaceTransaction(ctx context.Context, txHash common.Hash, feeBump uint64, maxFee *big.Int) (common.Hash, error)
+	// FillNonceGap fills the gap between the nonce of the transactions known to the backend and the next nonce of the service
+	// It returns the hash of the transaction sent to fill the gap and false if there is no gap
+	FillNonceGap(ctx context.Context) (common.Hash, bool, error)
 }
 
 type transactionService struct {
@@ -105,10 +136,13 @@ type transactionService struct {
 	store   storage.StateStorer
 	chainID *big.Int
 	monitor Monitor
+	fees    FeeOptions
 }
 
-// NewService creates a new transaction service.
-func NewService(logger log.Logger, backend Backend, signer crypto.Signer, store storage.StateStorer, chainID *big.Int, monitor Monitor) (Service, error) {
+// NewService creates a new transaction service. It sends dynamic fee
+// transactions with the given fee options on chains supporting EIP-1559
+// and legacy transactions on the other chains.
+func NewService(logger log.Logger, backend Backend, signer crypto.Signer, store storage.StateStorer, chainID *big.Int, monitor Monitor, fees FeeOptions) (Service, error) {
 	senderAddress, err := signer.EthereumAddress()
 	if err != nil {
 		return nil, err
@@ -126,6 +160,7 @@ func NewService(logger log.Logger, backend Backend, signer crypto.Signer, store
 		store:   store,
 		chainID: chainID,
 		monitor: monitor,
+		fees:    fees,
 	}
 
 	pendingTxs, err := t.PendingTransactions()
@@ -175,16 +210,7 @@ func (t *transactionService) Send(ctx context.Context, request *TxRequest) (txHa
 
 	txHash = signedTx.Hash()
 
-	err = t.store.Put(storedTransactionKey(txHash), StoredTransaction{
-		To:          signedTx.To(),
-		Data:        signedTx.Data(),
-		GasPrice:    signedTx.GasPrice(),
-		GasLimit:    signedTx.Gas(),
-		Value:       signedTx.Value(),
-		Nonce:       signedTx.Nonce(),
-		Created:     time.Now().Unix(),
-		Description: request.Description,
-	})
+	err = t.store.Put(storedTransactionKey(txHash), newStoredTransaction(signedTx, request.Description))
 	if err != nil {
 		return common.Hash{}, err
 	}
@@ -272,6 +298,28 @@ func (t *transactionService) prepareTransaction(ctx context.Context, request *Tx
 		gasLimit = request.GasLimit
 	}
 
+	header, err := t.backend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	// chains without EIP-1559 have no base fee
+	if header.BaseFee != nil {
+		gasFeeCap, gasTipCap, err := t.dynamicFees(ctx, request.GasPrice, header.BaseFee)
+		if err != nil {
+			return nil, err
+		}
+		return types.NewTx(&types.DynamicFeeTx{
+			ChainID:   t.chainID,
+			Nonce:     nonce,
+			GasTipCap: gasTipCap,
+			GasFeeCap: gasFeeCap,
+			Gas:       gasLimit,
+			To:        request.To,
+			Value:     request.Value,
+			Data:      request.Data,
+		}), nil
+	}
+
 	gasPrice := request.GasPrice
 	if gasPrice == nil {
 		gasPrice, err = t.backend.SuggestGasPrice(ctx)
@@ -293,6 +341,91 @@ func (t *transactionService) prepareTransaction(ctx context.Context, request *Tx
 	}), nil
 }
 
+// dynamicFees returns the max fee and the priority fee per gas of a dynamic
+// fee transaction. Without the requested max fee, the max fee covers the
+// priority fee on top of a multiple of the base fee, up to the configured
+// limit. The priority fee never exceeds the max fee.
+func (t *transactionService) dynamicFees(ctx context.Context, maxFee, baseFee *big.Int) (gasFeeCap, gasTipCap *big.Int, err error) {
+	gasTipCap = t.fees.PriorityFee
+	if gasTipCap == nil {
+		gasTipCap, err = t.backend.SuggestGasTipCap(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	gasFeeCap = maxFee
+	if gasFeeCap == nil {
+		gasFeeCap = new(big.Int).Mul(baseFee, big.NewInt(baseFeeMultiplier))
+		gasFeeCap.Add(gasFeeCap, gasTipCap)
+		if t.fees.MaxFee != nil && gasFeeCap.Cmp(t.fees.MaxFee) > 0 {
+			gasFeeCap = t.fees.MaxFee
+		}
+	}
+	if gasFeeCap.Cmp(minGasPrice) < 0 {
+		return nil, nil, ErrGasPriceTooLow
+	}
+
+	if gasTipCap.Cmp(gasFeeCap) > 0 {
+		gasTipCap = gasFeeCap
+	}
+
+	return gasFeeCap, gasTipCap, nil
+}
+
+// bumpFee returns the fee increased by the given percentage, but at least
+// enough for a replacement transaction to be accepted.
+func bumpFee(fee *big.Int, percent uint64) *big.Int {
+	if percent < replacementFeeBump {
+		percent = replacementFeeBump
+	}
+	bumped := new(big.Int).Mul(fee, new(big.Int).SetUint64(100+percent))
+	bumped.Div(bumped, big.NewInt(100))
+	return bumped.Add(bumped, big.NewInt(1))
+}
+
+// newStoredTransaction returns the stored
+// information of the signed transaction.
+func newStoredTransaction(signedTx *types.Transaction, description string) StoredTransaction {
+	stored := StoredTransaction{
+		To:          signedTx.To(),
+		Data:        signedTx.Data(),
+		GasPrice:    signedTx.GasPrice(),
+		GasLimit:    signedTx.Gas(),
+		Value:       signedTx.Value(),
+		Nonce:       signedTx.Nonce(),
+		Created:     time.Now().Unix(),
+		Description: description,
+	}
+	if signedTx.Type() == types.DynamicFeeTxType {
+		stored.GasFeeCap = signedTx.GasFeeCap()
+		stored.GasTipCap = signedTx.GasTipCap()
+	}
+	return stored
+}
+
+// Supersedes reports whether the transaction tx replaces the transaction
+// other with the same nonce, being either sent later or, if both were sent
+// within the same second, paying the higher fees.
+func Supersedes(tx, other *StoredTransaction) bool {
+	if tx.Created != other.Created {
+		return tx.Created > other.Created
+	}
+	return tx.GasPrice != nil && other.GasPrice != nil && tx.GasPrice.Cmp(other.GasPrice) > 0
+}
+
+// sameCall reports whether the replacement performs the same call as the
+// original transaction, unlike a cancellation.
+func sameCall(original, replacement *StoredTransaction) bool {
+	if (original.To == nil) != (replacement.To == nil) || (original.To != nil && *original.To != *replacement.To) {
+		return false
+	}
+	if (original.Value == nil) != (replacement.Value == nil) || (original.Value != nil && original.Value.Cmp(replacement.Value) != 0) {
+		return false
+	}
+	return bytes.Equal(original.Data, replacement.Data)
+}
+
 func (t *transactionService) nonceKey() string {
 	return fmt.Sprintf("%s%x", noncePrefix, t.sender)
 }
@@ -335,19 +468,34 @@ func (t *transactionService) putNonce(nonce uint64) error {
 
 // WaitForReceipt waits until either the transaction with the given hash has
 // been mined or the context is cancelled.
+// If the transaction was replaced, the receipt of the replacement is returned.
 func (t *transactionService) WaitForReceipt(ctx context.Context, txHash common.Hash) (receipt *types.Receipt, err error) {
-	receiptC, errC, err := t.WatchSentTransaction(txHash)
-	if err != nil {
-		return nil, err
-	}
-	select {
-	case receipt := <-receiptC:
-		return &receipt, nil
-	case err := <-errC:
-		return nil, err
-	// don't wait longer than the context that was passed in
-	case <-ctx.Done():
-		return nil, ctx.Err()
+	for {
+		receiptC, errC, err := t.WatchSentTransaction(txHash)
+		if err != nil {
+			return nil, err
+		}
+		select {
+		case receipt := <-receiptC:
+			return &receipt, nil
+		case err := <-errC:
+			if errors.Is(err, ErrTransactionCancelled) {
+				// the transaction lost its nonce to its replacement,
+				// which is followed unless it is a cancellation
+				storedTransaction, serr := t.StoredTransaction(txHash)
+				if serr == nil && storedTransaction.ReplacedBy != nil {
+					replacement, rerr := t.StoredTransaction(*storedTransaction.ReplacedBy)
+					if rerr == nil && sameCall(storedTransaction, replacement) {
+						txHash = *storedTransaction.ReplacedBy
+						continue
+					}
+				}
+			}
+			return nil, err
+		// don't wait longer than the context that was passed in
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
 	}
 }
 
@@ -384,14 +532,28 @@ func (t *transactionService) ResendTransaction(ctx context.Context, txHash commo
 		return err
 	}
 
-	tx := types.NewTx(&types.LegacyTx{
-		Nonce:    storedTransaction.Nonce,
-		To:       storedTransaction.To,
-		Value:    storedTransaction.Value,
-		Gas:      storedTransaction.GasLimit,
-		GasPrice: storedTransaction.GasPrice,
-		Data:     storedTransaction.Data,
-	})
+	var tx *types.Transaction
+	if storedTransaction.GasTipCap != nil {
+		tx = types.NewTx(&types.DynamicFeeTx{
+			ChainID:   t.chainID,
+			Nonce:     storedTransaction.Nonce,
+			GasTipCap: storedTransaction.GasTipCap,
+			GasFeeCap: storedTransaction.GasFeeCap,
+			Gas:       storedTransaction.GasLimit,
+			To:        storedTransaction.To,
+			Value:     storedTransaction.Value,
+			Data:      storedTransaction.Data,
+		})
+	} else {
+		tx = types.NewTx(&types.LegacyTx{
+			Nonce:    storedTransaction.Nonce,
+			To:       storedTransaction.To,
+			Value:    storedTransaction.Value,
+			Gas:      storedTransaction.GasLimit,
+			GasPrice: storedTransaction.GasPrice,
+			Data:     storedTransaction.Data,
+		})
+	}
 
 	signedTx, err := t.signer.SignTx(tx, t.chainID)
 	if err != nil {
@@ -412,26 +574,53 @@ func (t *transactionService) ResendTransaction(ctx context.Context, txHash commo
 }
 
 func (t *transactionService) CancelTransaction(ctx context.Context, originalTxHash common.Hash) (common.Hash, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
 	storedTransaction, err := t.StoredTransaction(originalTxHash)
 	if err != nil {
 		return common.Hash{}, err
 	}
 
-	gasPrice := sctx.GetGasPrice(ctx)
-	if gasPrice == nil {
-		gasPrice = new(big.Int).Add(storedTransaction.GasPrice, big.NewInt(1))
-	} else if gasPrice.Cmp(storedTransaction.GasPrice) <= 0 {
-		return common.Hash{}, ErrGasPriceTooLow
+	var tx *types.Transaction
+	if storedTransaction.GasTipCap != nil {
+		// both fees of a dynamic fee transaction need the bump for the replacement
+		gasTipCap := bumpFee(storedTransaction.GasTipCap, replacementFeeBump)
+		gasFeeCap := bumpFee(storedTransaction.GasFeeCap, replacementFeeBump)
+		if gasPrice := sctx.GetGasPrice(ctx); gasPrice != nil {
+			if gasPrice.Cmp(gasFeeCap) < 0 {
+				return common.Hash{}, ErrGasPriceTooLow
+			}
+			gasFeeCap = gasPrice
+		}
+		tx = types.NewTx(&types.DynamicFeeTx{
+			ChainID:   t.chainID,
+			Nonce:     storedTransaction.Nonce,
+			GasTipCap: gasTipCap,
+			GasFeeCap: gasFeeCap,
+			Gas:       21000,
+			To:        &t.sender,
+			Value:     big.NewInt(0),
+			Data:      []byte{},
+		})
+	} else {
+		gasPrice := sctx.GetGasPrice(ctx)
+		if gasPrice == nil {
+			gasPrice = new(big.Int).Add(storedTransaction.GasPrice, big.NewInt(1))
+		} else if gasPrice.Cmp(storedTransaction.GasPrice) <= 0 {
+			return common.Hash{}, ErrGasPriceTooLow
+		}
+		tx = types.NewTx(&types.LegacyTx{
+			Nonce:    storedTransaction.Nonce,
+			To:       &t.sender,
+			Value:    big.NewInt(0),
+			Gas:      21000,
+			GasPrice: gasPrice,
+			Data:     []byte{},
+		})
 	}
 
-	signedTx, err := t.signer.SignTx(types.NewTx(&types.LegacyTx{
-		Nonce:    storedTransaction.Nonce,
-		To:       &t.sender,
-		Value:    big.NewInt(0),
-		Gas:      21000,
-		GasPrice: gasPrice,
-		Data:     []byte{},
-	}), t.chainID)
+	signedTx, err := t.signer.SignTx(tx, t.chainID)
 	if err != nil {
 		return common.Hash{}, err
 	}
@@ -442,16 +631,13 @@ func (t *transactionService) CancelTransaction(ctx context.Context, originalTxHa
 	}
 
 	txHash := signedTx.Hash()
-	err = t.store.Put(storedTransactionKey(txHash), StoredTransaction{
-		To:          signedTx.To(),
-		Data:        signedTx.Data(),
-		GasPrice:    signedTx.GasPrice(),
-		GasLimit:    signedTx.Gas(),
-		Value:       signedTx.Value(),
-		Nonce:       signedTx.Nonce(),
-		Created:     time.Now().Unix(),
-		Description: fmt.Sprintf("%s (cancellation)", storedTransaction.Description),
-	})
+	err = t.store.Put(storedTransactionKey(txHash), newStoredTransaction(signedTx, fmt.Sprintf("%s (cancellation)", storedTransaction.Description)))
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	storedTransaction.ReplacedBy = &txHash
+	err = t.store.Put(storedTransactionKey(originalTxHash), storedTransaction)
 	if err != nil {
 		return common.Hash{}, err
 	}
@@ -466,6 +652,171 @@ func (t *transactionService) CancelTransaction(ctx context.Context, originalTxHa
 	return txHash, err
 }
 
+func (t *transactionService) ReplaceTransaction(ctx context.Context, txHash common.Hash, feeBump uint64, maxFee *big.Int) (common.Hash, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	storedTransaction, err := t.StoredTransaction(txHash)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if storedTransaction.ReplacedBy != nil {
+		return common.Hash{}, ErrAlreadyReplaced
+	}
+
+	var tx *types.Transaction
+	if storedTransaction.GasTipCap != nil {
+		gasFeeCap := bumpFee(storedTransaction.GasFeeCap, feeBump)
+		if maxFee != nil && gasFeeCap.Cmp(maxFee) > 0 {
+			return common.Hash{}, ErrMaxFeeReached
+		}
+		tx = types.NewTx(&types.DynamicFeeTx{
+			ChainID:   t.chainID,
+			Nonce:     storedTransaction.Nonce,
+			GasTipCap: bumpFee(storedTransaction.GasTipCap, feeBump),
+			GasFeeCap: gasFeeCap,
+			Gas:       storedTransaction.GasLimit,
+			To:        storedTransaction.To,
+			Value:     storedTransaction.Value,
+			Data:      storedTransaction.Data,
+		})
+	} else {
+		gasPrice := bumpFee(storedTransaction.GasPrice, feeBump)
+		if maxFee != nil && gasPrice.Cmp(maxFee) > 0 {
+			return common.Hash{}, ErrMaxFeeReached
+		}
+		tx = types.NewTx(&types.LegacyTx{
+			Nonce:    storedTransaction.Nonce,
+			To:       storedTransaction.To,
+			Value:    storedTransaction.Value,
+			Gas:      storedTransaction.GasLimit,
+			GasPrice: gasPrice,
+			Data:     storedTransaction.Data,
+		})
+	}
+
+	signedTx, err := t.signer.SignTx(tx, t.chainID)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	err = t.backend.SendTransaction(ctx, signedTx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	replacementHash := signedTx.Hash()
+	replacement := newStoredTransaction(signedTx, storedTransaction.Description)
+	err = t.store.Put(storedTransactionKey(replacementHash), replacement)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	storedTransaction.ReplacedBy = &replacementHash
+	err = t.store.Put(storedTransactionKey(txHash), storedTransaction)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	err = t.store.Put(pendingTransactionKey(replacementHash), struct{}{})
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	t.waitForPendingTx(replacementHash)
+
+	return replacementHash, nil
+}
+
+func (t *transactionService) FillNonceGap(ctx context.Context) (common.Hash, bool, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	var nonce uint64
+	err := t.store.Get(t.nonceKey(), &nonce)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return common.Hash{}, false, nil
+		}
+		return common.Hash{}, false, err
+	}
+
+	// the transactions with the nonces following the
+	// pending nonce can not be included before it is used
+	pendingNonce, err := t.backend.PendingNonceAt(ctx, t.sender)
+	if err != nil {
+		return common.Hash{}, false, err
+	}
+	if pendingNonce >= nonce {
+		return common.Hash{}, false, nil
+	}
+
+	pendingTxs, err := t.PendingTransactions()
+	if err != nil {
+		return common.Hash{}, false, err
+	}
+	var (
+		missingTxHash common.Hash
+		missingTx     *StoredTransaction
+	)
+	for _, txHash := range pendingTxs {
+		storedTransaction, err := t.StoredTransaction(txHash)
+		if err != nil {
+			return common.Hash{}, false, err
+		}
+		if storedTransaction.Nonce != pendingNonce || storedTransaction.ReplacedBy != nil {
+			continue
+		}
+		// only the latest of the transactions sharing the nonce can be included
+		if missingTx == nil || Supersedes(storedTransaction, missingTx) {
+			missingTxHash, missingTx = txHash, storedTransaction
+		}
+	}
+	if missingTx != nil {
+		// the backend lost the transaction with the missing nonce
+		if err := t.ResendTransaction(ctx, missingTxHash); err != nil && !errors.Is(err, ErrAlreadyImported) {
+			return common.Hash{}, false, err
+		}
+		return missingTxHash, true, nil
+	}
+
+	// no transaction of this service uses the missing nonce, so it is cancelled
+	tx, err := t.prepareTransaction(ctx, &TxRequest{
+		To:       &t.sender,
+		Value:    big.NewInt(0),
+		GasLimit: 21000,
+		Data:     []byte{},
+	}, pendingNonce)
+	if err != nil {
+		return common.Hash{}, false, err
+	}
+
+	signedTx, err := t.signer.SignTx(tx, t.chainID)
+	if err != nil {
+		return common.Hash{}, false, err
+	}
+
+	err = t.backend.SendTransaction(ctx, signedTx)
+	if err != nil {
+		return common.Hash{}, false, err
+	}
+
+	txHash := signedTx.Hash()
+	err = t.store.Put(storedTransactionKey(txHash), newStoredTransaction(signedTx, "nonce gap filling"))
+	if err != nil {
+		return common.Hash{}, false, err
+	}
+
+	err = t.store.Put(pendingTransactionKey(txHash), struct{}{})
+	if err != nil {
+		return common.Hash{}, false, err
+	}
+
+	t.waitForPendingTx(txHash)
+
+	return txHash, true, nil
+}
+
 func (t *transactionService) Close() error {
 	t.cancel()
 	t.wg.Wait()
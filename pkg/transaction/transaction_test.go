@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"math/big"
 	"testing"
+	"time"
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
@@ -20,6 +21,7 @@ import (
 	"github.com/ethersphere/bee/pkg/log"
 	"github.com/ethersphere/bee/pkg/sctx"
 	storemock "github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/storage"
 	"github.com/ethersphere/bee/pkg/transaction"
 	"github.com/ethersphere/bee/pkg/transaction/backendmock"
 	"github.com/ethersphere/bee/pkg/transaction/monitormock"
@@ -32,8 +34,8 @@ func nonceKey(sender common.Address) string {
 func signerMockForTransaction(signedTx *types.Transaction, sender common.Address, signerChainID *big.Int, t *testing.T) crypto.Signer {
 	return signermock.New(
 		signermock.WithSignTxFunc(func(transaction *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
-			if transaction.Type() != 0 {
-				t.Fatalf("wrong transaction type. wanted 0, got %d", transaction.Type())
+			if transaction.Type() != signedTx.Type() {
+				t.Fatalf("wrong transaction type. wanted %d, got %d", signedTx.Type(), transaction.Type())
 			}
 			if signedTx.To() == nil {
 				if transaction.To() != nil {
@@ -59,6 +61,9 @@ func signerMockForTransaction(signedTx *types.Transaction, sender common.Address
 			if transaction.GasPrice().Cmp(signedTx.GasPrice()) != 0 {
 				t.Fatalf("signing transaction with wrong gasprice. wanted %d, got %d", signedTx.GasPrice(), transaction.GasPrice())
 			}
+			if transaction.GasTipCap().Cmp(signedTx.GasTipCap()) != 0 {
+				t.Fatalf("signing transaction with wrong gas tip cap. wanted %d, got %d", signedTx.GasTipCap(), transaction.GasTipCap())
+			}
 
 			if transaction.Nonce() != signedTx.Nonce() {
 				t.Fatalf("signing transaction with wrong nonce. wanted %d, got %d", signedTx.Nonce(), transaction.Nonce())
@@ -124,6 +129,9 @@ func TestTransactionSend(t *testing.T) {
 				backendmock.WithSuggestGasPriceFunc(func(ctx context.Context) (*big.Int, error) {
 					return suggestedGasPrice, nil
 				}),
+				backendmock.WithHeaderbyNumberFunc(func(ctx context.Context, number *big.Int) (*types.Header, error) {
+					return &types.Header{}, nil
+				}),
 				backendmock.WithPendingNonceAtFunc(func(ctx context.Context, account common.Address) (uint64, error) {
 					return nonce - 1, nil
 				}),
@@ -136,6 +144,7 @@ func TestTransactionSend(t *testing.T) {
 					return nil, nil, nil
 				}),
 			),
+			transaction.FeeOptions{},
 		)
 		if err != nil {
 			t.Fatal(err)
@@ -238,6 +247,9 @@ func TestTransactionSend(t *testing.T) {
 				backendmock.WithSuggestGasPriceFunc(func(ctx context.Context) (*big.Int, error) {
 					return suggestedGasPrice, nil
 				}),
+				backendmock.WithHeaderbyNumberFunc(func(ctx context.Context, number *big.Int) (*types.Header, error) {
+					return &types.Header{}, nil
+				}),
 				backendmock.WithPendingNonceAtFunc(func(ctx context.Context, account common.Address) (uint64, error) {
 					return nonce, nil
 				}),
@@ -246,6 +258,7 @@ func TestTransactionSend(t *testing.T) {
 			store,
 			chainID,
 			monitormock.New(),
+			transaction.FeeOptions{},
 		)
 		if err != nil {
 			t.Fatal(err)
@@ -312,6 +325,9 @@ func TestTransactionSend(t *testing.T) {
 				backendmock.WithSuggestGasPriceFunc(func(ctx context.Context) (*big.Int, error) {
 					return suggestedGasPrice, nil
 				}),
+				backendmock.WithHeaderbyNumberFunc(func(ctx context.Context, number *big.Int) (*types.Header, error) {
+					return &types.Header{}, nil
+				}),
 				backendmock.WithPendingNonceAtFunc(func(ctx context.Context, account common.Address) (uint64, error) {
 					return nextNonce, nil
 				}),
@@ -320,6 +336,7 @@ func TestTransactionSend(t *testing.T) {
 			store,
 			chainID,
 			monitormock.New(),
+			transaction.FeeOptions{},
 		)
 		if err != nil {
 			t.Fatal(err)
@@ -387,6 +404,7 @@ func TestTransactionWaitForReceipt(t *testing.T) {
 				return receiptC, nil, nil
 			}),
 		),
+		transaction.FeeOptions{},
 	)
 	if err != nil {
 		t.Fatal(err)
@@ -450,6 +468,7 @@ func TestTransactionResend(t *testing.T) {
 		store,
 		chainID,
 		monitormock.New(),
+		transaction.FeeOptions{},
 	)
 	if err != nil {
 		t.Fatal(err)
@@ -518,6 +537,7 @@ func TestTransactionCancel(t *testing.T) {
 			store,
 			chainID,
 			monitormock.New(),
+			transaction.FeeOptions{},
 		)
 		if err != nil {
 			t.Fatal(err)
@@ -532,6 +552,14 @@ func TestTransactionCancel(t *testing.T) {
 		if cancelTx.Hash() != cancelTxHash {
 			t.Fatalf("returned wrong hash. wanted %v, got %v", cancelTx.Hash(), cancelTxHash)
 		}
+
+		var storedTransaction transaction.StoredTransaction
+		if err := store.Get(transaction.StoredTransactionKey(signedTx.Hash()), &storedTransaction); err != nil {
+			t.Fatal(err)
+		}
+		if storedTransaction.ReplacedBy == nil || *storedTransaction.ReplacedBy != cancelTxHash {
+			t.Fatalf("original transaction replaced by %v, want %v", storedTransaction.ReplacedBy, cancelTxHash)
+		}
 	})
 
 	t.Run("custom gas price", func(t *testing.T) {
@@ -558,6 +586,7 @@ func TestTransactionCancel(t *testing.T) {
 			store,
 			chainID,
 			monitormock.New(),
+			transaction.FeeOptions{},
 		)
 		if err != nil {
 			t.Fatal(err)
@@ -599,6 +628,7 @@ func TestTransactionCancel(t *testing.T) {
 			store,
 			chainID,
 			monitormock.New(),
+			transaction.FeeOptions{},
 		)
 		if err != nil {
 			t.Fatal(err)
@@ -612,3 +642,795 @@ func TestTransactionCancel(t *testing.T) {
 		}
 	})
 }
+
+func TestTransactionSendDynamicFee(t *testing.T) {
+	logger := log.Noop
+	sender := common.HexToAddress("0xddff")
+	recipient := common.HexToAddress("0xabcd")
+	txData := common.Hex2Bytes("0xabcdee")
+	value := big.NewInt(1)
+	baseFee := big.NewInt(500)
+	suggestedGasTipCap := big.NewInt(1000)
+	estimatedGasLimit := uint64(3)
+	nonce := uint64(2)
+	chainID := big.NewInt(5)
+
+	for _, tc := range []struct {
+		name              string
+		fees              transaction.FeeOptions
+		requestGasPrice   *big.Int
+		expectedGasFeeCap *big.Int
+		expectedGasTipCap *big.Int
+	}{
+		{
+			name:              "suggested",
+			expectedGasFeeCap: big.NewInt(2000),
+			expectedGasTipCap: suggestedGasTipCap,
+		},
+		{
+			name:              "priority fee",
+			fees:              transaction.FeeOptions{PriorityFee: big.NewInt(1500)},
+			expectedGasFeeCap: big.NewInt(2500),
+			expectedGasTipCap: big.NewInt(1500),
+		},
+		{
+			name:              "max fee",
+			fees:              transaction.FeeOptions{MaxFee: big.NewInt(1200)},
+			expectedGasFeeCap: big.NewInt(1200),
+			expectedGasTipCap: suggestedGasTipCap,
+		},
+		{
+			name:              "priority fee above max fee",
+			fees:              transaction.FeeOptions{MaxFee: big.NewInt(1200), PriorityFee: big.NewInt(1500)},
+			expectedGasFeeCap: big.NewInt(1200),
+			expectedGasTipCap: big.NewInt(1200),
+		},
+		{
+			name:              "requested gas price",
+			requestGasPrice:   big.NewInt(5000),
+			expectedGasFeeCap: big.NewInt(5000),
+			expectedGasTipCap: suggestedGasTipCap,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			signedTx := types.NewTx(&types.DynamicFeeTx{
+				ChainID:   chainID,
+				Nonce:     nonce,
+				GasTipCap: tc.expectedGasTipCap,
+				GasFeeCap: tc.expectedGasFeeCap,
+				Gas:       estimatedGasLimit,
+				To:        &recipient,
+				Value:     value,
+				Data:      txData,
+			})
+			request := &transaction.TxRequest{
+				To:       &recipient,
+				Data:     txData,
+				GasPrice: tc.requestGasPrice,
+				Value:    value,
+			}
+			store := storemock.NewStateStore()
+
+			transactionService, err := transaction.NewService(logger,
+				backendmock.New(
+					backendmock.WithSendTransactionFunc(func(ctx context.Context, tx *types.Transaction) error {
+						if tx != signedTx {
+							t.Fatal("not sending signed transaction")
+						}
+						return nil
+					}),
+					backendmock.WithEstimateGasFunc(func(ctx context.Context, call ethereum.CallMsg) (gas uint64, err error) {
+						return estimatedGasLimit, nil
+					}),
+					backendmock.WithHeaderbyNumberFunc(func(ctx context.Context, number *big.Int) (*types.Header, error) {
+						return &types.Header{BaseFee: baseFee}, nil
+					}),
+					backendmock.WithSuggestGasTipCapFunc(func(ctx context.Context) (*big.Int, error) {
+						return suggestedGasTipCap, nil
+					}),
+					backendmock.WithPendingNonceAtFunc(func(ctx context.Context, account common.Address) (uint64, error) {
+						return nonce, nil
+					}),
+				),
+				signerMockForTransaction(signedTx, sender, chainID, t),
+				store,
+				chainID,
+				monitormock.New(),
+				tc.fees,
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer transactionService.Close()
+
+			txHash, err := transactionService.Send(context.Background(), request)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			storedTransaction, err := transactionService.StoredTransaction(txHash)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if storedTransaction.GasFeeCap.Cmp(tc.expectedGasFeeCap) != 0 {
+				t.Fatalf("got wrong gas fee cap in stored transaction. wanted %d, got %d", tc.expectedGasFeeCap, storedTransaction.GasFeeCap)
+			}
+			if storedTransaction.GasTipCap.Cmp(tc.expectedGasTipCap) != 0 {
+				t.Fatalf("got wrong gas tip cap in stored transaction. wanted %d, got %d", tc.expectedGasTipCap, storedTransaction.GasTipCap)
+			}
+			if storedTransaction.GasPrice.Cmp(tc.expectedGasFeeCap) != 0 {
+				t.Fatalf("got wrong gas price in stored transaction. wanted %d, got %d", tc.expectedGasFeeCap, storedTransaction.GasPrice)
+			}
+		})
+	}
+}
+
+func TestTransactionResendDynamicFee(t *testing.T) {
+	logger := log.Noop
+	recipient := common.HexToAddress("0xbbbddd")
+	chainID := big.NewInt(5)
+	nonce := uint64(10)
+	data := []byte{1, 2, 3, 4}
+	gasFeeCap := big.NewInt(2000)
+	gasTipCap := big.NewInt(1000)
+	gasLimit := uint64(100000)
+	value := big.NewInt(0)
+
+	store := storemock.NewStateStore()
+	defer store.Close()
+
+	signedTx := types.NewTx(&types.DynamicFeeTx{
+		ChainID:   chainID,
+		Nonce:     nonce,
+		GasTipCap: gasTipCap,
+		GasFeeCap: gasFeeCap,
+		Gas:       gasLimit,
+		To:        &recipient,
+		Value:     value,
+		Data:      data,
+	})
+
+	err := store.Put(transaction.StoredTransactionKey(signedTx.Hash()), transaction.StoredTransaction{
+		Nonce:     nonce,
+		To:        &recipient,
+		Data:      data,
+		GasPrice:  gasFeeCap,
+		GasFeeCap: gasFeeCap,
+		GasTipCap: gasTipCap,
+		GasLimit:  gasLimit,
+		Value:     value,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	transactionService, err := transaction.NewService(logger,
+		backendmock.New(
+			backendmock.WithSendTransactionFunc(func(ctx context.Context, tx *types.Transaction) error {
+				if tx != signedTx {
+					t.Fatal("not sending signed transaction")
+				}
+				return nil
+			}),
+		),
+		signerMockForTransaction(signedTx, recipient, chainID, t),
+		store,
+		chainID,
+		monitormock.New(),
+		transaction.FeeOptions{},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer transactionService.Close()
+
+	err = transactionService.ResendTransaction(context.Background(), signedTx.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTransactionCancelDynamicFee(t *testing.T) {
+	logger := log.Noop
+	recipient := common.HexToAddress("0xbbbddd")
+	chainID := big.NewInt(5)
+	nonce := uint64(10)
+	data := []byte{1, 2, 3, 4}
+	gasFeeCap := big.NewInt(2000)
+	gasTipCap := big.NewInt(1000)
+	gasLimit := uint64(100000)
+	value := big.NewInt(0)
+
+	store := storemock.NewStateStore()
+	defer store.Close()
+
+	signedTx := types.NewTx(&types.DynamicFeeTx{
+		ChainID:   chainID,
+		Nonce:     nonce,
+		GasTipCap: gasTipCap,
+		GasFeeCap: gasFeeCap,
+		Gas:       gasLimit,
+		To:        &recipient,
+		Value:     value,
+		Data:      data,
+	})
+	err := store.Put(transaction.StoredTransactionKey(signedTx.Hash()), transaction.StoredTransaction{
+		Nonce:     nonce,
+		To:        &recipient,
+		Data:      data,
+		GasPrice:  gasFeeCap,
+		GasFeeCap: gasFeeCap,
+		GasTipCap: gasTipCap,
+		GasLimit:  gasLimit,
+		Value:     value,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newService := func(t *testing.T, cancelTx *types.Transaction) transaction.Service {
+		t.Helper()
+
+		transactionService, err := transaction.NewService(logger,
+			backendmock.New(
+				backendmock.WithSendTransactionFunc(func(ctx context.Context, tx *types.Transaction) error {
+					if tx != cancelTx {
+						t.Fatal("not sending signed transaction")
+					}
+					return nil
+				}),
+			),
+			signerMockForTransaction(cancelTx, recipient, chainID, t),
+			store,
+			chainID,
+			monitormock.New(),
+			transaction.FeeOptions{},
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { transactionService.Close() })
+		return transactionService
+	}
+
+	t.Run("ok", func(t *testing.T) {
+		// both fees are bumped by 10% for the replacement
+		cancelTx := types.NewTx(&types.DynamicFeeTx{
+			ChainID:   chainID,
+			Nonce:     nonce,
+			GasTipCap: big.NewInt(1101),
+			GasFeeCap: big.NewInt(2201),
+			Gas:       21000,
+			To:        &recipient,
+			Value:     big.NewInt(0),
+			Data:      []byte{},
+		})
+
+		cancelTxHash, err := newService(t, cancelTx).CancelTransaction(context.Background(), signedTx.Hash())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if cancelTx.Hash() != cancelTxHash {
+			t.Fatalf("returned wrong hash. wanted %v, got %v", cancelTx.Hash(), cancelTxHash)
+		}
+	})
+
+	t.Run("custom gas price", func(t *testing.T) {
+		customGasPrice := big.NewInt(5000)
+		cancelTx := types.NewTx(&types.DynamicFeeTx{
+			ChainID:   chainID,
+			Nonce:     nonce,
+			GasTipCap: big.NewInt(1101),
+			GasFeeCap: customGasPrice,
+			Gas:       21000,
+			To:        &recipient,
+			Value:     big.NewInt(0),
+			Data:      []byte{},
+		})
+
+		ctx := sctx.SetGasPrice(context.Background(), customGasPrice)
+		cancelTxHash, err := newService(t, cancelTx).CancelTransaction(ctx, signedTx.Hash())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if cancelTx.Hash() != cancelTxHash {
+			t.Fatalf("returned wrong hash. wanted %v, got %v", cancelTx.Hash(), cancelTxHash)
+		}
+	})
+
+	t.Run("too low gas price", func(t *testing.T) {
+		ctx := sctx.SetGasPrice(context.Background(), big.NewInt(2100))
+		_, err := newService(t, signedTx).CancelTransaction(ctx, signedTx.Hash())
+		if !errors.Is(err, transaction.ErrGasPriceTooLow) {
+			t.Fatalf("returned wrong error. wanted %v, got %v", transaction.ErrGasPriceTooLow, err)
+		}
+	})
+}
+
+func TestTransactionReplace(t *testing.T) {
+	logger := log.Noop
+	recipient := common.HexToAddress("0xbbbddd")
+	chainID := big.NewInt(5)
+	nonce := uint64(10)
+	data := []byte{1, 2, 3, 4}
+	gasPrice := big.NewInt(1000)
+	gasLimit := uint64(100000)
+	value := big.NewInt(0)
+
+	signedTx := types.NewTx(&types.LegacyTx{
+		Nonce:    nonce,
+		To:       &recipient,
+		Value:    value,
+		Gas:      gasLimit,
+		GasPrice: gasPrice,
+		Data:     data,
+	})
+	newStore := func(t *testing.T) storage.StateStorer {
+		t.Helper()
+
+		store := storemock.NewStateStore()
+		t.Cleanup(func() { store.Close() })
+		err := store.Put(transaction.StoredTransactionKey(signedTx.Hash()), transaction.StoredTransaction{
+			Nonce:       nonce,
+			To:          &recipient,
+			Data:        data,
+			GasPrice:    gasPrice,
+			GasLimit:    gasLimit,
+			Value:       value,
+			Description: "test",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return store
+	}
+
+	t.Run("ok", func(t *testing.T) {
+		store := newStore(t)
+		replacementTx := types.NewTx(&types.LegacyTx{
+			Nonce:    nonce,
+			To:       &recipient,
+			Value:    value,
+			Gas:      gasLimit,
+			GasPrice: big.NewInt(1201),
+			Data:     data,
+		})
+
+		transactionService, err := transaction.NewService(logger,
+			backendmock.New(
+				backendmock.WithSendTransactionFunc(func(ctx context.Context, tx *types.Transaction) error {
+					if tx != replacementTx {
+						t.Fatal("not sending signed transaction")
+					}
+					return nil
+				}),
+			),
+			signerMockForTransaction(replacementTx, recipient, chainID, t),
+			store,
+			chainID,
+			monitormock.New(),
+			transaction.FeeOptions{},
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer transactionService.Close()
+
+		replacementTxHash, err := transactionService.ReplaceTransaction(context.Background(), signedTx.Hash(), 20, big.NewInt(2000))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if replacementTxHash != replacementTx.Hash() {
+			t.Fatalf("returned wrong hash. wanted %v, got %v", replacementTx.Hash(), replacementTxHash)
+		}
+
+		storedTransaction, err := transactionService.StoredTransaction(signedTx.Hash())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if storedTransaction.ReplacedBy == nil || *storedTransaction.ReplacedBy != replacementTxHash {
+			t.Fatalf("got replaced by %v, want %v", storedTransaction.ReplacedBy, replacementTxHash)
+		}
+
+		replacement, err := transactionService.StoredTransaction(replacementTxHash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if replacement.Description != "test" {
+			t.Fatalf("got description %q, want %q", replacement.Description, "test")
+		}
+
+		pending, err := transactionService.PendingTransactions()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(pending) != 1 || pending[0] != replacementTxHash {
+			t.Fatalf("got pending transactions %v, want %v", pending, replacementTxHash)
+		}
+
+		_, err = transactionService.ReplaceTransaction(context.Background(), signedTx.Hash(), 20, nil)
+		if !errors.Is(err, transaction.ErrAlreadyReplaced) {
+			t.Fatalf("returned wrong error. wanted %v, got %v", transaction.ErrAlreadyReplaced, err)
+		}
+	})
+
+	t.Run("max fee reached", func(t *testing.T) {
+		transactionService, err := transaction.NewService(logger,
+			backendmock.New(),
+			signerMockForTransaction(signedTx, recipient, chainID, t),
+			newStore(t),
+			chainID,
+			monitormock.New(),
+			transaction.FeeOptions{},
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer transactionService.Close()
+
+		_, err = transactionService.ReplaceTransaction(context.Background(), signedTx.Hash(), 20, big.NewInt(1200))
+		if !errors.Is(err, transaction.ErrMaxFeeReached) {
+			t.Fatalf("returned wrong error. wanted %v, got %v", transaction.ErrMaxFeeReached, err)
+		}
+	})
+}
+
+func TestTransactionWaitForReceiptReplaced(t *testing.T) {
+	logger := log.Noop
+	txHash := common.HexToHash("0xabcdee")
+	replacementTxHash := common.HexToHash("0xabcdef")
+	chainID := big.NewInt(5)
+	nonce := uint64(10)
+
+	store := storemock.NewStateStore()
+	defer store.Close()
+
+	err := store.Put(transaction.StoredTransactionKey(txHash), transaction.StoredTransaction{
+		Nonce:      nonce,
+		ReplacedBy: &replacementTxHash,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = store.Put(transaction.StoredTransactionKey(replacementTxHash), transaction.StoredTransaction{
+		Nonce: nonce,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	transactionService, err := transaction.NewService(logger,
+		backendmock.New(),
+		signermock.New(),
+		store,
+		chainID,
+		monitormock.New(
+			monitormock.WithWatchTransactionFunc(func(txh common.Hash, n uint64) (<-chan types.Receipt, <-chan error, error) {
+				receiptC := make(chan types.Receipt, 1)
+				errC := make(chan error, 1)
+				if txh == replacementTxHash {
+					receiptC <- types.Receipt{TxHash: replacementTxHash}
+				} else {
+					errC <- transaction.ErrTransactionCancelled
+				}
+				return receiptC, errC, nil
+			}),
+		),
+		transaction.FeeOptions{},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer transactionService.Close()
+
+	receipt, err := transactionService.WaitForReceipt(context.Background(), txHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if receipt.TxHash != replacementTxHash {
+		t.Fatalf("got receipt of %x, want %x", receipt.TxHash, replacementTxHash)
+	}
+}
+
+func TestTransactionWaitForReceiptCancelled(t *testing.T) {
+	logger := log.Noop
+	txHash := common.HexToHash("0xabcdee")
+	cancelTxHash := common.HexToHash("0xabcdef")
+	sender := common.HexToAddress("0xddff")
+	recipient := common.HexToAddress("0xbbbddd")
+	chainID := big.NewInt(5)
+	nonce := uint64(10)
+
+	store := storemock.NewStateStore()
+	defer store.Close()
+
+	err := store.Put(transaction.StoredTransactionKey(txHash), transaction.StoredTransaction{
+		To:         &recipient,
+		Data:       []byte{1, 2, 3},
+		Value:      big.NewInt(0),
+		Nonce:      nonce,
+		ReplacedBy: &cancelTxHash,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = store.Put(transaction.StoredTransactionKey(cancelTxHash), transaction.StoredTransaction{
+		To:    &sender,
+		Data:  []byte{},
+		Value: big.NewInt(0),
+		Nonce: nonce,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	transactionService, err := transaction.NewService(logger,
+		backendmock.New(),
+		signermock.New(),
+		store,
+		chainID,
+		monitormock.New(
+			monitormock.WithWatchTransactionFunc(func(txh common.Hash, n uint64) (<-chan types.Receipt, <-chan error, error) {
+				receiptC := make(chan types.Receipt, 1)
+				errC := make(chan error, 1)
+				if txh == cancelTxHash {
+					receiptC <- types.Receipt{TxHash: cancelTxHash}
+				} else {
+					errC <- transaction.ErrTransactionCancelled
+				}
+				return receiptC, errC, nil
+			}),
+		),
+		transaction.FeeOptions{},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer transactionService.Close()
+
+	_, err = transactionService.WaitForReceipt(context.Background(), txHash)
+	if !errors.Is(err, transaction.ErrTransactionCancelled) {
+		t.Fatalf("got error %v, want %v", err, transaction.ErrTransactionCancelled)
+	}
+}
+
+func TestTransactionFillNonceGap(t *testing.T) {
+	logger := log.Noop
+	sender := common.HexToAddress("0xddff")
+	recipient := common.HexToAddress("0xbbbddd")
+	chainID := big.NewInt(5)
+	gasPrice := big.NewInt(1000)
+
+	t.Run("no gap", func(t *testing.T) {
+		store := storemock.NewStateStore()
+		defer store.Close()
+		if err := store.Put(nonceKey(sender), uint64(5)); err != nil {
+			t.Fatal(err)
+		}
+
+		transactionService, err := transaction.NewService(logger,
+			backendmock.New(
+				backendmock.WithPendingNonceAtFunc(func(ctx context.Context, account common.Address) (uint64, error) {
+					return 5, nil
+				}),
+			),
+			signerMockForTransaction(nil, sender, chainID, t),
+			store,
+			chainID,
+			monitormock.New(),
+			transaction.FeeOptions{},
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer transactionService.Close()
+
+		_, filled, err := transactionService.FillNonceGap(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if filled {
+			t.Fatal("filled nonce gap without a gap")
+		}
+	})
+
+	t.Run("resend", func(t *testing.T) {
+		signedTx := types.NewTx(&types.LegacyTx{
+			Nonce:    3,
+			To:       &recipient,
+			Value:    big.NewInt(0),
+			Gas:      21000,
+			GasPrice: gasPrice,
+			Data:     []byte{1},
+		})
+
+		store := storemock.NewStateStore()
+		defer store.Close()
+		if err := store.Put(nonceKey(sender), uint64(5)); err != nil {
+			t.Fatal(err)
+		}
+		err := store.Put(transaction.StoredTransactionKey(signedTx.Hash()), transaction.StoredTransaction{
+			Nonce:    3,
+			To:       &recipient,
+			Data:     []byte{1},
+			GasPrice: gasPrice,
+			GasLimit: 21000,
+			Value:    big.NewInt(0),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := store.Put(transaction.PendingTransactionKey(signedTx.Hash()), struct{}{}); err != nil {
+			t.Fatal(err)
+		}
+
+		sent := false
+		transactionService, err := transaction.NewService(logger,
+			backendmock.New(
+				backendmock.WithPendingNonceAtFunc(func(ctx context.Context, account common.Address) (uint64, error) {
+					return 3, nil
+				}),
+				backendmock.WithSendTransactionFunc(func(ctx context.Context, tx *types.Transaction) error {
+					if tx != signedTx {
+						t.Fatal("not sending signed transaction")
+					}
+					sent = true
+					return nil
+				}),
+			),
+			signerMockForTransaction(signedTx, sender, chainID, t),
+			store,
+			chainID,
+			monitormock.New(),
+			transaction.FeeOptions{},
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer transactionService.Close()
+
+		txHash, filled, err := transactionService.FillNonceGap(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !filled || txHash != signedTx.Hash() || !sent {
+			t.Fatalf("nonce gap not filled by resending %x", signedTx.Hash())
+		}
+	})
+
+	t.Run("resend latest", func(t *testing.T) {
+		store := storemock.NewStateStore()
+		defer store.Close()
+		if err := store.Put(nonceKey(sender), uint64(5)); err != nil {
+			t.Fatal(err)
+		}
+
+		// the last two transactions are sent within the same second
+		var latestTx *types.Transaction
+		created := time.Now().Add(-time.Hour).Unix()
+		for i, tx := range []struct {
+			price   *big.Int
+			created int64
+		}{
+			{gasPrice, created},
+			{big.NewInt(1200), created + 1},
+			{big.NewInt(1100), created + 1},
+		} {
+			signedTx := types.NewTx(&types.LegacyTx{
+				Nonce:    3,
+				To:       &recipient,
+				Value:    big.NewInt(0),
+				Gas:      21000,
+				GasPrice: tx.price,
+				Data:     []byte{1},
+			})
+			err := store.Put(transaction.StoredTransactionKey(signedTx.Hash()), transaction.StoredTransaction{
+				Nonce:    3,
+				To:       &recipient,
+				Data:     []byte{1},
+				GasPrice: tx.price,
+				GasLimit: 21000,
+				Value:    big.NewInt(0),
+				Created:  tx.created,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := store.Put(transaction.PendingTransactionKey(signedTx.Hash()), struct{}{}); err != nil {
+				t.Fatal(err)
+			}
+			if i == 1 {
+				latestTx = signedTx
+			}
+		}
+
+		transactionService, err := transaction.NewService(logger,
+			backendmock.New(
+				backendmock.WithPendingNonceAtFunc(func(ctx context.Context, account common.Address) (uint64, error) {
+					return 3, nil
+				}),
+				backendmock.WithSendTransactionFunc(func(ctx context.Context, tx *types.Transaction) error {
+					if tx != latestTx {
+						t.Fatal("not sending the latest transaction")
+					}
+					return nil
+				}),
+			),
+			signerMockForTransaction(latestTx, sender, chainID, t),
+			store,
+			chainID,
+			monitormock.New(),
+			transaction.FeeOptions{},
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer transactionService.Close()
+
+		txHash, filled, err := transactionService.FillNonceGap(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !filled || txHash != latestTx.Hash() {
+			t.Fatalf("nonce gap not filled by resending %x", latestTx.Hash())
+		}
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		cancelTx := types.NewTx(&types.LegacyTx{
+			Nonce:    3,
+			To:       &sender,
+			Value:    big.NewInt(0),
+			Gas:      21000,
+			GasPrice: gasPrice,
+			Data:     []byte{},
+		})
+
+		store := storemock.NewStateStore()
+		defer store.Close()
+		if err := store.Put(nonceKey(sender), uint64(5)); err != nil {
+			t.Fatal(err)
+		}
+
+		transactionService, err := transaction.NewService(logger,
+			backendmock.New(
+				backendmock.WithPendingNonceAtFunc(func(ctx context.Context, account common.Address) (uint64, error) {
+					return 3, nil
+				}),
+				backendmock.WithHeaderbyNumberFunc(func(ctx context.Context, number *big.Int) (*types.Header, error) {
+					return &types.Header{}, nil
+				}),
+				backendmock.WithSuggestGasPriceFunc(func(ctx context.Context) (*big.Int, error) {
+					return gasPrice, nil
+				}),
+				backendmock.WithSendTransactionFunc(func(ctx context.Context, tx *types.Transaction) error {
+					if tx != cancelTx {
+						t.Fatal("not sending signed transaction")
+					}
+					return nil
+				}),
+			),
+			signerMockForTransaction(cancelTx, sender, chainID, t),
+			store,
+			chainID,
+			monitormock.New(),
+			transaction.FeeOptions{},
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer transactionService.Close()
+
+		txHash, filled, err := transactionService.FillNonceGap(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !filled || txHash != cancelTx.Hash() {
+			t.Fatalf("nonce gap not filled by cancelling nonce 3")
+		}
+	})
+}
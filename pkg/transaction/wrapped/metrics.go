@@ -23,6 +23,7 @@ type metrics struct {
 	PendingNonceCalls       prometheus.Counter
 	CallContractCalls       prometheus.Counter
 	SuggestGasPriceCalls    prometheus.Counter
+	SuggestGasTipCapCalls   prometheus.Counter
 	EstimateGasCalls        prometheus.Counter
 	SendTransactionCalls    prometheus.Counter
 	FilterLogsCalls         prometheus.Counter
@@ -105,6 +106,12 @@ func newMetrics() metrics {
 			Name:      "calls_suggest_gasprice",
 			Help:      "Count of eth_suggestGasPrice rpc calls",
 		}),
+		SuggestGasTipCapCalls: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "calls_suggest_gastipcap",
+			Help:      "Count of eth_maxPriorityFeePerGas rpc calls",
+		}),
 		EstimateGasCalls: prometheus.NewCounter(prometheus.CounterOpts{
 			Namespace: m.Namespace,
 			Subsystem: subsystem,
@@ -132,6 +139,12 @@ func newMetrics() metrics {
 	}
 }
 
+// Metrics returns the metrics of the backend, together with
+// the ones of the wrapped backend if it exposes any.
 func (b *wrappedBackend) Metrics() []prometheus.Collector {
-	return m.PrometheusCollectorsFromFields(b.metrics)
+	collectors := m.PrometheusCollectorsFromFields(b.metrics)
+	if c, ok := b.backend.(m.Collector); ok {
+		collectors = append(collectors, c.Metrics()...)
+	}
+	return collectors
 }
@@ -0,0 +1,13 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package upload
+
+import "time"
+
+var Expire = (*Service).expire
+
+func (s *Service) SetNow(now func() time.Time) {
+	s.now = now
+}
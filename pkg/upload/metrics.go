@@ -0,0 +1,45 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package upload
+
+import (
+	m "github.com/ethersphere/bee/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type metrics struct {
+	CreatedCounter  prometheus.Counter
+	FinishedCounter prometheus.Counter
+	ExpiredCounter  prometheus.Counter
+}
+
+func newMetrics() metrics {
+	subsystem := "upload"
+
+	return metrics{
+		CreatedCounter: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "sessions_created",
+			Help:      "Total number of resumable upload sessions created.",
+		}),
+		FinishedCounter: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "sessions_finished",
+			Help:      "Total number of resumable upload sessions finished.",
+		}),
+		ExpiredCounter: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "sessions_expired",
+			Help:      "Total number of resumable upload sessions removed after the expiry.",
+		}),
+	}
+}
+
+func (s *Service) Metrics() []prometheus.Collector {
+	return m.PrometheusCollectorsFromFields(s.metrics)
+}
@@ -0,0 +1,262 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package upload keeps the sessions of the resumable uploads. A session
+// is bound to the tag and the postage batch of the upload and records the
+// offset of the data committed so far, together with the state of the
+// pipeline splitting the data, so that the upload can be continued after
+// a dropped connection or a restart of the node. The sessions are kept
+// in the statestore until they are finished, deleted or expired.
+package upload
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/log"
+	"github.com/ethersphere/bee/pkg/storage"
+)
+
+// loggerName is the tree path name of the logger for this package.
+const loggerName = "upload"
+
+const sessionKeyPrefix = "upload_session_"
+
+var (
+	// ErrNotFound is returned when the session does not exist.
+	ErrNotFound = errors.New("upload session not found")
+	// ErrBusy is returned when the session is acquired by another request.
+	ErrBusy = errors.New("upload session busy")
+)
+
+// Session is the state of a resumable upload.
+type Session struct {
+	ID         string `json:"id"`
+	TagID      uint32 `json:"tagID"`
+	TagCreated bool   `json:"tagCreated"` // the tag was created for the session
+	BatchID    []byte `json:"batchID"`
+	Encrypt    bool   `json:"encrypt"`
+	Deferred   bool   `json:"deferred"`
+	Offset     int64  `json:"offset"`    // number of the bytes committed
+	State      []byte `json:"state"`     // state of the pipeline at the offset
+	CreatedAt  int64  `json:"createdAt"` // unix time in seconds
+	UpdatedAt  int64  `json:"updatedAt"` // unix time in seconds
+}
+
+// Options are the options of the Service.
+type Options struct {
+	// Expiry is the time after the last update of a session after which
+	// the session is removed. The sessions are checked every half of the
+	// expiry. The zero value disables the expiry of the sessions.
+	Expiry time.Duration
+}
+
+// Service keeps the sessions of the resumable uploads.
+type Service struct {
+	logger  log.Logger
+	store   storage.StateStorer
+	expiry  time.Duration
+	now     func() time.Time
+	metrics metrics
+
+	mu   sync.Mutex
+	busy map[string]struct{} // sessions acquired by the requests
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// New constructs a new Service.
+func New(store storage.StateStorer, logger log.Logger, o Options) *Service {
+	s := &Service{
+		logger:  logger.WithName(loggerName).Register(),
+		store:   store,
+		expiry:  o.Expiry,
+		now:     time.Now,
+		metrics: newMetrics(),
+		busy:    make(map[string]struct{}),
+		quit:    make(chan struct{}),
+	}
+
+	if o.Expiry > 0 {
+		s.wg.Add(1)
+		go s.worker(o.Expiry / 2)
+	}
+	return s
+}
+
+// Create creates a new session with the tag and the upload parameters
+// of the given session. The id, the offset, the state and the times of
+// the session are set by the service.
+func (s *Service) Create(session Session) (Session, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return Session{}, fmt.Errorf("session id: %w", err)
+	}
+
+	now := s.now().Unix()
+	session.ID = hex.EncodeToString(id)
+	session.Offset = 0
+	session.State = nil
+	session.CreatedAt = now
+	session.UpdatedAt = now
+	if err := s.store.Put(sessionKey(session.ID), session); err != nil {
+		return Session{}, err
+	}
+
+	s.metrics.CreatedCounter.Inc()
+	return session, nil
+}
+
+// Session returns the session with the id.
+func (s *Service) Session(id string) (Session, error) {
+	var session Session
+	if err := s.store.Get(sessionKey(id), &session); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return session, ErrNotFound
+		}
+		return session, err
+	}
+	return session, nil
+}
+
+// Sessions returns all sessions from the oldest to the newest.
+func (s *Service) Sessions() ([]Session, error) {
+	sessions := make([]Session, 0)
+	err := s.store.Iterate(sessionKeyPrefix, func(key, value []byte) (bool, error) {
+		var session Session
+		if err := json.Unmarshal(value, &session); err != nil {
+			return true, fmt.Errorf("invalid session %q: %w", key, err)
+		}
+		sessions = append(sessions, session)
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return sessions[i].CreatedAt < sessions[j].CreatedAt
+	})
+	return sessions, nil
+}
+
+// Acquire returns the session with the id for the exclusive use of the
+// caller until the returned release function is called. The ErrBusy error
+// is returned if the session is already acquired.
+func (s *Service) Acquire(id string) (Session, func(), error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.busy[id]; ok {
+		return Session{}, nil, ErrBusy
+	}
+	session, err := s.Session(id)
+	if err != nil {
+		return Session{}, nil, err
+	}
+
+	s.busy[id] = struct{}{}
+	var once sync.Once
+	return session, func() {
+		once.Do(func() {
+			s.mu.Lock()
+			delete(s.busy, id)
+			s.mu.Unlock()
+		})
+	}, nil
+}
+
+// Commit saves the offset and the pipeline state of the acquired session.
+func (s *Service) Commit(session *Session) error {
+	session.UpdatedAt = s.now().Unix()
+	return s.store.Put(sessionKey(session.ID), session)
+}
+
+// Finish removes the acquired session once the upload is finished.
+func (s *Service) Finish(id string) error {
+	if err := s.store.Delete(sessionKey(id)); err != nil {
+		return err
+	}
+	s.metrics.FinishedCounter.Inc()
+	return nil
+}
+
+// Delete removes the session. The ErrBusy error is
+// returned if the session is acquired.
+func (s *Service) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.busy[id]; ok {
+		return ErrBusy
+	}
+	if _, err := s.Session(id); err != nil {
+		return err
+	}
+	return s.store.Delete(sessionKey(id))
+}
+
+// Close stops the expiry of the sessions.
+func (s *Service) Close() error {
+	close(s.quit)
+	s.wg.Wait()
+	return nil
+}
+
+// worker periodically removes the expired
+// sessions until the service is closed.
+func (s *Service) worker(interval time.Duration) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-s.quit:
+			return
+		}
+
+		if err := s.expire(); err != nil {
+			s.logger.Error(err, "upload sessions expiry failed")
+		}
+	}
+}
+
+// expire removes the sessions which are not acquired and
+// were not updated within the expiry.
+func (s *Service) expire() error {
+	sessions, err := s.Sessions()
+	if err != nil {
+		return err
+	}
+
+	deadline := s.now().Add(-s.expiry).Unix()
+	for _, session := range sessions {
+		if session.UpdatedAt > deadline {
+			continue
+		}
+		switch err := s.Delete(session.ID); {
+		case errors.Is(err, ErrBusy), errors.Is(err, ErrNotFound):
+			continue
+		case err != nil:
+			return err
+		}
+		s.metrics.ExpiredCounter.Inc()
+		s.logger.Debug("upload session expired", "id", session.ID, "offset", session.Offset)
+	}
+	return nil
+}
+
+func sessionKey(id string) string {
+	return sessionKeyPrefix + id
+}
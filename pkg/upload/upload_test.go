@@ -0,0 +1,157 @@
+// Copyright 2022 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package upload_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/log"
+	statestore "github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/upload"
+)
+
+var batchID = bytes.Repeat([]byte{1}, 32)
+
+func newTestService(t *testing.T, now *time.Time) *upload.Service {
+	t.Helper()
+
+	s := upload.New(statestore.NewStateStore(), log.Noop, upload.Options{Expiry: time.Hour})
+	s.SetNow(func() time.Time { return *now })
+	t.Cleanup(func() {
+		if err := s.Close(); err != nil {
+			t.Fatal(err)
+		}
+	})
+	return s
+}
+
+func TestSession(t *testing.T) {
+	now := time.Unix(1000, 0)
+	s := newTestService(t, &now)
+
+	session, err := s.Create(upload.Session{TagID: 7, BatchID: batchID, Encrypt: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if session.ID == "" || session.TagID != 7 || !bytes.Equal(session.BatchID, batchID) || !session.Encrypt || session.Deferred {
+		t.Fatalf("unexpected session %+v", session)
+	}
+
+	acquired, release, err := s.Acquire(session.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := s.Acquire(session.ID); !errors.Is(err, upload.ErrBusy) {
+		t.Fatalf("got error %v, want %v", err, upload.ErrBusy)
+	}
+	if err := s.Delete(session.ID); !errors.Is(err, upload.ErrBusy) {
+		t.Fatalf("got error %v, want %v", err, upload.ErrBusy)
+	}
+
+	now = now.Add(time.Minute)
+	acquired.Offset = 4096
+	acquired.State = []byte("state")
+	if err := s.Commit(&acquired); err != nil {
+		t.Fatal(err)
+	}
+	release()
+	release()
+
+	got, err := s.Session(session.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Offset != 4096 || string(got.State) != "state" || got.UpdatedAt != now.Unix() || got.CreatedAt != 1000 {
+		t.Fatalf("unexpected session %+v", got)
+	}
+
+	if _, release, err = s.Acquire(session.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Finish(session.ID); err != nil {
+		t.Fatal(err)
+	}
+	release()
+
+	if _, err := s.Session(session.ID); !errors.Is(err, upload.ErrNotFound) {
+		t.Fatalf("got error %v, want %v", err, upload.ErrNotFound)
+	}
+	if _, _, err := s.Acquire(session.ID); !errors.Is(err, upload.ErrNotFound) {
+		t.Fatalf("got error %v, want %v", err, upload.ErrNotFound)
+	}
+	if err := s.Delete(session.ID); !errors.Is(err, upload.ErrNotFound) {
+		t.Fatalf("got error %v, want %v", err, upload.ErrNotFound)
+	}
+}
+
+func TestSessions(t *testing.T) {
+	now := time.Unix(1000, 0)
+	s := newTestService(t, &now)
+
+	var ids []string
+	for i := 0; i < 3; i++ {
+		session, err := s.Create(upload.Session{TagID: uint32(i), BatchID: batchID, Deferred: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, session.ID)
+		now = now.Add(time.Second)
+	}
+
+	if err := s.Delete(ids[1]); err != nil {
+		t.Fatal(err)
+	}
+
+	sessions, err := s.Sessions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 2 || sessions[0].ID != ids[0] || sessions[1].ID != ids[2] {
+		t.Fatalf("unexpected sessions %+v", sessions)
+	}
+}
+
+func TestExpire(t *testing.T) {
+	now := time.Unix(1000, 0)
+	s := newTestService(t, &now)
+
+	stale, err := s.Create(upload.Session{TagID: 1, BatchID: batchID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	busy, err := s.Create(upload.Session{TagID: 2, BatchID: batchID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, release, err := s.Acquire(busy.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release()
+
+	now = now.Add(30 * time.Minute)
+	fresh, err := s.Create(upload.Session{TagID: 3, BatchID: batchID})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now = now.Add(31 * time.Minute)
+	if err := upload.Expire(s); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.Session(stale.ID); !errors.Is(err, upload.ErrNotFound) {
+		t.Fatalf("got error %v, want %v", err, upload.ErrNotFound)
+	}
+	// the acquired sessions are in use and do not expire
+	for _, id := range []string{busy.ID, fresh.ID} {
+		if _, err := s.Session(id); err != nil {
+			t.Fatal(err)
+		}
+	}
+}